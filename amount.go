@@ -0,0 +1,113 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+// Number locales for csvFormat.NumberLocale: which character separates
+// decimals and which groups thousands.
+const (
+	numberLocaleEN = "en" // 1,234.56 (default)
+	numberLocaleEU = "eu" // 1.234,56 or 1 234,56
+)
+
+// normalizeNumberLocale maps config spellings onto a known locale, reporting
+// false for anything unrecognised. Empty means the default.
+func normalizeNumberLocale(raw string) (string, bool) {
+	switch strings.ToLower(strings.TrimSpace(raw)) {
+	case "", numberLocaleEN, "us", "uk", "au":
+		return numberLocaleEN, true
+	case numberLocaleEU, "de", "fr", "nl", "it", "es":
+		return numberLocaleEU, true
+	}
+	return "", false
+}
+
+// parseAmount converts a string like "1,234.56" to float64.
+func parseAmount(input string) (float64, error) {
+	return parseAmountLocale(input, numberLocaleEN)
+}
+
+// parseAmountLocale parses a bank amount in the given number locale. Beyond
+// plain signed numbers it accepts parenthesised negatives "(45.00)", a
+// trailing minus "45.00-", "CR"/"DR" suffixes (DR is a debit, so negative),
+// currency symbols or codes ("$", "€", "AUD") and space or apostrophe digit
+// grouping.
+func parseAmountLocale(input, locale string) (float64, error) {
+	s := strings.TrimSpace(input)
+	if s == "" {
+		return 0, fmt.Errorf("empty amount")
+	}
+	negative := false
+	if strings.HasPrefix(s, "(") && strings.HasSuffix(s, ")") {
+		negative = true
+		s = strings.TrimSpace(s[1 : len(s)-1])
+	}
+	upper := strings.ToUpper(s)
+	switch {
+	case strings.HasSuffix(upper, "DR"):
+		negative = true
+		s = strings.TrimSpace(s[:len(s)-2])
+	case strings.HasSuffix(upper, "CR"):
+		s = strings.TrimSpace(s[:len(s)-2])
+	}
+	s = stripCurrencyMarks(s)
+	switch {
+	case strings.HasSuffix(s, "-"):
+		negative = !negative
+		s = strings.TrimSpace(strings.TrimSuffix(s, "-"))
+	case strings.HasPrefix(s, "-"):
+		negative = !negative
+		s = strings.TrimSpace(strings.TrimPrefix(s, "-"))
+	case strings.HasPrefix(s, "+"):
+		s = strings.TrimSpace(strings.TrimPrefix(s, "+"))
+	}
+	// Currency may sit inside the sign: "-$45.00".
+	s = stripCurrencyMarks(s)
+
+	decimal, group := ".", ","
+	if locale == numberLocaleEU {
+		decimal, group = ",", "."
+	}
+	if strings.Contains(s, group) && strings.Contains(s, decimal) && strings.LastIndex(s, group) > strings.LastIndex(s, decimal) {
+		return 0, fmt.Errorf("separators don't match number_locale %q", locale)
+	}
+	s = strings.NewReplacer(group, "", " ", "", "\u00a0", "", "\u202f", "", "'", "").Replace(s)
+	s = strings.Replace(s, decimal, ".", 1)
+	if s == "" || strings.ContainsFunc(s, func(r rune) bool { return r != '.' && !unicode.IsDigit(r) }) {
+		return 0, fmt.Errorf("not a number")
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("not a number")
+	}
+	if negative {
+		v = -v
+	}
+	return v, nil
+}
+
+// stripCurrencyMarks trims currency symbols and three-letter currency codes
+// from either end of an amount.
+func stripCurrencyMarks(s string) string {
+	s = strings.TrimFunc(s, func(r rune) bool { return unicode.Is(unicode.Sc, r) || unicode.IsSpace(r) })
+	if len(s) > 3 && isCurrencyCode(s[:3]) {
+		s = strings.TrimSpace(s[3:])
+	}
+	if len(s) > 3 && isCurrencyCode(s[len(s)-3:]) {
+		s = strings.TrimSpace(s[:len(s)-3])
+	}
+	return strings.TrimFunc(s, func(r rune) bool { return unicode.Is(unicode.Sc, r) || unicode.IsSpace(r) })
+}
+
+func isCurrencyCode(s string) bool {
+	for _, r := range s {
+		if r < 'A' || r > 'Z' {
+			return false
+		}
+	}
+	return len(s) == 3
+}
@@ -51,14 +51,15 @@ const (
 	sectionSettingsViews         = 4
 	sectionSettingsImportHistory = 5
 	sectionSettingsFilters       = 6
+	sectionSettingsBackups       = 7
 )
 
 type transaction struct {
 	id            int
 	dateRaw       string
 	dateISO       string
-	amount        float64
-	fullAmount    float64 // non-zero for parent rows when amount is remainder after allocations
+	amount        money
+	fullAmount    money // non-zero for parent rows when amount is remainder after allocations
 	description   string
 	categoryID    *int
 	categoryName  string // denormalized from JOIN
@@ -70,6 +71,13 @@ type transaction struct {
 	isAllocation  bool
 	parentTxnID   int
 	allocationID  int
+	bankBalance   *money // bank-reported balance after this transaction
+
+	accountCurrency string // empty means the base currency
+	origAmount      *money // foreign amount before the bank converted it
+	origCurrency    string
+	status          string // txnStatusPosted or txnStatusPending
+	merchant        string // cleaned-up description; see txnMerchant
 }
 
 // ---------------------------------------------------------------------------
@@ -87,11 +95,16 @@ type ingestDoneMsg struct {
 	err   error
 	file  string
 
+	// sourcePath is the imported file on disk, for archiving out of the inbox.
+	sourcePath string
+
 	rulesApplied    bool
 	rulesTxnUpdated int
 	rulesCatChanges int
 	rulesTagChanges int
 	rulesFailed     int
+
+	quarantined int // rows saved to import_rejects
 }
 
 type importPreviewParseError struct {
@@ -99,6 +112,13 @@ type importPreviewParseError struct {
 	sourceLine int
 	field      string
 	message    string
+
+	// The rejected record as read from the file, plus whatever date, amount
+	// and description could be picked out of it, kept for quarantine.
+	raw         string
+	dateRaw     string
+	amountRaw   string
+	description string
 }
 
 type importPreviewRow struct {
@@ -106,14 +126,54 @@ type importPreviewRow struct {
 	sourceLine  int
 	dateRaw     string
 	dateISO     string
-	amount      float64
+	amount      money
 	description string
+	externalRef string // bank-issued reference (e.g. OFX FITID); "" when absent
+	balance     *money // bank-reported balance after this row, when the source has one
 	isDupe      bool
 
+	origAmount   *money // foreign amount before the bank's conversion
+	origCurrency string
+	status       string // txnStatusPosted or txnStatusPending
+	merchant     string // description after merchant_rules
+
+	// replacesPending is the existing pending transaction this posted row
+	// settles; importing it carries the pending row's edits over and
+	// deletes it.
+	replacesPending *importDupeMatch
+
+	// likelyDupe is set when the row fuzzily matches an existing transaction
+	// (see flagLikelyDuplicates). skipLikelyDupe is the user's per-row call,
+	// honoured when importing with duplicates skipped.
+	likelyDupe     *importDupeMatch
+	skipLikelyDupe bool
+
+	// Source-provided classification (QIF). categoryName is created on
+	// import when no category with that name exists.
+	categoryName string
+	notes        string
+	allocations  []importPreviewAllocation
+
 	previewCat      string
 	previewTags     []string
 	previewCatColor string
 	previewTagObjs  []tag
+
+	// Hand classification from the preview's pickers. An edited row imports
+	// with exactly editCategory (a category name, "" for none) and editTags,
+	// and rules leave it alone.
+	edited       bool
+	editCategory string
+	editCatColor string
+	editTags     []tag
+}
+
+// importPreviewAllocation is a source split written to
+// transaction_allocations when the row is imported.
+type importPreviewAllocation struct {
+	amount       money
+	categoryName string
+	note         string
 }
 
 type importPreviewLockedRules struct {
@@ -126,15 +186,48 @@ type importPreviewLockedRules struct {
 }
 
 type importPreviewSnapshot struct {
-	fileName    string
-	createdAt   time.Time
-	totalRows   int
-	newCount    int
-	dupeCount   int
-	errorCount  int
-	rows        []importPreviewRow
-	parseErrors []importPreviewParseError
-	lockedRules importPreviewLockedRules
+	fileName   string
+	sourcePath string // scanned file on disk
+	createdAt  time.Time
+	totalRows  int
+	newCount   int
+	dupeCount  int
+	errorCount int
+
+	// likelyDupeCount rows are neither new nor exact duplicates.
+	likelyDupeCount int
+	// pendingSettleCount rows (counted as new) replace pending transactions.
+	pendingSettleCount int
+	rows               []importPreviewRow
+	parseErrors        []importPreviewParseError
+	lockedRules        importPreviewLockedRules
+
+	// Statement ledger balance, when the source file reports one (OFX).
+	statementBalance     *money
+	statementBalanceDate string
+
+	// balanceGaps are breaks in the rows' running-balance chain.
+	balanceGaps []importBalanceGap
+
+	// File provenance, recorded on the imports row.
+	formatName  string // CSV format name, or "OFX"/"QIF"
+	contentHash string
+	fileSize    int64
+	dateFrom    string
+	dateTo      string
+
+	// Earlier imports of the same file, or into the same account over a
+	// date range that already covers this one. Importing asks for a second
+	// confirmation while either is non-empty.
+	sameFileImports   []importRecord
+	coveringImports   []importRecord
+	reimportConfirmed bool
+
+	// dateFormat is the layout rejected rows' dates were expected in.
+	dateFormat string
+	// quarantine imports the good rows and saves row-level parse errors to
+	// import_rejects instead of blocking the import.
+	quarantine bool
 
 	// Internal import context captured at preview-open.
 	accountID int
@@ -152,22 +245,43 @@ type refreshDoneMsg struct {
 	tags             []tag
 	txnTags          map[int][]tag
 	imports          []importRecord
+	importRejects    []importReject
+	backups          []dbBackup
 	accounts         []account
 	selectedAccounts map[int]bool
 	info             dbInfo
 	filterUsage      map[string]filterUsageState
+	fxRates          []fxRate
 	err              error
 }
 
 type filesLoadedMsg struct {
-	files []string
-	err   error
+	files   []string
+	matches map[string][]formatMatch // CSV file -> ranked format candidates
+	err     error
+}
+
+type importArchivedMsg struct {
+	file string
+	dest string
+	err  error
 }
 
 type clearDoneMsg struct {
 	err error
 }
 
+type importUndoSummaryMsg struct {
+	summary importUndoSummary
+	err     error
+}
+
+type importUndoneMsg struct {
+	filename string
+	removed  int
+	err      error
+}
+
 type txnSavedMsg struct {
 	err error
 }
@@ -299,11 +413,13 @@ const (
 	settSecChart
 	settSecDBImport
 	settSecImportHistory
+	settSecBackups
 	settSecCount
 )
 
 // Column mapping: left column has Categories (row 0), Tags (row 1), Rules (row 2).
-// Right column has Chart (row 0), Database (row 1), Import History (row 2).
+// Right column has Chart (row 0), Database (row 1), Import History (row 2),
+// Backups (row 3).
 const (
 	settColLeft  = 0
 	settColRight = 1
@@ -330,6 +446,8 @@ const (
 	confirmActionDeleteRule     settingsConfirmAction = "delete_rule"
 	confirmActionDeleteFilter   settingsConfirmAction = "delete_filter"
 	confirmActionClearDB        settingsConfirmAction = "clear_db"
+	confirmActionUndoImport     settingsConfirmAction = "undo_import"
+	confirmActionRestoreBackup  settingsConfirmAction = "restore_backup"
 )
 
 type drillReturnState struct {
@@ -349,6 +467,8 @@ type drillReturnState struct {
 
 type model struct {
 	db         *sql.DB
+	dbPath     string // database file of the open ledger
+	profile    string // named profile of the open ledger; "" is the default
 	status     string
 	statusErr  bool // true if status is an error (render in Red)
 	ready      bool
@@ -366,9 +486,19 @@ type model struct {
 	height     int
 
 	// Import flow (file picker + preview modal)
-	importPicking bool     // showing file picker
-	importFiles   []string // CSV files in basePath
-	importCursor  int      // cursor in file picker
+	importPicking      bool                     // showing file picker
+	importFiles        []string                 // importable files under basePath, relative slash paths
+	importCursor       int                      // cursor in file picker
+	importFileMatches  map[string][]formatMatch // ranked format candidates per CSV file
+	importFormatChoice int                      // selected candidate for the file under the cursor
+	importForWizard    bool                     // picker opened from the account modal: selecting a file starts the wizard
+	importWizardAcct   string                   // account name carried from the account modal into the wizard
+	importInbox        importInboxSettings      // where the picker scans; archives imported files when set
+	importQueue        *importQueueState        // active "import all pending" run, nil otherwise
+	importAllOnLoad    bool                     // start an import-all run once the file list loads
+
+	// CSV format wizard (nil when closed)
+	formatWizard *formatWizardState
 
 	// Import preview overlay state
 	importPreviewOpen      bool
@@ -378,6 +508,12 @@ type model struct {
 	importPreviewScroll    int
 	importPreviewSnapshot  *importPreviewSnapshot
 
+	// Category/tag picker opened on a preview row; importPreviewPickRow
+	// indexes snapshot.rows.
+	importPreviewPickingCategory bool
+	importPreviewPickingTags     bool
+	importPreviewPickRow         int
+
 	// Filter input state
 	filterInputMode   bool
 	filterInput       string
@@ -394,6 +530,11 @@ type model struct {
 	filterEditID      string
 	filterEditOrigID  string
 	filterEditName    string
+	// Ledger switcher
+	ledgerPicker      *pickerState
+	ledgerOrder       []string // profile per picker item; "" is the default ledger
+	dbOverride        string   // --db path, used whenever dbOverrideProfile is opened
+	dbOverrideProfile string
 	filterEditExpr    string
 	filterEditIDCur   int
 	filterEditNameCur int
@@ -434,8 +575,9 @@ type model struct {
 	detailRowValid       bool
 	detailCatCursor      int // cursor in category picker
 	detailNotes          string
-	detailNotesCursor    int    // cursor position inside detailNotes when editing
-	detailEditing        string // "category" or "notes" or ""
+	detailNotesCursor    int         // cursor position inside detailNotes when editing
+	detailEditing        string      // "category" or "notes" or ""
+	detailHistory        []txnChange // newest first, loaded when the modal opens
 	catPicker            *pickerState
 	catPickerFor         []int
 	tagPicker            *pickerState
@@ -457,6 +599,9 @@ type model struct {
 	tags            []tag
 	txnTags         map[int][]tag
 	imports         []importRecord
+	importRejects   []importReject
+	rejectsReview   *importRejectsReview // nil unless the rejected-rows modal is open
+	backups         []dbBackup           // snapshots of the open database, newest first
 	dbInfo          dbInfo
 	settSection     int                   // which section is focused (settSec*)
 	settColumn      int                   // 0 = left column, 1 = right column
@@ -556,6 +701,12 @@ type model struct {
 	// Configurable display
 	maxVisibleRows     int          // max rows shown in transaction table (5-50, default 20)
 	spendingWeekAnchor time.Weekday // week boundary marker for spending tracker (Sunday/Monday)
+	dupeMatch          dupeMatchSettings
+	baseCurrency       string       // base_currency; empty means reportingCurrency infers it
+	fxRatesFile        string       // rates CSV loaded by "fx:load"
+	fx                 *fxConverter // nil when every account uses the reporting currency
+	merchantRules      []merchantRule
+	merchants          *merchantNormalizer
 
 	// Jump mode
 	jumpModeActive    bool
@@ -563,12 +714,13 @@ type model struct {
 	focusedSection    int
 }
 
-func newModel() model {
+// newModel builds the model for a ledger profile; "" is the default ledger.
+func newModel(profile string) model {
 	cwd, cwdErr := os.Getwd()
 	if cwdErr != nil || cwd == "" {
 		cwd = "."
 	}
-	formats, appCfg, savedFilters, customPaneModes, cfgWarnings, fmtErr := loadAppConfigExtended()
+	formats, appCfg, savedFilters, customPaneModes, cfgWarnings, fmtErr := loadAppConfigExtended(profile)
 	status := ""
 	statusErr := false
 	if fmtErr != nil {
@@ -598,11 +750,16 @@ func newModel() model {
 		weekAnchor = time.Monday
 	}
 	m := model{
-		basePath:            cwd,
+		basePath:            appCfg.importInbox().root(cwd),
+		importInbox:         appCfg.importInbox(),
 		activeTab:           tabDashboard,
 		managerMode:         managerModeTransactions,
 		maxVisibleRows:      appCfg.RowsPerPage,
 		spendingWeekAnchor:  weekAnchor,
+		dupeMatch:           appCfg.dupeMatch(),
+		baseCurrency:        appCfg.BaseCurrency,
+		fxRatesFile:         appCfg.FXRatesFile,
+		merchantRules:       appCfg.MerchantRules,
 		dashTimeframe:       dashTimeframeThisMonth,
 		dashAnchorMonth:     time.Now().Format("2006-01"),
 		dashCustomStart:     appCfg.DashCustomStart,
@@ -629,6 +786,17 @@ func newModel() model {
 		jumpPreviousFocus:   sectionUnfocused,
 		focusedSection:      sectionUnfocused,
 	}
+	// normalizeSettings already dropped patterns that don't compile.
+	if merchants, err := newMerchantNormalizer(appCfg.MerchantRules); err == nil {
+		m.merchants = merchants
+	}
+	m.profile = profile
+	if dbPath, err := resolveDatabasePath(profile, "", appCfg); err != nil {
+		m.setError(fmt.Sprintf("Database path error: %v", err))
+		m.dbPath = defaultDBName
+	} else {
+		m.dbPath = dbPath
+	}
 	m.syncBudgetMonthFromDashboard()
 	return m
 }
@@ -638,8 +806,12 @@ func newModel() model {
 // ---------------------------------------------------------------------------
 
 func (m model) Init() tea.Cmd {
+	dbPath := m.dbPath
+	if dbPath == "" {
+		dbPath = defaultDBName
+	}
 	return func() tea.Msg {
-		db, err := openDB("transactions.db")
+		db, err := openDB(dbPath)
 		return dbReadyMsg{db: db, err: err}
 	}
 }
@@ -651,7 +823,7 @@ func (m model) View() string {
 		return status
 	}
 
-	header := renderHeader(appName, m.activeTab, m.width, m.accountFilterLabel())
+	header := renderHeader(appName, m.ledgerLabel(), m.activeTab, m.width, m.accountFilterLabel())
 	statusLine := m.renderStatus(m.status, m.statusErr)
 	footer := m.renderFooter(m.footerBindings())
 	if m.commandOpen && m.commandUIKind == commandUIKindColon {
@@ -678,8 +850,16 @@ func (m model) View() string {
 			return m.composeOverlay(header, body, statusLine, footer, detail)
 		}
 	}
+	if m.formatWizard != nil {
+		modal := renderFormatWizard(m.formatWizard, m.keys)
+		return m.composeOverlay(header, body, statusLine, footer, modal)
+	}
+	if m.rejectsReview != nil {
+		modal := renderImportRejects(m.importRejects, m.rejectsReview, m.keys)
+		return m.composeOverlay(header, body, statusLine, footer, modal)
+	}
 	if m.importPicking {
-		picker := renderFilePicker(m.importFiles, m.importCursor, m.keys)
+		picker := renderFilePicker(m.importFiles, m.importFileMatches, m.importCursor, m.importFormatChoice, m.keys)
 		return m.composeOverlay(header, body, statusLine, footer, picker)
 	}
 	if m.importPreviewOpen {
@@ -693,7 +873,14 @@ func (m model) View() string {
 			m.width,
 			m.keys,
 		)
-		return m.composeOverlay(header, body, statusLine, footer, preview)
+		view := m.composeOverlay(header, body, statusLine, footer, preview)
+		if m.importPreviewPickingCategory && m.catPicker != nil {
+			return m.stackOverlay(view, renderPicker(m.catPicker, min(56, m.width-10), m.keys, scopeCategoryPicker))
+		}
+		if m.importPreviewPickingTags && m.tagPicker != nil {
+			return m.stackOverlay(view, renderPicker(m.tagPicker, min(56, m.width-10), m.keys, scopeTagPicker))
+		}
+		return view
 	}
 	if m.catPicker != nil {
 		picker := renderPicker(m.catPicker, min(56, m.width-10), m.keys, scopeCategoryPicker)
@@ -711,6 +898,10 @@ func (m model) View() string {
 		picker := renderPicker(m.filterApplyPicker, min(64, m.width-10), m.keys, scopeFilterApplyPicker)
 		return m.composeOverlay(header, body, statusLine, footer, picker)
 	}
+	if m.ledgerPicker != nil {
+		picker := renderPicker(m.ledgerPicker, min(56, m.width-10), m.keys, scopeLedgerPicker)
+		return m.composeOverlay(header, body, statusLine, footer, picker)
+	}
 	if m.managerActionPicker != nil {
 		picker := renderPicker(m.managerActionPicker, min(56, m.width-10), m.keys, scopeManagerAccountAction)
 		return m.composeOverlay(header, body, statusLine, footer, picker)
@@ -985,7 +1176,12 @@ func (m model) normalizeBodyLine(line string) string {
 }
 
 func (m model) composeOverlay(header, body, statusLine, footer, content string) string {
-	baseView := m.composeFrame(header, body, statusLine, footer)
+	return m.stackOverlay(m.composeFrame(header, body, statusLine, footer), content)
+}
+
+// stackOverlay centres a modal over an already composed view, e.g. a picker
+// opened from inside the import preview.
+func (m model) stackOverlay(baseView, content string) string {
 	if m.height == 0 || m.width == 0 {
 		return baseView + "\n\n" + content
 	}
@@ -1323,7 +1519,7 @@ func (m model) managerRowsUnfiltered() []transaction {
 		parents[i].isAllocation = false
 		parents[i].parentTxnID = parents[i].id
 		parents[i].allocationID = 0
-		allocatedSum := 0.0
+		var allocatedSum money
 		for _, alloc := range m.allocationsByParent[parents[i].id] {
 			allocatedSum += alloc.amount
 		}
@@ -1395,7 +1591,7 @@ func (m model) getFilteredRows() []transaction {
 }
 
 func (m model) getDashboardRows() []transaction {
-	return filteredRows(m.rows, m.buildDashboardScopeFilter(), m.txnTags, sortByDate, false)
+	return m.fx.convertRows(filteredRows(m.rows, m.buildDashboardScopeFilter(), m.txnTags, sortByDate, false))
 }
 
 func (m model) dashboardChartRange(now time.Time) (time.Time, time.Time) {
@@ -1465,7 +1661,7 @@ func (m model) buildDashboardModeFilter(mode widgetMode) *filterNode {
 }
 
 func (m model) dashboardRowsForMode(mode widgetMode) []transaction {
-	return filteredRows(m.rows, m.buildDashboardModeFilter(mode), m.txnTags, sortByDate, false)
+	return m.fx.convertRows(filteredRows(m.rows, m.buildDashboardModeFilter(mode), m.txnTags, sortByDate, false))
 }
 
 func (m model) dashboardFocusedWidgetIndex() int {
@@ -0,0 +1,407 @@
+package main
+
+import (
+	"archive/tar"
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/pbkdf2"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+)
+
+// An archive is a passphrase-encrypted copy of a ledger for off-machine
+// backups: the database, the ledger's config.toml and the shared
+// keybindings.toml, as a tar sealed with AES-256-GCM. The file is
+//
+//	magic | PBKDF2 iterations (uint32) | salt | nonce | ciphertext
+//
+// with the key derived by PBKDF2-HMAC-SHA256 and the header bound to the
+// ciphertext as additional data.
+
+const (
+	archiveMagic         = "JMARCH01"
+	archiveKDFIterations = 600_000
+	archiveMaxIterations = 10_000_000
+	archiveSaltSize      = 16
+	archiveKeySize       = 32
+	archiveMinPassphrase = 8
+
+	archiveDBEntry          = "transactions.db"
+	archiveConfigEntry      = "config.toml"
+	archiveKeybindingsEntry = "keybindings.toml"
+)
+
+var errArchivePassphrase = errors.New("wrong passphrase or damaged archive")
+
+// archiveContents is the unsealed payload. config and keybindings are nil
+// when the ledger had no such file.
+type archiveContents struct {
+	db          []byte
+	config      []byte
+	keybindings []byte
+}
+
+func deriveArchiveKey(passphrase []byte, salt []byte, iterations int) ([]byte, error) {
+	key, err := pbkdf2.Key(sha256.New, string(passphrase), salt, iterations, archiveKeySize)
+	if err != nil {
+		return nil, fmt.Errorf("derive key: %w", err)
+	}
+	return key, nil
+}
+
+func archiveAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("init cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+// sealArchive encrypts plaintext under a key derived from passphrase with a
+// fresh salt and nonce.
+func sealArchive(plaintext, passphrase []byte) ([]byte, error) {
+	if len(passphrase) < archiveMinPassphrase {
+		return nil, fmt.Errorf("passphrase must be at least %d characters", archiveMinPassphrase)
+	}
+	salt := make([]byte, archiveSaltSize)
+	if _, err := rand.Read(salt); err != nil {
+		return nil, fmt.Errorf("generate salt: %w", err)
+	}
+	key, err := deriveArchiveKey(passphrase, salt, archiveKDFIterations)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := archiveAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("generate nonce: %w", err)
+	}
+	header := make([]byte, 0, len(archiveMagic)+4+len(salt)+len(nonce))
+	header = append(header, archiveMagic...)
+	header = binary.BigEndian.AppendUint32(header, archiveKDFIterations)
+	header = append(header, salt...)
+	header = append(header, nonce...)
+	return aead.Seal(header, nonce, plaintext, header), nil
+}
+
+// openArchive reverses sealArchive. A wrong passphrase and a tampered file
+// are indistinguishable and both return errArchivePassphrase.
+func openArchive(data, passphrase []byte) ([]byte, error) {
+	if len(data) < len(archiveMagic) || string(data[:len(archiveMagic)]) != archiveMagic {
+		return nil, fmt.Errorf("not a jaskmoney archive")
+	}
+	rest := data[len(archiveMagic):]
+	if len(rest) < 4+archiveSaltSize {
+		return nil, fmt.Errorf("archive header is truncated")
+	}
+	iterations := binary.BigEndian.Uint32(rest)
+	if iterations == 0 || iterations > archiveMaxIterations {
+		return nil, fmt.Errorf("archive has an invalid key derivation cost")
+	}
+	salt := rest[4 : 4+archiveSaltSize]
+	key, err := deriveArchiveKey(passphrase, salt, int(iterations))
+	if err != nil {
+		return nil, err
+	}
+	aead, err := archiveAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	headerLen := len(archiveMagic) + 4 + archiveSaltSize + aead.NonceSize()
+	if len(data) < headerLen+aead.Overhead() {
+		return nil, fmt.Errorf("archive header is truncated")
+	}
+	header := data[:headerLen]
+	nonce := header[headerLen-aead.NonceSize():]
+	plaintext, err := aead.Open(nil, nonce, data[headerLen:], header)
+	if err != nil {
+		return nil, errArchivePassphrase
+	}
+	return plaintext, nil
+}
+
+// ---------------------------------------------------------------------------
+// Export
+// ---------------------------------------------------------------------------
+
+// exportArchive writes an encrypted archive of db and the config files to
+// dest. The database is copied with VACUUM INTO so a running app's data is
+// captured consistently.
+func exportArchive(db *sql.DB, configFile, keybindingsFile, dest string, passphrase []byte) error {
+	tmpDir, err := os.MkdirTemp("", "jaskmoney-archive-")
+	if err != nil {
+		return fmt.Errorf("create temp dir: %w", err)
+	}
+	defer os.RemoveAll(tmpDir)
+	snapshot := filepath.Join(tmpDir, archiveDBEntry)
+	if _, err := db.Exec(`VACUUM INTO ?`, snapshot); err != nil {
+		return fmt.Errorf("copy database: %w", err)
+	}
+	var contents archiveContents
+	if contents.db, err = os.ReadFile(snapshot); err != nil {
+		return fmt.Errorf("read database copy: %w", err)
+	}
+	if contents.config, err = readOptionalFile(configFile); err != nil {
+		return fmt.Errorf("read config: %w", err)
+	}
+	if contents.keybindings, err = readOptionalFile(keybindingsFile); err != nil {
+		return fmt.Errorf("read keybindings: %w", err)
+	}
+
+	payload, err := packArchive(contents)
+	if err != nil {
+		return err
+	}
+	sealed, err := sealArchive(payload, passphrase)
+	if err != nil {
+		return err
+	}
+	if err := writeFileAtomic(dest, sealed, 0o600); err != nil {
+		return fmt.Errorf("write archive: %w", err)
+	}
+	return nil
+}
+
+func readOptionalFile(path string) ([]byte, error) {
+	if path == "" {
+		return nil, nil
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	return data, err
+}
+
+func writeFileAtomic(path string, data []byte, perm os.FileMode) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, perm); err != nil {
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		_ = os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+func packArchive(contents archiveContents) ([]byte, error) {
+	var buf bytes.Buffer
+	tw := tar.NewWriter(&buf)
+	now := time.Now()
+	entries := []struct {
+		name string
+		data []byte
+	}{
+		{archiveDBEntry, contents.db},
+		{archiveConfigEntry, contents.config},
+		{archiveKeybindingsEntry, contents.keybindings},
+	}
+	for _, e := range entries {
+		if e.data == nil {
+			continue
+		}
+		hdr := &tar.Header{Name: e.name, Mode: 0o600, Size: int64(len(e.data)), ModTime: now}
+		if err := tw.WriteHeader(hdr); err != nil {
+			return nil, fmt.Errorf("pack %s: %w", e.name, err)
+		}
+		if _, err := tw.Write(e.data); err != nil {
+			return nil, fmt.Errorf("pack %s: %w", e.name, err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		return nil, fmt.Errorf("pack archive: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// ---------------------------------------------------------------------------
+// Restore
+// ---------------------------------------------------------------------------
+
+// readArchive decrypts and unpacks the archive at path.
+func readArchive(path string, passphrase []byte) (archiveContents, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return archiveContents{}, fmt.Errorf("read archive: %w", err)
+	}
+	payload, err := openArchive(data, passphrase)
+	if err != nil {
+		return archiveContents{}, err
+	}
+	var contents archiveContents
+	tr := tar.NewReader(bytes.NewReader(payload))
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return archiveContents{}, fmt.Errorf("unpack archive: %w", err)
+		}
+		data, err := io.ReadAll(tr)
+		if err != nil {
+			return archiveContents{}, fmt.Errorf("unpack %s: %w", hdr.Name, err)
+		}
+		switch hdr.Name {
+		case archiveDBEntry:
+			contents.db = data
+		case archiveConfigEntry:
+			contents.config = data
+		case archiveKeybindingsEntry:
+			contents.keybindings = data
+		}
+	}
+	if len(contents.db) == 0 {
+		return archiveContents{}, fmt.Errorf("archive has no database")
+	}
+	return contents, nil
+}
+
+// checkArchiveDatabase opens a staged database read-only and rejects it
+// unless it is an intact jaskmoney database this build can open.
+func checkArchiveDatabase(path string) (int, error) {
+	staged, err := sql.Open("sqlite", "file:"+path+"?mode=ro")
+	if err != nil {
+		return 0, fmt.Errorf("open archived database: %w", err)
+	}
+	defer staged.Close()
+	var check string
+	if err := staged.QueryRow(`PRAGMA quick_check`).Scan(&check); err != nil {
+		return 0, fmt.Errorf("check archived database: %w", err)
+	}
+	if check != "ok" {
+		return 0, fmt.Errorf("archived database is corrupt: %s", check)
+	}
+	ver, err := currentSchemaVersion(staged)
+	if err != nil {
+		return 0, fmt.Errorf("read archived schema version: %w", err)
+	}
+	if ver == 0 {
+		return 0, fmt.Errorf("archived database has no jaskmoney schema")
+	}
+	if ver < oldestMigratableSchema {
+		// Opening it would rebuild it empty rather than migrate it.
+		return 0, fmt.Errorf("archive is from a jaskmoney too old to migrate (schema v%d, the oldest supported is v%d)", ver, oldestMigratableSchema)
+	}
+	if ver > schemaVersion {
+		return 0, fmt.Errorf("archive is from a newer jaskmoney (schema v%d, this build supports v%d)", ver, schemaVersion)
+	}
+	return ver, nil
+}
+
+// restoreArchive swaps the archived database in for db and writes the
+// archived config files over configFile and keybindingsFile, keeping the
+// previous ones as .bak. The restored config keeps the current
+// database_path, so the ledger goes on opening the database restored into.
+// Nothing is replaced until the archived schema version has been checked;
+// the current database is snapshotted first so the restore can be reverted
+// from Settings → Backups, and is put back if the archived database won't
+// open. db is closed and the reopened (and, for older archives, migrated)
+// database is returned.
+func restoreArchive(db *sql.DB, contents archiveContents, configFile, keybindingsFile string) (*sql.DB, error) {
+	dbPath, err := databaseFilePath(db)
+	if err != nil {
+		return nil, err
+	}
+	if dbPath == "" {
+		return nil, fmt.Errorf("cannot restore into an in-memory database")
+	}
+	staged := dbPath + ".restore"
+	if err := os.WriteFile(staged, contents.db, 0o600); err != nil {
+		return nil, fmt.Errorf("stage archived database: %w", err)
+	}
+	defer os.Remove(staged)
+	if _, err := checkArchiveDatabase(staged); err != nil {
+		return nil, err
+	}
+	config, err := archivedConfigForLedger(contents.config, configFile)
+	if err != nil {
+		return nil, fmt.Errorf("restore config: %w", err)
+	}
+	preRestore, err := snapshotDB(db, "pre-archive-restore")
+	if err != nil {
+		return nil, fmt.Errorf("snapshot before restore: %w", err)
+	}
+	if err := db.Close(); err != nil {
+		return nil, fmt.Errorf("close database: %w", err)
+	}
+	if err := os.Rename(staged, dbPath); err != nil {
+		reopened, _ := openDB(dbPath)
+		return reopened, fmt.Errorf("replace database: %w", err)
+	}
+	restored, err := openDB(dbPath)
+	if err != nil {
+		return revertToSnapshot(dbPath, preRestore, err)
+	}
+	if err := restoreArchiveFile(configFile, config); err != nil {
+		return restored, fmt.Errorf("restore config: %w", err)
+	}
+	if err := restoreArchiveFile(keybindingsFile, contents.keybindings); err != nil {
+		return restored, fmt.Errorf("restore keybindings: %w", err)
+	}
+	return restored, nil
+}
+
+// archivedConfigForLedger returns the archived config.toml with its
+// database_path replaced by the one in the config at path, which may point
+// somewhere else entirely on the machine the archive came from.
+func archivedConfigForLedger(data []byte, path string) ([]byte, error) {
+	if data == nil {
+		return nil, nil
+	}
+	current := ""
+	if path != "" && fileExists(path) {
+		existing, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read config: %w", err)
+		}
+		var cfg configFile
+		if _, err := toml.Decode(string(existing), &cfg); err == nil {
+			current = strings.TrimSpace(cfg.Settings.DatabasePath)
+		}
+	}
+	archived := configFile{Settings: defaultSettings()}
+	if err := toml.Unmarshal(data, &archived); err != nil {
+		return nil, fmt.Errorf("parse archived config.toml: %w", err)
+	}
+	if strings.TrimSpace(archived.Settings.DatabasePath) == current {
+		return data, nil
+	}
+	archived.Settings.DatabasePath = current
+	var buf bytes.Buffer
+	if err := toml.NewEncoder(&buf).Encode(archived); err != nil {
+		return nil, fmt.Errorf("encode config.toml: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func restoreArchiveFile(path string, data []byte) error {
+	if path == "" || data == nil {
+		return nil
+	}
+	if fileExists(path) {
+		if err := copyFile(path, path+".bak"); err != nil {
+			return err
+		}
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	return writeFileAtomic(path, data, 0o644)
+}
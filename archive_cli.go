@@ -0,0 +1,180 @@
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/charmbracelet/x/term"
+)
+
+const archiveUsage = `usage: jaskmoney archive export|restore <file> [--profile NAME] [--db PATH]`
+
+// archivePassphraseEnv supplies the passphrase for scripted backups instead
+// of prompting on the terminal.
+const archivePassphraseEnv = "JASKMONEY_ARCHIVE_PASSPHRASE"
+
+// passphraseFunc returns the archive passphrase. confirm asks for it twice,
+// for exports, so a typo doesn't seal an archive nobody can open.
+type passphraseFunc func(confirm bool) ([]byte, error)
+
+// archiveCLIOptions are the flags of `jaskmoney archive`.
+type archiveCLIOptions struct {
+	db      string
+	profile string
+}
+
+func parseArchiveArgs(args []string, stderr io.Writer) (archiveCLIOptions, string, string, error) {
+	var opts archiveCLIOptions
+	fs := flag.NewFlagSet("archive", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, archiveUsage)
+		fs.PrintDefaults()
+	}
+	fs.StringVar(&opts.profile, "profile", "", "use this ledger profile's config and database")
+	fs.StringVar(&opts.db, "db", "", "use this database file")
+
+	var positional []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return opts, "", "", err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			break
+		}
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+	if len(positional) != 2 || (positional[0] != "export" && positional[0] != "restore") {
+		fs.Usage()
+		return opts, "", "", fmt.Errorf("expected export or restore and one archive file")
+	}
+	return opts, positional[0], positional[1], nil
+}
+
+// runArchiveCommand is the `jaskmoney archive` entry point. It returns the
+// process exit code: 0 on success, 1 when the export or restore failed, 2
+// for usage and setup errors.
+func runArchiveCommand(args []string, dbPath string, passphrase passphraseFunc, stdout, stderr io.Writer) int {
+	opts, verb, file, err := parseArchiveArgs(args, stderr)
+	if err != nil {
+		if err != flag.ErrHelp {
+			fmt.Fprintln(stderr, "archive:", err)
+		}
+		return 2
+	}
+	profile := ""
+	if opts.profile != "" {
+		profile, err = normalizeProfileName(opts.profile)
+		if err != nil {
+			fmt.Fprintln(stderr, "archive:", err)
+			return 2
+		}
+	}
+	_, settings, _, _, _, err := loadAppConfigExtended(profile)
+	if err != nil {
+		fmt.Fprintln(stderr, "archive: load config:", err)
+		return 2
+	}
+	if opts.db != "" || dbPath == "" {
+		dbPath, err = resolveDatabasePath(profile, opts.db, settings)
+		if err != nil {
+			fmt.Fprintln(stderr, "archive:", err)
+			return 2
+		}
+	}
+	configFile, err := configPath(profile)
+	if err != nil {
+		fmt.Fprintln(stderr, "archive:", err)
+		return 2
+	}
+	keybindingsFile, err := keybindingsPath()
+	if err != nil {
+		fmt.Fprintln(stderr, "archive:", err)
+		return 2
+	}
+
+	pass, err := passphrase(verb == "export")
+	if err != nil {
+		fmt.Fprintln(stderr, "archive:", err)
+		return 2
+	}
+	if verb == "restore" {
+		// Decrypt before touching the database so a wrong passphrase
+		// doesn't leave a fresh empty database or a snapshot behind.
+		contents, err := readArchive(file, pass)
+		if err != nil {
+			fmt.Fprintln(stderr, "archive:", err)
+			return 1
+		}
+		db, err := openDB(dbPath)
+		if err != nil {
+			fmt.Fprintln(stderr, "archive: open database:", err)
+			return 2
+		}
+		restored, err := restoreArchive(db, contents, configFile, keybindingsFile)
+		if restored != nil {
+			defer restored.Close()
+		}
+		if err != nil {
+			fmt.Fprintln(stderr, "archive:", err)
+			return 1
+		}
+		fmt.Fprintf(stdout, "Restored %s into %s\n", file, dbPath)
+		return 0
+	}
+
+	db, err := openDB(dbPath)
+	if err != nil {
+		fmt.Fprintln(stderr, "archive: open database:", err)
+		return 2
+	}
+	defer db.Close()
+	if err := exportArchive(db, configFile, keybindingsFile, file, pass); err != nil {
+		fmt.Fprintln(stderr, "archive:", err)
+		return 1
+	}
+	fmt.Fprintf(stdout, "Exported %s to %s\n", dbPath, file)
+	return 0
+}
+
+// promptPassphrase reads the passphrase from $JASKMONEY_ARCHIVE_PASSPHRASE,
+// or from the terminal without echo.
+func promptPassphrase(stderr io.Writer) passphraseFunc {
+	return func(confirm bool) ([]byte, error) {
+		if env := os.Getenv(archivePassphraseEnv); env != "" {
+			return []byte(env), nil
+		}
+		fd := os.Stdin.Fd()
+		if !term.IsTerminal(fd) {
+			return nil, fmt.Errorf("no passphrase: set %s or run from a terminal", archivePassphraseEnv)
+		}
+		fmt.Fprint(stderr, "Archive passphrase: ")
+		pass, err := term.ReadPassword(fd)
+		fmt.Fprintln(stderr)
+		if err != nil {
+			return nil, fmt.Errorf("read passphrase: %w", err)
+		}
+		if !confirm {
+			return pass, nil
+		}
+		fmt.Fprint(stderr, "Repeat passphrase: ")
+		again, err := term.ReadPassword(fd)
+		fmt.Fprintln(stderr)
+		if err != nil {
+			return nil, fmt.Errorf("read passphrase: %w", err)
+		}
+		if !bytes.Equal(pass, again) {
+			return nil, fmt.Errorf("passphrases do not match")
+		}
+		if len(strings.TrimSpace(string(pass))) < archiveMinPassphrase {
+			return nil, fmt.Errorf("passphrase must be at least %d characters", archiveMinPassphrase)
+		}
+		return pass, nil
+	}
+}
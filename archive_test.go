@@ -0,0 +1,210 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var testArchivePassphrase = []byte("correct horse battery")
+
+func TestSealArchiveRoundTrip(t *testing.T) {
+	sealed, err := sealArchive([]byte("ledger"), testArchivePassphrase)
+	if err != nil {
+		t.Fatalf("sealArchive: %v", err)
+	}
+	if bytes.Contains(sealed, []byte("ledger")) {
+		t.Fatal("plaintext visible in sealed archive")
+	}
+	plain, err := openArchive(sealed, testArchivePassphrase)
+	if err != nil || string(plain) != "ledger" {
+		t.Fatalf("openArchive = %q, %v", plain, err)
+	}
+	if _, err := openArchive(sealed, []byte("wrong passphrase")); !errors.Is(err, errArchivePassphrase) {
+		t.Fatalf("wrong passphrase err = %v", err)
+	}
+	tampered := append([]byte(nil), sealed...)
+	tampered[len(archiveMagic)+2] ^= 0xff // iteration count is authenticated too
+	if _, err := openArchive(tampered, testArchivePassphrase); err == nil {
+		t.Fatal("tampered header accepted")
+	}
+	if _, err := sealArchive([]byte("ledger"), []byte("short")); err == nil {
+		t.Fatal("short passphrase accepted")
+	}
+}
+
+func TestExportAndRestoreArchive(t *testing.T) {
+	db, cleanup := testDB(t)
+	defer cleanup()
+	seedJournalTxns(t, db)
+	dir := t.TempDir()
+	configFile := filepath.Join(dir, "config.toml")
+	keybindingsFile := filepath.Join(dir, "keybindings.toml")
+	if err := os.WriteFile(configFile, []byte("# archived\n"), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	archive := filepath.Join(dir, "ledger.jmarch")
+	if err := exportArchive(db, configFile, keybindingsFile, archive, testArchivePassphrase); err != nil {
+		t.Fatalf("exportArchive: %v", err)
+	}
+
+	if err := clearAllData(db); err != nil {
+		t.Fatalf("clearAllData: %v", err)
+	}
+	if err := os.WriteFile(configFile, []byte("# edited\n"), 0o644); err != nil {
+		t.Fatalf("edit config: %v", err)
+	}
+	contents, err := readArchive(archive, testArchivePassphrase)
+	if err != nil {
+		t.Fatalf("readArchive: %v", err)
+	}
+	if contents.keybindings != nil {
+		t.Fatalf("missing keybindings file archived as %q", contents.keybindings)
+	}
+	restored, err := restoreArchive(db, contents, configFile, keybindingsFile)
+	if err != nil {
+		t.Fatalf("restoreArchive: %v", err)
+	}
+	defer restored.Close()
+	if n := countTxns(t, restored); n != 3 {
+		t.Fatalf("restored database has %d transactions, want 3", n)
+	}
+	if got, _ := os.ReadFile(configFile); string(got) != "# archived\n" {
+		t.Fatalf("config = %q, want the archived one", got)
+	}
+	if got, _ := os.ReadFile(configFile + ".bak"); string(got) != "# edited\n" {
+		t.Fatalf("config.bak = %q, want the replaced one", got)
+	}
+	if fileExists(keybindingsFile) {
+		t.Fatal("keybindings written though the archive had none")
+	}
+	path, _ := databaseFilePath(restored)
+	backups, _ := listBackups(path)
+	if len(backups) == 0 || backups[0].reason != "pre-archive-restore" {
+		t.Fatalf("backups = %+v, want a pre-archive-restore snapshot", backups)
+	}
+}
+
+func TestRestoreArchiveKeepsCurrentDatabasePath(t *testing.T) {
+	db, cleanup := testDB(t)
+	defer cleanup()
+	seedJournalTxns(t, db)
+	dir := t.TempDir()
+	configFile := filepath.Join(dir, "config.toml")
+	if err := os.WriteFile(configFile, []byte("[account.ANZ]\ndate_format = \"2/01/2006\"\n\n[settings]\ndatabase_path = \"/elsewhere/ledger.db\"\nrows_per_page = 33\n"), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	archive := filepath.Join(dir, "ledger.jmarch")
+	if err := exportArchive(db, configFile, "", archive, testArchivePassphrase); err != nil {
+		t.Fatalf("exportArchive: %v", err)
+	}
+	if err := os.WriteFile(configFile, []byte("[settings]\ndatabase_path = \"here.db\"\n"), 0o644); err != nil {
+		t.Fatalf("edit config: %v", err)
+	}
+	contents, err := readArchive(archive, testArchivePassphrase)
+	if err != nil {
+		t.Fatalf("readArchive: %v", err)
+	}
+	restored, err := restoreArchive(db, contents, configFile, "")
+	if err != nil {
+		t.Fatalf("restoreArchive: %v", err)
+	}
+	defer restored.Close()
+
+	data, err := os.ReadFile(configFile)
+	if err != nil {
+		t.Fatalf("read restored config: %v", err)
+	}
+	_, settings, _, _, _, _, err := parseConfigExt(data)
+	if err != nil {
+		t.Fatalf("parse restored config: %v", err)
+	}
+	if settings.DatabasePath != "here.db" || settings.RowsPerPage != 33 {
+		t.Fatalf("restored settings database_path=%q rows_per_page=%d, want here.db and the archived 33", settings.DatabasePath, settings.RowsPerPage)
+	}
+}
+
+func TestRestoreArchiveRejectsUnsupportedSchema(t *testing.T) {
+	cases := []struct {
+		version int
+		want    string
+	}{
+		{schemaVersion + 1, "newer"},
+		{oldestMigratableSchema - 1, "too old"},
+	}
+	for _, tc := range cases {
+		db, cleanup := testDB(t)
+		seedJournalTxns(t, db)
+		if _, err := db.Exec(`UPDATE schema_meta SET version = ?`, tc.version); err != nil {
+			t.Fatalf("set schema: %v", err)
+		}
+		archive := filepath.Join(t.TempDir(), "unsupported.jmarch")
+		if err := exportArchive(db, "", "", archive, testArchivePassphrase); err != nil {
+			t.Fatalf("exportArchive: %v", err)
+		}
+		if _, err := db.Exec(`UPDATE schema_meta SET version = ?`, schemaVersion); err != nil {
+			t.Fatalf("reset schema: %v", err)
+		}
+		if err := clearAllData(db); err != nil {
+			t.Fatalf("clearAllData: %v", err)
+		}
+
+		contents, err := readArchive(archive, testArchivePassphrase)
+		if err != nil {
+			t.Fatalf("readArchive: %v", err)
+		}
+		if _, err := restoreArchive(db, contents, "", ""); err == nil || !strings.Contains(err.Error(), tc.want) {
+			t.Fatalf("schema v%d: restoreArchive err = %v, want %q rejection", tc.version, err, tc.want)
+		}
+		// The open database was left alone.
+		if n := countTxns(t, db); n != 0 {
+			t.Fatalf("schema v%d: database has %d transactions after rejected restore, want 0", tc.version, n)
+		}
+		cleanup()
+	}
+}
+
+func TestArchiveCommandExportRestore(t *testing.T) {
+	useTestConfigHome(t)
+	dir := t.TempDir()
+	dbPath := filepath.Join(dir, "ledger.db")
+	defer os.RemoveAll(backupDir(dbPath))
+	db, err := openDB(dbPath)
+	if err != nil {
+		t.Fatalf("openDB: %v", err)
+	}
+	seedJournalTxns(t, db)
+	db.Close()
+
+	archive := filepath.Join(dir, "ledger.jmarch")
+	pass := func(bool) ([]byte, error) { return testArchivePassphrase, nil }
+	var stderr bytes.Buffer
+	if code := runArchiveCommand([]string{"export", archive}, dbPath, pass, io.Discard, &stderr); code != 0 {
+		t.Fatalf("export exit %d: %s", code, stderr.String())
+	}
+
+	other := filepath.Join(dir, "other.db")
+	defer os.RemoveAll(backupDir(other))
+	wrong := func(bool) ([]byte, error) { return []byte("not the passphrase"), nil }
+	if code := runArchiveCommand([]string{"restore", archive, "--db", other}, "", wrong, io.Discard, io.Discard); code != 1 {
+		t.Fatalf("wrong passphrase exit %d, want 1", code)
+	}
+	if fileExists(other) {
+		t.Fatal("failed restore created the target database")
+	}
+	if code := runArchiveCommand([]string{"restore", archive, "--db", other}, "", pass, io.Discard, &stderr); code != 0 {
+		t.Fatalf("restore exit %d: %s", code, stderr.String())
+	}
+	restored, err := openDB(other)
+	if err != nil {
+		t.Fatalf("open restored: %v", err)
+	}
+	defer restored.Close()
+	if n := countTxns(t, restored); n != 3 {
+		t.Fatalf("restored %d transactions, want 3", n)
+	}
+}
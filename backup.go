@@ -0,0 +1,283 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// backupRetention is how many snapshots are kept per database. Older ones
+// are pruned each time a new snapshot is taken.
+const backupRetention = 10
+
+const backupTimeLayout = "20060102-150405"
+
+// dbBackup is a snapshot file taken with VACUUM INTO before a migration or
+// destructive operation.
+type dbBackup struct {
+	path      string
+	name      string
+	reason    string
+	createdAt time.Time
+	size      int64
+	modTime   time.Time // orders snapshots taken in the same second
+}
+
+// databaseFilePath returns the file behind db's main schema, or "" for an
+// in-memory database.
+func databaseFilePath(db *sql.DB) (string, error) {
+	rows, err := db.Query(`PRAGMA database_list`)
+	if err != nil {
+		return "", fmt.Errorf("list databases: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var seq int
+		var name, file string
+		if err := rows.Scan(&seq, &name, &file); err != nil {
+			return "", fmt.Errorf("scan database list: %w", err)
+		}
+		if name == "main" {
+			return file, nil
+		}
+	}
+	return "", rows.Err()
+}
+
+// backupDir is where snapshots of the database at dbPath are written:
+// "transactions.db" keeps them in "transactions-backups" beside it.
+func backupDir(dbPath string) string {
+	return filepath.Join(filepath.Dir(dbPath), backupStem(dbPath)+"-backups")
+}
+
+func backupStem(dbPath string) string {
+	base := filepath.Base(dbPath)
+	return strings.TrimSuffix(base, filepath.Ext(base))
+}
+
+// snapshotDB writes a timestamped copy of the database with VACUUM INTO and
+// prunes old snapshots. It returns "" without writing anything for
+// in-memory databases and databases with no tables yet.
+func snapshotDB(db *sql.DB, reason string) (string, error) {
+	dbPath, err := databaseFilePath(db)
+	if err != nil || dbPath == "" {
+		return "", err
+	}
+	var tables int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table'`).Scan(&tables); err != nil {
+		return "", fmt.Errorf("count tables: %w", err)
+	}
+	if tables == 0 {
+		return "", nil
+	}
+
+	dir := backupDir(dbPath)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return "", fmt.Errorf("create backup directory: %w", err)
+	}
+	name := fmt.Sprintf("%s-%s-%s", backupStem(dbPath), time.Now().Format(backupTimeLayout), backupReasonSlug(reason))
+	path := filepath.Join(dir, name+".db")
+	for n := 2; fileExists(path); n++ {
+		path = filepath.Join(dir, fmt.Sprintf("%s-%d.db", name, n))
+	}
+	if _, err := db.Exec(`VACUUM INTO ?`, path); err != nil {
+		return "", fmt.Errorf("snapshot database: %w", err)
+	}
+	if err := pruneBackups(dbPath, backupRetention); err != nil {
+		return path, err
+	}
+	return path, nil
+}
+
+func backupReasonSlug(reason string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(strings.TrimSpace(reason)) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			b.WriteRune(r)
+		default:
+			b.WriteByte('-')
+		}
+	}
+	if slug := strings.Trim(b.String(), "-"); slug != "" {
+		return slug
+	}
+	return "manual"
+}
+
+// listBackups returns the snapshots of the database at dbPath, newest first.
+func listBackups(dbPath string) ([]dbBackup, error) {
+	if dbPath == "" {
+		return nil, nil
+	}
+	dir := backupDir(dbPath)
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read backup directory: %w", err)
+	}
+	prefix := backupStem(dbPath) + "-"
+	var out []dbBackup
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasPrefix(name, prefix) || filepath.Ext(name) != ".db" {
+			continue
+		}
+		rest := strings.TrimSuffix(strings.TrimPrefix(name, prefix), ".db")
+		if len(rest) < len(backupTimeLayout)+2 {
+			continue
+		}
+		createdAt, err := time.ParseInLocation(backupTimeLayout, rest[:len(backupTimeLayout)], time.Local)
+		if err != nil {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			return nil, fmt.Errorf("stat backup %s: %w", name, err)
+		}
+		out = append(out, dbBackup{
+			path:      filepath.Join(dir, name),
+			name:      name,
+			reason:    rest[len(backupTimeLayout)+1:],
+			createdAt: createdAt,
+			size:      info.Size(),
+			modTime:   info.ModTime(),
+		})
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		if !out[i].createdAt.Equal(out[j].createdAt) {
+			return out[i].createdAt.After(out[j].createdAt)
+		}
+		return out[i].modTime.After(out[j].modTime)
+	})
+	return out, nil
+}
+
+// pruneBackups deletes all but the newest keep snapshots.
+func pruneBackups(dbPath string, keep int) error {
+	backups, err := listBackups(dbPath)
+	if err != nil {
+		return err
+	}
+	for i := keep; i < len(backups); i++ {
+		if err := os.Remove(backups[i].path); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("prune backup %s: %w", backups[i].name, err)
+		}
+	}
+	return nil
+}
+
+// restoreBackup replaces the database with a snapshot. The current database
+// is snapshotted first so the restore itself can be undone. The reopened
+// (and, for older snapshots, migrated) database is returned; db is left
+// open for the caller to close once nothing uses it any more. If the
+// restored file won't open, the pre-restore snapshot is put back and a
+// handle on it is returned along with the error.
+func restoreBackup(db *sql.DB, backup dbBackup) (*sql.DB, error) {
+	dbPath, err := databaseFilePath(db)
+	if err != nil {
+		return nil, err
+	}
+	if dbPath == "" {
+		return nil, fmt.Errorf("cannot restore into an in-memory database")
+	}
+	// Stage the copy first: the pre-restore snapshot may prune the backup
+	// being restored.
+	staged := dbPath + ".restore"
+	if err := copyFile(backup.path, staged); err != nil {
+		return nil, fmt.Errorf("stage backup %s: %w", backup.name, err)
+	}
+	defer os.Remove(staged)
+	preRestore, err := snapshotDB(db, "pre-restore")
+	if err != nil {
+		return nil, fmt.Errorf("snapshot before restore: %w", err)
+	}
+
+	// Hold the write lock across the swap so no write through db is
+	// mid-commit, with its journal beside the file, when the restored
+	// database takes its place.
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("lock database: %w", err)
+	}
+	defer conn.Close()
+	if _, err := conn.ExecContext(ctx, `BEGIN EXCLUSIVE`); err != nil {
+		return nil, fmt.Errorf("lock database: %w", err)
+	}
+	defer conn.ExecContext(ctx, `ROLLBACK`) //nolint:errcheck
+	if err := os.Rename(staged, dbPath); err != nil {
+		return nil, fmt.Errorf("replace database: %w", err)
+	}
+	restored, err := openDB(dbPath)
+	if err != nil {
+		return revertToSnapshot(dbPath, preRestore, err)
+	}
+	return restored, nil
+}
+
+// revertToSnapshot puts the pre-restore snapshot back at dbPath after the
+// restored database failed to open, so callers don't keep a handle on the
+// replaced file. The reopened database is returned with openErr.
+func revertToSnapshot(dbPath, snapshot string, openErr error) (*sql.DB, error) {
+	if snapshot == "" {
+		return nil, fmt.Errorf("open restored database: %w", openErr)
+	}
+	staged := dbPath + ".revert"
+	if err := copyFile(snapshot, staged); err != nil {
+		return nil, fmt.Errorf("open restored database: %w; revert failed: %v", openErr, err)
+	}
+	if err := os.Rename(staged, dbPath); err != nil {
+		os.Remove(staged)
+		return nil, fmt.Errorf("open restored database: %w; revert failed: %v", openErr, err)
+	}
+	reverted, err := openDB(dbPath)
+	if err != nil {
+		return nil, fmt.Errorf("open restored database: %w; revert failed: %v", openErr, err)
+	}
+	return reverted, fmt.Errorf("open restored database: %w; the previous database was put back", openErr)
+}
+
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	out, err := os.Create(dst)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+// ---------------------------------------------------------------------------
+// Tea commands
+// ---------------------------------------------------------------------------
+
+type backupRestoredMsg struct {
+	db     *sql.DB
+	backup dbBackup
+	err    error
+}
+
+func restoreBackupCmd(db *sql.DB, backup dbBackup) tea.Cmd {
+	return func() tea.Msg {
+		restored, err := restoreBackup(db, backup)
+		return backupRestoredMsg{db: restored, backup: backup, err: err}
+	}
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func countTxns(t *testing.T, db *sql.DB) int {
+	t.Helper()
+	var n int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM transactions`).Scan(&n); err != nil {
+		t.Fatalf("count transactions: %v", err)
+	}
+	return n
+}
+
+func TestClearAllDataSnapshotsFirst(t *testing.T) {
+	db, cleanup := testDB(t)
+	defer cleanup()
+	seedJournalTxns(t, db)
+	if err := clearAllData(db); err != nil {
+		t.Fatalf("clearAllData: %v", err)
+	}
+
+	path, err := databaseFilePath(db)
+	if err != nil {
+		t.Fatalf("databaseFilePath: %v", err)
+	}
+	backups, err := listBackups(path)
+	if err != nil {
+		t.Fatalf("listBackups: %v", err)
+	}
+	if len(backups) != 1 || backups[0].reason != "clear-all" || backups[0].size == 0 {
+		t.Fatalf("backups = %+v, want one clear-all snapshot", backups)
+	}
+	snap, err := sql.Open("sqlite", backups[0].path)
+	if err != nil {
+		t.Fatalf("open snapshot: %v", err)
+	}
+	defer snap.Close()
+	if n := countTxns(t, snap); n != 3 {
+		t.Fatalf("snapshot has %d transactions, want 3", n)
+	}
+}
+
+func TestSnapshotRetentionKeepsNewest(t *testing.T) {
+	db, cleanup := testDB(t)
+	defer cleanup()
+	var paths []string
+	for i := 0; i < backupRetention+2; i++ {
+		p, err := snapshotDB(db, "manual")
+		if err != nil {
+			t.Fatalf("snapshotDB %d: %v", i, err)
+		}
+		paths = append(paths, p)
+	}
+	path, _ := databaseFilePath(db)
+	backups, err := listBackups(path)
+	if err != nil {
+		t.Fatalf("listBackups: %v", err)
+	}
+	if len(backups) != backupRetention {
+		t.Fatalf("kept %d snapshots, want %d", len(backups), backupRetention)
+	}
+	if backups[0].path != paths[len(paths)-1] {
+		t.Fatalf("newest snapshot = %s, want %s", backups[0].path, paths[len(paths)-1])
+	}
+	kept := make(map[string]bool, len(backups))
+	for _, b := range backups {
+		kept[b.path] = true
+	}
+	for _, p := range paths[2:] {
+		if !kept[p] {
+			t.Fatalf("recent snapshot %s was pruned", p)
+		}
+	}
+}
+
+func TestMigrationSnapshotsOldSchema(t *testing.T) {
+	f, err := os.CreateTemp("", "jaskmoney-snap-*.db")
+	if err != nil {
+		t.Fatalf("create temp: %v", err)
+	}
+	path := f.Name()
+	f.Close()
+	defer os.Remove(path)
+	defer os.RemoveAll(backupDir(path))
+
+	db, err := openDB(path)
+	if err != nil {
+		t.Fatalf("openDB: %v", err)
+	}
+	if backups, _ := listBackups(path); len(backups) != 0 {
+		t.Fatalf("fresh database snapshotted: %+v", backups)
+	}
+	if _, err := db.Exec(`UPDATE schema_meta SET version = 17`); err != nil {
+		t.Fatalf("downgrade: %v", err)
+	}
+	db.Close()
+
+	db2, err := openDB(path)
+	if err != nil {
+		t.Fatalf("openDB upgrade: %v", err)
+	}
+	defer db2.Close()
+	backups, err := listBackups(path)
+	if err != nil {
+		t.Fatalf("listBackups: %v", err)
+	}
+	if len(backups) != 1 || backups[0].reason != "pre-migrate-v17" {
+		t.Fatalf("backups = %+v, want a pre-migrate-v17 snapshot", backups)
+	}
+}
+
+func TestRestoreBackupReplacesDatabase(t *testing.T) {
+	db, cleanup := testDB(t)
+	defer cleanup()
+	seedJournalTxns(t, db)
+	if err := clearAllData(db); err != nil {
+		t.Fatalf("clearAllData: %v", err)
+	}
+	path, _ := databaseFilePath(db)
+	backups, _ := listBackups(path)
+	if len(backups) != 1 {
+		t.Fatalf("backups = %+v, want one", backups)
+	}
+
+	restored, err := restoreBackup(db, backups[0])
+	if err != nil {
+		t.Fatalf("restoreBackup: %v", err)
+	}
+	defer restored.Close()
+	if n := countTxns(t, restored); n != 3 {
+		t.Fatalf("restored database has %d transactions, want 3", n)
+	}
+	after, _ := listBackups(path)
+	if len(after) != 2 || after[0].reason != "pre-restore" {
+		t.Fatalf("backups after restore = %+v, want a pre-restore snapshot on top", after)
+	}
+}
+
+func TestRestoreBackupPutsDatabaseBackWhenRestoredFileWontOpen(t *testing.T) {
+	db, cleanup := testDB(t)
+	defer cleanup()
+	seedJournalTxns(t, db)
+	bad := filepath.Join(t.TempDir(), "bad.db")
+	if err := os.WriteFile(bad, []byte("not a database"), 0o644); err != nil {
+		t.Fatalf("write bad backup: %v", err)
+	}
+
+	reverted, err := restoreBackup(db, dbBackup{path: bad, name: "bad.db"})
+	if err == nil || reverted == nil {
+		t.Fatalf("restoreBackup = %v, %v; want the reverted database and an error", reverted, err)
+	}
+	defer reverted.Close()
+	if n := countTxns(t, reverted); n != 3 {
+		t.Fatalf("reverted database has %d transactions, want 3", n)
+	}
+}
+
+func TestSettingsBackupsRestoreNeedsConfirm(t *testing.T) {
+	m, cleanup := testPhase5Model(t)
+	defer cleanup()
+	if err := clearAllData(m.db); err != nil {
+		t.Fatalf("clearAllData: %v", err)
+	}
+	path, _ := databaseFilePath(m.db)
+	m.backups, _ = listBackups(path)
+	m.activeTab = tabSettings
+	m.settColumn = settColRight
+	m.settSection = settSecBackups
+	m.settActive = true
+
+	restoreKey := keyMsg(m.primaryActionKey(scopeSettingsActiveBackups, actionRestoreBackup, "r"))
+	next, _ := m.Update(restoreKey)
+	armed := next.(model)
+	if armed.confirmAction != confirmActionRestoreBackup || !strings.Contains(armed.status, "clear-all") {
+		t.Fatalf("confirm = %q status = %q, want restore armed", armed.confirmAction, armed.status)
+	}
+
+	next, cmd := armed.Update(restoreKey)
+	if cmd == nil {
+		t.Fatal("expected restore command on confirm")
+	}
+	msg, ok := cmd().(backupRestoredMsg)
+	if !ok || msg.err != nil {
+		t.Fatalf("restore msg = %+v, want success", msg)
+	}
+	defer msg.db.Close()
+	old := m.db
+	got, _ := next.(model).Update(msg)
+	if n := countTxns(t, got.(model).db); n != 3 {
+		t.Fatalf("model database has %d transactions after restore, want 3", n)
+	}
+	if err := old.Ping(); err == nil {
+		t.Fatal("old database handle still open after the swap")
+	}
+}
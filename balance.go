@@ -0,0 +1,102 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"slices"
+)
+
+// importBalanceGap is a break in a file's running-balance chain: the bank
+// balance after `after` plus the next row's amount doesn't reach the balance
+// reported on `before`, so transactions are missing between them.
+type importBalanceGap struct {
+	afterDate   string
+	afterDesc   string
+	beforeDate  string
+	beforeDesc  string
+	unexplained money // before.balance - (after.balance + before.amount)
+}
+
+func (g importBalanceGap) String() string {
+	return fmt.Sprintf("missing transactions between %s %s and %s %s (%s unaccounted)",
+		g.afterDate, g.afterDesc, g.beforeDate, g.beforeDesc, formatMoney(g.unexplained))
+}
+
+// balanceLink is one row in chain order, reduced to what the check needs.
+type balanceLink struct {
+	date    string
+	desc    string
+	amount  money
+	balance money
+}
+
+// checkBalanceChain walks the rows that report a bank balance and returns
+// every place where previous balance + amount != reported balance. Exports
+// list rows oldest-first or newest-first, so both orders are tried and the
+// one with fewer breaks wins (date order breaks ties). The earliest row is
+// also checked against the latest stored balance dated before it.
+func checkBalanceChain(db *sql.DB, rows []importPreviewRow, accountID int) ([]importBalanceGap, error) {
+	var links []balanceLink
+	for _, row := range rows {
+		if row.balance == nil {
+			continue
+		}
+		links = append(links, balanceLink{date: row.dateISO, desc: row.description, amount: row.amount, balance: *row.balance})
+	}
+	if len(links) == 0 {
+		return nil, nil
+	}
+
+	forward := balanceChainGaps(links)
+	reversed := slices.Clone(links)
+	slices.Reverse(reversed)
+	backward := balanceChainGaps(reversed)
+	chain, gaps := links, forward
+	if len(backward) < len(forward) || (len(backward) == len(forward) && links[0].date > links[len(links)-1].date) {
+		chain, gaps = reversed, backward
+	}
+
+	first := chain[0]
+	var prev balanceLink
+	err := db.QueryRow(`
+		SELECT date_iso, description, amount, bank_balance
+		FROM transactions
+		WHERE account_id = ? AND bank_balance IS NOT NULL AND date_iso < ?
+		ORDER BY date_iso DESC, id DESC
+		LIMIT 1
+	`, accountID, first.date).Scan(&prev.date, &prev.desc, &prev.amount, &prev.balance)
+	if err == sql.ErrNoRows {
+		return gaps, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("load previous bank balance: %w", err)
+	}
+	if gap, broken := balanceLinkGap(prev, first); broken {
+		gaps = append([]importBalanceGap{gap}, gaps...)
+	}
+	return gaps, nil
+}
+
+func balanceChainGaps(chain []balanceLink) []importBalanceGap {
+	var gaps []importBalanceGap
+	for i := 1; i < len(chain); i++ {
+		if gap, broken := balanceLinkGap(chain[i-1], chain[i]); broken {
+			gaps = append(gaps, gap)
+		}
+	}
+	return gaps
+}
+
+func balanceLinkGap(after, before balanceLink) (importBalanceGap, bool) {
+	diff := before.balance - (after.balance + before.amount)
+	if diff == 0 {
+		return importBalanceGap{}, false
+	}
+	return importBalanceGap{
+		afterDate:   after.date,
+		afterDesc:   after.desc,
+		beforeDate:  before.date,
+		beforeDesc:  before.desc,
+		unexplained: diff,
+	}, true
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func balanceRow(date, desc string, amount, balance float64) importPreviewRow {
+	bal := moneyFromFloat(balance)
+	return importPreviewRow{dateISO: date, description: desc, amount: moneyFromFloat(amount), balance: &bal}
+}
+
+func TestCheckBalanceChainReportsGapsInEitherRowOrder(t *testing.T) {
+	db, cleanup := testDB(t)
+	defer cleanup()
+	accountID, err := insertAccount(db, "ANZ", "debit", true)
+	if err != nil {
+		t.Fatalf("insertAccount: %v", err)
+	}
+
+	// Newest first, as many banks export; the -30.00 row between the first
+	// two is missing.
+	rows := []importPreviewRow{
+		balanceRow("2026-02-05", "RENT", -500, 420),
+		balanceRow("2026-02-04", "WOOLWORTHS", -50, 920),
+		balanceRow("2026-02-02", "SALARY", 1000, 1000),
+		{dateISO: "2026-02-01", description: "NO BALANCE", amount: -100},
+	}
+	gaps, err := checkBalanceChain(db, rows, accountID)
+	if err != nil {
+		t.Fatalf("checkBalanceChain: %v", err)
+	}
+	if len(gaps) != 1 {
+		t.Fatalf("gaps = %+v, want 1", gaps)
+	}
+	g := gaps[0]
+	if g.afterDesc != "SALARY" || g.beforeDesc != "WOOLWORTHS" || g.unexplained != -3000 {
+		t.Fatalf("gap = %+v", g)
+	}
+	if !strings.Contains(g.String(), "missing transactions between 2026-02-02 SALARY and 2026-02-04 WOOLWORTHS") {
+		t.Fatalf("gap text = %q", g.String())
+	}
+
+	// A stored earlier balance that doesn't lead into the file is a gap too.
+	if _, err := db.Exec(`INSERT INTO transactions (date_raw, date_iso, amount, description, account_id, bank_balance)
+		VALUES ('2026-01-30', '2026-01-30', -100000, 'OLD', ?, 1000)`, accountID); err != nil {
+		t.Fatalf("seed stored balance: %v", err)
+	}
+	gaps, err = checkBalanceChain(db, rows[1:3], accountID)
+	if err != nil {
+		t.Fatalf("checkBalanceChain with history: %v", err)
+	}
+	if len(gaps) != 2 || gaps[0].afterDesc != "OLD" || gaps[0].beforeDesc != "SALARY" {
+		t.Fatalf("gaps with history = %+v", gaps)
+	}
+}
+
+func TestScanDupesCmdStoresBankBalanceFromBalanceColumn(t *testing.T) {
+	db, cleanup := testDB(t)
+	defer cleanup()
+	if _, err := insertAccount(db, "ANZ", "debit", true); err != nil {
+		t.Fatalf("insertAccount: %v", err)
+	}
+	balanceCol := 3
+	format := testANZFormat()
+	format.Account = "ANZ"
+	format.ImportPrefix = "anz"
+	format.DescJoin = false
+	format.BalanceCol = &balanceCol
+
+	dir := t.TempDir()
+	file := "ANZ-balance.csv"
+	csv := "3/02/2026,-20.00,COFFEE,\"1,080.00\"\n5/02/2026,-30.00,LUNCH,1000.00\n"
+	if err := os.WriteFile(filepath.Join(dir, file), []byte(csv), 0o644); err != nil {
+		t.Fatalf("write csv: %v", err)
+	}
+	preview, ok := scanDupesCmd(db, file, dir, []csvFormat{format}, nil, defaultSettings().dupeMatch())().(importPreviewMsg)
+	if !ok || preview.err != nil || preview.snapshot == nil {
+		t.Fatalf("scanDupesCmd: ok=%v err=%v", ok, preview.err)
+	}
+	if len(preview.snapshot.balanceGaps) != 1 {
+		t.Fatalf("balanceGaps = %+v, want 1 (1080 - 30 != 1000)", preview.snapshot.balanceGaps)
+	}
+	if view := renderImportPreview(preview.snapshot, true, true, 0, 0, 10, 120, NewKeyRegistry()); !strings.Contains(view, "missing transactions between") {
+		t.Fatalf("preview missing balance gap:\n%s", view)
+	}
+
+	done, ok := ingestSnapshotCmd(db, preview.snapshot, true, false)().(ingestDoneMsg)
+	if !ok || done.err != nil {
+		t.Fatalf("ingestSnapshotCmd: ok=%v err=%v", ok, done.err)
+	}
+	rows, err := loadRows(db)
+	if err != nil {
+		t.Fatalf("loadRows: %v", err)
+	}
+	if len(rows) != 2 || rows[1].bankBalance == nil || *rows[1].bankBalance != 108000 {
+		t.Fatalf("stored rows = %+v", rows)
+	}
+}
@@ -11,21 +11,21 @@ import (
 type categoryBudget struct {
 	id         int
 	categoryID int
-	amount     float64
+	amount     money
 }
 
 type budgetOverride struct {
 	id       int
 	budgetID int
 	monthKey string
-	amount   float64
+	amount   money
 }
 
 type spendingTarget struct {
 	id            int
 	name          string
 	savedFilterID string
-	amount        float64
+	amount        money
 	periodType    string
 }
 
@@ -33,25 +33,25 @@ type targetOverride struct {
 	id        int
 	targetID  int
 	periodKey string
-	amount    float64
+	amount    money
 }
 
 type budgetLine struct {
 	categoryID    int
 	categoryName  string
 	categoryColor string
-	budgeted      float64
-	spent         float64
-	remaining     float64
+	budgeted      money
+	spent         money
+	remaining     money
 	overBudget    bool
 }
 
 type targetLine struct {
 	targetID   int
 	name       string
-	budgeted   float64
-	spent      float64
-	remaining  float64
+	budgeted   money
+	spent      money
+	remaining  money
 	overBudget bool
 	periodType string
 	periodKey  string
@@ -102,12 +102,14 @@ func accountFilterIDs(accountFilter map[int]bool) []int {
 	return ids
 }
 
-func queryEffectiveSpendByCategory(db *sql.DB, startISO, endISO string, accountFilter map[int]bool) (map[int]float64, error) {
+// queryEffectiveSpendByCategory sums spend per category after allocations,
+// converting each account's spend to the base currency through fx.
+func queryEffectiveSpendByCategory(db *sql.DB, startISO, endISO string, accountFilter map[int]bool, fx *fxConverter) (map[int]money, error) {
 	ids := accountFilterIDs(accountFilter)
 	args := []any{startISO, endISO}
 	query := `
 		WITH scoped_txn AS (
-			SELECT id, category_id, amount
+			SELECT id, category_id, amount, account_id, date_iso
 			FROM transactions
 			WHERE date_iso >= ?
 			  AND date_iso < ?
@@ -123,7 +125,7 @@ func queryEffectiveSpendByCategory(db *sql.DB, startISO, endISO string, accountF
 	query += `
 		),
 		scoped_alloc AS (
-			SELECT a.parent_txn_id, a.category_id, a.amount
+			SELECT a.parent_txn_id, a.category_id, a.amount, s.account_id, s.date_iso
 			FROM transaction_allocations a
 			JOIN scoped_txn s ON s.id = a.parent_txn_id
 		),
@@ -133,19 +135,19 @@ func queryEffectiveSpendByCategory(db *sql.DB, startISO, endISO string, accountF
 			GROUP BY parent_txn_id
 		),
 		parent_remainder AS (
-			SELECT s.category_id, (s.amount - COALESCE(a.allocated, 0)) AS amount
+			SELECT s.category_id, (s.amount - COALESCE(a.allocated, 0)) AS amount, s.account_id, s.date_iso
 			FROM scoped_txn s
 			LEFT JOIN alloc_sum a ON a.parent_txn_id = s.id
 		),
 		effective_rows AS (
-			SELECT category_id, amount FROM scoped_alloc
+			SELECT category_id, amount, account_id, date_iso FROM scoped_alloc
 			UNION ALL
-			SELECT category_id, amount FROM parent_remainder
+			SELECT category_id, amount, account_id, date_iso FROM parent_remainder
 		)
-		SELECT category_id, COALESCE(SUM(-amount), 0)
+		SELECT category_id, account_id, date_iso, COALESCE(SUM(-amount), 0)
 		FROM effective_rows
 		WHERE amount < 0
-		GROUP BY category_id
+		GROUP BY category_id, account_id, date_iso
 	`
 
 	rows, err := db.Query(query, args...)
@@ -154,27 +156,31 @@ func queryEffectiveSpendByCategory(db *sql.DB, startISO, endISO string, accountF
 	}
 	defer rows.Close()
 
-	out := make(map[int]float64)
+	out := make(map[int]money)
 	for rows.Next() {
-		var categoryID sql.NullInt64
-		var spent float64
-		if err := rows.Scan(&categoryID, &spent); err != nil {
+		var categoryID, accountID sql.NullInt64
+		var dateISO string
+		var spent money
+		if err := rows.Scan(&categoryID, &accountID, &dateISO, &spent); err != nil {
 			return nil, fmt.Errorf("scan budget effective aggregate: %w", err)
 		}
-		if categoryID.Valid {
-			out[int(categoryID.Int64)] = spent
+		if !categoryID.Valid {
+			continue
+		}
+		if amount, ok := fx.toBase(spent, int(accountID.Int64), dateISO); ok {
+			out[int(categoryID.Int64)] += amount
 		}
 	}
 	return out, rows.Err()
 }
 
-func computeBudgetLines(db *sql.DB, budgets []categoryBudget, overrides map[int][]budgetOverride, month string, accountFilter map[int]bool) ([]budgetLine, error) {
+func computeBudgetLines(db *sql.DB, budgets []categoryBudget, overrides map[int][]budgetOverride, month string, accountFilter map[int]bool, fx *fxConverter) ([]budgetLine, error) {
 	start, end, err := parseMonthKey(month)
 	if err != nil {
 		return nil, err
 	}
 
-	spendByCategory, err := queryEffectiveSpendByCategory(db, start.Format("2006-01-02"), end.Format("2006-01-02"), accountFilter)
+	spendByCategory, err := queryEffectiveSpendByCategory(db, start.Format("2006-01-02"), end.Format("2006-01-02"), accountFilter, fx)
 	if err != nil {
 		return nil, err
 	}
@@ -229,7 +235,7 @@ func computeBudgetLines(db *sql.DB, budgets []categoryBudget, overrides map[int]
 	return lines, nil
 }
 
-func computeTargetLines(db *sql.DB, targets []spendingTarget, overrides map[int][]targetOverride, txnTags map[int][]tag, savedFilters []savedFilter, accountFilter map[int]bool) ([]targetLine, error) {
+func computeTargetLines(db *sql.DB, targets []spendingTarget, overrides map[int][]targetOverride, txnTags map[int][]tag, savedFilters []savedFilter, accountFilter map[int]bool, fx *fxConverter) ([]targetLine, error) {
 	byFilterID := make(map[string]savedFilter, len(savedFilters))
 	for _, sf := range savedFilters {
 		byFilterID[strings.ToLower(strings.TrimSpace(sf.ID))] = sf
@@ -316,7 +322,7 @@ func computeTargetLines(db *sql.DB, targets []spendingTarget, overrides map[int]
 		for _, parent := range parentRows {
 			parent.fullAmount = parent.amount
 			parent.parentTxnID = parent.id
-			allocated := 0.0
+			var allocated money
 			for _, alloc := range allocByParent[parent.id] {
 				allocated += alloc.amount
 			}
@@ -346,7 +352,7 @@ func computeTargetLines(db *sql.DB, targets []spendingTarget, overrides map[int]
 			}
 		}
 
-		spent := 0.0
+		var spent money
 		for _, row := range effectiveRows {
 			if !evalFilter(node, row, effectiveTags[row.id]) {
 				continue
@@ -354,7 +360,13 @@ func computeTargetLines(db *sql.DB, targets []spendingTarget, overrides map[int]
 			if row.amount >= 0 {
 				continue
 			}
-			spent += -row.amount
+			accountID := 0
+			if row.accountID != nil {
+				accountID = *row.accountID
+			}
+			if amount, ok := fx.toBase(-row.amount, accountID, row.dateISO); ok {
+				spent += amount
+			}
 		}
 		remaining := effectiveBudget - spent
 		lines = append(lines, targetLine{
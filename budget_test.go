@@ -1,15 +1,10 @@
 package main
 
 import (
-	"math"
 	"testing"
 	"time"
 )
 
-func almostEqual(a, b float64) bool {
-	return math.Abs(a-b) < 0.0001
-}
-
 func TestComputeBudgetLinesUsesAllocationsWithOverride(t *testing.T) {
 	db, cleanup := testDB(t)
 	defer cleanup()
@@ -53,10 +48,10 @@ func TestComputeBudgetLinesUsesAllocationsWithOverride(t *testing.T) {
 	if groceryBudget.id == 0 {
 		t.Fatal("missing grocery budget row")
 	}
-	if err := upsertCategoryBudget(db, groceries.id, 100); err != nil {
+	if err := upsertCategoryBudget(db, groceries.id, 10000); err != nil {
 		t.Fatalf("upsertCategoryBudget: %v", err)
 	}
-	if err := upsertBudgetOverride(db, groceryBudget.id, monthKey, 80); err != nil {
+	if err := upsertBudgetOverride(db, groceryBudget.id, monthKey, 8000); err != nil {
 		t.Fatalf("upsertBudgetOverride: %v", err)
 	}
 	accountID, err := insertAccount(db, "Budget Test", "debit", true)
@@ -70,8 +65,8 @@ func TestComputeBudgetLinesUsesAllocationsWithOverride(t *testing.T) {
 			(?,?,?,?,?,?,?),
 			(?,?,?,?,?,?,?)
 	`,
-		monthStart.Format("02/01/2006"), monthStart.Format("2006-01-02"), -50, "A", "", groceries.id, accountID,
-		nextMonthStart.AddDate(0, 0, -1).Format("02/01/2006"), nextMonthStart.AddDate(0, 0, -1).Format("2006-01-02"), -10, "B", "", groceries.id, accountID,
+		monthStart.Format("02/01/2006"), monthStart.Format("2006-01-02"), -5000, "A", "", groceries.id, accountID,
+		nextMonthStart.AddDate(0, 0, -1).Format("02/01/2006"), nextMonthStart.AddDate(0, 0, -1).Format("2006-01-02"), -1000, "B", "", groceries.id, accountID,
 	)
 	if err != nil {
 		t.Fatalf("insert transactions: %v", err)
@@ -81,7 +76,7 @@ func TestComputeBudgetLinesUsesAllocationsWithOverride(t *testing.T) {
 		t.Fatalf("last insert id: %v", err)
 	}
 	debit1 := int(lastID - 1)
-	if _, err := insertTransactionAllocation(db, debit1, 20, &transport.id, "split", nil); err != nil {
+	if _, err := insertTransactionAllocation(db, debit1, 2000, &transport.id, "split", nil); err != nil {
 		t.Fatalf("insertTransactionAllocation: %v", err)
 	}
 
@@ -91,7 +86,7 @@ func TestComputeBudgetLinesUsesAllocationsWithOverride(t *testing.T) {
 		t.Fatalf("loadBudgetOverrides: %v", err)
 	}
 
-	lines, err := computeBudgetLines(db, budgets, overrides, monthKey, nil)
+	lines, err := computeBudgetLines(db, budgets, overrides, monthKey, nil, nil)
 	if err != nil {
 		t.Fatalf("computeBudgetLines: %v", err)
 	}
@@ -105,14 +100,14 @@ func TestComputeBudgetLinesUsesAllocationsWithOverride(t *testing.T) {
 	if got == nil {
 		t.Fatal("missing groceries line")
 	}
-	if !almostEqual(got.budgeted, 80) {
-		t.Fatalf("budgeted = %.2f, want 80", got.budgeted)
+	if got.budgeted != 8000 {
+		t.Fatalf("budgeted = %s, want 80.00", got.budgeted)
 	}
-	if !almostEqual(got.spent, 40) {
-		t.Fatalf("spent = %.2f, want 40", got.spent)
+	if got.spent != 4000 {
+		t.Fatalf("spent = %s, want 40.00", got.spent)
 	}
-	if !almostEqual(got.remaining, 40) {
-		t.Fatalf("remaining = %.2f, want 40", got.remaining)
+	if got.remaining != 4000 {
+		t.Fatalf("remaining = %s, want 40.00", got.remaining)
 	}
 	if got.overBudget {
 		t.Fatal("overBudget = true, want false")
@@ -145,9 +140,9 @@ func TestComputeTargetLinesUsesSavedFilterIDAndPeriodKeys(t *testing.T) {
 			(?,?,?,?,?,?,?),
 			(?,?,?,?,?,?,?)
 		`,
-		monthStart.AddDate(0, 0, 9).Format("02/01/2006"), monthStart.AddDate(0, 0, 9).Format("2006-01-02"), -40, "woolies", "", groceries.id, accountID,
-		monthStart.AddDate(0, 0, 10).Format("02/01/2006"), monthStart.AddDate(0, 0, 10).Format("2006-01-02"), +10, "refund", "", groceries.id, accountID,
-		prevMonth.AddDate(0, 0, 9).Format("02/01/2006"), prevMonth.AddDate(0, 0, 9).Format("2006-01-02"), -30, "woolies prev", "", groceries.id, accountID,
+		monthStart.AddDate(0, 0, 9).Format("02/01/2006"), monthStart.AddDate(0, 0, 9).Format("2006-01-02"), -4000, "woolies", "", groceries.id, accountID,
+		monthStart.AddDate(0, 0, 10).Format("02/01/2006"), monthStart.AddDate(0, 0, 10).Format("2006-01-02"), +1000, "refund", "", groceries.id, accountID,
+		prevMonth.AddDate(0, 0, 9).Format("02/01/2006"), prevMonth.AddDate(0, 0, 9).Format("2006-01-02"), -3000, "woolies prev", "", groceries.id, accountID,
 	); err != nil {
 		t.Fatalf("insert txns: %v", err)
 	}
@@ -165,17 +160,17 @@ func TestComputeTargetLinesUsesSavedFilterIDAndPeriodKeys(t *testing.T) {
 	if febDebitID == 0 {
 		t.Fatal("missing feb txn ids")
 	}
-	if _, err := insertTransactionAllocation(db, febDebitID, 10, &transport.id, "split", nil); err != nil {
+	if _, err := insertTransactionAllocation(db, febDebitID, 1000, &transport.id, "split", nil); err != nil {
 		t.Fatalf("insertTransactionAllocation: %v", err)
 	}
 
 	targets := []spendingTarget{
-		{id: 1, name: "Grocery Q1", savedFilterID: "grocery", amount: 200, periodType: "quarterly"},
+		{id: 1, name: "Grocery Q1", savedFilterID: "grocery", amount: 20000, periodType: "quarterly"},
 	}
 	saved := []savedFilter{
 		{ID: "grocery", Name: "Grocery", Expr: "cat:Groceries"},
 	}
-	targetLines, err := computeTargetLines(db, targets, nil, nil, saved, nil)
+	targetLines, err := computeTargetLines(db, targets, nil, nil, saved, nil, nil)
 	if err != nil {
 		t.Fatalf("computeTargetLines: %v", err)
 	}
@@ -187,10 +182,10 @@ func TestComputeTargetLinesUsesSavedFilterIDAndPeriodKeys(t *testing.T) {
 	if line.periodKey != wantPeriod {
 		t.Fatalf("periodKey=%q want %s", line.periodKey, wantPeriod)
 	}
-	if !almostEqual(line.spent, 60) {
-		t.Fatalf("spent=%.2f want 60", line.spent)
+	if line.spent != 6000 {
+		t.Fatalf("spent=%s want 60.00", line.spent)
 	}
-	if !almostEqual(line.spent, 60) {
-		t.Fatalf("spent=%.2f want 60", line.spent)
+	if line.spent != 6000 {
+		t.Fatalf("spent=%s want 60.00", line.spent)
 	}
 }
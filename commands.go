@@ -230,7 +230,7 @@ func NewCommandRegistry(keys *KeyRegistry, savedFilters []savedFilter) *CommandR
 				m.dashAnchorMonth = thisMonth
 				m.budgetMonth = thisMonth
 				m.budgetYear = now.Year()
-				saveCmd := saveSettingsCmd(m.currentAppSettings())
+				saveCmd := saveSettingsCmd(m.profile, m.currentAppSettings())
 				if m.db != nil {
 					return m, tea.Batch(saveCmd, refreshCmd(m.db)), nil
 				}
@@ -323,9 +323,9 @@ func NewCommandRegistry(keys *KeyRegistry, savedFilters []savedFilter) *CommandR
 							cellAmount = ov
 						}
 					}
-					m.budgetEditValue = fmt.Sprintf("%.2f", cellAmount)
+					m.budgetEditValue = cellAmount.String()
 				} else {
-					m.budgetEditValue = fmt.Sprintf("%.2f", line.budgeted)
+					m.budgetEditValue = line.budgeted.String()
 				}
 				m.budgetEditCursor = len(m.budgetEditValue)
 				return m, nil, nil
@@ -638,7 +638,7 @@ func NewCommandRegistry(keys *KeyRegistry, savedFilters []savedFilter) *CommandR
 					return m, nil, fmt.Errorf("no transaction selected")
 				}
 				m.openDetail(filtered[m.cursor])
-				return m, nil, nil
+				return m, loadTxnHistoryCmd(m.db, m.detailIdx), nil
 			},
 		},
 		{
@@ -796,6 +796,32 @@ func NewCommandRegistry(keys *KeyRegistry, savedFilters []savedFilter) *CommandR
 				return m, m.beginImportFlow(), nil
 			},
 		},
+		{
+			ID:          "import:pending",
+			Label:       "Import Pending Files",
+			Description: "Preview and import every file in the import inbox, one at a time",
+			Category:    "Actions",
+			Scopes:      []string{scopeFilePicker, scopeSettingsNav, scopeSettingsActiveDBImport, scopeGlobal},
+			Enabled: func(m model) (bool, string) {
+				if m.importPicking && m.importForWizard {
+					return false, "Picker is choosing a file for the format wizard."
+				}
+				return true, ""
+			},
+			Execute: func(m model) (model, tea.Cmd, error) {
+				if m.importPicking && len(m.importFiles) > 0 {
+					return m, m.startImportQueue(m.importFiles), nil
+				}
+				m.activeTab = tabSettings
+				m.settColumn = settColRight
+				m.settSection = settSecDBImport
+				m.settActive = true
+				m.focusedSection = sectionSettingsDatabase
+				cmd := m.beginImportFlow()
+				m.importAllOnLoad = true
+				return m, cmd, nil
+			},
+		},
 		{
 			ID:          "import:all",
 			Label:       "Import All",
@@ -806,7 +832,7 @@ func NewCommandRegistry(keys *KeyRegistry, savedFilters []savedFilter) *CommandR
 				if m.importPreviewSnapshot == nil {
 					return false, "No import preview snapshot."
 				}
-				if m.importPreviewSnapshot.errorCount > 0 {
+				if m.importPreviewSnapshot.importBlocked() {
 					return false, "Preview has parse/normalize errors."
 				}
 				return true, ""
@@ -815,10 +841,15 @@ func NewCommandRegistry(keys *KeyRegistry, savedFilters []savedFilter) *CommandR
 				if m.importPreviewSnapshot == nil {
 					return m, nil, fmt.Errorf("missing import preview snapshot")
 				}
-				if m.importPreviewSnapshot.errorCount > 0 {
+				if m.importPreviewSnapshot.importBlocked() {
 					return m, nil, fmt.Errorf("preview has parse/normalize errors")
 				}
 				snapshot := m.importPreviewSnapshot
+				if snapshot.needsReimportConfirm() {
+					snapshot.reimportConfirmed = true
+					m.setError(reimportWarning(snapshot) + ". Run again to import anyway.")
+					return m, nil, nil
+				}
 				applyRules := m.importPreviewPostRules
 				m.importPreviewOpen = false
 				m.importPreviewPostRules = true
@@ -839,7 +870,7 @@ func NewCommandRegistry(keys *KeyRegistry, savedFilters []savedFilter) *CommandR
 				if m.importPreviewSnapshot == nil {
 					return false, "No import preview snapshot."
 				}
-				if m.importPreviewSnapshot.errorCount > 0 {
+				if m.importPreviewSnapshot.importBlocked() {
 					return false, "Preview has parse/normalize errors."
 				}
 				return true, ""
@@ -848,10 +879,15 @@ func NewCommandRegistry(keys *KeyRegistry, savedFilters []savedFilter) *CommandR
 				if m.importPreviewSnapshot == nil {
 					return m, nil, fmt.Errorf("missing import preview snapshot")
 				}
-				if m.importPreviewSnapshot.errorCount > 0 {
+				if m.importPreviewSnapshot.importBlocked() {
 					return m, nil, fmt.Errorf("preview has parse/normalize errors")
 				}
 				snapshot := m.importPreviewSnapshot
+				if snapshot.needsReimportConfirm() {
+					snapshot.reimportConfirmed = true
+					m.setError(reimportWarning(snapshot) + ". Run again to import anyway.")
+					return m, nil, nil
+				}
 				applyRules := m.importPreviewPostRules
 				m.importPreviewOpen = false
 				m.importPreviewPostRules = true
@@ -888,6 +924,46 @@ func NewCommandRegistry(keys *KeyRegistry, savedFilters []savedFilter) *CommandR
 				return m, nil, nil
 			},
 		},
+		{
+			ID:          "import:quarantine",
+			Label:       "Quarantine Bad Rows",
+			Description: "Import the good rows and save rows that failed to parse for review",
+			Category:    "Import",
+			Scopes:      []string{scopeImportPreview},
+			Enabled: func(m model) (bool, string) {
+				if m.importPreviewSnapshot == nil {
+					return false, "No import preview snapshot."
+				}
+				if !m.importPreviewSnapshot.canQuarantine() {
+					return false, "No row-level parse errors to quarantine."
+				}
+				return true, ""
+			},
+			Execute: func(m model) (model, tea.Cmd, error) {
+				next, cmd := m.toggleImportQuarantine()
+				return next, cmd, nil
+			},
+		},
+		{
+			ID:          "import:rejects",
+			Label:       "Review Rejected Rows",
+			Description: "Fix and promote rows that failed to parse during import",
+			Category:    "Import",
+			Scopes:      []string{scopeSettingsNav, scopeSettingsActiveImportHist, scopeGlobal},
+			Enabled: func(m model) (bool, string) {
+				if m.db == nil {
+					return false, "Database not ready."
+				}
+				if len(m.importRejects) == 0 {
+					return false, "No rejected rows."
+				}
+				return true, ""
+			},
+			Execute: func(m model) (model, tea.Cmd, error) {
+				m.openRejectsReview()
+				return m, nil, nil
+			},
+		},
 		{
 			ID:          "import:cancel",
 			Label:       "Cancel Import",
@@ -903,6 +979,10 @@ func NewCommandRegistry(keys *KeyRegistry, savedFilters []savedFilter) *CommandR
 				m.importPreviewScroll = 0
 				m.importPreviewSnapshot = nil
 				m.setStatus("Import cancelled.")
+				if m.importQueue != nil {
+					m.importQueue.skipped++
+					return m, m.nextQueuedImport(), nil
+				}
 				return m, nil, nil
 			},
 		},
@@ -1025,6 +1105,89 @@ func NewCommandRegistry(keys *KeyRegistry, savedFilters []savedFilter) *CommandR
 				return m, cmd, nil
 			},
 		},
+		{
+			ID:          "fx:load",
+			Label:       "Load FX Rates",
+			Description: "Import exchange rates from the configured fx_rates_file",
+			Category:    "Settings",
+			Scopes:      []string{scopeSettingsNav, scopeSettingsActiveDBImport, scopeGlobal},
+			Enabled: func(m model) (bool, string) {
+				if m.db == nil {
+					return false, "Database not ready."
+				}
+				if strings.TrimSpace(m.fxRatesFile) == "" {
+					return false, "Set fx_rates_file in config.toml first."
+				}
+				return true, ""
+			},
+			Execute: func(m model) (model, tea.Cmd, error) {
+				m.setStatus("Loading FX rates...")
+				return m, loadFXRatesFileCmd(m.db, m.fxRatesFile, m.baseCurrency), nil
+			},
+		},
+		{
+			ID:          "merchant:rerun",
+			Label:       "Re-run Merchant Rules",
+			Description: "Re-derive every transaction's merchant from its description",
+			Category:    "Settings",
+			Scopes:      []string{scopeSettingsNav, scopeSettingsActiveDBImport, scopeGlobal},
+			Enabled: func(m model) (bool, string) {
+				if m.db == nil {
+					return false, "Database not ready."
+				}
+				return true, ""
+			},
+			Execute: func(m model) (model, tea.Cmd, error) {
+				m.setStatus("Re-running merchant rules...")
+				return m, rerunMerchantsCmd(m.db, m.profile), nil
+			},
+		},
+		{
+			ID:          "ledger:undo",
+			Label:       "Undo",
+			Description: "Reverse the last category, tag, allocation or rule edit",
+			Category:    "Transactions",
+			Scopes:      []string{scopeGlobal},
+			Enabled: func(m model) (bool, string) {
+				if m.db == nil {
+					return false, "Database not ready."
+				}
+				return true, ""
+			},
+			Execute: func(m model) (model, tea.Cmd, error) {
+				return m, undoLedgerCmd(m.db), nil
+			},
+		},
+		{
+			ID:          "ledger:redo",
+			Label:       "Redo",
+			Description: "Re-apply the last undone edit",
+			Category:    "Transactions",
+			Scopes:      []string{scopeGlobal},
+			Enabled: func(m model) (bool, string) {
+				if m.db == nil {
+					return false, "Database not ready."
+				}
+				return true, ""
+			},
+			Execute: func(m model) (model, tea.Cmd, error) {
+				return m, redoLedgerCmd(m.db), nil
+			},
+		},
+		{
+			ID:          "ledger:switch",
+			Label:       "Switch Ledger",
+			Description: "Open another profile's database, or create a new profile",
+			Category:    "Settings",
+			Scopes:      []string{scopeGlobal},
+			Enabled:     commandAlwaysEnabled,
+			Execute: func(m model) (model, tea.Cmd, error) {
+				if err := m.openLedgerPicker(); err != nil {
+					return m, nil, err
+				}
+				return m, nil, nil
+			},
+		},
 		{
 			ID:          "dash:mode-next",
 			Label:       "Next Widget Mode",
@@ -44,14 +44,22 @@ func TestCommandRegistryHasExpectedCommands(t *testing.T) {
 		"filter:save":           true,
 		"filter:apply":          true,
 		"import:start":          true,
+		"import:pending":        true,
 		"import:all":            true,
 		"import:skip-dupes":     true,
 		"import:raw-view":       true,
 		"import:preview-toggle": true,
+		"import:quarantine":     true,
+		"import:rejects":        true,
 		"import:cancel":         true,
 		"rules:apply":           true,
 		"rules:dry-run":         true,
 		"settings:clear-db":     true,
+		"fx:load":               true,
+		"merchant:rerun":        true,
+		"ledger:undo":           true,
+		"ledger:redo":           true,
+		"ledger:switch":         true,
 		"dash:mode-next":        true,
 		"dash:mode-prev":        true,
 		"dash:drill-down":       true,
@@ -88,7 +96,7 @@ func TestCommandRegistryAddsSavedFilterCommands(t *testing.T) {
 
 func TestCommandSearchIncludesDescriptionAndID(t *testing.T) {
 	reg := NewCommandRegistry(NewKeyRegistry(), nil)
-	m := newModel()
+	m := newModel("")
 
 	byDesc := reg.Search("transaction", scopeTransactions, m, "")
 	if len(byDesc) == 0 {
@@ -110,7 +118,7 @@ func TestCommandSearchIncludesDescriptionAndID(t *testing.T) {
 
 func TestCommandSearchRespectsScope(t *testing.T) {
 	reg := NewCommandRegistry(NewKeyRegistry(), nil)
-	m := newModel()
+	m := newModel("")
 	results := reg.Search("widget", scopeTransactions, m, "")
 	for _, match := range results {
 		if match.Command.ID == "dash:mode-next" || match.Command.ID == "dash:mode-prev" {
@@ -121,7 +129,7 @@ func TestCommandSearchRespectsScope(t *testing.T) {
 
 func TestCommandSearchPrefersMRUWhenMatched(t *testing.T) {
 	reg := NewCommandRegistry(NewKeyRegistry(), nil)
-	m := newModel()
+	m := newModel("")
 	got := reg.Search("nav", scopeGlobal, m, "nav:manager")
 	if len(got) == 0 {
 		t.Fatal("expected search results")
@@ -133,7 +141,7 @@ func TestCommandSearchPrefersMRUWhenMatched(t *testing.T) {
 
 func TestExecuteByIDRejectsScopeMismatch(t *testing.T) {
 	reg := NewCommandRegistry(NewKeyRegistry(), nil)
-	m := newModel()
+	m := newModel("")
 	_, _, err := reg.ExecuteByID("budget:prev-month", scopeTransactions, m)
 	if err == nil {
 		t.Fatal("expected scope mismatch error")
@@ -141,7 +149,7 @@ func TestExecuteByIDRejectsScopeMismatch(t *testing.T) {
 }
 
 func TestExecuteSelectedCommandShowsDisabledReasonAtCursor(t *testing.T) {
-	m := newModel()
+	m := newModel("")
 	m.commandOpen = true
 	m.commandSourceScope = scopeGlobal
 	m.commandCursor = 0
@@ -177,7 +185,7 @@ func TestExecuteSelectedCommandShowsDisabledReasonAtCursor(t *testing.T) {
 }
 
 func TestCommandOpenHotkeys(t *testing.T) {
-	m := newModel()
+	m := newModel("")
 	m.ready = true
 	m.activeTab = tabManager
 
@@ -187,7 +195,7 @@ func TestCommandOpenHotkeys(t *testing.T) {
 		t.Fatalf("ctrl+k should open palette, open=%v kind=%q", got.commandOpen, got.commandUIKind)
 	}
 
-	m2 := newModel()
+	m2 := newModel("")
 	m2.ready = true
 	m2.activeTab = tabManager
 	next2, _ := m2.Update(keyMsg(":"))
@@ -198,7 +206,7 @@ func TestCommandOpenHotkeys(t *testing.T) {
 }
 
 func TestCommandModeEnterOnDisabledCommandDoesNotExecute(t *testing.T) {
-	m := newModel()
+	m := newModel("")
 	m.commandOpen = true
 	m.commandUIKind = commandUIKindColon
 	m.commandSourceScope = scopeGlobal
@@ -232,7 +240,7 @@ func TestCommandModeEnterOnDisabledCommandDoesNotExecute(t *testing.T) {
 }
 
 func TestCommandOpenBlockedByModal(t *testing.T) {
-	m := newModel()
+	m := newModel("")
 	m.ready = true
 	m.showDetail = true
 
@@ -244,14 +252,14 @@ func TestCommandOpenBlockedByModal(t *testing.T) {
 }
 
 func TestCommandOpenBlockedByImportPreviewOverlay(t *testing.T) {
-	m := newModel()
+	m := newModel("")
 	m.ready = true
 	m.importPreviewOpen = true
 	m.importPreviewSnapshot = &importPreviewSnapshot{
 		fileName:  "ANZ.csv",
 		totalRows: 1,
 		rows: []importPreviewRow{
-			{index: 1, sourceLine: 1, dateRaw: "3/02/2026", dateISO: "2026-02-03", amount: -1, description: "row"},
+			{index: 1, sourceLine: 1, dateRaw: "3/02/2026", dateISO: "2026-02-03", amount: -100, description: "row"},
 		},
 	}
 
@@ -280,7 +288,7 @@ func TestNextTransactionSortColumnMatchesVisualOrder(t *testing.T) {
 }
 
 func TestCommandCursorScrollOffsetStaysVisible(t *testing.T) {
-	m := newModel()
+	m := newModel("")
 	m.openCommandUI(commandUIKindPalette)
 	m.commandMatches = make([]CommandMatch, 20)
 	m.commandPageSize = 10
@@ -304,7 +312,7 @@ func TestCommandCursorScrollOffsetStaysVisible(t *testing.T) {
 }
 
 func TestCommandUIPrintableJKAreLiteralFirst(t *testing.T) {
-	m := newModel()
+	m := newModel("")
 	m.commandOpen = true
 	m.commandUIKind = commandUIKindPalette
 	m.commandQuery = "abc"
@@ -320,7 +328,7 @@ func TestCommandUIPrintableJKAreLiteralFirst(t *testing.T) {
 }
 
 func TestCommandUIArrowDownStillNavigates(t *testing.T) {
-	m := newModel()
+	m := newModel("")
 	m.commandOpen = true
 	m.commandUIKind = commandUIKindPalette
 	m.commandCursor = 0
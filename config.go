@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
 	"sort"
 	"strings"
 	"time"
@@ -14,21 +15,43 @@ import (
 
 // csvFormat defines how to parse a bank CSV file.
 type csvFormat struct {
-	Name         string `toml:"name"`
-	Account      string `toml:"account"`
-	AccountType  string `toml:"account_type"`
-	ImportPrefix string `toml:"import_prefix"`
-	SortOrder    int    `toml:"sort_order"`
-	IsActive     bool   `toml:"is_active"`
-	Description  string `toml:"description"`
-	DateFormat   string `toml:"date_format"`
-	HasHeader    bool   `toml:"has_header"`
-	Delimiter    string `toml:"delimiter"`
-	DateCol      int    `toml:"date_col"`
-	AmountCol    int    `toml:"amount_col"`
-	DescCol      int    `toml:"desc_col"`     // starting column for description
-	DescJoin     bool   `toml:"desc_join"`    // if true, join desc_col..end
-	AmountStrip  string `toml:"amount_strip"` // chars to strip from amount
+	Name            string `toml:"name"`
+	Account         string `toml:"account"`
+	AccountType     string `toml:"account_type"`
+	ImportPrefix    string `toml:"import_prefix"`
+	SortOrder       int    `toml:"sort_order"`
+	IsActive        bool   `toml:"is_active"`
+	Description     string `toml:"description"`
+	DateFormat      string `toml:"date_format"`
+	HasHeader       bool   `toml:"has_header"`
+	Delimiter       string `toml:"delimiter"`
+	DateCol         int    `toml:"date_col"`
+	AmountCol       int    `toml:"amount_col"`
+	DescCol         int    `toml:"desc_col"`          // starting column for description
+	DescJoin        bool   `toml:"desc_join"`         // if true, join desc_col..end
+	AmountStrip     string `toml:"amount_strip"`      // chars to strip from amount
+	NumberLocale    string `toml:"number_locale"`     // "en" (1,234.56, default) or "eu" (1.234,56)
+	DebitCol        *int   `toml:"debit_col"`         // withdrawals column; replaces amount_col when set
+	CreditCol       *int   `toml:"credit_col"`        // deposits column; replaces amount_col when set
+	InvertSign      bool   `toml:"invert_sign"`       // negate parsed amounts (e.g. purchases exported as positive)
+	OFXAccountID    string `toml:"ofx_account_id"`    // OFX/QFX ACCTID (or trailing digits) mapped to this account
+	RefCol          *int   `toml:"ref_col"`           // bank transaction reference; dedupes on (account, ref) when present
+	BalanceCol      *int   `toml:"balance_col"`       // bank-reported running balance after the row
+	Currency        string `toml:"currency"`          // ISO code of the account; empty means base_currency
+	OrigAmountCol   *int   `toml:"orig_amount_col"`   // foreign amount before the bank's conversion
+	OrigCurrencyCol *int   `toml:"orig_currency_col"` // ISO code of orig_amount_col
+	StatusCol       *int   `toml:"status_col"`        // "pending"/"posted" marker; rows without one are posted
+
+	// Header names override the matching *_col index per file when
+	// has_header is true, so reordered exports keep importing correctly.
+	DateHeader    string `toml:"date_header"`
+	AmountHeader  string `toml:"amount_header"`
+	DescHeader    string `toml:"desc_header"`
+	DebitHeader   string `toml:"debit_header"`
+	CreditHeader  string `toml:"credit_header"`
+	RefHeader     string `toml:"ref_header"`
+	BalanceHeader string `toml:"balance_header"`
+	StatusHeader  string `toml:"status_header"`
 }
 
 type configFile struct {
@@ -39,20 +62,39 @@ type configFile struct {
 }
 
 type accountConfig struct {
-	Name         string `toml:"name"` // optional for table form; key is canonical name
-	Type         string `toml:"type"`
-	SortOrder    int    `toml:"sort_order"`
-	IsActive     bool   `toml:"is_active"`
-	ImportPrefix string `toml:"import_prefix"`
-	Description  string `toml:"description"`
-	DateFormat   string `toml:"date_format"`
-	HasHeader    bool   `toml:"has_header"`
-	Delimiter    string `toml:"delimiter"`
-	DateCol      int    `toml:"date_col"`
-	AmountCol    int    `toml:"amount_col"`
-	DescCol      int    `toml:"desc_col"`
-	DescJoin     bool   `toml:"desc_join"`
-	AmountStrip  string `toml:"amount_strip"`
+	Name            string `toml:"name"` // optional for table form; key is canonical name
+	Type            string `toml:"type"`
+	SortOrder       int    `toml:"sort_order"`
+	IsActive        bool   `toml:"is_active"`
+	ImportPrefix    string `toml:"import_prefix"`
+	Description     string `toml:"description"`
+	DateFormat      string `toml:"date_format"`
+	HasHeader       bool   `toml:"has_header"`
+	Delimiter       string `toml:"delimiter"`
+	DateCol         int    `toml:"date_col"`
+	AmountCol       int    `toml:"amount_col"`
+	DescCol         int    `toml:"desc_col"`
+	DescJoin        bool   `toml:"desc_join"`
+	AmountStrip     string `toml:"amount_strip"`
+	NumberLocale    string `toml:"number_locale,omitempty"`
+	DebitCol        *int   `toml:"debit_col,omitempty"`
+	CreditCol       *int   `toml:"credit_col,omitempty"`
+	InvertSign      bool   `toml:"invert_sign,omitempty"`
+	OFXAccountID    string `toml:"ofx_account_id,omitempty"`
+	RefCol          *int   `toml:"ref_col,omitempty"`
+	BalanceCol      *int   `toml:"balance_col,omitempty"`
+	Currency        string `toml:"currency,omitempty"`
+	OrigAmountCol   *int   `toml:"orig_amount_col,omitempty"`
+	OrigCurrencyCol *int   `toml:"orig_currency_col,omitempty"`
+	StatusCol       *int   `toml:"status_col,omitempty"`
+	DateHeader      string `toml:"date_header,omitempty"`
+	AmountHeader    string `toml:"amount_header,omitempty"`
+	DescHeader      string `toml:"desc_header,omitempty"`
+	DebitHeader     string `toml:"debit_header,omitempty"`
+	CreditHeader    string `toml:"credit_header,omitempty"`
+	RefHeader       string `toml:"ref_header,omitempty"`
+	BalanceHeader   string `toml:"balance_header,omitempty"`
+	StatusHeader    string `toml:"status_header,omitempty"`
 }
 
 type appSettings struct {
@@ -62,6 +104,35 @@ type appSettings struct {
 	DashCustomStart         string `toml:"dash_custom_start"`
 	DashCustomEnd           string `toml:"dash_custom_end"`
 	CommandDefaultInterface string `toml:"command_default_interface"` // "palette" or "colon"
+
+	// Likely-duplicate matching in the import preview: same account and
+	// amount, a date within DupeMatchDays, and a description similarity of
+	// at least DupeMatchSimilarity (0..1). DupeMatchDays = 0 disables it.
+	DupeMatchDays       int     `toml:"dupe_match_days"`
+	DupeMatchSimilarity float64 `toml:"dupe_match_similarity"`
+
+	// Import inbox scanned by the file picker. Empty means the working
+	// directory. When set, imported files move to its archive/ subfolder.
+	// ImportInboxGlobs (e.g. "anz-*.csv", "*/*.ofx") narrow the listing.
+	ImportInbox          string   `toml:"import_inbox"`
+	ImportInboxRecursive bool     `toml:"import_inbox_recursive"`
+	ImportInboxGlobs     []string `toml:"import_inbox_globs"`
+
+	// Amounts in accounts with another currency are converted to
+	// BaseCurrency for dashboards and budgets, using rates loaded from
+	// FXRatesFile (CSV: date,currency,rate). Empty means the currency most
+	// accounts are in.
+	BaseCurrency string `toml:"base_currency"`
+	FXRatesFile  string `toml:"fx_rates_file"`
+
+	// MerchantRules rewrite a raw description into the stored merchant,
+	// applied in order. Defaults strip card prefixes, card and store
+	// numbers and trailing locations.
+	MerchantRules []merchantRule `toml:"merchant_rules"`
+
+	// DatabasePath is this ledger's database. Relative paths are against
+	// the directory holding this config.toml; empty uses the default.
+	DatabasePath string `toml:"database_path"`
 }
 
 type savedFilter struct {
@@ -119,6 +190,13 @@ dash_timeframe = 0
 dash_custom_start = ""
 dash_custom_end = ""
 command_default_interface = "palette"
+dupe_match_days = 3
+dupe_match_similarity = 0.6
+import_inbox = ""
+import_inbox_recursive = false
+import_inbox_globs = []
+base_currency = ""
+fx_rates_file = ""
 `
 
 func configDir() (string, error) {
@@ -129,8 +207,9 @@ func configDir() (string, error) {
 	return filepath.Join(dir, "jaskmoney"), nil
 }
 
-func configPath() (string, error) {
-	dir, err := configDir()
+// configPath is the config.toml of a ledger; "" is the default ledger.
+func configPath(profile string) (string, error) {
+	dir, err := profileDir(profile)
 	if err != nil {
 		return "", err
 	}
@@ -145,8 +224,8 @@ func keybindingsPath() (string, error) {
 	return filepath.Join(dir, "keybindings.toml"), nil
 }
 
-func loadFormats() ([]csvFormat, error) {
-	formats, _, err := loadAppConfig()
+func loadFormats(profile string) ([]csvFormat, error) {
+	formats, _, err := loadAppConfig(profile)
 	return formats, err
 }
 
@@ -158,6 +237,9 @@ func defaultSettings() appSettings {
 		DashCustomStart:         "",
 		DashCustomEnd:           "",
 		CommandDefaultInterface: commandUIKindPalette,
+		DupeMatchDays:           3,
+		DupeMatchSimilarity:     0.6,
+		MerchantRules:           defaultMerchantRules(),
 	}
 }
 
@@ -168,8 +250,10 @@ func defaultConfigFile() configFile {
 	}
 }
 
-func loadAppConfigExtended() ([]csvFormat, appSettings, []savedFilter, []customPaneMode, []string, error) {
-	primaryPath, err := configPath()
+// loadAppConfigExtended loads a ledger's config.toml, writing the defaults
+// when it is missing or unreadable.
+func loadAppConfigExtended(profile string) ([]csvFormat, appSettings, []savedFilter, []customPaneMode, []string, error) {
+	primaryPath, err := configPath(profile)
 	if err != nil {
 		cfg := defaultConfigFile()
 		return defaultFormats(), cfg.Settings, nil, nil, nil, err
@@ -204,8 +288,8 @@ func loadAppConfigExtended() ([]csvFormat, appSettings, []savedFilter, []customP
 	return formats, settings, saved, customModes, warnings, nil
 }
 
-func loadAppConfig() ([]csvFormat, appSettings, error) {
-	formats, settings, _, _, _, err := loadAppConfigExtended()
+func loadAppConfig(profile string) ([]csvFormat, appSettings, error) {
+	formats, settings, _, _, _, err := loadAppConfigExtended(profile)
 	return formats, settings, err
 }
 
@@ -265,7 +349,8 @@ func parseConfig(data []byte) ([]csvFormat, appSettings, []keybindingConfig, err
 }
 
 func parseConfigExt(data []byte) ([]csvFormat, appSettings, []keybindingConfig, []savedFilter, []customPaneMode, []string, error) {
-	var cfg configFile
+	// Seed settings so keys missing from older configs keep their defaults.
+	cfg := configFile{Settings: defaultSettings()}
 	if err := toml.Unmarshal(data, &cfg); err != nil {
 		return nil, defaultSettings(), nil, nil, nil, nil, fmt.Errorf("parse config.toml: %w", err)
 	}
@@ -299,21 +384,40 @@ func parseConfigExt(data []byte) ([]csvFormat, appSettings, []keybindingConfig,
 			items = append(items, namedFormat{
 				name: name,
 				fmt: csvFormat{
-					Name:         name,
-					Account:      name,
-					AccountType:  acctType,
-					ImportPrefix: importPrefix,
-					SortOrder:    sortOrder,
-					IsActive:     raw.IsActive,
-					Description:  raw.Description,
-					DateFormat:   raw.DateFormat,
-					HasHeader:    raw.HasHeader,
-					Delimiter:    raw.Delimiter,
-					DateCol:      raw.DateCol,
-					AmountCol:    raw.AmountCol,
-					DescCol:      raw.DescCol,
-					DescJoin:     raw.DescJoin,
-					AmountStrip:  raw.AmountStrip,
+					Name:            name,
+					Account:         name,
+					AccountType:     acctType,
+					ImportPrefix:    importPrefix,
+					SortOrder:       sortOrder,
+					IsActive:        raw.IsActive,
+					Description:     raw.Description,
+					DateFormat:      raw.DateFormat,
+					HasHeader:       raw.HasHeader,
+					Delimiter:       raw.Delimiter,
+					DateCol:         raw.DateCol,
+					AmountCol:       raw.AmountCol,
+					DescCol:         raw.DescCol,
+					DescJoin:        raw.DescJoin,
+					AmountStrip:     raw.AmountStrip,
+					NumberLocale:    strings.TrimSpace(raw.NumberLocale),
+					DebitCol:        copyIntPtr(raw.DebitCol),
+					CreditCol:       copyIntPtr(raw.CreditCol),
+					InvertSign:      raw.InvertSign,
+					OFXAccountID:    strings.TrimSpace(raw.OFXAccountID),
+					RefCol:          copyIntPtr(raw.RefCol),
+					BalanceCol:      copyIntPtr(raw.BalanceCol),
+					Currency:        strings.TrimSpace(raw.Currency),
+					OrigAmountCol:   copyIntPtr(raw.OrigAmountCol),
+					OrigCurrencyCol: copyIntPtr(raw.OrigCurrencyCol),
+					StatusCol:       copyIntPtr(raw.StatusCol),
+					DateHeader:      strings.TrimSpace(raw.DateHeader),
+					AmountHeader:    strings.TrimSpace(raw.AmountHeader),
+					DescHeader:      strings.TrimSpace(raw.DescHeader),
+					DebitHeader:     strings.TrimSpace(raw.DebitHeader),
+					CreditHeader:    strings.TrimSpace(raw.CreditHeader),
+					RefHeader:       strings.TrimSpace(raw.RefHeader),
+					BalanceHeader:   strings.TrimSpace(raw.BalanceHeader),
+					StatusHeader:    strings.TrimSpace(raw.StatusHeader),
 				},
 			})
 		}
@@ -338,6 +442,34 @@ func parseConfigExt(data []byte) ([]csvFormat, appSettings, []keybindingConfig,
 		if f.DateFormat == "" {
 			return nil, defaultSettings(), nil, nil, nil, nil, fmt.Errorf("format[%d] %q: date_format is required", i, f.Name)
 		}
+		if (f.DebitCol != nil && *f.DebitCol < 0) || (f.CreditCol != nil && *f.CreditCol < 0) {
+			return nil, defaultSettings(), nil, nil, nil, nil, fmt.Errorf("format[%d] %q: debit_col/credit_col must be >= 0", i, f.Name)
+		}
+		if f.RefCol != nil && *f.RefCol < 0 {
+			return nil, defaultSettings(), nil, nil, nil, nil, fmt.Errorf("format[%d] %q: ref_col must be >= 0", i, f.Name)
+		}
+		if f.BalanceCol != nil && *f.BalanceCol < 0 {
+			return nil, defaultSettings(), nil, nil, nil, nil, fmt.Errorf("format[%d] %q: balance_col must be >= 0", i, f.Name)
+		}
+		if f.StatusCol != nil && *f.StatusCol < 0 {
+			return nil, defaultSettings(), nil, nil, nil, nil, fmt.Errorf("format[%d] %q: status_col must be >= 0", i, f.Name)
+		}
+		if (f.OrigAmountCol != nil && *f.OrigAmountCol < 0) || (f.OrigCurrencyCol != nil && *f.OrigCurrencyCol < 0) {
+			return nil, defaultSettings(), nil, nil, nil, nil, fmt.Errorf("format[%d] %q: orig_amount_col/orig_currency_col must be >= 0", i, f.Name)
+		}
+		currency, ok := normalizeCurrencyCode(f.Currency)
+		if !ok {
+			return nil, defaultSettings(), nil, nil, nil, nil, fmt.Errorf("format[%d] %q: currency must be a three-letter ISO code", i, f.Name)
+		}
+		f.Currency = currency
+		locale, ok := normalizeNumberLocale(f.NumberLocale)
+		if !ok {
+			return nil, defaultSettings(), nil, nil, nil, nil, fmt.Errorf("format[%d] %q: number_locale must be %q or %q", i, f.Name, numberLocaleEN, numberLocaleEU)
+		}
+		f.NumberLocale = locale
+		if f.usesHeaderNames() && !f.HasHeader {
+			return nil, defaultSettings(), nil, nil, nil, nil, fmt.Errorf("format[%d] %q: *_header columns require has_header = true", i, f.Name)
+		}
 		if strings.TrimSpace(f.Account) == "" {
 			f.Account = f.Name
 		}
@@ -355,6 +487,13 @@ func parseConfigExt(data []byte) ([]csvFormat, appSettings, []keybindingConfig,
 
 	settings := normalizeSettings(cfg.Settings)
 	saved, customModes, warnings := normalizeFilterConfigEntries(cfg.SavedFilter, cfg.DashboardView)
+	for i, r := range cfg.Settings.MerchantRules {
+		if strings.TrimSpace(r.Pattern) == "" {
+			warnings = append(warnings, fmt.Sprintf("merchant_rules[%d] skipped: pattern is required", i))
+		} else if _, err := regexp.Compile(r.Pattern); err != nil {
+			warnings = append(warnings, fmt.Sprintf("merchant_rules[%d] skipped: %v", i, err))
+		}
+	}
 	return formats, settings, nil, saved, customModes, warnings, nil
 }
 
@@ -702,15 +841,41 @@ func normalizeSettings(s appSettings) appSettings {
 	default:
 		out.CommandDefaultInterface = commandUIKindPalette
 	}
+	if s.DupeMatchDays >= 0 && s.DupeMatchDays <= maxDupeMatchDays {
+		out.DupeMatchDays = s.DupeMatchDays
+	}
+	if s.DupeMatchSimilarity > 0 && s.DupeMatchSimilarity <= 1 {
+		out.DupeMatchSimilarity = s.DupeMatchSimilarity
+	}
+	out.ImportInbox = strings.TrimSpace(s.ImportInbox)
+	out.ImportInboxRecursive = s.ImportInboxRecursive
+	for _, g := range s.ImportInboxGlobs {
+		if g = strings.TrimSpace(g); g != "" && validInboxGlob(g) {
+			out.ImportInboxGlobs = append(out.ImportInboxGlobs, g)
+		}
+	}
+	if base, ok := normalizeCurrencyCode(s.BaseCurrency); ok && base != "" {
+		out.BaseCurrency = base
+	}
+	out.FXRatesFile = strings.TrimSpace(s.FXRatesFile)
+	out.DatabasePath = strings.TrimSpace(s.DatabasePath)
+	if s.MerchantRules != nil {
+		out.MerchantRules = []merchantRule{}
+		for _, r := range s.MerchantRules {
+			if _, err := regexp.Compile(r.Pattern); err == nil && strings.TrimSpace(r.Pattern) != "" {
+				out.MerchantRules = append(out.MerchantRules, r)
+			}
+		}
+	}
 	return out
 }
 
-func saveAppSettings(s appSettings) error {
-	primaryPath, err := configPath()
+func saveAppSettings(profile string, s appSettings) error {
+	primaryPath, err := configPath(profile)
 	if err != nil {
 		return err
 	}
-	formats, _, saved, customModes, _, loadErr := loadAppConfigExtended()
+	formats, _, saved, customModes, _, loadErr := loadAppConfigExtended(profile)
 	if loadErr != nil {
 		return loadErr
 	}
@@ -723,12 +888,12 @@ func saveAppSettings(s appSettings) error {
 	return writeConfigFile(primaryPath, cfg)
 }
 
-func saveFormats(formats []csvFormat) error {
-	primaryPath, err := configPath()
+func saveFormats(profile string, formats []csvFormat) error {
+	primaryPath, err := configPath(profile)
 	if err != nil {
 		return err
 	}
-	_, settings, saved, customModes, _, loadErr := loadAppConfigExtended()
+	_, settings, saved, customModes, _, loadErr := loadAppConfigExtended(profile)
 	if loadErr != nil {
 		return loadErr
 	}
@@ -741,8 +906,8 @@ func saveFormats(formats []csvFormat) error {
 	return writeConfigFile(primaryPath, cfg)
 }
 
-func saveSavedFilters(saved []savedFilter) error {
-	primaryPath, err := configPath()
+func saveSavedFilters(profile string, saved []savedFilter) error {
+	primaryPath, err := configPath(profile)
 	if err != nil {
 		return err
 	}
@@ -772,7 +937,7 @@ func saveSavedFilters(saved []savedFilter) error {
 			Expr: filterExprString(node),
 		})
 	}
-	formats, settings, _, customModes, _, loadErr := loadAppConfigExtended()
+	formats, settings, _, customModes, _, loadErr := loadAppConfigExtended(profile)
 	if loadErr != nil {
 		return loadErr
 	}
@@ -785,8 +950,8 @@ func saveSavedFilters(saved []savedFilter) error {
 	return writeConfigFile(primaryPath, cfg)
 }
 
-func saveCustomPaneModes(modes []customPaneMode) error {
-	primaryPath, err := configPath()
+func saveCustomPaneModes(profile string, modes []customPaneMode) error {
+	primaryPath, err := configPath(profile)
 	if err != nil {
 		return err
 	}
@@ -804,7 +969,7 @@ func saveCustomPaneModes(modes []customPaneMode) error {
 		seenPane[pane] = true
 		deduped = append(deduped, mode)
 	}
-	formats, settings, saved, _, _, loadErr := loadAppConfigExtended()
+	formats, settings, saved, _, _, loadErr := loadAppConfigExtended(profile)
 	if loadErr != nil {
 		return loadErr
 	}
@@ -817,8 +982,8 @@ func saveCustomPaneModes(modes []customPaneMode) error {
 	return writeConfigFile(primaryPath, cfg)
 }
 
-func upsertFormatForAccount(name, acctType string) error {
-	formats, _, err := loadAppConfig()
+func upsertFormatForAccount(profile, name, acctType string) error {
+	formats, _, err := loadAppConfig(profile)
 	if err != nil {
 		return err
 	}
@@ -834,7 +999,7 @@ func upsertFormatForAccount(name, acctType string) error {
 			if strings.TrimSpace(formats[i].ImportPrefix) == "" {
 				formats[i].ImportPrefix = strings.ToLower(target)
 			}
-			return saveFormats(formats)
+			return saveFormats(profile, formats)
 		}
 	}
 
@@ -846,11 +1011,11 @@ func upsertFormatForAccount(name, acctType string) error {
 	base.SortOrder = len(formats) + 1
 	base.IsActive = true
 	formats = append(formats, base)
-	return saveFormats(formats)
+	return saveFormats(profile, formats)
 }
 
-func removeFormatForAccount(name string) error {
-	formats, _, err := loadAppConfig()
+func removeFormatForAccount(profile, name string) error {
+	formats, _, err := loadAppConfig(profile)
 	if err != nil {
 		return err
 	}
@@ -865,7 +1030,7 @@ func removeFormatForAccount(name string) error {
 		}
 		out = append(out, f)
 	}
-	return saveFormats(out)
+	return saveFormats(profile, out)
 }
 
 func writeConfigFile(path string, cfg configFile) error {
@@ -955,6 +1120,9 @@ func renderKeybindingsTemplate(bindings []keybindingConfig) string {
 				string(actionCommandPalette),
 				string(actionCommandMode),
 				string(actionCommandDefault),
+				string(actionUndo),
+				string(actionRedo),
+				string(actionSwitchLedger),
 			},
 		},
 		{
@@ -987,6 +1155,7 @@ func renderKeybindingsTemplate(bindings []keybindingConfig) string {
 				string(actionImportAll),
 				string(actionSkipDupes),
 				string(actionClearDB),
+				string(actionRestoreBackup),
 				string(actionRowsPerPage),
 				string(actionResetKeybindings),
 			},
@@ -1100,20 +1269,43 @@ func formatsToAccountConfigs(formats []csvFormat) map[string]accountConfig {
 		if acctType == "" {
 			acctType = inferAccountTypeFromName(name)
 		}
+		locale, _ := normalizeNumberLocale(f.NumberLocale)
+		if locale == numberLocaleEN {
+			locale = "" // the default; keep it out of config.toml
+		}
 		out[name] = accountConfig{
-			Type:         normalizeAccountType(acctType),
-			SortOrder:    sortOrder,
-			IsActive:     f.IsActive,
-			ImportPrefix: importPrefix,
-			Description:  f.Description,
-			DateFormat:   f.DateFormat,
-			HasHeader:    f.HasHeader,
-			Delimiter:    f.Delimiter,
-			DateCol:      f.DateCol,
-			AmountCol:    f.AmountCol,
-			DescCol:      f.DescCol,
-			DescJoin:     f.DescJoin,
-			AmountStrip:  f.AmountStrip,
+			Type:            normalizeAccountType(acctType),
+			SortOrder:       sortOrder,
+			IsActive:        f.IsActive,
+			ImportPrefix:    importPrefix,
+			Description:     f.Description,
+			DateFormat:      f.DateFormat,
+			HasHeader:       f.HasHeader,
+			Delimiter:       f.Delimiter,
+			DateCol:         f.DateCol,
+			AmountCol:       f.AmountCol,
+			DescCol:         f.DescCol,
+			DescJoin:        f.DescJoin,
+			AmountStrip:     f.AmountStrip,
+			NumberLocale:    locale,
+			DebitCol:        copyIntPtr(f.DebitCol),
+			CreditCol:       copyIntPtr(f.CreditCol),
+			InvertSign:      f.InvertSign,
+			OFXAccountID:    f.OFXAccountID,
+			RefCol:          copyIntPtr(f.RefCol),
+			BalanceCol:      copyIntPtr(f.BalanceCol),
+			Currency:        f.Currency,
+			OrigAmountCol:   copyIntPtr(f.OrigAmountCol),
+			OrigCurrencyCol: copyIntPtr(f.OrigCurrencyCol),
+			StatusCol:       copyIntPtr(f.StatusCol),
+			DateHeader:      f.DateHeader,
+			AmountHeader:    f.AmountHeader,
+			DescHeader:      f.DescHeader,
+			DebitHeader:     f.DebitHeader,
+			CreditHeader:    f.CreditHeader,
+			RefHeader:       f.RefHeader,
+			BalanceHeader:   f.BalanceHeader,
+			StatusHeader:    f.StatusHeader,
 		}
 	}
 	return out
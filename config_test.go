@@ -37,6 +37,97 @@ amount_strip = ","
 	}
 }
 
+func TestParseFormatsDebitCreditColumns(t *testing.T) {
+	data := []byte(`
+[account.Everyday]
+date_format = "2/01/2006"
+date_col = 0
+desc_col = 1
+debit_col = 2
+credit_col = 3
+invert_sign = true
+	`)
+	formats, err := parseFormats(data)
+	if err != nil {
+		t.Fatalf("parseFormats: %v", err)
+	}
+	f := formats[0]
+	if f.DebitCol == nil || *f.DebitCol != 2 || f.CreditCol == nil || *f.CreditCol != 3 || !f.InvertSign {
+		t.Fatalf("format = %+v", f)
+	}
+	cfg := formatsToAccountConfigs(formats)["Everyday"]
+	if cfg.DebitCol == nil || *cfg.DebitCol != 2 || cfg.CreditCol == nil || *cfg.CreditCol != 3 || !cfg.InvertSign {
+		t.Fatalf("account config = %+v", cfg)
+	}
+
+	if _, err := parseFormats([]byte("[account.Bad]\ndate_format = \"2006-01-02\"\ndebit_col = -1\n")); err == nil {
+		t.Fatal("expected error for negative debit_col")
+	}
+	if _, err := parseFormats([]byte("[account.Bad]\ndate_format = \"2006-01-02\"\nref_col = -1\n")); err == nil {
+		t.Fatal("expected error for negative ref_col")
+	}
+	if _, err := parseFormats([]byte("[account.Bad]\ndate_format = \"2006-01-02\"\nstatus_col = -1\n")); err == nil {
+		t.Fatal("expected error for negative status_col")
+	}
+	if _, err := parseFormats([]byte("[account.Bad]\ndate_format = \"2006-01-02\"\nnumber_locale = \"klingon\"\n")); err == nil {
+		t.Fatal("expected error for unknown number_locale")
+	}
+	formats, err = parseFormats([]byte("[account.ING]\ndate_format = \"02.01.2006\"\nnumber_locale = \"DE\"\n"))
+	if err != nil || formats[0].NumberLocale != numberLocaleEU {
+		t.Fatalf("number_locale DE: formats=%+v err=%v", formats, err)
+	}
+}
+
+func TestParseFormatsCurrency(t *testing.T) {
+	formats, err := parseFormats([]byte("[account.Wise]\ndate_format = \"2006-01-02\"\ncurrency = \" usd \"\norig_amount_col = 4\norig_currency_col = 5\n"))
+	if err != nil {
+		t.Fatalf("parseFormats: %v", err)
+	}
+	f := formats[0]
+	if f.Currency != "USD" || f.OrigAmountCol == nil || *f.OrigAmountCol != 4 || f.OrigCurrencyCol == nil || *f.OrigCurrencyCol != 5 {
+		t.Fatalf("format = %+v", f)
+	}
+	cfg := formatsToAccountConfigs(formats)["Wise"]
+	if cfg.Currency != "USD" || cfg.OrigAmountCol == nil || *cfg.OrigAmountCol != 4 {
+		t.Fatalf("account config = %+v", cfg)
+	}
+	if _, err := parseFormats([]byte("[account.Bad]\ndate_format = \"2006-01-02\"\ncurrency = \"dollars\"\n")); err == nil {
+		t.Fatal("expected error for invalid currency")
+	}
+	if _, err := parseFormats([]byte("[account.Bad]\ndate_format = \"2006-01-02\"\norig_amount_col = -1\n")); err == nil {
+		t.Fatal("expected error for negative orig_amount_col")
+	}
+
+	if got := normalizeSettings(appSettings{BaseCurrency: "nzd"}).BaseCurrency; got != "NZD" {
+		t.Fatalf("base_currency = %q, want NZD", got)
+	}
+	if got := normalizeSettings(appSettings{BaseCurrency: "kiwi"}).BaseCurrency; got != "" {
+		t.Fatalf("invalid base_currency = %q, want empty (inferred from accounts)", got)
+	}
+}
+
+func TestParseFormatsHeaderNamesRequireHeader(t *testing.T) {
+	data := []byte(`
+[account.Everyday]
+date_format = "2/01/2006"
+has_header = true
+date_header = " Transaction Date "
+amount_header = "Amount"
+ref_header = " Reference "
+balance_header = "Balance"
+	`)
+	formats, err := parseFormats(data)
+	if err != nil {
+		t.Fatalf("parseFormats: %v", err)
+	}
+	if formats[0].DateHeader != "Transaction Date" || formats[0].AmountHeader != "Amount" || formats[0].RefHeader != "Reference" || formats[0].BalanceHeader != "Balance" {
+		t.Fatalf("format = %+v", formats[0])
+	}
+	if _, err := parseFormats([]byte("[account.Bad]\ndate_format = \"2006-01-02\"\ndate_header = \"Date\"\n")); err == nil {
+		t.Fatal("expected error for date_header without has_header")
+	}
+}
+
 func TestParseConfigSettingsDefaultsAndNormalization(t *testing.T) {
 	data := []byte(`
 [account.ANZ]
@@ -72,13 +163,38 @@ dash_custom_end = "2026-02-10"
 	if settings.CommandDefaultInterface != commandUIKindPalette {
 		t.Fatalf("command_default_interface = %q, want %q", settings.CommandDefaultInterface, commandUIKindPalette)
 	}
+	if settings.DupeMatchDays != 3 || settings.DupeMatchSimilarity != 0.6 {
+		t.Fatalf("dupe match = %d/%v, want defaults for missing keys", settings.DupeMatchDays, settings.DupeMatchSimilarity)
+	}
+}
+
+func TestParseConfigDupeMatchCanBeDisabled(t *testing.T) {
+	data := []byte(`
+[account.ANZ]
+type = "credit"
+date_format = "2/01/2006"
+
+[settings]
+dupe_match_days = 0
+dupe_match_similarity = 1.5
+`)
+	_, settings, _, err := parseConfig(data)
+	if err != nil {
+		t.Fatalf("parseConfig: %v", err)
+	}
+	if settings.DupeMatchDays != 0 || settings.dupeMatch().enabled() {
+		t.Fatalf("dupe_match_days = %d, want 0 (disabled)", settings.DupeMatchDays)
+	}
+	if settings.DupeMatchSimilarity != 0.6 {
+		t.Fatalf("dupe_match_similarity = %v, want default 0.6 for out-of-range value", settings.DupeMatchSimilarity)
+	}
 }
 
 func TestLoadAndSaveAppSettings(t *testing.T) {
 	xdg := t.TempDir()
 	t.Setenv("XDG_CONFIG_HOME", xdg)
 
-	formats, settings, err := loadAppConfig()
+	formats, settings, err := loadAppConfig("")
 	if err != nil {
 		t.Fatalf("loadAppConfig: %v", err)
 	}
@@ -97,11 +213,11 @@ func TestLoadAndSaveAppSettings(t *testing.T) {
 		DashCustomEnd:           "2026-02-10",
 		CommandDefaultInterface: commandUIKindColon,
 	}
-	if err := saveAppSettings(saved); err != nil {
+	if err := saveAppSettings("", saved); err != nil {
 		t.Fatalf("saveAppSettings: %v", err)
 	}
 
-	_, loaded, err := loadAppConfig()
+	_, loaded, err := loadAppConfig("")
 	if err != nil {
 		t.Fatalf("reload app config: %v", err)
 	}
@@ -142,7 +258,7 @@ func TestLoadAppConfigResetsInvalidConfigToDefaults(t *testing.T) {
 		t.Fatalf("write invalid config: %v", err)
 	}
 
-	formats, settings, saved, custom, warnings, err := loadAppConfigExtended()
+	formats, settings, saved, custom, warnings, err := loadAppConfigExtended("")
 	if err != nil {
 		t.Fatalf("loadAppConfigExtended: %v", err)
 	}
@@ -433,7 +549,7 @@ func TestSaveSavedFiltersRoundTrip(t *testing.T) {
 	xdg := t.TempDir()
 	t.Setenv("XDG_CONFIG_HOME", xdg)
 
-	if _, _, _, _, _, err := loadAppConfigExtended(); err != nil {
+	if _, _, _, _, _, err := loadAppConfigExtended(""); err != nil {
 		t.Fatalf("loadAppConfigExtended: %v", err)
 	}
 
@@ -441,11 +557,11 @@ func TestSaveSavedFiltersRoundTrip(t *testing.T) {
 		{ID: "groceries", Name: "Groceries", Expr: "cat:Groceries AND amt:<0"},
 		{ID: "large_debits", Name: "Large Debits", Expr: "type:debit AND amt:<-100"},
 	}
-	if err := saveSavedFilters(in); err != nil {
+	if err := saveSavedFilters("", in); err != nil {
 		t.Fatalf("saveSavedFilters: %v", err)
 	}
 
-	_, _, out, _, _, err := loadAppConfigExtended()
+	_, _, out, _, _, err := loadAppConfigExtended("")
 	if err != nil {
 		t.Fatalf("loadAppConfigExtended reload: %v", err)
 	}
@@ -461,7 +577,7 @@ func TestSaveCustomPaneModesRoundTripDedupesPerPane(t *testing.T) {
 	xdg := t.TempDir()
 	t.Setenv("XDG_CONFIG_HOME", xdg)
 
-	if _, _, _, _, _, err := loadAppConfigExtended(); err != nil {
+	if _, _, _, _, _, err := loadAppConfigExtended(""); err != nil {
 		t.Fatalf("loadAppConfigExtended: %v", err)
 	}
 
@@ -470,11 +586,11 @@ func TestSaveCustomPaneModesRoundTripDedupesPerPane(t *testing.T) {
 		{Pane: "net_cashflow", Name: "Renovation B", Expr: "cat:Home", ViewType: "line"},
 		{Pane: "composition", Name: "Dining", Expr: "cat:Dining AND amt:<0", ViewType: "pie"},
 	}
-	if err := saveCustomPaneModes(in); err != nil {
+	if err := saveCustomPaneModes("", in); err != nil {
 		t.Fatalf("saveCustomPaneModes: %v", err)
 	}
 
-	_, _, _, out, warnings, err := loadAppConfigExtended()
+	_, _, _, out, warnings, err := loadAppConfigExtended("")
 	if err != nil {
 		t.Fatalf("loadAppConfigExtended reload: %v", err)
 	}
@@ -514,11 +630,11 @@ func TestSaveCustomPaneModesRejectsInvalidEntry(t *testing.T) {
 	xdg := t.TempDir()
 	t.Setenv("XDG_CONFIG_HOME", xdg)
 
-	if _, _, _, _, _, err := loadAppConfigExtended(); err != nil {
+	if _, _, _, _, _, err := loadAppConfigExtended(""); err != nil {
 		t.Fatalf("loadAppConfigExtended: %v", err)
 	}
 
-	err := saveCustomPaneModes([]customPaneMode{
+	err := saveCustomPaneModes("", []customPaneMode{
 		{Pane: "net_cashflow", Name: "Broken", Expr: "cat:", ViewType: "line"},
 	})
 	if err == nil {
@@ -528,7 +644,7 @@ func TestSaveCustomPaneModesRejectsInvalidEntry(t *testing.T) {
 		t.Fatalf("expected dashboard_view invalid prefix, got %v", err)
 	}
 
-	_, _, _, custom, _, reloadErr := loadAppConfigExtended()
+	_, _, _, custom, _, reloadErr := loadAppConfigExtended("")
 	if reloadErr != nil {
 		t.Fatalf("reload config: %v", reloadErr)
 	}
@@ -8,7 +8,7 @@ import (
 )
 
 func TestDashboardViewRendersLowerAnalyticsGridPanes(t *testing.T) {
-	m := newModel()
+	m := newModel("")
 	m.ready = true
 	m.activeTab = tabDashboard
 	m.width = 120
@@ -28,7 +28,7 @@ func TestDashboardViewRendersLowerAnalyticsGridPanes(t *testing.T) {
 }
 
 func TestDashboardAnalyticsLayoutWideUsesSixtyFortySplitWithOneGap(t *testing.T) {
-	m := newModel()
+	m := newModel("")
 	m.ready = true
 	m.activeTab = tabDashboard
 	m.width = 120
@@ -59,7 +59,7 @@ func TestDashboardAnalyticsLayoutWideUsesSixtyFortySplitWithOneGap(t *testing.T)
 }
 
 func TestDashboardAnalyticsGridNarrowFallbackStacksPanes(t *testing.T) {
-	m := newModel()
+	m := newModel("")
 	m.ready = true
 	m.activeTab = tabDashboard
 	m.width = 70
@@ -77,7 +77,7 @@ func TestDashboardAnalyticsGridNarrowFallbackStacksPanes(t *testing.T) {
 }
 
 func TestDashboardFocusedPaneShowsActiveTitleMarker(t *testing.T) {
-	m := newModel()
+	m := newModel("")
 	m.ready = true
 	m.activeTab = tabDashboard
 	m.width = 120
@@ -104,7 +104,7 @@ func TestDashboardFocusedPaneShowsActiveTitleMarker(t *testing.T) {
 }
 
 func TestDashboardPaneLayoutUsesFortyPercentViewportHeight(t *testing.T) {
-	m := newModel()
+	m := newModel("")
 	m.height = 60
 
 	spec := dashboardPaneLayoutSpecFor(m, widgetNetCashflow)
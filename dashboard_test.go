@@ -8,7 +8,7 @@ import (
 )
 
 func TestDashboardDefaultScopeUsesTimeframeAndAccountsOnly(t *testing.T) {
-	m := newModel()
+	m := newModel("")
 	m.activeTab = tabDashboard
 	m.accounts = []account{
 		{id: 1, name: "ANZ"},
@@ -20,9 +20,9 @@ func TestDashboardDefaultScopeUsesTimeframeAndAccountsOnly(t *testing.T) {
 	m.filterInput = "cat:Transport"
 	m.reparseFilterInput()
 	m.rows = []transaction{
-		{id: 1, dateISO: "2026-01-10", amount: -10, categoryName: "Groceries", accountName: "ANZ"},
-		{id: 2, dateISO: "2026-01-11", amount: -20, categoryName: "Transport", accountName: "CBA"},
-		{id: 3, dateISO: "2026-02-01", amount: -30, categoryName: "Groceries", accountName: "ANZ"},
+		{id: 1, dateISO: "2026-01-10", amount: -1000, categoryName: "Groceries", accountName: "ANZ"},
+		{id: 2, dateISO: "2026-01-11", amount: -2000, categoryName: "Transport", accountName: "CBA"},
+		{id: 3, dateISO: "2026-02-01", amount: -3000, categoryName: "Groceries", accountName: "ANZ"},
 	}
 
 	rows := m.getDashboardRows()
@@ -32,15 +32,15 @@ func TestDashboardDefaultScopeUsesTimeframeAndAccountsOnly(t *testing.T) {
 }
 
 func TestDashboardCustomModeFilterComposesViaAST(t *testing.T) {
-	m := newModel()
+	m := newModel("")
 	m.activeTab = tabDashboard
 	m.dashMonthMode = true
 	m.dashAnchorMonth = "2026-01"
 	m.rows = []transaction{
-		{id: 1, dateISO: "2026-01-10", amount: -10, categoryName: "Groceries", accountName: "ANZ"},
-		{id: 2, dateISO: "2026-01-11", amount: -20, categoryName: "Dining", accountName: "ANZ"},
-		{id: 3, dateISO: "2026-01-12", amount: -30, categoryName: "Utilities", accountName: "ANZ"},
-		{id: 4, dateISO: "2026-02-01", amount: -40, categoryName: "Groceries", accountName: "ANZ"},
+		{id: 1, dateISO: "2026-01-10", amount: -1000, categoryName: "Groceries", accountName: "ANZ"},
+		{id: 2, dateISO: "2026-01-11", amount: -2000, categoryName: "Dining", accountName: "ANZ"},
+		{id: 3, dateISO: "2026-01-12", amount: -3000, categoryName: "Utilities", accountName: "ANZ"},
+		{id: 4, dateISO: "2026-02-01", amount: -4000, categoryName: "Groceries", accountName: "ANZ"},
 	}
 	mode := widgetMode{filterExpr: "cat:Groceries OR cat:Dining", custom: true}
 
@@ -61,7 +61,7 @@ func TestDashboardCustomModeFilterComposesViaAST(t *testing.T) {
 }
 
 func TestManagerFilterPillShowsDashboardDrillPrefix(t *testing.T) {
-	m := newModel()
+	m := newModel("")
 	m.activeTab = tabManager
 	m.filterInput = "cat:Groceries"
 	m.reparseFilterInput()
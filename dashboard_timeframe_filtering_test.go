@@ -100,15 +100,15 @@ func TestDashboardDateRangeUsesPresetBoundsEvenWithSparseData(t *testing.T) {
 func TestDashboardTimeframeRelationshipsWithPanels(t *testing.T) {
 	now := time.Date(2026, time.February, 11, 9, 0, 0, 0, time.Local)
 	rows := []transaction{
-		{id: 1, dateISO: "2026-02-10", amount: -10, categoryName: "Groceries"},
-		{id: 2, dateISO: "2026-01-10", amount: -20, categoryName: "Transport"},
-		{id: 3, dateISO: "2025-12-12", amount: -30, categoryName: "Dining"},
-		{id: 4, dateISO: "2025-11-12", amount: -40, categoryName: "Utilities"},
-		{id: 5, dateISO: "2025-08-12", amount: -50, categoryName: "Travel"},
-		{id: 6, dateISO: "2025-07-10", amount: -60, categoryName: "Old"},
-		{id: 7, dateISO: "2026-02-08", amount: 1000, categoryName: "Income"},
-		{id: 8, dateISO: "2026-02-07", amount: -5, categoryName: "Uncategorised"},
-		{id: 9, dateISO: "2026-02-06", amount: -7, categoryName: "Groceries"},
+		{id: 1, dateISO: "2026-02-10", amount: -1000, categoryName: "Groceries"},
+		{id: 2, dateISO: "2026-01-10", amount: -2000, categoryName: "Transport"},
+		{id: 3, dateISO: "2025-12-12", amount: -3000, categoryName: "Dining"},
+		{id: 4, dateISO: "2025-11-12", amount: -4000, categoryName: "Utilities"},
+		{id: 5, dateISO: "2025-08-12", amount: -5000, categoryName: "Travel"},
+		{id: 6, dateISO: "2025-07-10", amount: -6000, categoryName: "Old"},
+		{id: 7, dateISO: "2026-02-08", amount: 100000, categoryName: "Income"},
+		{id: 8, dateISO: "2026-02-07", amount: -500, categoryName: "Uncategorised"},
+		{id: 9, dateISO: "2026-02-06", amount: -700, categoryName: "Groceries"},
 	}
 	txnTags := map[int][]tag{
 		9: {{id: 1, name: "IGNORE"}},
@@ -164,7 +164,7 @@ func TestDashboardTimeframeRelationshipsWithPanels(t *testing.T) {
 				t.Fatalf("spending rows = %v, want %v", got, tc.wantSpend)
 			}
 
-			m := newModel()
+			m := newModel("")
 			m.dashTimeframe = tc.timeframe
 			start, end := m.dashboardChartRange(now)
 			if got := start.Format("2006-01-02"); got != tc.wantStart {
@@ -182,7 +182,7 @@ func TestDashboardTimeframeRelationshipsWithPanels(t *testing.T) {
 }
 
 func TestManagerTransactionsDateFilterRemoved(t *testing.T) {
-	m := newModel()
+	m := newModel("")
 	m.activeTab = tabManager
 	m.managerMode = managerModeTransactions
 	m.status = "unchanged"
@@ -1,16 +1,17 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"encoding/json"
 	"fmt"
-	"math"
 	"os"
 	"path/filepath"
 	"sort"
 	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
 	_ "modernc.org/sqlite"
 )
 
@@ -18,7 +19,12 @@ import (
 // Schema version
 // ---------------------------------------------------------------------------
 
-const schemaVersion = 7
+const schemaVersion = 18
+
+// oldestMigratableSchema is the oldest schema migrateSchema upgrades in
+// place; older databases are rebuilt empty by migrateClean.
+const oldestMigratableSchema = 3
+
 const mandatoryIgnoreTagName = "IGNORE"
 const legacyRuleExprPrefix = "__legacy_expr__:"
 
@@ -55,7 +61,7 @@ var mandatoryTags = []struct {
 // Schema DDL
 // ---------------------------------------------------------------------------
 
-const schemaV7 = `
+const schemaV18 = `
 CREATE TABLE IF NOT EXISTS schema_meta (
 	version INTEGER NOT NULL
 );
@@ -98,7 +104,8 @@ CREATE TABLE IF NOT EXISTS accounts (
 	name       TEXT NOT NULL UNIQUE,
 	type       TEXT NOT NULL CHECK(type IN ('debit','credit')) DEFAULT 'debit',
 	sort_order INTEGER NOT NULL DEFAULT 0,
-	is_active  INTEGER NOT NULL DEFAULT 1
+	is_active  INTEGER NOT NULL DEFAULT 1,
+	currency   TEXT NOT NULL DEFAULT ''
 );
 
 CREATE TABLE IF NOT EXISTS account_selection (
@@ -109,12 +116,19 @@ CREATE TABLE IF NOT EXISTS transactions (
 	id            INTEGER PRIMARY KEY AUTOINCREMENT,
 	date_raw      TEXT NOT NULL,
 	date_iso      TEXT NOT NULL,
-	amount        REAL NOT NULL,
+	amount        INTEGER NOT NULL,
 	description   TEXT NOT NULL,
 	category_id   INTEGER REFERENCES categories(id) ON DELETE SET NULL,
 	notes         TEXT NOT NULL DEFAULT '',
 	import_id     INTEGER REFERENCES imports(id),
 	account_id    INTEGER REFERENCES accounts(id),
+	external_ref  TEXT NOT NULL DEFAULT '',
+	edited_at     TEXT NOT NULL DEFAULT '',
+	bank_balance  INTEGER,
+	original_amount   INTEGER,
+	original_currency TEXT NOT NULL DEFAULT '',
+	status        TEXT NOT NULL DEFAULT 'posted' CHECK(status IN ('pending','posted')),
+	merchant      TEXT NOT NULL DEFAULT '',
 	created_at    TEXT NOT NULL DEFAULT (datetime('now'))
 );
 
@@ -122,13 +136,67 @@ CREATE TABLE IF NOT EXISTS imports (
 	id            INTEGER PRIMARY KEY AUTOINCREMENT,
 	filename      TEXT NOT NULL,
 	row_count     INTEGER NOT NULL,
+	content_hash  TEXT NOT NULL DEFAULT '',
+	file_size     INTEGER NOT NULL DEFAULT 0,
+	format        TEXT NOT NULL DEFAULT '',
+	account_id    INTEGER REFERENCES accounts(id) ON DELETE SET NULL,
+	date_from     TEXT NOT NULL DEFAULT '',
+	date_to       TEXT NOT NULL DEFAULT '',
+	settled_pending INTEGER NOT NULL DEFAULT 0,
 	imported_at   TEXT NOT NULL DEFAULT (datetime('now'))
 );
 
+CREATE TABLE IF NOT EXISTS import_rejects (
+	id            INTEGER PRIMARY KEY AUTOINCREMENT,
+	import_id     INTEGER REFERENCES imports(id) ON DELETE CASCADE,
+	account_id    INTEGER REFERENCES accounts(id) ON DELETE SET NULL,
+	filename      TEXT NOT NULL,
+	source_line   INTEGER NOT NULL,
+	raw           TEXT NOT NULL DEFAULT '',
+	field         TEXT NOT NULL DEFAULT '',
+	error         TEXT NOT NULL DEFAULT '',
+	date_raw      TEXT NOT NULL DEFAULT '',
+	date_format   TEXT NOT NULL DEFAULT '',
+	amount_raw    TEXT NOT NULL DEFAULT '',
+	description   TEXT NOT NULL DEFAULT '',
+	created_at    TEXT NOT NULL DEFAULT (datetime('now'))
+);
+
+CREATE TABLE IF NOT EXISTS ledger_journal (
+	id            INTEGER PRIMARY KEY AUTOINCREMENT,
+	label         TEXT NOT NULL,
+	before_state  TEXT NOT NULL,
+	after_state   TEXT NOT NULL,
+	row_count     INTEGER NOT NULL DEFAULT 0,
+	undone        INTEGER NOT NULL DEFAULT 0,
+	created_at    TEXT NOT NULL DEFAULT (datetime('now'))
+);
+
+CREATE TABLE IF NOT EXISTS transaction_history (
+	id          INTEGER PRIMARY KEY AUTOINCREMENT,
+	txn_id      INTEGER NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+	field       TEXT NOT NULL,
+	old_value   TEXT NOT NULL DEFAULT '',
+	new_value   TEXT NOT NULL DEFAULT '',
+	source      TEXT NOT NULL DEFAULT 'manual',
+	source_id   INTEGER,
+	changed_at  TEXT NOT NULL DEFAULT (datetime('now'))
+);
+
+CREATE INDEX IF NOT EXISTS idx_transaction_history_txn ON transaction_history(txn_id);
+
+CREATE TABLE IF NOT EXISTS fx_rates (
+	date_iso TEXT NOT NULL,
+	base     TEXT NOT NULL,
+	currency TEXT NOT NULL,
+	rate     REAL NOT NULL CHECK(rate > 0),
+	PRIMARY KEY (date_iso, base, currency)
+);
+
 CREATE TABLE IF NOT EXISTS category_budgets (
 	id          INTEGER PRIMARY KEY AUTOINCREMENT,
 	category_id INTEGER NOT NULL UNIQUE REFERENCES categories(id) ON DELETE CASCADE,
-	amount      REAL NOT NULL DEFAULT 0,
+	amount      INTEGER NOT NULL DEFAULT 0,
 	created_at  TEXT NOT NULL DEFAULT (datetime('now'))
 );
 
@@ -136,7 +204,7 @@ CREATE TABLE IF NOT EXISTS category_budget_overrides (
 	id        INTEGER PRIMARY KEY AUTOINCREMENT,
 	budget_id INTEGER NOT NULL REFERENCES category_budgets(id) ON DELETE CASCADE,
 	month_key TEXT NOT NULL,
-	amount    REAL NOT NULL,
+	amount    INTEGER NOT NULL,
 	UNIQUE(budget_id, month_key)
 );
 
@@ -144,7 +212,7 @@ CREATE TABLE IF NOT EXISTS spending_targets (
 	id          INTEGER PRIMARY KEY AUTOINCREMENT,
 	name        TEXT NOT NULL,
 	saved_filter_id TEXT NOT NULL,
-	amount      REAL NOT NULL DEFAULT 0,
+	amount      INTEGER NOT NULL DEFAULT 0,
 	period_type TEXT NOT NULL DEFAULT 'monthly'
 	            CHECK(period_type IN ('monthly','quarterly','annual')),
 	created_at  TEXT NOT NULL DEFAULT (datetime('now'))
@@ -154,14 +222,14 @@ CREATE TABLE IF NOT EXISTS spending_target_overrides (
 	id         INTEGER PRIMARY KEY AUTOINCREMENT,
 	target_id  INTEGER NOT NULL REFERENCES spending_targets(id) ON DELETE CASCADE,
 	period_key TEXT NOT NULL,
-	amount     REAL NOT NULL,
+	amount     INTEGER NOT NULL,
 	UNIQUE(target_id, period_key)
 );
 
 CREATE TABLE IF NOT EXISTS transaction_allocations (
 	id            INTEGER PRIMARY KEY AUTOINCREMENT,
 	parent_txn_id INTEGER NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
-	amount        REAL NOT NULL CHECK(amount != 0),
+	amount        INTEGER NOT NULL CHECK(amount != 0),
 	category_id   INTEGER REFERENCES categories(id) ON DELETE SET NULL,
 	note          TEXT NOT NULL DEFAULT '',
 	created_at    TEXT NOT NULL DEFAULT (datetime('now')),
@@ -177,6 +245,10 @@ CREATE TABLE IF NOT EXISTS transaction_allocation_tags (
 CREATE INDEX IF NOT EXISTS idx_transactions_date ON transactions(date_iso);
 CREATE INDEX IF NOT EXISTS idx_transactions_category ON transactions(category_id);
 CREATE INDEX IF NOT EXISTS idx_transactions_account ON transactions(account_id);
+CREATE INDEX IF NOT EXISTS idx_transactions_external_ref ON transactions(account_id, external_ref);
+CREATE INDEX IF NOT EXISTS idx_transactions_import ON transactions(import_id);
+CREATE INDEX IF NOT EXISTS idx_imports_hash ON imports(content_hash);
+CREATE INDEX IF NOT EXISTS idx_import_rejects_import ON import_rejects(import_id);
 CREATE INDEX IF NOT EXISTS idx_accounts_sort_order ON accounts(sort_order);
 CREATE INDEX IF NOT EXISTS idx_tags_sort_order ON tags(sort_order);
 CREATE INDEX IF NOT EXISTS idx_rules_v2_sort ON rules_v2(sort_order);
@@ -241,6 +313,29 @@ func openDB(path string) (*sql.DB, error) {
 	return db, nil
 }
 
+// openDBReadOnly opens an existing database for commands that only report.
+// Unlike openDB it never creates, migrates or writes to the file, so it
+// fails when the database is missing or not at the current schema.
+func openDBReadOnly(path string) (*sql.DB, error) {
+	if !fileExists(path) {
+		return nil, fmt.Errorf("database %s does not exist", path)
+	}
+	db, err := sql.Open("sqlite", "file:"+path+"?mode=ro")
+	if err != nil {
+		return nil, fmt.Errorf("open db: %w", err)
+	}
+	ver, err := currentSchemaVersion(db)
+	if err != nil {
+		_ = db.Close()
+		return nil, fmt.Errorf("check schema version: %w", err)
+	}
+	if ver != schemaVersion {
+		_ = db.Close()
+		return nil, fmt.Errorf("database is at schema v%d, this build needs v%d; open it once without --dry-run to migrate", ver, schemaVersion)
+	}
+	return db, nil
+}
+
 // currentSchemaVersion returns the schema version from schema_meta,
 // or 0 if the table doesn't exist (indicating v0.1 or fresh DB).
 func currentSchemaVersion(db *sql.DB) (int, error) {
@@ -265,39 +360,44 @@ func currentSchemaVersion(db *sql.DB) (int, error) {
 	return ver, err
 }
 
-// migrateSchema upgrades the schema to the current version.
+// migrateSchema upgrades the schema to the current version. Each step
+// migrates exactly one version forward and records its own version, so an
+// older database walks the chain until it reaches schemaVersion. The old
+// database is snapshotted first.
 func migrateSchema(db *sql.DB, fromVersion int) error {
-	if fromVersion == 6 {
-		return migrateFromV6ToV7(db)
-	}
-	if fromVersion == 5 {
-		if err := migrateFromV5ToV6(db); err != nil {
-			return err
-		}
-		return migrateFromV6ToV7(db)
-	}
-	if fromVersion == 4 {
-		if err := migrateFromV4ToV5(db); err != nil {
-			return err
-		}
-		if err := migrateFromV5ToV6(db); err != nil {
-			return err
-		}
-		return migrateFromV6ToV7(db)
-	}
-	if fromVersion == 3 {
-		if err := migrateFromV3ToV4(db); err != nil {
-			return err
-		}
-		if err := migrateFromV4ToV5(db); err != nil {
-			return err
+	if _, err := snapshotDB(db, fmt.Sprintf("pre-migrate-v%d", fromVersion)); err != nil {
+		return fmt.Errorf("snapshot before migration: %w", err)
+	}
+	steps := map[int]func(*sql.DB) error{
+		3:  migrateFromV3ToV4,
+		4:  migrateFromV4ToV5,
+		5:  migrateFromV5ToV6,
+		6:  migrateFromV6ToV7,
+		7:  migrateFromV7ToV8,
+		8:  migrateFromV8ToV9,
+		9:  migrateFromV9ToV10,
+		10: migrateFromV10ToV11,
+		11: migrateFromV11ToV12,
+		12: migrateFromV12ToV13,
+		13: migrateFromV13ToV14,
+		14: migrateFromV14ToV15,
+		15: migrateFromV15ToV16,
+		16: migrateFromV16ToV17,
+		17: migrateFromV17ToV18,
+	}
+	if fromVersion < oldestMigratableSchema {
+		return migrateClean(db)
+	}
+	for ver := fromVersion; ver < schemaVersion; ver++ {
+		step, ok := steps[ver]
+		if !ok {
+			return fmt.Errorf("no migration from schema v%d", ver)
 		}
-		if err := migrateFromV5ToV6(db); err != nil {
+		if err := step(db); err != nil {
 			return err
 		}
-		return migrateFromV6ToV7(db)
 	}
-	return migrateClean(db)
+	return nil
 }
 
 func migrateFromV3ToV4(db *sql.DB) error {
@@ -516,6 +616,543 @@ func migrateFromV6ToV7(db *sql.DB) error {
 	return nil
 }
 
+// migrateFromV7ToV8 adds transactions.external_ref, the bank-issued
+// transaction identifier (e.g. OFX FITID) used for reference-based dedupe.
+func migrateFromV7ToV8(db *sql.DB) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin v7->v8 migration: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	hasExternalRef, err := tableHasColumnTx(tx, "transactions", "external_ref")
+	if err != nil {
+		return fmt.Errorf("inspect transactions schema: %w", err)
+	}
+	stmts := []string{
+		`CREATE INDEX IF NOT EXISTS idx_transactions_external_ref ON transactions(account_id, external_ref)`,
+		`DELETE FROM schema_meta`,
+		`INSERT INTO schema_meta (version) VALUES (8)`,
+	}
+	if !hasExternalRef {
+		stmts = append([]string{`ALTER TABLE transactions ADD COLUMN external_ref TEXT NOT NULL DEFAULT ''`}, stmts...)
+	}
+	for _, stmt := range stmts {
+		if _, err := tx.Exec(stmt); err != nil {
+			return fmt.Errorf("migrate v7->v8 statement failed: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit v7->v8 migration: %w", err)
+	}
+	return nil
+}
+
+// migrateFromV8ToV9 adds transactions.edited_at, stamped by manual edits so
+// undoing an import can warn before discarding hand-made changes.
+func migrateFromV8ToV9(db *sql.DB) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin v8->v9 migration: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	hasEditedAt, err := tableHasColumnTx(tx, "transactions", "edited_at")
+	if err != nil {
+		return fmt.Errorf("inspect transactions schema: %w", err)
+	}
+	stmts := []string{
+		`CREATE INDEX IF NOT EXISTS idx_transactions_import ON transactions(import_id)`,
+		`DELETE FROM schema_meta`,
+		`INSERT INTO schema_meta (version) VALUES (9)`,
+	}
+	if !hasEditedAt {
+		stmts = append([]string{`ALTER TABLE transactions ADD COLUMN edited_at TEXT NOT NULL DEFAULT ''`}, stmts...)
+	}
+	for _, stmt := range stmts {
+		if _, err := tx.Exec(stmt); err != nil {
+			return fmt.Errorf("migrate v8->v9 statement failed: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit v8->v9 migration: %w", err)
+	}
+	return nil
+}
+
+// migrateFromV9ToV10 adds transactions.bank_balance, the running balance a
+// bank export reports after each row (NULL when the source has none).
+func migrateFromV9ToV10(db *sql.DB) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin v9->v10 migration: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	hasBalance, err := tableHasColumnTx(tx, "transactions", "bank_balance")
+	if err != nil {
+		return fmt.Errorf("inspect transactions schema: %w", err)
+	}
+	stmts := []string{
+		`DELETE FROM schema_meta`,
+		`INSERT INTO schema_meta (version) VALUES (10)`,
+	}
+	if !hasBalance {
+		stmts = append([]string{`ALTER TABLE transactions ADD COLUMN bank_balance REAL`}, stmts...)
+	}
+	for _, stmt := range stmts {
+		if _, err := tx.Exec(stmt); err != nil {
+			return fmt.Errorf("migrate v9->v10 statement failed: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit v9->v10 migration: %w", err)
+	}
+	return nil
+}
+
+// migrateFromV10ToV11 adds multi-currency support: accounts.currency,
+// the original foreign amount on transactions, and the fx_rates table.
+func migrateFromV10ToV11(db *sql.DB) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin v10->v11 migration: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	var stmts []string
+	for _, col := range []struct{ table, name, ddl string }{
+		{"accounts", "currency", `ALTER TABLE accounts ADD COLUMN currency TEXT NOT NULL DEFAULT ''`},
+		{"transactions", "original_amount", `ALTER TABLE transactions ADD COLUMN original_amount REAL`},
+		{"transactions", "original_currency", `ALTER TABLE transactions ADD COLUMN original_currency TEXT NOT NULL DEFAULT ''`},
+	} {
+		has, err := tableHasColumnTx(tx, col.table, col.name)
+		if err != nil {
+			return fmt.Errorf("inspect %s schema: %w", col.table, err)
+		}
+		if !has {
+			stmts = append(stmts, col.ddl)
+		}
+	}
+	stmts = append(stmts,
+		`CREATE TABLE IF NOT EXISTS fx_rates (
+			date_iso TEXT NOT NULL,
+			base     TEXT NOT NULL,
+			currency TEXT NOT NULL,
+			rate     REAL NOT NULL CHECK(rate > 0),
+			PRIMARY KEY (date_iso, base, currency)
+		)`,
+		`DELETE FROM schema_meta`,
+		`INSERT INTO schema_meta (version) VALUES (11)`,
+	)
+	for _, stmt := range stmts {
+		if _, err := tx.Exec(stmt); err != nil {
+			return fmt.Errorf("migrate v10->v11 statement failed: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit v10->v11 migration: %w", err)
+	}
+	return nil
+}
+
+// migrateFromV11ToV12 adds transactions.status so pending authorisations
+// can be told apart from (and later replaced by) posted transactions.
+func migrateFromV11ToV12(db *sql.DB) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin v11->v12 migration: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	hasStatus, err := tableHasColumnTx(tx, "transactions", "status")
+	if err != nil {
+		return fmt.Errorf("inspect transactions schema: %w", err)
+	}
+	hasSettled, err := tableHasColumnTx(tx, "imports", "settled_pending")
+	if err != nil {
+		return fmt.Errorf("inspect imports schema: %w", err)
+	}
+	var stmts []string
+	if !hasStatus {
+		stmts = append(stmts, `ALTER TABLE transactions ADD COLUMN status TEXT NOT NULL DEFAULT 'posted' CHECK(status IN ('pending','posted'))`)
+	}
+	if !hasSettled {
+		stmts = append(stmts, `ALTER TABLE imports ADD COLUMN settled_pending INTEGER NOT NULL DEFAULT 0`)
+	}
+	stmts = append(stmts,
+		`DELETE FROM schema_meta`,
+		`INSERT INTO schema_meta (version) VALUES (12)`,
+	)
+	for _, stmt := range stmts {
+		if _, err := tx.Exec(stmt); err != nil {
+			return fmt.Errorf("migrate v11->v12 statement failed: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit v11->v12 migration: %w", err)
+	}
+	return nil
+}
+
+func migrateFromV12ToV13(db *sql.DB) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin v12->v13 migration: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	hasMerchant, err := tableHasColumnTx(tx, "transactions", "merchant")
+	if err != nil {
+		return fmt.Errorf("inspect transactions schema: %w", err)
+	}
+	stmts := []string{
+		`DELETE FROM schema_meta`,
+		`INSERT INTO schema_meta (version) VALUES (13)`,
+	}
+	if !hasMerchant {
+		stmts = append([]string{`ALTER TABLE transactions ADD COLUMN merchant TEXT NOT NULL DEFAULT ''`}, stmts...)
+	}
+	for _, stmt := range stmts {
+		if _, err := tx.Exec(stmt); err != nil {
+			return fmt.Errorf("migrate v12->v13 statement failed: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit v12->v13 migration: %w", err)
+	}
+	return nil
+}
+
+// migrateFromV13ToV14 adds file provenance to imports: content hash, size,
+// detected format, account and the date range the file covered.
+func migrateFromV13ToV14(db *sql.DB) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin v13->v14 migration: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	columns := []struct{ name, ddl string }{
+		{"content_hash", `ALTER TABLE imports ADD COLUMN content_hash TEXT NOT NULL DEFAULT ''`},
+		{"file_size", `ALTER TABLE imports ADD COLUMN file_size INTEGER NOT NULL DEFAULT 0`},
+		{"format", `ALTER TABLE imports ADD COLUMN format TEXT NOT NULL DEFAULT ''`},
+		{"account_id", `ALTER TABLE imports ADD COLUMN account_id INTEGER REFERENCES accounts(id) ON DELETE SET NULL`},
+		{"date_from", `ALTER TABLE imports ADD COLUMN date_from TEXT NOT NULL DEFAULT ''`},
+		{"date_to", `ALTER TABLE imports ADD COLUMN date_to TEXT NOT NULL DEFAULT ''`},
+	}
+	var stmts []string
+	for _, col := range columns {
+		has, err := tableHasColumnTx(tx, "imports", col.name)
+		if err != nil {
+			return fmt.Errorf("inspect imports schema: %w", err)
+		}
+		if !has {
+			stmts = append(stmts, col.ddl)
+		}
+	}
+	stmts = append(stmts,
+		`CREATE INDEX IF NOT EXISTS idx_imports_hash ON imports(content_hash)`,
+		`DELETE FROM schema_meta`,
+		`INSERT INTO schema_meta (version) VALUES (14)`,
+	)
+	for _, stmt := range stmts {
+		if _, err := tx.Exec(stmt); err != nil {
+			return fmt.Errorf("migrate v13->v14 statement failed: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit v13->v14 migration: %w", err)
+	}
+	return nil
+}
+
+// migrateFromV14ToV15 adds the import_rejects quarantine table.
+func migrateFromV14ToV15(db *sql.DB) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin v14->v15 migration: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	stmts := []string{
+		`CREATE TABLE IF NOT EXISTS import_rejects (
+			id            INTEGER PRIMARY KEY AUTOINCREMENT,
+			import_id     INTEGER REFERENCES imports(id) ON DELETE CASCADE,
+			account_id    INTEGER REFERENCES accounts(id) ON DELETE SET NULL,
+			filename      TEXT NOT NULL,
+			source_line   INTEGER NOT NULL,
+			raw           TEXT NOT NULL DEFAULT '',
+			field         TEXT NOT NULL DEFAULT '',
+			error         TEXT NOT NULL DEFAULT '',
+			date_raw      TEXT NOT NULL DEFAULT '',
+			date_format   TEXT NOT NULL DEFAULT '',
+			amount_raw    TEXT NOT NULL DEFAULT '',
+			description   TEXT NOT NULL DEFAULT '',
+			created_at    TEXT NOT NULL DEFAULT (datetime('now'))
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_import_rejects_import ON import_rejects(import_id)`,
+		`DELETE FROM schema_meta`,
+		`INSERT INTO schema_meta (version) VALUES (15)`,
+	}
+	for _, stmt := range stmts {
+		if _, err := tx.Exec(stmt); err != nil {
+			return fmt.Errorf("migrate v14->v15 statement failed: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit v14->v15 migration: %w", err)
+	}
+	return nil
+}
+
+// moneyTableRebuild describes a table whose REAL amount columns become
+// INTEGER cents in v16. create is the v16 DDL with %s for the table name.
+type moneyTableRebuild struct {
+	table   string
+	create  string
+	columns []string
+	cents   []string // columns converted from major units to cents
+	indexes []string
+}
+
+var moneyTableRebuilds = []moneyTableRebuild{
+	{
+		table: "transactions",
+		create: `CREATE TABLE %s (
+			id            INTEGER PRIMARY KEY AUTOINCREMENT,
+			date_raw      TEXT NOT NULL,
+			date_iso      TEXT NOT NULL,
+			amount        INTEGER NOT NULL,
+			description   TEXT NOT NULL,
+			category_id   INTEGER REFERENCES categories(id) ON DELETE SET NULL,
+			notes         TEXT NOT NULL DEFAULT '',
+			import_id     INTEGER REFERENCES imports(id),
+			account_id    INTEGER REFERENCES accounts(id),
+			external_ref  TEXT NOT NULL DEFAULT '',
+			edited_at     TEXT NOT NULL DEFAULT '',
+			bank_balance  INTEGER,
+			original_amount   INTEGER,
+			original_currency TEXT NOT NULL DEFAULT '',
+			status        TEXT NOT NULL DEFAULT 'posted' CHECK(status IN ('pending','posted')),
+			merchant      TEXT NOT NULL DEFAULT '',
+			created_at    TEXT NOT NULL DEFAULT (datetime('now'))
+		)`,
+		columns: []string{"id", "date_raw", "date_iso", "amount", "description", "category_id", "notes", "import_id",
+			"account_id", "external_ref", "edited_at", "bank_balance", "original_amount", "original_currency",
+			"status", "merchant", "created_at"},
+		cents: []string{"amount", "bank_balance", "original_amount"},
+		indexes: []string{
+			`CREATE INDEX IF NOT EXISTS idx_transactions_date ON transactions(date_iso)`,
+			`CREATE INDEX IF NOT EXISTS idx_transactions_category ON transactions(category_id)`,
+			`CREATE INDEX IF NOT EXISTS idx_transactions_account ON transactions(account_id)`,
+			`CREATE INDEX IF NOT EXISTS idx_transactions_external_ref ON transactions(account_id, external_ref)`,
+			`CREATE INDEX IF NOT EXISTS idx_transactions_import ON transactions(import_id)`,
+		},
+	},
+	{
+		table: "category_budgets",
+		create: `CREATE TABLE %s (
+			id          INTEGER PRIMARY KEY AUTOINCREMENT,
+			category_id INTEGER NOT NULL UNIQUE REFERENCES categories(id) ON DELETE CASCADE,
+			amount      INTEGER NOT NULL DEFAULT 0,
+			created_at  TEXT NOT NULL DEFAULT (datetime('now'))
+		)`,
+		columns: []string{"id", "category_id", "amount", "created_at"},
+		cents:   []string{"amount"},
+		indexes: []string{`CREATE INDEX IF NOT EXISTS idx_category_budgets_cat ON category_budgets(category_id)`},
+	},
+	{
+		table: "category_budget_overrides",
+		create: `CREATE TABLE %s (
+			id        INTEGER PRIMARY KEY AUTOINCREMENT,
+			budget_id INTEGER NOT NULL REFERENCES category_budgets(id) ON DELETE CASCADE,
+			month_key TEXT NOT NULL,
+			amount    INTEGER NOT NULL,
+			UNIQUE(budget_id, month_key)
+		)`,
+		columns: []string{"id", "budget_id", "month_key", "amount"},
+		cents:   []string{"amount"},
+	},
+	{
+		table: "spending_targets",
+		create: `CREATE TABLE %s (
+			id          INTEGER PRIMARY KEY AUTOINCREMENT,
+			name        TEXT NOT NULL,
+			saved_filter_id TEXT NOT NULL,
+			amount      INTEGER NOT NULL DEFAULT 0,
+			period_type TEXT NOT NULL DEFAULT 'monthly'
+			            CHECK(period_type IN ('monthly','quarterly','annual')),
+			created_at  TEXT NOT NULL DEFAULT (datetime('now'))
+		)`,
+		columns: []string{"id", "name", "saved_filter_id", "amount", "period_type", "created_at"},
+		cents:   []string{"amount"},
+	},
+	{
+		table: "spending_target_overrides",
+		create: `CREATE TABLE %s (
+			id         INTEGER PRIMARY KEY AUTOINCREMENT,
+			target_id  INTEGER NOT NULL REFERENCES spending_targets(id) ON DELETE CASCADE,
+			period_key TEXT NOT NULL,
+			amount     INTEGER NOT NULL,
+			UNIQUE(target_id, period_key)
+		)`,
+		columns: []string{"id", "target_id", "period_key", "amount"},
+		cents:   []string{"amount"},
+	},
+	{
+		table: "transaction_allocations",
+		create: `CREATE TABLE %s (
+			id            INTEGER PRIMARY KEY AUTOINCREMENT,
+			parent_txn_id INTEGER NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+			amount        INTEGER NOT NULL CHECK(amount != 0),
+			category_id   INTEGER REFERENCES categories(id) ON DELETE SET NULL,
+			note          TEXT NOT NULL DEFAULT '',
+			created_at    TEXT NOT NULL DEFAULT (datetime('now')),
+			updated_at    TEXT NOT NULL DEFAULT (datetime('now'))
+		)`,
+		columns: []string{"id", "parent_txn_id", "amount", "category_id", "note", "created_at", "updated_at"},
+		cents:   []string{"amount"},
+		indexes: []string{
+			`CREATE INDEX IF NOT EXISTS idx_txn_alloc_parent ON transaction_allocations(parent_txn_id)`,
+			`CREATE INDEX IF NOT EXISTS idx_txn_alloc_category ON transaction_allocations(category_id)`,
+		},
+	},
+}
+
+// statements returns the rebuild: copy into a v16 table with amounts
+// rounded to the cent, drop the old table and take its name.
+func (r moneyTableRebuild) statements() []string {
+	tmp := r.table + "_v16"
+	cents := make(map[string]bool, len(r.cents))
+	for _, col := range r.cents {
+		cents[col] = true
+	}
+	selects := make([]string, len(r.columns))
+	for i, col := range r.columns {
+		selects[i] = col
+		if cents[col] {
+			selects[i] = fmt.Sprintf("CAST(ROUND(%s * 100) AS INTEGER)", col)
+		}
+	}
+	stmts := []string{
+		fmt.Sprintf(r.create, tmp),
+		fmt.Sprintf("INSERT INTO %s (%s) SELECT %s FROM %s",
+			tmp, strings.Join(r.columns, ", "), strings.Join(selects, ", "), r.table),
+		"DROP TABLE " + r.table,
+		fmt.Sprintf("ALTER TABLE %s RENAME TO %s", tmp, r.table),
+	}
+	return append(stmts, r.indexes...)
+}
+
+// migrateFromV15ToV16 stores money as integer cents. SQLite can't change a
+// column's type, so each table with amounts is rebuilt, keeping row ids.
+// Foreign keys are off on the migrating connection so dropping the old
+// tables doesn't cascade into their children.
+func migrateFromV15ToV16(db *sql.DB) error {
+	ctx := context.Background()
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("reserve v15->v16 connection: %w", err)
+	}
+	defer conn.Close()
+	if _, err := conn.ExecContext(ctx, "PRAGMA foreign_keys = OFF"); err != nil {
+		return fmt.Errorf("disable foreign keys for v15->v16: %w", err)
+	}
+	defer conn.ExecContext(ctx, "PRAGMA foreign_keys = ON") //nolint:errcheck
+
+	tx, err := conn.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin v15->v16 migration: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	var stmts []string
+	for _, r := range moneyTableRebuilds {
+		stmts = append(stmts, r.statements()...)
+	}
+	stmts = append(stmts,
+		`DELETE FROM schema_meta`,
+		`INSERT INTO schema_meta (version) VALUES (16)`,
+	)
+	for _, stmt := range stmts {
+		if _, err := tx.Exec(stmt); err != nil {
+			return fmt.Errorf("migrate v15->v16 statement failed: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit v15->v16 migration: %w", err)
+	}
+	return nil
+}
+
+// migrateFromV16ToV17 adds the ledger_journal table behind undo/redo.
+func migrateFromV16ToV17(db *sql.DB) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin v16->v17 migration: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	stmts := []string{
+		`CREATE TABLE IF NOT EXISTS ledger_journal (
+			id            INTEGER PRIMARY KEY AUTOINCREMENT,
+			label         TEXT NOT NULL,
+			before_state  TEXT NOT NULL,
+			after_state   TEXT NOT NULL,
+			row_count     INTEGER NOT NULL DEFAULT 0,
+			undone        INTEGER NOT NULL DEFAULT 0,
+			created_at    TEXT NOT NULL DEFAULT (datetime('now'))
+		)`,
+		`DELETE FROM schema_meta`,
+		`INSERT INTO schema_meta (version) VALUES (17)`,
+	}
+	for _, stmt := range stmts {
+		if _, err := tx.Exec(stmt); err != nil {
+			return fmt.Errorf("migrate v16->v17 statement failed: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit v16->v17 migration: %w", err)
+	}
+	return nil
+}
+
+// migrateFromV17ToV18 adds transaction_history, the per-transaction audit
+// trail shown in the detail modal.
+func migrateFromV17ToV18(db *sql.DB) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin v17->v18 migration: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	stmts := []string{
+		`CREATE TABLE IF NOT EXISTS transaction_history (
+			id          INTEGER PRIMARY KEY AUTOINCREMENT,
+			txn_id      INTEGER NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
+			field       TEXT NOT NULL,
+			old_value   TEXT NOT NULL DEFAULT '',
+			new_value   TEXT NOT NULL DEFAULT '',
+			source      TEXT NOT NULL DEFAULT 'manual',
+			source_id   INTEGER,
+			changed_at  TEXT NOT NULL DEFAULT (datetime('now'))
+		)`,
+		`CREATE INDEX IF NOT EXISTS idx_transaction_history_txn ON transaction_history(txn_id)`,
+		`DELETE FROM schema_meta`,
+		`INSERT INTO schema_meta (version) VALUES (18)`,
+	}
+	for _, stmt := range stmts {
+		if _, err := tx.Exec(stmt); err != nil {
+			return fmt.Errorf("migrate v17->v18 statement failed: %w", err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit v17->v18 migration: %w", err)
+	}
+	return nil
+}
+
 func tableHasColumnTx(tx *sql.Tx, tableName, columnName string) (bool, error) {
 	query := fmt.Sprintf(`PRAGMA table_info(%s)`, tableName)
 	rows, err := tx.Query(query)
@@ -579,7 +1216,7 @@ func ensureRuntimeSchemaCompatibility(db *sql.DB) error {
 	if _, err := tx.Exec(`CREATE TABLE IF NOT EXISTS transaction_allocations (
 		id            INTEGER PRIMARY KEY AUTOINCREMENT,
 		parent_txn_id INTEGER NOT NULL REFERENCES transactions(id) ON DELETE CASCADE,
-		amount        REAL NOT NULL CHECK(amount != 0),
+		amount        INTEGER NOT NULL CHECK(amount != 0),
 		category_id   INTEGER REFERENCES categories(id) ON DELETE SET NULL,
 		note          TEXT NOT NULL DEFAULT '',
 		created_at    TEXT NOT NULL DEFAULT (datetime('now')),
@@ -607,7 +1244,7 @@ func ensureRuntimeSchemaCompatibility(db *sql.DB) error {
 	return nil
 }
 
-// migrateClean drops everything and starts fresh at schema v7.
+// migrateClean drops everything and starts fresh at the current schema.
 func migrateClean(db *sql.DB) error {
 	drops := []string{
 		"DROP TABLE IF EXISTS transaction_allocation_tags",
@@ -618,12 +1255,16 @@ func migrateClean(db *sql.DB) error {
 		"DROP TABLE IF EXISTS spending_targets",
 		"DROP TABLE IF EXISTS category_budget_overrides",
 		"DROP TABLE IF EXISTS category_budgets",
+		"DROP TABLE IF EXISTS fx_rates",
+		"DROP TABLE IF EXISTS ledger_journal",
+		"DROP TABLE IF EXISTS transaction_history",
 		"DROP TABLE IF EXISTS rules_v2",
 		"DROP TABLE IF EXISTS transaction_tags",
 		"DROP TABLE IF EXISTS tag_rules",
 		"DROP TABLE IF EXISTS category_rules",
 		"DROP TABLE IF EXISTS account_selection",
 		"DROP TABLE IF EXISTS transactions",
+		"DROP TABLE IF EXISTS import_rejects",
 		"DROP TABLE IF EXISTS imports",
 		"DROP TABLE IF EXISTS tags",
 		"DROP TABLE IF EXISTS accounts",
@@ -635,8 +1276,8 @@ func migrateClean(db *sql.DB) error {
 			return fmt.Errorf("drop table: %w", err)
 		}
 	}
-	if _, err := db.Exec(schemaV7); err != nil {
-		return fmt.Errorf("create v7 schema: %w", err)
+	if _, err := db.Exec(schemaV18); err != nil {
+		return fmt.Errorf("create v18 schema: %w", err)
 	}
 	if err := seedDefaultCategories(db); err != nil {
 		return fmt.Errorf("seed categories: %w", err)
@@ -1359,7 +2000,7 @@ func ensureCategoryBudgetRows(db *sql.DB) error {
 	return nil
 }
 
-func upsertCategoryBudget(db *sql.DB, categoryID int, amount float64) error {
+func upsertCategoryBudget(db *sql.DB, categoryID int, amount money) error {
 	if categoryID <= 0 {
 		return fmt.Errorf("category id is required")
 	}
@@ -1396,7 +2037,7 @@ func loadBudgetOverrides(db *sql.DB) (map[int][]budgetOverride, error) {
 	return out, rows.Err()
 }
 
-func upsertBudgetOverride(db *sql.DB, budgetID int, monthKey string, amount float64) error {
+func upsertBudgetOverride(db *sql.DB, budgetID int, monthKey string, amount money) error {
 	if budgetID <= 0 {
 		return fmt.Errorf("budget id is required")
 	}
@@ -1536,7 +2177,7 @@ func loadTargetOverrides(db *sql.DB) (map[int][]targetOverride, error) {
 	return out, rows.Err()
 }
 
-func upsertTargetOverride(db *sql.DB, targetID int, periodKey string, amount float64) error {
+func upsertTargetOverride(db *sql.DB, targetID int, periodKey string, amount money) error {
 	if targetID <= 0 {
 		return fmt.Errorf("target id is required")
 	}
@@ -1619,31 +2260,95 @@ type importRecord struct {
 	filename   string
 	rowCount   int
 	importedAt string
+
+	// Provenance of the imported file. Imports recorded before schema v14
+	// have these blank.
+	contentHash string // hex SHA-256 of the file bytes
+	fileSize    int64
+	format      string // CSV format name, or "OFX"/"QIF"
+	accountID   *int
+	accountName string
+	dateFrom    string // ISO dates of the earliest and latest rows
+	dateTo      string
 }
 
-// loadImports retrieves all import records ordered by most recent first.
-func loadImports(db *sql.DB) ([]importRecord, error) {
-	rows, err := db.Query(`
-		SELECT id, filename, row_count, imported_at
-		FROM imports
-		ORDER BY imported_at DESC
-	`)
-	if err != nil {
-		return nil, fmt.Errorf("query imports: %w", err)
-	}
-	defer rows.Close()
+const importRecordColumns = `
+	i.id, i.filename, i.row_count, i.imported_at,
+	i.content_hash, i.file_size, i.format, i.account_id, COALESCE(a.name, ''), i.date_from, i.date_to`
 
+func scanImportRecords(rows *sql.Rows) ([]importRecord, error) {
+	defer rows.Close()
 	var out []importRecord
 	for rows.Next() {
 		var r importRecord
-		if err := rows.Scan(&r.id, &r.filename, &r.rowCount, &r.importedAt); err != nil {
+		var acctID sql.NullInt64
+		if err := rows.Scan(&r.id, &r.filename, &r.rowCount, &r.importedAt,
+			&r.contentHash, &r.fileSize, &r.format, &acctID, &r.accountName, &r.dateFrom, &r.dateTo); err != nil {
 			return nil, fmt.Errorf("scan import: %w", err)
 		}
+		if acctID.Valid {
+			id := int(acctID.Int64)
+			r.accountID = &id
+		}
 		out = append(out, r)
 	}
 	return out, rows.Err()
 }
 
+// loadImports retrieves all import records ordered by most recent first.
+func loadImports(db *sql.DB) ([]importRecord, error) {
+	rows, err := db.Query(`
+		SELECT` + importRecordColumns + `
+		FROM imports i
+		LEFT JOIN accounts a ON a.id = i.account_id
+		ORDER BY i.imported_at DESC, i.id DESC
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("query imports: %w", err)
+	}
+	return scanImportRecords(rows)
+}
+
+// loadPriorImports finds earlier imports of the same file (by content hash)
+// and, separately, imports into the same account whose date range already
+// covers from..to. An import listed as the same file is not repeated as an
+// overlap.
+func loadPriorImports(db *sql.DB, contentHash string, accountID int, from, to string) (sameFile, covering []importRecord, err error) {
+	if contentHash != "" {
+		rows, err := db.Query(`
+			SELECT`+importRecordColumns+`
+			FROM imports i
+			LEFT JOIN accounts a ON a.id = i.account_id
+			WHERE i.content_hash = ?
+			ORDER BY i.imported_at DESC, i.id DESC
+		`, contentHash)
+		if err != nil {
+			return nil, nil, fmt.Errorf("query imports by hash: %w", err)
+		}
+		if sameFile, err = scanImportRecords(rows); err != nil {
+			return nil, nil, err
+		}
+	}
+	if from == "" || to == "" {
+		return sameFile, nil, nil
+	}
+	rows, err := db.Query(`
+		SELECT`+importRecordColumns+`
+		FROM imports i
+		LEFT JOIN accounts a ON a.id = i.account_id
+		WHERE i.account_id = ? AND i.content_hash != ?
+		  AND i.date_from != '' AND i.date_from <= ? AND i.date_to >= ?
+		ORDER BY i.imported_at DESC, i.id DESC
+	`, accountID, contentHash, from, to)
+	if err != nil {
+		return nil, nil, fmt.Errorf("query overlapping imports: %w", err)
+	}
+	if covering, err = scanImportRecords(rows); err != nil {
+		return nil, nil, err
+	}
+	return sameFile, covering, nil
+}
+
 // ---------------------------------------------------------------------------
 // Transaction queries
 // ---------------------------------------------------------------------------
@@ -1653,7 +2358,8 @@ func loadRows(db *sql.DB) ([]transaction, error) {
 	rows, err := db.Query(`
 		SELECT t.id, t.date_raw, t.date_iso, t.amount, t.description,
 		       t.category_id, COALESCE(c.name, 'Uncategorised'), COALESCE(c.color, '#7f849c'),
-		       t.notes, t.account_id, COALESCE(a.name, ''), COALESCE(a.type, '')
+		       t.notes, t.account_id, COALESCE(a.name, ''), COALESCE(a.type, ''), t.bank_balance,
+		       COALESCE(a.currency, ''), t.original_amount, t.original_currency, t.status, t.merchant
 		FROM transactions t
 		LEFT JOIN categories c ON t.category_id = c.id
 		LEFT JOIN accounts a ON t.account_id = a.id
@@ -1668,7 +2374,8 @@ func loadRows(db *sql.DB) ([]transaction, error) {
 	for rows.Next() {
 		var t transaction
 		if err := rows.Scan(&t.id, &t.dateRaw, &t.dateISO, &t.amount, &t.description,
-			&t.categoryID, &t.categoryName, &t.categoryColor, &t.notes, &t.accountID, &t.accountName, &t.accountType); err != nil {
+			&t.categoryID, &t.categoryName, &t.categoryColor, &t.notes, &t.accountID, &t.accountName, &t.accountType, &t.bankBalance,
+			&t.accountCurrency, &t.origAmount, &t.origCurrency, &t.status, &t.merchant); err != nil {
 			return nil, fmt.Errorf("scan transaction: %w", err)
 		}
 		out = append(out, t)
@@ -1699,7 +2406,8 @@ func loadRowsForAccountScope(db *sql.DB, accountFilter map[int]bool) ([]transact
 	query := fmt.Sprintf(`
 		SELECT t.id, t.date_raw, t.date_iso, t.amount, t.description,
 		       t.category_id, COALESCE(c.name, 'Uncategorised'), COALESCE(c.color, '#7f849c'),
-		       t.notes, t.account_id, COALESCE(a.name, ''), COALESCE(a.type, '')
+		       t.notes, t.account_id, COALESCE(a.name, ''), COALESCE(a.type, ''), t.bank_balance,
+		       COALESCE(a.currency, ''), t.original_amount, t.original_currency, t.status, t.merchant
 		FROM transactions t
 		LEFT JOIN categories c ON t.category_id = c.id
 		LEFT JOIN accounts a ON t.account_id = a.id
@@ -1715,7 +2423,8 @@ func loadRowsForAccountScope(db *sql.DB, accountFilter map[int]bool) ([]transact
 	for rows.Next() {
 		var t transaction
 		if err := rows.Scan(&t.id, &t.dateRaw, &t.dateISO, &t.amount, &t.description,
-			&t.categoryID, &t.categoryName, &t.categoryColor, &t.notes, &t.accountID, &t.accountName, &t.accountType); err != nil {
+			&t.categoryID, &t.categoryName, &t.categoryColor, &t.notes, &t.accountID, &t.accountName, &t.accountType, &t.bankBalance,
+			&t.accountCurrency, &t.origAmount, &t.origCurrency, &t.status, &t.merchant); err != nil {
 			return nil, fmt.Errorf("scan scoped transaction: %w", err)
 		}
 		out = append(out, t)
@@ -1738,7 +2447,8 @@ func loadRowsByTxnIDs(db *sql.DB, txnIDs []int) ([]transaction, error) {
 	query := fmt.Sprintf(`
 		SELECT t.id, t.date_raw, t.date_iso, t.amount, t.description,
 		       t.category_id, COALESCE(c.name, 'Uncategorised'), COALESCE(c.color, '#7f849c'),
-		       t.notes, t.account_id, COALESCE(a.name, ''), COALESCE(a.type, '')
+		       t.notes, t.account_id, COALESCE(a.name, ''), COALESCE(a.type, ''), t.bank_balance,
+		       COALESCE(a.currency, ''), t.original_amount, t.original_currency, t.status, t.merchant
 		FROM transactions t
 		LEFT JOIN categories c ON t.category_id = c.id
 		LEFT JOIN accounts a ON t.account_id = a.id
@@ -1754,7 +2464,8 @@ func loadRowsByTxnIDs(db *sql.DB, txnIDs []int) ([]transaction, error) {
 	for rows.Next() {
 		var t transaction
 		if err := rows.Scan(&t.id, &t.dateRaw, &t.dateISO, &t.amount, &t.description,
-			&t.categoryID, &t.categoryName, &t.categoryColor, &t.notes, &t.accountID, &t.accountName, &t.accountType); err != nil {
+			&t.categoryID, &t.categoryName, &t.categoryColor, &t.notes, &t.accountID, &t.accountName, &t.accountType, &t.bankBalance,
+			&t.accountCurrency, &t.origAmount, &t.origCurrency, &t.status, &t.merchant); err != nil {
 			return nil, fmt.Errorf("scan transaction by id: %w", err)
 		}
 		out = append(out, t)
@@ -1765,7 +2476,7 @@ func loadRowsByTxnIDs(db *sql.DB, txnIDs []int) ([]transaction, error) {
 type transactionAllocation struct {
 	id            int
 	parentTxnID   int
-	amount        float64
+	amount        money
 	categoryID    *int
 	categoryName  string
 	categoryColor string
@@ -1929,23 +2640,25 @@ func normalizeIDList(ids []int) []int {
 	return out
 }
 
-func loadTransactionAmountTx(tx *sql.Tx, txnID int) (float64, error) {
-	var amount float64
+func loadTransactionAmountTx(tx *sql.Tx, txnID int) (money, error) {
+	var amount money
 	if err := tx.QueryRow(`SELECT amount FROM transactions WHERE id = ?`, txnID).Scan(&amount); err != nil {
 		return 0, fmt.Errorf("load parent transaction amount: %w", err)
 	}
 	return amount, nil
 }
 
-func remainingAllocationCapacityTx(tx *sql.Tx, parentTxnID, excludeAllocationID int) (float64, float64, error) {
+// remainingAllocationCapacityTx returns how much of the parent's magnitude
+// is still unallocated, and the parent's signed amount.
+func remainingAllocationCapacityTx(tx *sql.Tx, parentTxnID, excludeAllocationID int) (money, money, error) {
 	parentAmount, err := loadTransactionAmountTx(tx, parentTxnID)
 	if err != nil {
 		return 0, 0, err
 	}
-	if math.Abs(parentAmount) <= 1e-9 {
+	if parentAmount == 0 {
 		return 0, 0, fmt.Errorf("parent transaction amount is zero")
 	}
-	var allocatedAbs float64
+	var allocatedAbs money
 	if excludeAllocationID > 0 {
 		if err := tx.QueryRow(`
 			SELECT COALESCE(SUM(ABS(amount)), 0)
@@ -1964,18 +2677,19 @@ func remainingAllocationCapacityTx(tx *sql.Tx, parentTxnID, excludeAllocationID
 			return 0, 0, fmt.Errorf("sum transaction allocations: %w", err)
 		}
 	}
-	remaining := math.Abs(parentAmount) - allocatedAbs
+	remaining := parentAmount.abs() - allocatedAbs
 	if remaining < 0 {
 		remaining = 0
 	}
 	return remaining, parentAmount, nil
 }
 
-func normalizeAllocationAmount(parentAmount, enteredAmount float64) (float64, error) {
-	if math.Abs(enteredAmount) <= 1e-9 {
+// normalizeAllocationAmount gives an allocation the sign of its parent.
+func normalizeAllocationAmount(parentAmount, enteredAmount money) (money, error) {
+	if enteredAmount == 0 {
 		return 0, fmt.Errorf("allocation amount must be non-zero")
 	}
-	absAmt := math.Abs(enteredAmount)
+	absAmt := enteredAmount.abs()
 	if parentAmount > 0 {
 		return absAmt, nil
 	}
@@ -1985,136 +2699,122 @@ func normalizeAllocationAmount(parentAmount, enteredAmount float64) (float64, er
 	return 0, fmt.Errorf("parent transaction amount is zero")
 }
 
-func insertTransactionAllocation(db *sql.DB, parentTxnID int, enteredAmount float64, categoryID *int, note string, tagIDs []int) (int, error) {
+func insertTransactionAllocation(db *sql.DB, parentTxnID int, enteredAmount money, categoryID *int, note string, tagIDs []int) (int, error) {
 	if parentTxnID <= 0 {
 		return 0, fmt.Errorf("parent transaction id is required")
 	}
-	tx, err := db.Begin()
-	if err != nil {
-		return 0, fmt.Errorf("begin insert transaction allocation: %w", err)
-	}
-	defer tx.Rollback() //nolint:errcheck
-
-	remainingAbs, parentAmount, err := remainingAllocationCapacityTx(tx, parentTxnID, 0)
-	if err != nil {
-		return 0, err
-	}
-	amount, err := normalizeAllocationAmount(parentAmount, enteredAmount)
-	if err != nil {
-		return 0, err
-	}
-	if math.Abs(amount)-remainingAbs > 1e-9 {
-		return 0, fmt.Errorf("allocation exceeds remaining parent capacity")
-	}
+	var allocationID int
+	err := editLedger(db, "add allocation", ledgerScope{txnIDs: []int{parentTxnID}}, func(tx *sql.Tx) error {
+		remainingAbs, parentAmount, err := remainingAllocationCapacityTx(tx, parentTxnID, 0)
+		if err != nil {
+			return err
+		}
+		amount, err := normalizeAllocationAmount(parentAmount, enteredAmount)
+		if err != nil {
+			return err
+		}
+		if amount.abs() > remainingAbs {
+			return fmt.Errorf("allocation exceeds remaining parent capacity")
+		}
 
-	res, err := tx.Exec(`
-		INSERT INTO transaction_allocations (parent_txn_id, amount, category_id, note)
-		VALUES (?, ?, ?, ?)
-	`, parentTxnID, amount, categoryID, strings.TrimSpace(note))
-	if err != nil {
-		return 0, fmt.Errorf("insert transaction allocation: %w", err)
-	}
-	id64, err := res.LastInsertId()
+		res, err := tx.Exec(`
+			INSERT INTO transaction_allocations (parent_txn_id, amount, category_id, note)
+			VALUES (?, ?, ?, ?)
+		`, parentTxnID, amount, categoryID, strings.TrimSpace(note))
+		if err != nil {
+			return fmt.Errorf("insert transaction allocation: %w", err)
+		}
+		id64, err := res.LastInsertId()
+		if err != nil {
+			return fmt.Errorf("transaction allocation last insert id: %w", err)
+		}
+		allocationID = int(id64)
+		if err := setTransactionAllocationTagsTx(tx, allocationID, tagIDs); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(markTransactionEditedSQL, parentTxnID); err != nil {
+			return fmt.Errorf("mark txn %d edited: %w", parentTxnID, err)
+		}
+		return nil
+	})
 	if err != nil {
-		return 0, fmt.Errorf("transaction allocation last insert id: %w", err)
-	}
-	allocationID := int(id64)
-	if err := setTransactionAllocationTagsTx(tx, allocationID, tagIDs); err != nil {
 		return 0, err
 	}
-	if err := tx.Commit(); err != nil {
-		return 0, fmt.Errorf("commit insert transaction allocation: %w", err)
-	}
 	return allocationID, nil
 }
 
-func updateTransactionAllocationAmount(db *sql.DB, allocationID int, enteredAmount float64) error {
-	if allocationID <= 0 {
-		return fmt.Errorf("allocation id is required")
-	}
-	tx, err := db.Begin()
-	if err != nil {
-		return fmt.Errorf("begin update allocation amount: %w", err)
-	}
-	defer tx.Rollback() //nolint:errcheck
+func updateTransactionAllocationAmount(db *sql.DB, allocationID int, enteredAmount money) error {
+	return updateAllocationAmount(db, allocationID, enteredAmount, nil)
+}
 
-	var parentTxnID int
-	if err := tx.QueryRow(`SELECT parent_txn_id FROM transaction_allocations WHERE id = ?`, allocationID).Scan(&parentTxnID); err != nil {
-		return fmt.Errorf("load allocation parent: %w", err)
-	}
-	remainingAbs, parentAmount, err := remainingAllocationCapacityTx(tx, parentTxnID, allocationID)
-	if err != nil {
-		return err
-	}
-	amount, err := normalizeAllocationAmount(parentAmount, enteredAmount)
-	if err != nil {
-		return err
-	}
-	if math.Abs(amount)-remainingAbs > 1e-9 {
-		return fmt.Errorf("allocation exceeds remaining parent capacity")
-	}
-	if _, err := tx.Exec(`
-		UPDATE transaction_allocations
-		SET amount = ?, updated_at = datetime('now')
-		WHERE id = ?
-	`, amount, allocationID); err != nil {
-		return fmt.Errorf("update allocation amount: %w", err)
-	}
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("commit update allocation amount: %w", err)
-	}
-	return nil
+func updateTransactionAllocationAmountAndNote(db *sql.DB, allocationID int, enteredAmount money, note string) error {
+	return updateAllocationAmount(db, allocationID, enteredAmount, &note)
 }
 
-func updateTransactionAllocationAmountAndNote(db *sql.DB, allocationID int, enteredAmount float64, note string) error {
+// updateAllocationAmount re-checks the parent's
+// capacity and sets the amount, and the note when one is given.
+func updateAllocationAmount(db *sql.DB, allocationID int, enteredAmount money, note *string) error {
 	if allocationID <= 0 {
 		return fmt.Errorf("allocation id is required")
 	}
-	tx, err := db.Begin()
-	if err != nil {
-		return fmt.Errorf("begin update allocation amount+note: %w", err)
-	}
-	defer tx.Rollback() //nolint:errcheck
-
-	var parentTxnID int
-	if err := tx.QueryRow(`SELECT parent_txn_id FROM transaction_allocations WHERE id = ?`, allocationID).Scan(&parentTxnID); err != nil {
-		return fmt.Errorf("load allocation parent: %w", err)
-	}
-	remainingAbs, parentAmount, err := remainingAllocationCapacityTx(tx, parentTxnID, allocationID)
-	if err != nil {
-		return err
-	}
-	amount, err := normalizeAllocationAmount(parentAmount, enteredAmount)
-	if err != nil {
-		return err
-	}
-	if math.Abs(amount)-remainingAbs > 1e-9 {
-		return fmt.Errorf("allocation exceeds remaining parent capacity")
-	}
-	if _, err := tx.Exec(`
-		UPDATE transaction_allocations
-		SET amount = ?, note = ?, updated_at = datetime('now')
-		WHERE id = ?
-	`, amount, strings.TrimSpace(note), allocationID); err != nil {
-		return fmt.Errorf("update allocation amount+note: %w", err)
-	}
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("commit update allocation amount+note: %w", err)
-	}
-	return nil
+	return editLedger(db, "edit allocation", ledgerScope{allocationIDs: []int{allocationID}}, func(tx *sql.Tx) error {
+		var parentTxnID int
+		if err := tx.QueryRow(`SELECT parent_txn_id FROM transaction_allocations WHERE id = ?`, allocationID).Scan(&parentTxnID); err != nil {
+			return fmt.Errorf("load allocation parent: %w", err)
+		}
+		remainingAbs, parentAmount, err := remainingAllocationCapacityTx(tx, parentTxnID, allocationID)
+		if err != nil {
+			return err
+		}
+		amount, err := normalizeAllocationAmount(parentAmount, enteredAmount)
+		if err != nil {
+			return err
+		}
+		if amount.abs() > remainingAbs {
+			return fmt.Errorf("allocation exceeds remaining parent capacity")
+		}
+		if note == nil {
+			if _, err := tx.Exec(`
+				UPDATE transaction_allocations
+				SET amount = ?, updated_at = datetime('now')
+				WHERE id = ?
+			`, amount, allocationID); err != nil {
+				return fmt.Errorf("update allocation amount: %w", err)
+			}
+		} else if _, err := tx.Exec(`
+			UPDATE transaction_allocations
+			SET amount = ?, note = ?, updated_at = datetime('now')
+			WHERE id = ?
+		`, amount, strings.TrimSpace(*note), allocationID); err != nil {
+			return fmt.Errorf("update allocation amount+note: %w", err)
+		}
+		if _, err := tx.Exec(markTransactionEditedSQL, parentTxnID); err != nil {
+			return fmt.Errorf("mark txn %d edited: %w", parentTxnID, err)
+		}
+		return nil
+	})
 }
 
 func updateTransactionAllocationCategory(db *sql.DB, allocationID int, categoryID *int) error {
 	if allocationID <= 0 {
 		return fmt.Errorf("allocation id is required")
 	}
-	if _, err := db.Exec(`
+	return editLedger(db, "set category", ledgerScope{allocationIDs: []int{allocationID}}, func(tx *sql.Tx) error {
+		return updateTransactionAllocationCategoryTx(tx, allocationID, categoryID)
+	})
+}
+
+func updateTransactionAllocationCategoryTx(tx *sql.Tx, allocationID int, categoryID *int) error {
+	if _, err := tx.Exec(`
 		UPDATE transaction_allocations
 		SET category_id = ?, updated_at = datetime('now')
 		WHERE id = ?
 	`, categoryID, allocationID); err != nil {
 		return fmt.Errorf("update transaction allocation category: %w", err)
 	}
+	if _, err := tx.Exec(markAllocationParentEditedSQL, allocationID); err != nil {
+		return fmt.Errorf("mark allocation %d parent edited: %w", allocationID, err)
+	}
 	return nil
 }
 
@@ -2122,24 +2822,37 @@ func updateTransactionAllocationNote(db *sql.DB, allocationID int, note string)
 	if allocationID <= 0 {
 		return fmt.Errorf("allocation id is required")
 	}
-	if _, err := db.Exec(`
-		UPDATE transaction_allocations
-		SET note = ?, updated_at = datetime('now')
-		WHERE id = ?
-	`, note, allocationID); err != nil {
-		return fmt.Errorf("update transaction allocation note: %w", err)
-	}
-	return nil
+	return editLedger(db, "set note", ledgerScope{allocationIDs: []int{allocationID}}, func(tx *sql.Tx) error {
+		if _, err := tx.Exec(`
+			UPDATE transaction_allocations
+			SET note = ?, updated_at = datetime('now')
+			WHERE id = ?
+		`, note, allocationID); err != nil {
+			return fmt.Errorf("update transaction allocation note: %w", err)
+		}
+		if _, err := tx.Exec(markAllocationParentEditedSQL, allocationID); err != nil {
+			return fmt.Errorf("mark allocation %d parent edited: %w", allocationID, err)
+		}
+		return nil
+	})
 }
 
 func deleteTransactionAllocation(db *sql.DB, allocationID int) error {
 	if allocationID <= 0 {
 		return fmt.Errorf("allocation id is required")
 	}
-	if _, err := db.Exec(`DELETE FROM transaction_allocations WHERE id = ?`, allocationID); err != nil {
-		return fmt.Errorf("delete transaction allocation: %w", err)
-	}
-	return nil
+	return editLedger(db, "delete allocation", ledgerScope{allocationIDs: []int{allocationID}}, func(tx *sql.Tx) error {
+		if _, err := tx.Exec(markAllocationParentEditedSQL, allocationID); err != nil {
+			return fmt.Errorf("mark allocation %d parent edited: %w", allocationID, err)
+		}
+		if _, err := tx.Exec(`DELETE FROM transaction_allocation_tags WHERE allocation_id = ?`, allocationID); err != nil {
+			return fmt.Errorf("delete transaction allocation tags: %w", err)
+		}
+		if _, err := tx.Exec(`DELETE FROM transaction_allocations WHERE id = ?`, allocationID); err != nil {
+			return fmt.Errorf("delete transaction allocation: %w", err)
+		}
+		return nil
+	})
 }
 
 func setTransactionAllocationTagsTx(tx *sql.Tx, allocationID int, tagIDs []int) error {
@@ -2159,19 +2872,15 @@ func setTransactionAllocationTagsTx(tx *sql.Tx, allocationID int, tagIDs []int)
 }
 
 func setTransactionAllocationTags(db *sql.DB, allocationID int, tagIDs []int) error {
-	tx, err := db.Begin()
-	if err != nil {
-		return fmt.Errorf("begin set allocation tags: %w", err)
-	}
-	defer tx.Rollback() //nolint:errcheck
-
-	if err := setTransactionAllocationTagsTx(tx, allocationID, tagIDs); err != nil {
-		return err
-	}
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("commit set allocation tags: %w", err)
-	}
-	return nil
+	return editLedger(db, "set tags", ledgerScope{allocationIDs: []int{allocationID}}, func(tx *sql.Tx) error {
+		if err := setTransactionAllocationTagsTx(tx, allocationID, tagIDs); err != nil {
+			return err
+		}
+		if _, err := tx.Exec(markAllocationParentEditedSQL, allocationID); err != nil {
+			return fmt.Errorf("mark allocation %d parent edited: %w", allocationID, err)
+		}
+		return nil
+	})
 }
 
 func addTagsToAllocations(db *sql.DB, allocationIDs, tagIDs []int) (int, error) {
@@ -2180,15 +2889,22 @@ func addTagsToAllocations(db *sql.DB, allocationIDs, tagIDs []int) (int, error)
 	if len(allocs) == 0 || len(tags) == 0 {
 		return 0, nil
 	}
-	tx, err := db.Begin()
+	var affected int
+	err := editLedger(db, "add tags", ledgerScope{allocationIDs: allocs}, func(tx *sql.Tx) error {
+		var err error
+		affected, err = addTagsToAllocationsTx(tx, allocs, tags)
+		return err
+	})
 	if err != nil {
-		return 0, fmt.Errorf("begin add tags to allocations: %w", err)
+		return 0, err
 	}
-	defer tx.Rollback() //nolint:errcheck
+	return affected, nil
+}
 
+func addTagsToAllocationsTx(tx *sql.Tx, allocationIDs, tagIDs []int) (int, error) {
 	affected := 0
-	for _, allocationID := range allocs {
-		for _, tagID := range tags {
+	for _, allocationID := range normalizeIDList(allocationIDs) {
+		for _, tagID := range normalizeIDList(tagIDs) {
 			res, err := tx.Exec(`
 				INSERT INTO transaction_allocation_tags (allocation_id, tag_id)
 				VALUES (?, ?)
@@ -2198,12 +2914,14 @@ func addTagsToAllocations(db *sql.DB, allocationIDs, tagIDs []int) (int, error)
 				return 0, fmt.Errorf("insert allocation tag allocation=%d tag=%d: %w", allocationID, tagID, err)
 			}
 			n, _ := res.RowsAffected()
+			if n > 0 {
+				if _, err := tx.Exec(markAllocationParentEditedSQL, allocationID); err != nil {
+					return 0, fmt.Errorf("mark allocation %d parent edited: %w", allocationID, err)
+				}
+			}
 			affected += int(n)
 		}
 	}
-	if err := tx.Commit(); err != nil {
-		return 0, fmt.Errorf("commit add tags to allocations: %w", err)
-	}
 	return affected, nil
 }
 
@@ -2212,14 +2930,21 @@ func removeTagFromAllocations(db *sql.DB, allocationIDs []int, tagID int) (int,
 	if len(allocs) == 0 || tagID <= 0 {
 		return 0, nil
 	}
-	tx, err := db.Begin()
+	var affected int
+	err := editLedger(db, "remove tag", ledgerScope{allocationIDs: allocs}, func(tx *sql.Tx) error {
+		var err error
+		affected, err = removeTagFromAllocationsTx(tx, allocs, tagID)
+		return err
+	})
 	if err != nil {
-		return 0, fmt.Errorf("begin remove tag from allocations: %w", err)
+		return 0, err
 	}
-	defer tx.Rollback() //nolint:errcheck
+	return affected, nil
+}
 
+func removeTagFromAllocationsTx(tx *sql.Tx, allocationIDs []int, tagID int) (int, error) {
 	affected := 0
-	for _, allocationID := range allocs {
+	for _, allocationID := range normalizeIDList(allocationIDs) {
 		res, execErr := tx.Exec(`
 			DELETE FROM transaction_allocation_tags
 			WHERE allocation_id = ? AND tag_id = ?
@@ -2231,11 +2956,13 @@ func removeTagFromAllocations(db *sql.DB, allocationIDs []int, tagID int) (int,
 		if rowsErr != nil {
 			return 0, fmt.Errorf("rows affected delete allocation=%d tag=%d: %w", allocationID, tagID, rowsErr)
 		}
+		if n > 0 {
+			if _, err := tx.Exec(markAllocationParentEditedSQL, allocationID); err != nil {
+				return 0, fmt.Errorf("mark allocation %d parent edited: %w", allocationID, err)
+			}
+		}
 		affected += int(n)
 	}
-	if err := tx.Commit(); err != nil {
-		return 0, fmt.Errorf("commit remove tag from allocations: %w", err)
-	}
 	return affected, nil
 }
 
@@ -2339,92 +3066,110 @@ func applyResolvedRulesV2ToRows(db *sql.DB, rules []resolvedRuleV2, txnTags map[
 	if err != nil {
 		return 0, 0, 0, err
 	}
-	catNames := categoryNameByID(categories)
-	tagByID := tagByIDMap(tags)
-
-	tx, err := db.Begin()
-	if err != nil {
-		return 0, 0, 0, fmt.Errorf("begin apply rules_v2: %w", err)
-	}
-	defer tx.Rollback() //nolint:errcheck
-
-	for _, row := range rows {
-		currentCat := copyIntPtr(row.categoryID)
-		currentTagSet := tagIDSet(txnTags[row.id])
-		workCat := copyIntPtr(currentCat)
-		workTagSet := make(map[int]bool, len(currentTagSet))
-		for id, on := range currentTagSet {
-			workTagSet[id] = on
-		}
-
-		workTxn := row
-		workTxn.categoryID = copyIntPtr(workCat)
-		workTxn.categoryName = categoryNameForPtr(workCat, catNames)
+	catNames := categoryNameByID(categories)
+	tagByID := tagByIDMap(tags)
 
-		for _, rule := range rules {
-			if rule.parsed == nil {
-				continue
-			}
-			if !evalFilter(rule.parsed, workTxn, tagStateToSlice(workTagSet, tagByID)) {
-				continue
-			}
-			if rule.rule.setCategoryID != nil {
-				workCat = copyIntPtr(rule.rule.setCategoryID)
-				workTxn.categoryID = copyIntPtr(workCat)
-				workTxn.categoryName = categoryNameForPtr(workCat, catNames)
+	txnIDs := make([]int, len(rows))
+	for i, row := range rows {
+		txnIDs[i] = row.id
+	}
+	// The last matching rule to set a txn's category, and the first to add
+	// each tag, are credited in the history.
+	catRule := make(map[int]int)
+	tagRule := make(map[[2]int]int)
+	source := func(c txnChange) changeSource {
+		id := catRule[c.txnID]
+		if c.field == "tag" {
+			id = tagRule[[2]int{c.txnID, c.refID}]
+		}
+		if id == 0 {
+			return manualChange
+		}
+		return changeSource{kind: changeRule, id: id}
+	}
+	err = editLedgerBy(db, "apply rules", source, ledgerScope{txnIDs: txnIDs}, func(tx *sql.Tx) error {
+		for _, row := range rows {
+			currentCat := copyIntPtr(row.categoryID)
+			currentTagSet := tagIDSet(txnTags[row.id])
+			workCat := copyIntPtr(currentCat)
+			workTagSet := make(map[int]bool, len(currentTagSet))
+			for id, on := range currentTagSet {
+				workTagSet[id] = on
 			}
-			for _, id := range rule.rule.addTagIDs {
-				if id > 0 {
-					workTagSet[id] = true
+
+			workTxn := row
+			workTxn.categoryID = copyIntPtr(workCat)
+			workTxn.categoryName = categoryNameForPtr(workCat, catNames)
+
+			for _, rule := range rules {
+				if rule.parsed == nil {
+					continue
+				}
+				if !evalFilter(rule.parsed, workTxn, tagStateToSlice(workTagSet, tagByID)) {
+					continue
+				}
+				if rule.rule.setCategoryID != nil {
+					workCat = copyIntPtr(rule.rule.setCategoryID)
+					workTxn.categoryID = copyIntPtr(workCat)
+					workTxn.categoryName = categoryNameForPtr(workCat, catNames)
+					catRule[row.id] = rule.rule.id
+				}
+				for _, id := range rule.rule.addTagIDs {
+					if id > 0 {
+						if !workTagSet[id] {
+							tagRule[[2]int{row.id, id}] = rule.rule.id
+						}
+						workTagSet[id] = true
+					}
 				}
 			}
-		}
 
-		rowUpdated := false
-		if !intPtrEqual(currentCat, workCat) {
-			if _, err := tx.Exec(`UPDATE transactions SET category_id = ? WHERE id = ?`, workCat, row.id); err != nil {
-				return 0, 0, 0, fmt.Errorf("update txn %d category: %w", row.id, err)
-			}
-			catChanges++
-			rowUpdated = true
-		}
-		added, removed := diffTagSets(currentTagSet, workTagSet)
-		for _, tagID := range added {
-			res, execErr := tx.Exec(`
-				INSERT INTO transaction_tags (transaction_id, tag_id)
-				VALUES (?, ?)
-				ON CONFLICT(transaction_id, tag_id) DO NOTHING
-			`, row.id, tagID)
-			if execErr != nil {
-				return 0, 0, 0, fmt.Errorf("add tag %d to txn %d: %w", tagID, row.id, execErr)
-			}
-			n, _ := res.RowsAffected()
-			if n > 0 {
-				tagChanges += int(n)
+			rowUpdated := false
+			if !intPtrEqual(currentCat, workCat) {
+				if _, err := tx.Exec(`UPDATE transactions SET category_id = ? WHERE id = ?`, workCat, row.id); err != nil {
+					return fmt.Errorf("update txn %d category: %w", row.id, err)
+				}
+				catChanges++
 				rowUpdated = true
 			}
-		}
-		for _, tagID := range removed {
-			res, execErr := tx.Exec(`
-				DELETE FROM transaction_tags
-				WHERE transaction_id = ? AND tag_id = ?
-			`, row.id, tagID)
-			if execErr != nil {
-				return 0, 0, 0, fmt.Errorf("remove tag %d from txn %d: %w", tagID, row.id, execErr)
+			added, removed := diffTagSets(currentTagSet, workTagSet)
+			for _, tagID := range added {
+				res, execErr := tx.Exec(`
+					INSERT INTO transaction_tags (transaction_id, tag_id)
+					VALUES (?, ?)
+					ON CONFLICT(transaction_id, tag_id) DO NOTHING
+				`, row.id, tagID)
+				if execErr != nil {
+					return fmt.Errorf("add tag %d to txn %d: %w", tagID, row.id, execErr)
+				}
+				n, _ := res.RowsAffected()
+				if n > 0 {
+					tagChanges += int(n)
+					rowUpdated = true
+				}
 			}
-			n, _ := res.RowsAffected()
-			if n > 0 {
-				tagChanges += int(n)
-				rowUpdated = true
+			for _, tagID := range removed {
+				res, execErr := tx.Exec(`
+					DELETE FROM transaction_tags
+					WHERE transaction_id = ? AND tag_id = ?
+				`, row.id, tagID)
+				if execErr != nil {
+					return fmt.Errorf("remove tag %d from txn %d: %w", tagID, row.id, execErr)
+				}
+				n, _ := res.RowsAffected()
+				if n > 0 {
+					tagChanges += int(n)
+					rowUpdated = true
+				}
+			}
+			if rowUpdated {
+				updatedTxns++
 			}
 		}
-		if rowUpdated {
-			updatedTxns++
-		}
-	}
-
-	if err := tx.Commit(); err != nil {
-		return 0, 0, 0, fmt.Errorf("commit apply rules_v2: %w", err)
+		return nil
+	})
+	if err != nil {
+		return 0, 0, 0, err
 	}
 	return updatedTxns, catChanges, tagChanges, nil
 }
@@ -2547,11 +3292,12 @@ func dryRunRulesV2(db *sql.DB, rules []ruleV2, rows []transaction, txnTags map[i
 
 // updateTransactionCategory sets the category for a transaction.
 func updateTransactionCategory(db *sql.DB, txnID int, categoryID *int) error {
-	_, err := db.Exec("UPDATE transactions SET category_id = ? WHERE id = ?", categoryID, txnID)
-	if err != nil {
-		return fmt.Errorf("update category: %w", err)
-	}
-	return nil
+	return editLedger(db, "set category", ledgerScope{txnIDs: []int{txnID}}, func(tx *sql.Tx) error {
+		if _, err := tx.Exec("UPDATE transactions SET category_id = ?, edited_at = datetime('now') WHERE id = ?", categoryID, txnID); err != nil {
+			return fmt.Errorf("update category: %w", err)
+		}
+		return nil
+	})
 }
 
 // updateTransactionsCategory sets the same category for a list of transactions
@@ -2560,13 +3306,20 @@ func updateTransactionsCategory(db *sql.DB, txnIDs []int, categoryID *int) (int,
 	if len(txnIDs) == 0 {
 		return 0, nil
 	}
-	tx, err := db.Begin()
+	var affected int
+	err := editLedger(db, "set category", ledgerScope{txnIDs: txnIDs}, func(tx *sql.Tx) error {
+		var err error
+		affected, err = updateTransactionsCategoryTx(tx, txnIDs, categoryID)
+		return err
+	})
 	if err != nil {
-		return 0, fmt.Errorf("begin tx: %w", err)
+		return 0, err
 	}
-	defer tx.Rollback() //nolint:errcheck // rollback is a no-op after commit
+	return affected, nil
+}
 
-	stmt, err := tx.Prepare("UPDATE transactions SET category_id = ? WHERE id = ?")
+func updateTransactionsCategoryTx(tx *sql.Tx, txnIDs []int, categoryID *int) (int, error) {
+	stmt, err := tx.Prepare("UPDATE transactions SET category_id = ?, edited_at = datetime('now') WHERE id = ?")
 	if err != nil {
 		return 0, fmt.Errorf("prepare update category: %w", err)
 	}
@@ -2584,37 +3337,27 @@ func updateTransactionsCategory(db *sql.DB, txnIDs []int, categoryID *int) (int,
 		}
 		affected += int(n)
 	}
-	if err := tx.Commit(); err != nil {
-		return 0, fmt.Errorf("commit tx: %w", err)
-	}
 	return affected, nil
 }
 
 // updateTransactionNotes sets the notes for a transaction.
 func updateTransactionNotes(db *sql.DB, txnID int, notes string) error {
-	_, err := db.Exec("UPDATE transactions SET notes = ? WHERE id = ?", notes, txnID)
-	if err != nil {
-		return fmt.Errorf("update notes: %w", err)
-	}
-	return nil
+	return editLedger(db, "set notes", ledgerScope{txnIDs: []int{txnID}}, func(tx *sql.Tx) error {
+		if _, err := tx.Exec("UPDATE transactions SET notes = ?, edited_at = datetime('now') WHERE id = ?", notes, txnID); err != nil {
+			return fmt.Errorf("update notes: %w", err)
+		}
+		return nil
+	})
 }
 
 // updateTransactionDetail updates category and notes in a single transaction.
 func updateTransactionDetail(db *sql.DB, txnID int, categoryID *int, notes string) error {
-	tx, err := db.Begin()
-	if err != nil {
-		return fmt.Errorf("begin tx: %w", err)
-	}
-	defer tx.Rollback() //nolint:errcheck // rollback is a no-op after commit
-
-	_, err = tx.Exec("UPDATE transactions SET category_id = ?, notes = ? WHERE id = ?", categoryID, notes, txnID)
-	if err != nil {
-		return fmt.Errorf("update transaction: %w", err)
-	}
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("commit tx: %w", err)
-	}
-	return nil
+	return editLedger(db, "edit transaction", ledgerScope{txnIDs: []int{txnID}}, func(tx *sql.Tx) error {
+		if _, err := tx.Exec("UPDATE transactions SET category_id = ?, notes = ?, edited_at = datetime('now') WHERE id = ?", categoryID, notes, txnID); err != nil {
+			return fmt.Errorf("update transaction: %w", err)
+		}
+		return nil
+	})
 }
 
 // ---------------------------------------------------------------------------
@@ -2647,6 +3390,46 @@ func insertCategory(db *sql.DB, name, color string) (int, error) {
 	return categoryID, nil
 }
 
+// ensureCategoryByNameTx returns the ID of the category named name
+// (case-insensitive), creating it with an automatic color when missing.
+func ensureCategoryByNameTx(tx *sql.Tx, name string) (int, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return 0, fmt.Errorf("category name is required")
+	}
+	var id int
+	err := tx.QueryRow(`SELECT id FROM categories WHERE LOWER(name) = LOWER(?) LIMIT 1`, name).Scan(&id)
+	if err == nil {
+		return id, nil
+	}
+	if err != sql.ErrNoRows {
+		return 0, fmt.Errorf("lookup category %q: %w", name, err)
+	}
+	var maxOrder int
+	if err := tx.QueryRow("SELECT COALESCE(MAX(sort_order), 0) FROM categories").Scan(&maxOrder); err != nil {
+		return 0, fmt.Errorf("max sort_order: %w", err)
+	}
+	res, err := tx.Exec(`
+		INSERT INTO categories (name, color, sort_order, is_default)
+		VALUES (?, ?, ?, 0)
+	`, name, autoCategoryColor(name), maxOrder+1)
+	if err != nil {
+		return 0, fmt.Errorf("insert category %q: %w", name, err)
+	}
+	id64, err := res.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("last insert id: %w", err)
+	}
+	if _, err := tx.Exec(`
+		INSERT INTO category_budgets (category_id, amount)
+		VALUES (?, 0)
+		ON CONFLICT(category_id) DO NOTHING
+	`, id64); err != nil {
+		return 0, fmt.Errorf("seed category budget row: %w", err)
+	}
+	return int(id64), nil
+}
+
 // updateCategory modifies an existing category's name and color.
 func updateCategory(db *sql.DB, id int, name, color string) error {
 	_, err := db.Exec("UPDATE categories SET name = ?, color = ? WHERE id = ?", name, color, id)
@@ -2923,47 +3706,66 @@ func loadTransactionTagsOrEmpty(db *sql.DB) map[int][]tag {
 	return tags
 }
 
+// markTransactionEditedSQL stamps a transaction as manually edited. Rule
+// applies write through their own statements and deliberately skip this.
+const markTransactionEditedSQL = `UPDATE transactions SET edited_at = datetime('now') WHERE id = ?`
+
+// markAllocationParentEditedSQL stamps the parent of an allocation.
+const markAllocationParentEditedSQL = `
+	UPDATE transactions SET edited_at = datetime('now')
+	WHERE id = (SELECT parent_txn_id FROM transaction_allocations WHERE id = ?)
+`
+
 func upsertTransactionTag(db *sql.DB, txnID, tagID int) error {
-	if _, err := db.Exec(`
-		INSERT INTO transaction_tags (transaction_id, tag_id)
-		VALUES (?, ?)
-		ON CONFLICT(transaction_id, tag_id) DO NOTHING
-	`, txnID, tagID); err != nil {
-		return fmt.Errorf("upsert transaction tag txn=%d tag=%d: %w", txnID, tagID, err)
-	}
-	return nil
+	return editLedger(db, "add tags", ledgerScope{txnIDs: []int{txnID}}, func(tx *sql.Tx) error {
+		if _, err := tx.Exec(`
+			INSERT INTO transaction_tags (transaction_id, tag_id)
+			VALUES (?, ?)
+			ON CONFLICT(transaction_id, tag_id) DO NOTHING
+		`, txnID, tagID); err != nil {
+			return fmt.Errorf("upsert transaction tag txn=%d tag=%d: %w", txnID, tagID, err)
+		}
+		if _, err := tx.Exec(markTransactionEditedSQL, txnID); err != nil {
+			return fmt.Errorf("mark txn %d edited: %w", txnID, err)
+		}
+		return nil
+	})
 }
 
 func setTransactionTags(db *sql.DB, txnID int, tagIDs []int) error {
-	tx, err := db.Begin()
-	if err != nil {
-		return fmt.Errorf("begin set transaction tags: %w", err)
-	}
-	defer tx.Rollback() //nolint:errcheck
-	if _, err := tx.Exec(`DELETE FROM transaction_tags WHERE transaction_id = ?`, txnID); err != nil {
-		return fmt.Errorf("clear transaction tags: %w", err)
-	}
-	for _, tagID := range tagIDs {
-		if _, err := tx.Exec(`INSERT INTO transaction_tags (transaction_id, tag_id) VALUES (?, ?)`, txnID, tagID); err != nil {
-			return fmt.Errorf("insert transaction tag: %w", err)
+	return editLedger(db, "set tags", ledgerScope{txnIDs: []int{txnID}}, func(tx *sql.Tx) error {
+		if _, err := tx.Exec(`DELETE FROM transaction_tags WHERE transaction_id = ?`, txnID); err != nil {
+			return fmt.Errorf("clear transaction tags: %w", err)
 		}
-	}
-	if err := tx.Commit(); err != nil {
-		return fmt.Errorf("commit set transaction tags: %w", err)
-	}
-	return nil
+		for _, tagID := range tagIDs {
+			if _, err := tx.Exec(`INSERT INTO transaction_tags (transaction_id, tag_id) VALUES (?, ?)`, txnID, tagID); err != nil {
+				return fmt.Errorf("insert transaction tag: %w", err)
+			}
+		}
+		if _, err := tx.Exec(markTransactionEditedSQL, txnID); err != nil {
+			return fmt.Errorf("mark txn %d edited: %w", txnID, err)
+		}
+		return nil
+	})
 }
 
 func addTagsToTransactions(db *sql.DB, txnIDs, tagIDs []int) (int, error) {
 	if len(txnIDs) == 0 || len(tagIDs) == 0 {
 		return 0, nil
 	}
-	tx, err := db.Begin()
+	var affected int
+	err := editLedger(db, "add tags", ledgerScope{txnIDs: txnIDs}, func(tx *sql.Tx) error {
+		var err error
+		affected, err = addTagsToTransactionsTx(tx, txnIDs, tagIDs)
+		return err
+	})
 	if err != nil {
-		return 0, fmt.Errorf("begin add tags to transactions: %w", err)
+		return 0, err
 	}
-	defer tx.Rollback() //nolint:errcheck
+	return affected, nil
+}
 
+func addTagsToTransactionsTx(tx *sql.Tx, txnIDs, tagIDs []int) (int, error) {
 	affected := 0
 	for _, txnID := range txnIDs {
 		for _, tagID := range tagIDs {
@@ -2976,12 +3778,14 @@ func addTagsToTransactions(db *sql.DB, txnIDs, tagIDs []int) (int, error) {
 				return 0, fmt.Errorf("insert transaction tag txn=%d tag=%d: %w", txnID, tagID, err)
 			}
 			n, _ := res.RowsAffected()
+			if n > 0 {
+				if _, err := tx.Exec(markTransactionEditedSQL, txnID); err != nil {
+					return 0, fmt.Errorf("mark txn %d edited: %w", txnID, err)
+				}
+			}
 			affected += int(n)
 		}
 	}
-	if err := tx.Commit(); err != nil {
-		return 0, fmt.Errorf("commit add tags to transactions: %w", err)
-	}
 	return affected, nil
 }
 
@@ -2989,12 +3793,19 @@ func removeTagFromTransactions(db *sql.DB, txnIDs []int, tagID int) (int, error)
 	if len(txnIDs) == 0 || tagID == 0 {
 		return 0, nil
 	}
-	tx, err := db.Begin()
+	var affected int
+	err := editLedger(db, "remove tag", ledgerScope{txnIDs: txnIDs}, func(tx *sql.Tx) error {
+		var err error
+		affected, err = removeTagFromTransactionsTx(tx, txnIDs, tagID)
+		return err
+	})
 	if err != nil {
-		return 0, fmt.Errorf("begin remove tag from transactions: %w", err)
+		return 0, err
 	}
-	defer tx.Rollback() //nolint:errcheck
+	return affected, nil
+}
 
+func removeTagFromTransactionsTx(tx *sql.Tx, txnIDs []int, tagID int) (int, error) {
 	affected := 0
 	for _, txnID := range txnIDs {
 		res, execErr := tx.Exec(`
@@ -3008,11 +3819,13 @@ func removeTagFromTransactions(db *sql.DB, txnIDs []int, tagID int) (int, error)
 		if rowsErr != nil {
 			return 0, fmt.Errorf("rows affected delete txn=%d tag=%d: %w", txnID, tagID, rowsErr)
 		}
+		if n > 0 {
+			if _, err := tx.Exec(markTransactionEditedSQL, txnID); err != nil {
+				return 0, fmt.Errorf("mark txn %d edited: %w", txnID, err)
+			}
+		}
 		affected += int(n)
 	}
-	if err := tx.Commit(); err != nil {
-		return 0, fmt.Errorf("commit remove tag from transactions: %w", err)
-	}
 	return affected, nil
 }
 
@@ -3039,7 +3852,15 @@ func applyTagRules(db *sql.DB) (int, error) {
 }
 
 func autoTagColor(name string) string {
-	palette := TagAccentColors()
+	return autoPaletteColor(TagAccentColors(), name)
+}
+
+func autoCategoryColor(name string) string {
+	return autoPaletteColor(CategoryAccentColors(), name)
+}
+
+// autoPaletteColor picks a stable palette entry from a hash of name.
+func autoPaletteColor(palette []lipgloss.Color, name string) string {
 	if len(palette) == 0 {
 		return "#94e2d5"
 	}
@@ -3057,8 +3878,34 @@ func autoTagColor(name string) string {
 
 // insertImportRecord records an import and returns its ID.
 func insertImportRecord(db *sql.DB, filename string, rowCount int) (int, error) {
-	res, err := db.Exec(`INSERT INTO imports (filename, row_count) VALUES (?, ?)`,
-		filename, rowCount)
+	return recordImport(db, importRecord{filename: filename, rowCount: rowCount})
+}
+
+// recordImport inserts an imports row with its file provenance and returns
+// its ID.
+func recordImport(db *sql.DB, rec importRecord) (int, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("begin import: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+	id, err := recordImportTx(tx, rec)
+	if err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("commit import: %w", err)
+	}
+	return id, nil
+}
+
+// recordImportTx inserts an imports row inside tx, so the transactions it
+// creates can carry its import_id from the start.
+func recordImportTx(tx *sql.Tx, rec importRecord) (int, error) {
+	res, err := tx.Exec(`
+		INSERT INTO imports (filename, row_count, content_hash, file_size, format, account_id, date_from, date_to)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+	`, rec.filename, rec.rowCount, rec.contentHash, rec.fileSize, rec.format, rec.accountID, rec.dateFrom, rec.dateTo)
 	if err != nil {
 		return 0, fmt.Errorf("insert import: %w", err)
 	}
@@ -3069,6 +3916,176 @@ func insertImportRecord(db *sql.DB, filename string, rowCount int) (int, error)
 	return int(id), nil
 }
 
+// finishImportTx completes an import begun with recordImportTx: it stores
+// the number of rows actually inserted and starts each one's history.
+func finishImportTx(tx *sql.Tx, importID, rowCount int, txnIDs []int) error {
+	if _, err := tx.Exec(`UPDATE imports SET row_count = ? WHERE id = ?`, rowCount, importID); err != nil {
+		return fmt.Errorf("update import row count: %w", err)
+	}
+	if len(txnIDs) == 0 {
+		return nil
+	}
+	return recordImportHistoryTx(tx, importID, txnIDs)
+}
+
+// linkTransactionsToImport records which import created each transaction so
+// the import can later be rolled back as a unit, and starts each one's
+// history with the category, tags and allocations it was imported with.
+func linkTransactionsToImport(db *sql.DB, importID int, txnIDs []int) error {
+	if importID <= 0 || len(txnIDs) == 0 {
+		return nil
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin link import: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	stmt, err := tx.Prepare(`UPDATE transactions SET import_id = ? WHERE id = ?`)
+	if err != nil {
+		return fmt.Errorf("prepare link import: %w", err)
+	}
+	defer stmt.Close()
+	for _, id := range txnIDs {
+		if _, err := stmt.Exec(importID, id); err != nil {
+			return fmt.Errorf("link txn %d to import %d: %w", id, importID, err)
+		}
+	}
+	if err := recordImportHistoryTx(tx, importID, txnIDs); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit link import: %w", err)
+	}
+	return nil
+}
+
+// importUndoSummary describes what rolling back an import would remove.
+type importUndoSummary struct {
+	importID    int
+	filename    string
+	rows        int
+	allocations int
+	tags        int // transaction and allocation tag links
+	edited      int // rows manually edited since the import
+}
+
+// loadImportUndoSummary counts the rows, allocations and tag links owned by
+// an import and how many of its rows were edited by hand afterwards.
+func loadImportUndoSummary(db *sql.DB, importID int) (importUndoSummary, error) {
+	s := importUndoSummary{importID: importID}
+	var recorded, settled int
+	if err := db.QueryRow(`SELECT filename, row_count, settled_pending FROM imports WHERE id = ?`, importID).Scan(&s.filename, &recorded, &settled); err != nil {
+		return s, fmt.Errorf("load import %d: %w", importID, err)
+	}
+	err := db.QueryRow(`
+		SELECT
+			COUNT(*),
+			COALESCE(SUM(CASE WHEN t.edited_at != '' THEN 1 ELSE 0 END), 0),
+			(SELECT COUNT(*) FROM transaction_allocations a
+				JOIN transactions p ON p.id = a.parent_txn_id
+				WHERE p.import_id = ?),
+			(SELECT COUNT(*) FROM transaction_tags tt
+				JOIN transactions p ON p.id = tt.transaction_id
+				WHERE p.import_id = ?)
+			+ (SELECT COUNT(*) FROM transaction_allocation_tags at
+				JOIN transaction_allocations a ON a.id = at.allocation_id
+				JOIN transactions p ON p.id = a.parent_txn_id
+				WHERE p.import_id = ?)
+		FROM transactions t
+		WHERE t.import_id = ?
+	`, importID, importID, importID, importID).Scan(&s.rows, &s.edited, &s.allocations, &s.tags)
+	if err != nil {
+		return s, fmt.Errorf("summarize import %d: %w", importID, err)
+	}
+	if err := checkImportUndoable(importID, recorded, s.rows, settled); err != nil {
+		return s, err
+	}
+	return s, nil
+}
+
+// checkImportUndoable refuses to undo an import that recorded rows but owns
+// none of them, or that settled pending transactions. Imports from before
+// import tracking never linked their rows, so undoing one would only drop
+// the record and leave its transactions behind; settling deleted the
+// pending rows, so undoing would lose them along with their edits.
+func checkImportUndoable(importID, recorded, linked, settled int) error {
+	if recorded > 0 && linked == 0 {
+		return fmt.Errorf("import %d has no linked transactions (it predates import tracking); delete its rows by hand", importID)
+	}
+	if settled > 0 {
+		return fmt.Errorf("import %d settled %d pending transaction(s); undoing it would lose them", importID, settled)
+	}
+	return nil
+}
+
+// deleteImport removes an import's transactions, with their allocations and
+// tag links, and its imports row in one transaction. Dependents are deleted
+// explicitly rather than relying on foreign-key cascades being enabled on
+// every pooled connection.
+func deleteImport(db *sql.DB, importID int) (int, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("begin delete import: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	var recorded, linked, settled int
+	err = tx.QueryRow(`
+		SELECT row_count, (SELECT COUNT(*) FROM transactions WHERE import_id = imports.id), settled_pending
+		FROM imports WHERE id = ?
+	`, importID).Scan(&recorded, &linked, &settled)
+	if err == sql.ErrNoRows {
+		return 0, fmt.Errorf("import %d not found", importID)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("load import %d: %w", importID, err)
+	}
+	if err := checkImportUndoable(importID, recorded, linked, settled); err != nil {
+		return 0, err
+	}
+
+	dependents := []string{
+		`DELETE FROM transaction_allocation_tags WHERE allocation_id IN (
+			SELECT a.id FROM transaction_allocations a
+			JOIN transactions t ON t.id = a.parent_txn_id
+			WHERE t.import_id = ?)`,
+		`DELETE FROM transaction_allocations WHERE parent_txn_id IN (
+			SELECT id FROM transactions WHERE import_id = ?)`,
+		`DELETE FROM transaction_tags WHERE transaction_id IN (
+			SELECT id FROM transactions WHERE import_id = ?)`,
+		`DELETE FROM transaction_history WHERE txn_id IN (
+			SELECT id FROM transactions WHERE import_id = ?)`,
+	}
+	for _, stmt := range dependents {
+		if _, err := tx.Exec(stmt, importID); err != nil {
+			return 0, fmt.Errorf("delete import dependents: %w", err)
+		}
+	}
+	res, err := tx.Exec(`DELETE FROM transactions WHERE import_id = ?`, importID)
+	if err != nil {
+		return 0, fmt.Errorf("delete import transactions: %w", err)
+	}
+	n, err := res.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("rows affected delete import transactions: %w", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM import_rejects WHERE import_id = ?`, importID); err != nil {
+		return 0, fmt.Errorf("delete import rejects: %w", err)
+	}
+	res, err = tx.Exec(`DELETE FROM imports WHERE id = ?`, importID)
+	if err != nil {
+		return 0, fmt.Errorf("delete import record: %w", err)
+	}
+	if gone, _ := res.RowsAffected(); gone == 0 {
+		return 0, fmt.Errorf("import %d not found", importID)
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("commit delete import: %w", err)
+	}
+	return int(n), nil
+}
+
 // ---------------------------------------------------------------------------
 // Accounts
 // ---------------------------------------------------------------------------
@@ -3080,6 +4097,7 @@ type account struct {
 	sortOrder int
 	isActive  bool
 	txnCount  int
+	currency  string // ISO code; empty means the base currency
 }
 
 func loadAccounts(db *sql.DB) ([]account, error) {
@@ -3090,7 +4108,8 @@ func loadAccounts(db *sql.DB) ([]account, error) {
 			a.type,
 			a.sort_order,
 			a.is_active,
-			COALESCE(tx.txn_count, 0) AS txn_count
+			COALESCE(tx.txn_count, 0) AS txn_count,
+			a.currency
 		FROM accounts a
 		LEFT JOIN (
 			SELECT account_id, COUNT(*) AS txn_count
@@ -3108,7 +4127,7 @@ func loadAccounts(db *sql.DB) ([]account, error) {
 	for rows.Next() {
 		var a account
 		var active int
-		if err := rows.Scan(&a.id, &a.name, &a.acctType, &a.sortOrder, &active, &a.txnCount); err != nil {
+		if err := rows.Scan(&a.id, &a.name, &a.acctType, &a.sortOrder, &active, &a.txnCount, &a.currency); err != nil {
 			return nil, fmt.Errorf("scan account: %w", err)
 		}
 		a.isActive = active == 1
@@ -3121,11 +4140,11 @@ func loadAccountByNameCI(db *sql.DB, name string) (*account, error) {
 	var a account
 	var active int
 	err := db.QueryRow(`
-		SELECT id, name, type, sort_order, is_active
+		SELECT id, name, type, sort_order, is_active, currency
 		FROM accounts
 		WHERE LOWER(name) = LOWER(?)
 		LIMIT 1
-	`, name).Scan(&a.id, &a.name, &a.acctType, &a.sortOrder, &active)
+	`, name).Scan(&a.id, &a.name, &a.acctType, &a.sortOrder, &active, &a.currency)
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -3184,6 +4203,9 @@ func countTransactionsForAccount(db *sql.DB, accountID int) (int, error) {
 }
 
 func clearTransactionsForAccount(db *sql.DB, accountID int) (int, error) {
+	if _, err := snapshotDB(db, fmt.Sprintf("clear-account-%d", accountID)); err != nil {
+		return 0, fmt.Errorf("snapshot before clearing account %d: %w", accountID, err)
+	}
 	res, err := db.Exec(`DELETE FROM transactions WHERE account_id = ?`, accountID)
 	if err != nil {
 		return 0, fmt.Errorf("clear transactions for account %d: %w", accountID, err)
@@ -3213,6 +4235,9 @@ func deleteAccountIfEmpty(db *sql.DB, accountID int) error {
 }
 
 func nukeAccountWithTransactions(db *sql.DB, accountID int) (int, error) {
+	if _, err := snapshotDB(db, fmt.Sprintf("delete-account-%d", accountID)); err != nil {
+		return 0, fmt.Errorf("snapshot before deleting account %d: %w", accountID, err)
+	}
 	tx, err := db.Begin()
 	if err != nil {
 		return 0, fmt.Errorf("begin nuke account tx: %w", err)
@@ -3307,18 +4332,18 @@ func syncAccountsFromFormats(db *sql.DB, formats []csvFormat) error {
 		}
 		if existing == nil {
 			if _, err := db.Exec(`
-				INSERT INTO accounts (name, type, sort_order, is_active)
-				VALUES (?, ?, ?, ?)
-			`, ent.fmt.Account, normalizeAccountType(ent.fmt.AccountType), sortOrder, active); err != nil {
+				INSERT INTO accounts (name, type, sort_order, is_active, currency)
+				VALUES (?, ?, ?, ?, ?)
+			`, ent.fmt.Account, normalizeAccountType(ent.fmt.AccountType), sortOrder, active, ent.fmt.Currency); err != nil {
 				return fmt.Errorf("insert synced account %q: %w", ent.fmt.Account, err)
 			}
 			continue
 		}
 		if _, err := db.Exec(`
 			UPDATE accounts
-			SET type = ?, sort_order = ?, is_active = ?
+			SET type = ?, sort_order = ?, is_active = ?, currency = ?
 			WHERE id = ?
-		`, normalizeAccountType(ent.fmt.AccountType), sortOrder, active, existing.id); err != nil {
+		`, normalizeAccountType(ent.fmt.AccountType), sortOrder, active, ent.fmt.Currency, existing.id); err != nil {
 			return fmt.Errorf("update synced account %q: %w", ent.fmt.Account, err)
 		}
 	}
@@ -3463,11 +4488,18 @@ func deleteFilterUsageState(db *sql.DB, filterID string) error {
 	return nil
 }
 
-// clearAllData deletes all transactions and imports, but preserves categories and rules.
+// clearAllData deletes all transactions and imports, but preserves categories
+// and rules. The database is snapshotted first.
 func clearAllData(db *sql.DB) error {
+	if _, err := snapshotDB(db, "clear-all"); err != nil {
+		return fmt.Errorf("snapshot before clear: %w", err)
+	}
 	statements := []string{
+		"DELETE FROM transaction_history",
 		"DELETE FROM transactions",
+		"DELETE FROM import_rejects",
 		"DELETE FROM imports",
+		"DELETE FROM ledger_journal",
 	}
 	for _, stmt := range statements {
 		if _, err := db.Exec(stmt); err != nil {
@@ -3509,6 +4541,18 @@ func refreshCmd(db *sql.DB) tea.Cmd {
 		if err != nil {
 			return refreshDoneMsg{err: err}
 		}
+		importRejects, err := loadImportRejects(db)
+		if err != nil {
+			return refreshDoneMsg{err: err}
+		}
+		dbPath, err := databaseFilePath(db)
+		if err != nil {
+			return refreshDoneMsg{err: err}
+		}
+		backups, err := listBackups(dbPath)
+		if err != nil {
+			return refreshDoneMsg{err: err}
+		}
 		accounts, err := loadAccounts(db)
 		if err != nil {
 			return refreshDoneMsg{err: err}
@@ -3525,6 +4569,10 @@ func refreshCmd(db *sql.DB) tea.Cmd {
 		if err != nil {
 			return refreshDoneMsg{err: err}
 		}
+		fxRates, err := loadFXRates(db)
+		if err != nil {
+			return refreshDoneMsg{err: err}
+		}
 		return refreshDoneMsg{
 			rows:             rows,
 			categories:       cats,
@@ -3532,10 +4580,13 @@ func refreshCmd(db *sql.DB) tea.Cmd {
 			tags:             tags,
 			txnTags:          txnTags,
 			imports:          imports,
+			importRejects:    importRejects,
+			backups:          backups,
 			accounts:         accounts,
 			selectedAccounts: selectedAccounts,
 			info:             info,
 			filterUsage:      filterUsage,
+			fxRates:          fxRates,
 		}
 	}
 }
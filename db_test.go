@@ -27,6 +27,7 @@ func testDB(t *testing.T) (*sql.DB, func()) {
 	return db, func() {
 		db.Close()
 		os.Remove(path)
+		os.RemoveAll(backupDir(path))
 	}
 }
 
@@ -52,6 +53,7 @@ func TestOpenDBCreatesCurrentSchema(t *testing.T) {
 		"accounts", "account_selection", "tags", "transaction_tags", "rules_v2",
 		"category_budgets", "category_budget_overrides", "spending_targets",
 		"spending_target_overrides", "transaction_allocations", "transaction_allocation_tags",
+		"fx_rates",
 	}
 	for _, table := range tables {
 		var count int
@@ -151,6 +153,7 @@ func TestOpenDBRecreatesOutdatedSchema(t *testing.T) {
 	path := f.Name()
 	f.Close()
 	defer os.Remove(path)
+	defer os.RemoveAll(backupDir(path))
 
 	// Create minimal v2-style schema manually
 	db, err := sql.Open("sqlite", path)
@@ -175,7 +178,7 @@ func TestOpenDBRecreatesOutdatedSchema(t *testing.T) {
 	}
 	// Insert a legacy row that should be removed by fresh recreate.
 	_, err = db.Exec(`INSERT INTO transactions (date_raw, date_iso, amount, description)
-		VALUES ('03/02/2026', '2026-02-03', -20.00, 'DAN MURPHYS')`)
+		VALUES ('03/02/2026', '2026-02-03', -2000, 'DAN MURPHYS')`)
 	if err != nil {
 		db.Close()
 		t.Fatalf("insert legacy row: %v", err)
@@ -225,6 +228,7 @@ func TestMigrationFromV3ToV4PreservesTransactions(t *testing.T) {
 	path := f.Name()
 	f.Close()
 	defer os.Remove(path)
+	defer os.RemoveAll(backupDir(path))
 
 	db, err := sql.Open("sqlite", path)
 	if err != nil {
@@ -283,7 +287,7 @@ func TestMigrationFromV3ToV4PreservesTransactions(t *testing.T) {
 		);
 
 		INSERT INTO transactions (date_raw, date_iso, amount, description, notes)
-		VALUES ('03/02/2026', '2026-02-03', -20.00, 'PRESERVE-ME', '');
+		VALUES ('03/02/2026', '2026-02-03', -2000, 'PRESERVE-ME', '');
 	`)
 	if err != nil {
 		db.Close()
@@ -317,6 +321,331 @@ func TestMigrationFromV3ToV4PreservesTransactions(t *testing.T) {
 	}
 }
 
+func TestMigrateFromV7ToV8AddsExternalRef(t *testing.T) {
+	f, err := os.CreateTemp("", "jaskmoney-v7-*.db")
+	if err != nil {
+		t.Fatalf("create temp: %v", err)
+	}
+	path := f.Name()
+	f.Close()
+	defer os.Remove(path)
+	defer os.RemoveAll(backupDir(path))
+
+	db, err := openDB(path)
+	if err != nil {
+		t.Fatalf("openDB: %v", err)
+	}
+	// Downgrade the fresh schema to its v7 shape.
+	stmts := []string{
+		`DROP INDEX idx_transactions_external_ref`,
+		`ALTER TABLE transactions DROP COLUMN external_ref`,
+		`INSERT INTO transactions (date_raw, date_iso, amount, description) VALUES ('3/02/2026', '2026-02-03', -20, 'KEEP ME')`,
+		`UPDATE schema_meta SET version = 7`,
+	}
+	for _, stmt := range stmts {
+		if _, err := db.Exec(stmt); err != nil {
+			db.Close()
+			t.Fatalf("downgrade %q: %v", stmt, err)
+		}
+	}
+	db.Close()
+
+	db2, err := openDB(path)
+	if err != nil {
+		t.Fatalf("openDB upgrade: %v", err)
+	}
+	defer db2.Close()
+
+	var ver int
+	if err := db2.QueryRow("SELECT version FROM schema_meta LIMIT 1").Scan(&ver); err != nil {
+		t.Fatalf("query version: %v", err)
+	}
+	if ver != schemaVersion {
+		t.Fatalf("version = %d, want %d", ver, schemaVersion)
+	}
+	var desc, ref string
+	if err := db2.QueryRow("SELECT description, external_ref FROM transactions").Scan(&desc, &ref); err != nil {
+		t.Fatalf("query migrated row: %v", err)
+	}
+	if desc != "KEEP ME" || ref != "" {
+		t.Fatalf("migrated row = (%q, %q), want (KEEP ME, empty ref)", desc, ref)
+	}
+}
+
+func TestMigrateFromV13ToV14AddsImportProvenance(t *testing.T) {
+	f, err := os.CreateTemp("", "jaskmoney-v13-*.db")
+	if err != nil {
+		t.Fatalf("create temp: %v", err)
+	}
+	path := f.Name()
+	f.Close()
+	defer os.Remove(path)
+	defer os.RemoveAll(backupDir(path))
+
+	db, err := openDB(path)
+	if err != nil {
+		t.Fatalf("openDB: %v", err)
+	}
+	stmts := []string{
+		`DROP INDEX idx_imports_hash`,
+		`ALTER TABLE imports DROP COLUMN content_hash`,
+		`ALTER TABLE imports DROP COLUMN file_size`,
+		`ALTER TABLE imports DROP COLUMN format`,
+		`ALTER TABLE imports DROP COLUMN account_id`,
+		`ALTER TABLE imports DROP COLUMN date_from`,
+		`ALTER TABLE imports DROP COLUMN date_to`,
+		`INSERT INTO imports (filename, row_count) VALUES ('old.csv', 3)`,
+		`UPDATE schema_meta SET version = 13`,
+	}
+	for _, stmt := range stmts {
+		if _, err := db.Exec(stmt); err != nil {
+			db.Close()
+			t.Fatalf("downgrade %q: %v", stmt, err)
+		}
+	}
+	db.Close()
+
+	db2, err := openDB(path)
+	if err != nil {
+		t.Fatalf("openDB upgrade: %v", err)
+	}
+	defer db2.Close()
+	imports, err := loadImports(db2)
+	if err != nil {
+		t.Fatalf("loadImports: %v", err)
+	}
+	if len(imports) != 1 || imports[0].filename != "old.csv" || imports[0].contentHash != "" || imports[0].accountID != nil {
+		t.Fatalf("imports after migration = %+v", imports)
+	}
+}
+
+func TestMigrateFromV15ToV16StoresCents(t *testing.T) {
+	f, err := os.CreateTemp("", "jaskmoney-v15-*.db")
+	if err != nil {
+		t.Fatalf("create temp: %v", err)
+	}
+	path := f.Name()
+	f.Close()
+	defer os.Remove(path)
+	defer os.RemoveAll(backupDir(path))
+
+	db, err := openDB(path)
+	if err != nil {
+		t.Fatalf("openDB: %v", err)
+	}
+	// v15 stored major units as REAL; INTEGER affinity keeps fractional
+	// values as REAL, so the fresh schema can hold them as-is.
+	stmts := []string{
+		`INSERT INTO categories (name, color, sort_order) VALUES ('Split', '#fff', 99)`,
+		`INSERT INTO tags (name) VALUES ('trip')`,
+		`INSERT INTO transactions (id, date_raw, date_iso, amount, description, bank_balance, original_amount)
+		 VALUES (7, '3/02/2026', '2026-02-03', -12.34, 'KEEP ME', 1050.1, -8.005)`,
+		`INSERT INTO transaction_tags (transaction_id, tag_id) SELECT 7, id FROM tags WHERE name = 'trip'`,
+		`INSERT INTO transaction_allocations (id, parent_txn_id, amount, note) VALUES (3, 7, -2.5, 'share')`,
+		`INSERT INTO category_budgets (category_id, amount) SELECT id, 200.5 FROM categories WHERE name = 'Split'`,
+		`UPDATE schema_meta SET version = 15`,
+	}
+	for _, stmt := range stmts {
+		if _, err := db.Exec(stmt); err != nil {
+			db.Close()
+			t.Fatalf("downgrade %q: %v", stmt, err)
+		}
+	}
+	db.Close()
+
+	db2, err := openDB(path)
+	if err != nil {
+		t.Fatalf("openDB upgrade: %v", err)
+	}
+	defer db2.Close()
+
+	var amount, balance, orig money
+	var kind string
+	if err := db2.QueryRow(`SELECT amount, bank_balance, original_amount, typeof(amount) FROM transactions WHERE id = 7`).Scan(&amount, &balance, &orig, &kind); err != nil {
+		t.Fatalf("query migrated transaction: %v", err)
+	}
+	if amount != -1234 || balance != 105010 || orig != -801 || kind != "integer" {
+		t.Fatalf("migrated transaction = %d/%d/%d (%s), want -1234/105010/-801 integer", amount, balance, orig, kind)
+	}
+	var allocation, budget money
+	if err := db2.QueryRow(`SELECT amount FROM transaction_allocations WHERE id = 3 AND parent_txn_id = 7`).Scan(&allocation); err != nil || allocation != -250 {
+		t.Fatalf("allocation = %d (%v), want -250", allocation, err)
+	}
+	if err := db2.QueryRow(`SELECT b.amount FROM category_budgets b JOIN categories c ON c.id = b.category_id WHERE c.name = 'Split'`).Scan(&budget); err != nil || budget != 20050 {
+		t.Fatalf("budget = %d (%v), want 20050", budget, err)
+	}
+	tags, err := loadTransactionTags(db2)
+	if err != nil {
+		t.Fatalf("loadTransactionTags: %v", err)
+	}
+	if len(tags[7]) != 1 {
+		t.Fatalf("tags = %+v, want trip kept on the rebuilt transaction", tags[7])
+	}
+}
+
+func TestMigrateFromV16ToV17AddsLedgerJournal(t *testing.T) {
+	f, err := os.CreateTemp("", "jaskmoney-v16-*.db")
+	if err != nil {
+		t.Fatalf("create temp: %v", err)
+	}
+	path := f.Name()
+	f.Close()
+	defer os.Remove(path)
+	defer os.RemoveAll(backupDir(path))
+
+	db, err := openDB(path)
+	if err != nil {
+		t.Fatalf("openDB: %v", err)
+	}
+	for _, stmt := range []string{`DROP TABLE ledger_journal`, `UPDATE schema_meta SET version = 16`} {
+		if _, err := db.Exec(stmt); err != nil {
+			db.Close()
+			t.Fatalf("downgrade %q: %v", stmt, err)
+		}
+	}
+	db.Close()
+
+	db2, err := openDB(path)
+	if err != nil {
+		t.Fatalf("openDB upgrade: %v", err)
+	}
+	defer db2.Close()
+	if entry, err := undoLedgerEdit(db2); err != nil || entry != nil {
+		t.Fatalf("undo on migrated db = %+v (%v), want nothing to undo", entry, err)
+	}
+}
+
+func TestMigrateFromV17ToV18AddsTransactionHistory(t *testing.T) {
+	f, err := os.CreateTemp("", "jaskmoney-v17-*.db")
+	if err != nil {
+		t.Fatalf("create temp: %v", err)
+	}
+	path := f.Name()
+	f.Close()
+	defer os.Remove(path)
+	defer os.RemoveAll(backupDir(path))
+
+	db, err := openDB(path)
+	if err != nil {
+		t.Fatalf("openDB: %v", err)
+	}
+	for _, stmt := range []string{`DROP TABLE transaction_history`, `UPDATE schema_meta SET version = 17`} {
+		if _, err := db.Exec(stmt); err != nil {
+			db.Close()
+			t.Fatalf("downgrade %q: %v", stmt, err)
+		}
+	}
+	db.Close()
+
+	db2, err := openDB(path)
+	if err != nil {
+		t.Fatalf("openDB upgrade: %v", err)
+	}
+	defer db2.Close()
+	if changes, err := loadTransactionHistory(db2, 1); err != nil || len(changes) != 0 {
+		t.Fatalf("history on migrated db = %+v (%v), want empty", changes, err)
+	}
+}
+
+func TestMigrateFromV10ToV11AddsCurrencyColumns(t *testing.T) {
+	f, err := os.CreateTemp("", "jaskmoney-v10-*.db")
+	if err != nil {
+		t.Fatalf("create temp: %v", err)
+	}
+	path := f.Name()
+	f.Close()
+	defer os.Remove(path)
+	defer os.RemoveAll(backupDir(path))
+
+	db, err := openDB(path)
+	if err != nil {
+		t.Fatalf("openDB: %v", err)
+	}
+	// Downgrade the fresh schema to its v10 shape.
+	stmts := []string{
+		`DROP TABLE fx_rates`,
+		`ALTER TABLE accounts DROP COLUMN currency`,
+		`ALTER TABLE transactions DROP COLUMN original_amount`,
+		`ALTER TABLE transactions DROP COLUMN original_currency`,
+		`INSERT INTO accounts (name, type) VALUES ('Old', 'debit')`,
+		`INSERT INTO transactions (date_raw, date_iso, amount, description) VALUES ('3/02/2026', '2026-02-03', -2000, 'KEEP ME')`,
+		`UPDATE schema_meta SET version = 10`,
+	}
+	for _, stmt := range stmts {
+		if _, err := db.Exec(stmt); err != nil {
+			db.Close()
+			t.Fatalf("downgrade %q: %v", stmt, err)
+		}
+	}
+	db.Close()
+
+	db2, err := openDB(path)
+	if err != nil {
+		t.Fatalf("openDB upgrade: %v", err)
+	}
+	defer db2.Close()
+
+	var ver int
+	if err := db2.QueryRow("SELECT version FROM schema_meta LIMIT 1").Scan(&ver); err != nil {
+		t.Fatalf("query version: %v", err)
+	}
+	if ver != schemaVersion {
+		t.Fatalf("version = %d, want %d", ver, schemaVersion)
+	}
+	var currency string
+	if err := db2.QueryRow("SELECT currency FROM accounts WHERE name = 'Old'").Scan(&currency); err != nil || currency != "" {
+		t.Fatalf("account currency = %q (%v), want empty", currency, err)
+	}
+	var desc, origCurrency string
+	var origAmount *float64
+	if err := db2.QueryRow("SELECT description, original_amount, original_currency FROM transactions").Scan(&desc, &origAmount, &origCurrency); err != nil {
+		t.Fatalf("query migrated row: %v", err)
+	}
+	if desc != "KEEP ME" || origAmount != nil || origCurrency != "" {
+		t.Fatalf("migrated row = (%q, %v, %q), want (KEEP ME, nil, empty)", desc, origAmount, origCurrency)
+	}
+	if err := upsertFXRates(db2, []fxRate{{dateISO: "2026-02-03", base: "AUD", currency: "USD", rate: 1.5}}); err != nil {
+		t.Fatalf("fx_rates after migration: %v", err)
+	}
+}
+
+func TestMigrateFromV11ToV12AddsSettledPending(t *testing.T) {
+	f, err := os.CreateTemp("", "jaskmoney-v11-*.db")
+	if err != nil {
+		t.Fatalf("create temp: %v", err)
+	}
+	path := f.Name()
+	f.Close()
+	defer os.Remove(path)
+	defer os.RemoveAll(backupDir(path))
+
+	db, err := openDB(path)
+	if err != nil {
+		t.Fatalf("openDB: %v", err)
+	}
+	for _, stmt := range []string{`ALTER TABLE imports DROP COLUMN settled_pending`, `UPDATE schema_meta SET version = 11`} {
+		if _, err := db.Exec(stmt); err != nil {
+			db.Close()
+			t.Fatalf("downgrade %q: %v", stmt, err)
+		}
+	}
+	if _, err := insertImportRecord(db, "old.csv", 0); err != nil {
+		db.Close()
+		t.Fatalf("insertImportRecord: %v", err)
+	}
+	db.Close()
+
+	db2, err := openDB(path)
+	if err != nil {
+		t.Fatalf("openDB upgrade: %v", err)
+	}
+	defer db2.Close()
+	var settled int
+	if err := db2.QueryRow(`SELECT settled_pending FROM imports WHERE filename = 'old.csv'`).Scan(&settled); err != nil || settled != 0 {
+		t.Fatalf("settled_pending = %d (%v), want 0", settled, err)
+	}
+}
+
 func TestOpenDBIdempotent(t *testing.T) {
 	f, err := os.CreateTemp("", "jaskmoney-idem-*.db")
 	if err != nil {
@@ -325,6 +654,7 @@ func TestOpenDBIdempotent(t *testing.T) {
 	path := f.Name()
 	f.Close()
 	defer os.Remove(path)
+	defer os.RemoveAll(backupDir(path))
 
 	// Open once
 	db1, err := openDB(path)
@@ -365,6 +695,7 @@ func TestOpenDBReconcilesLegacySpendingTargetsAndBudgetColumns(t *testing.T) {
 	path := f.Name()
 	f.Close()
 	defer os.Remove(path)
+	defer os.RemoveAll(backupDir(path))
 
 	db, err := openDB(path)
 	if err != nil {
@@ -412,7 +743,7 @@ func TestOpenDBReconcilesLegacySpendingTargetsAndBudgetColumns(t *testing.T) {
 	_, err = insertSpendingTarget(db2, spendingTarget{
 		name:          "Smoke test target",
 		savedFilterID: "filter-1",
-		amount:        100,
+		amount:        10000,
 		periodType:    "monthly",
 	})
 	if err != nil {
@@ -593,14 +924,14 @@ func TestInsertAndLoadTransactions(t *testing.T) {
 
 	_, err := db.Exec(`
 		INSERT INTO transactions (date_raw, date_iso, amount, description, notes)
-		VALUES ('03/02/2026', '2026-02-03', -20.00, 'DAN MURPHYS', '')
+		VALUES ('03/02/2026', '2026-02-03', -2000, 'DAN MURPHYS', '')
 	`)
 	if err != nil {
 		t.Fatalf("insert: %v", err)
 	}
 	_, err = db.Exec(`
 		INSERT INTO transactions (date_raw, date_iso, amount, description, notes)
-		VALUES ('04/02/2026', '2026-02-04', 100.00, 'PAYMENT', '')
+		VALUES ('04/02/2026', '2026-02-04', 10000, 'PAYMENT', '')
 	`)
 	if err != nil {
 		t.Fatalf("insert: %v", err)
@@ -637,7 +968,7 @@ func TestClearTransactions(t *testing.T) {
 
 	_, err := db.Exec(`
 		INSERT INTO transactions (date_raw, date_iso, amount, description, notes)
-		VALUES ('03/02/2026', '2026-02-03', -20.00, 'TEST', '')
+		VALUES ('03/02/2026', '2026-02-03', -2000, 'TEST', '')
 	`)
 	if err != nil {
 		t.Fatalf("insert: %v", err)
@@ -667,7 +998,7 @@ func TestUpdateTransactionCategory(t *testing.T) {
 	// Insert a transaction
 	res, err := db.Exec(`
 		INSERT INTO transactions (date_raw, date_iso, amount, description, notes)
-		VALUES ('03/02/2026', '2026-02-03', -20.00, 'TEST', '')
+		VALUES ('03/02/2026', '2026-02-03', -2000, 'TEST', '')
 	`)
 	if err != nil {
 		t.Fatalf("insert: %v", err)
@@ -702,7 +1033,7 @@ func TestUpdateTransactionNotes(t *testing.T) {
 
 	res, err := db.Exec(`
 		INSERT INTO transactions (date_raw, date_iso, amount, description, notes)
-		VALUES ('03/02/2026', '2026-02-03', -20.00, 'TEST', '')
+		VALUES ('03/02/2026', '2026-02-03', -2000, 'TEST', '')
 	`)
 	if err != nil {
 		t.Fatalf("insert: %v", err)
@@ -728,7 +1059,7 @@ func TestUpdateTransactionDetailAtomicSuccess(t *testing.T) {
 
 	res, err := db.Exec(`
 		INSERT INTO transactions (date_raw, date_iso, amount, description, notes)
-		VALUES ('03/02/2026', '2026-02-03', -20.00, 'TEST', 'seed')
+		VALUES ('03/02/2026', '2026-02-03', -2000, 'TEST', 'seed')
 	`)
 	if err != nil {
 		t.Fatalf("insert txn: %v", err)
@@ -766,7 +1097,7 @@ func TestUpdateTransactionDetailAtomicRollbackOnInvalidCategory(t *testing.T) {
 
 	res, err := db.Exec(`
 		INSERT INTO transactions (date_raw, date_iso, amount, description, notes)
-		VALUES ('03/02/2026', '2026-02-03', -20.00, 'TEST', 'seed')
+		VALUES ('03/02/2026', '2026-02-03', -2000, 'TEST', 'seed')
 	`)
 	if err != nil {
 		t.Fatalf("insert txn: %v", err)
@@ -923,7 +1254,7 @@ func TestDeleteCategoryNullsTransactions(t *testing.T) {
 	// Insert a transaction with this category
 	_, err = db.Exec(`
 		INSERT INTO transactions (date_raw, date_iso, amount, description, notes, category_id)
-		VALUES ('03/02/2026', '2026-02-03', -10.00, 'TEST', '', ?)
+		VALUES ('03/02/2026', '2026-02-03', -1000, 'TEST', '', ?)
 	`, id)
 	if err != nil {
 		t.Fatalf("insert txn: %v", err)
@@ -1042,11 +1373,11 @@ func TestApplyCategoryRules(t *testing.T) {
 	// Insert transactions (uncategorised)
 	_, _ = db.Exec(`
 		INSERT INTO transactions (date_raw, date_iso, amount, description, notes)
-		VALUES ('03/02/2026', '2026-02-03', -55.30, 'WOOLWORTHS 1234', '')
+		VALUES ('03/02/2026', '2026-02-03', -5530, 'WOOLWORTHS 1234', '')
 	`)
 	_, _ = db.Exec(`
 		INSERT INTO transactions (date_raw, date_iso, amount, description, notes)
-		VALUES ('03/02/2026', '2026-02-03', -20.00, 'DAN MURPHYS', '')
+		VALUES ('03/02/2026', '2026-02-03', -2000, 'DAN MURPHYS', '')
 	`)
 
 	count, err := applyCategoryRules(db)
@@ -1086,7 +1417,7 @@ func TestApplyCategoryRulesSkipsCategorised(t *testing.T) {
 	// Insert transaction already categorised as Dining
 	_, _ = db.Exec(`
 		INSERT INTO transactions (date_raw, date_iso, amount, description, notes, category_id)
-		VALUES ('03/02/2026', '2026-02-03', -55.30, 'WOOLWORTHS 1234', '', ?)
+		VALUES ('03/02/2026', '2026-02-03', -5530, 'WOOLWORTHS 1234', '', ?)
 	`, diningID)
 
 	count, _ := applyCategoryRules(db)
@@ -1109,7 +1440,7 @@ func TestApplyCategoryRulesCaseInsensitive(t *testing.T) {
 
 	_, _ = db.Exec(`
 		INSERT INTO transactions (date_raw, date_iso, amount, description, notes)
-		VALUES ('03/02/2026', '2026-02-03', -55.30, 'WOOLWORTHS 1234', '')
+		VALUES ('03/02/2026', '2026-02-03', -5530, 'WOOLWORTHS 1234', '')
 	`)
 
 	count, _ := applyCategoryRules(db)
@@ -1144,6 +1475,91 @@ func TestInsertImportRecord(t *testing.T) {
 	}
 }
 
+func TestDeleteImportRemovesRowsAndReportsEdits(t *testing.T) {
+	db, cleanup := testDB(t)
+	defer cleanup()
+
+	var txnIDs []int
+	for _, desc := range []string{"A", "B", "KEEP"} {
+		res, err := db.Exec(`INSERT INTO transactions (date_raw, date_iso, amount, description, notes)
+			VALUES ('03/02/2026', '2026-02-03', -2000, ?, '')`, desc)
+		if err != nil {
+			t.Fatalf("insert txn: %v", err)
+		}
+		id, _ := res.LastInsertId()
+		txnIDs = append(txnIDs, int(id))
+	}
+	keepID := txnIDs[2]
+	txnIDs = txnIDs[:2]
+	importID, err := insertImportRecord(db, "bank.csv", 2)
+	if err != nil {
+		t.Fatalf("insertImportRecord: %v", err)
+	}
+	if err := linkTransactionsToImport(db, importID, txnIDs); err != nil {
+		t.Fatalf("linkTransactionsToImport: %v", err)
+	}
+	tagID, err := insertTag(db, "trip", "", nil)
+	if err != nil {
+		t.Fatalf("insertTag: %v", err)
+	}
+	if _, err := insertTransactionAllocation(db, txnIDs[0], 500, nil, "split", []int{tagID}); err != nil {
+		t.Fatalf("insertTransactionAllocation: %v", err)
+	}
+
+	summary, err := loadImportUndoSummary(db, importID)
+	if err != nil {
+		t.Fatalf("loadImportUndoSummary: %v", err)
+	}
+	if summary.rows != 2 || summary.allocations != 1 || summary.tags != 1 || summary.edited != 1 {
+		t.Fatalf("summary = %+v, want 2 rows, 1 allocation, 1 tag, 1 edited", summary)
+	}
+
+	removed, err := deleteImport(db, importID)
+	if err != nil {
+		t.Fatalf("deleteImport: %v", err)
+	}
+	if removed != 2 {
+		t.Fatalf("removed = %d, want 2", removed)
+	}
+	rows, _ := loadRows(db)
+	if len(rows) != 1 || rows[0].id != keepID {
+		t.Fatalf("remaining rows = %+v, want only KEEP", rows)
+	}
+	var orphans int
+	if err := db.QueryRow(`SELECT (SELECT COUNT(*) FROM transaction_allocations) + (SELECT COUNT(*) FROM transaction_allocation_tags)`).Scan(&orphans); err != nil {
+		t.Fatalf("count orphans: %v", err)
+	}
+	if orphans != 0 {
+		t.Fatalf("orphaned allocation rows = %d", orphans)
+	}
+	if imports, _ := loadImports(db); len(imports) != 0 {
+		t.Fatalf("imports = %+v, want none", imports)
+	}
+}
+
+func TestDeleteImportRefusesUnlinkedImport(t *testing.T) {
+	db, cleanup := testDB(t)
+	defer cleanup()
+
+	if _, err := db.Exec(`INSERT INTO transactions (date_raw, date_iso, amount, description, notes)
+		VALUES ('03/02/2026', '2026-02-03', -2000, 'LEGACY', '')`); err != nil {
+		t.Fatalf("insert txn: %v", err)
+	}
+	importID, err := insertImportRecord(db, "legacy.csv", 1)
+	if err != nil {
+		t.Fatalf("insertImportRecord: %v", err)
+	}
+	if _, err := loadImportUndoSummary(db, importID); err == nil || !strings.Contains(err.Error(), "no linked transactions") {
+		t.Fatalf("loadImportUndoSummary err = %v, want unlinked refusal", err)
+	}
+	if _, err := deleteImport(db, importID); err == nil {
+		t.Fatal("deleteImport removed an import with no linked rows")
+	}
+	if imports, _ := loadImports(db); len(imports) != 1 {
+		t.Fatalf("imports = %+v, want the record kept", imports)
+	}
+}
+
 // ---- DB info tests (Phase 4) ----
 
 func TestLoadDBInfo(t *testing.T) {
@@ -1176,7 +1592,7 @@ func TestLoadDBInfoAfterInserts(t *testing.T) {
 	defer cleanup()
 
 	_, _ = db.Exec(`INSERT INTO transactions (date_raw, date_iso, amount, description, notes)
-		VALUES ('03/02/2026', '2026-02-03', -20.00, 'TEST', '')`)
+		VALUES ('03/02/2026', '2026-02-03', -2000, 'TEST', '')`)
 	cats, _ := loadCategories(db)
 	_, _ = insertCategoryRule(db, "TEST", cats[0].id)
 	_, _ = insertImportRecord(db, "test.csv", 1)
@@ -1204,7 +1620,7 @@ func TestClearAllData(t *testing.T) {
 
 	// Add some data
 	_, _ = db.Exec(`INSERT INTO transactions (date_raw, date_iso, amount, description, notes)
-		VALUES ('03/02/2026', '2026-02-03', -20.00, 'TEST', '')`)
+		VALUES ('03/02/2026', '2026-02-03', -2000, 'TEST', '')`)
 	_, _ = insertImportRecord(db, "test.csv", 1)
 
 	if err := clearAllData(db); err != nil {
@@ -1240,7 +1656,7 @@ func TestTransactionCategoryForeignKey(t *testing.T) {
 	// Insert transaction with valid category
 	_, err = db.Exec(`
 		INSERT INTO transactions (date_raw, date_iso, amount, description, notes, category_id)
-		VALUES ('03/02/2026', '2026-02-03', -20.00, 'TEST', '', ?)
+		VALUES ('03/02/2026', '2026-02-03', -2000, 'TEST', '', ?)
 	`, cats[0].id)
 	if err != nil {
 		t.Fatalf("insert with category: %v", err)
@@ -1287,8 +1703,8 @@ func TestLoadAccountsIncludesTransactionCounts(t *testing.T) {
 
 	if _, err := db.Exec(`
 		INSERT INTO transactions (date_raw, date_iso, amount, description, notes, account_id)
-		VALUES ('03/02/2026', '2026-02-03', -20.00, 'A', '', ?),
-		       ('04/02/2026', '2026-02-04', -10.00, 'B', '', ?)
+		VALUES ('03/02/2026', '2026-02-03', -2000, 'A', '', ?),
+		       ('04/02/2026', '2026-02-04', -1000, 'B', '', ?)
 	`, primaryID, primaryID); err != nil {
 		t.Fatalf("insert transactions: %v", err)
 	}
@@ -1338,7 +1754,7 @@ func TestAccountCRUDAndDeleteGuards(t *testing.T) {
 
 	_, err = db.Exec(`
 		INSERT INTO transactions (date_raw, date_iso, amount, description, notes, account_id)
-		VALUES ('03/02/2026', '2026-02-03', -20.00, 'ACC-TXN', '', ?)
+		VALUES ('03/02/2026', '2026-02-03', -2000, 'ACC-TXN', '', ?)
 	`, loaded.id)
 	if err != nil {
 		t.Fatalf("insert txn with account: %v", err)
@@ -1441,7 +1857,7 @@ func TestTagCRUDAndRuleApplication(t *testing.T) {
 	}
 	if _, err := db.Exec(`
 		INSERT INTO transactions (date_raw, date_iso, amount, description, notes)
-		VALUES ('03/02/2026', '2026-02-03', -10.00, 'WOOLWORTHS 123', '')
+		VALUES ('03/02/2026', '2026-02-03', -1000, 'WOOLWORTHS 123', '')
 	`); err != nil {
 		t.Fatalf("insert transaction: %v", err)
 	}
@@ -1518,7 +1934,7 @@ func TestNormalizeExistingTagNamesMergesCaseDuplicates(t *testing.T) {
 
 	res, err = db.Exec(`
 		INSERT INTO transactions (date_raw, date_iso, amount, description, notes)
-		VALUES ('03/02/2026', '2026-02-03', -10.00, 'MERGE CASE TAGS', '')
+		VALUES ('03/02/2026', '2026-02-03', -1000, 'MERGE CASE TAGS', '')
 	`)
 	if err != nil {
 		t.Fatalf("insert transaction: %v", err)
@@ -1626,7 +2042,7 @@ func TestTransactionAllocationCapacityValidation(t *testing.T) {
 	}
 	res, err := db.Exec(`
 		INSERT INTO transactions (date_raw, date_iso, amount, description, notes, account_id)
-		VALUES ('01/02/2026', '2026-02-01', -50, 'ALLOC PARENT', '', ?)
+		VALUES ('01/02/2026', '2026-02-01', -5000, 'ALLOC PARENT', '', ?)
 	`, accountID)
 	if err != nil {
 		t.Fatalf("insert parent txn: %v", err)
@@ -1637,19 +2053,19 @@ func TestTransactionAllocationCapacityValidation(t *testing.T) {
 	}
 	parentID := int(parentID64)
 
-	allocationID, err := insertTransactionAllocation(db, parentID, 20, nil, "split", nil)
+	allocationID, err := insertTransactionAllocation(db, parentID, 2000, nil, "split", nil)
 	if err != nil {
 		t.Fatalf("insertTransactionAllocation: %v", err)
 	}
-	var amount float64
+	var amount money
 	if err := db.QueryRow(`SELECT amount FROM transaction_allocations WHERE id = ?`, allocationID).Scan(&amount); err != nil {
 		t.Fatalf("load allocation amount: %v", err)
 	}
-	if amount != -20 {
-		t.Fatalf("allocation amount = %.2f, want -20", amount)
+	if amount != -2000 {
+		t.Fatalf("allocation amount = %s, want -20.00", amount)
 	}
 
-	if _, err := insertTransactionAllocation(db, parentID, 40, nil, "too much", nil); err == nil {
+	if _, err := insertTransactionAllocation(db, parentID, 4000, nil, "too much", nil); err == nil {
 		t.Fatal("expected over-capacity allocation to fail")
 	}
 }
@@ -71,14 +71,30 @@ func overlayPrecedence() []overlayEntry {
 			forFooter:       true,
 			forCommandScope: true,
 		},
+		{
+			name:            "formatWizard",
+			guard:           func(m model) bool { return m.formatWizard != nil },
+			scope:           func(m model) string { return scopeFormatWizard },
+			handler:         func(m model, msg tea.KeyMsg) (tea.Model, tea.Cmd) { return m.updateFormatWizard(msg) },
+			forFooter:       true,
+			forCommandScope: true,
+		},
 		{
 			name:            "importPreview",
-			guard:           func(m model) bool { return m.importPreviewOpen },
+			guard:           func(m model) bool { return m.importPreviewOpen && !m.importPreviewPicking() },
 			scope:           func(m model) string { return scopeImportPreview },
 			handler:         func(m model, msg tea.KeyMsg) (tea.Model, tea.Cmd) { return m.updateImportPreview(msg) },
 			forFooter:       true,
 			forCommandScope: true,
 		},
+		{
+			name:            "importRejects",
+			guard:           func(m model) bool { return m.rejectsReview != nil },
+			scope:           func(m model) string { return scopeImportRejects },
+			handler:         func(m model, msg tea.KeyMsg) (tea.Model, tea.Cmd) { return m.updateImportRejects(msg) },
+			forFooter:       true,
+			forCommandScope: true,
+		},
 		{
 			name:            "filePicker",
 			guard:           func(m model) bool { return m.importPicking },
@@ -119,6 +135,14 @@ func overlayPrecedence() []overlayEntry {
 			forFooter:       true,
 			forCommandScope: true,
 		},
+		{
+			name:            "ledgerPicker",
+			guard:           func(m model) bool { return m.ledgerPicker != nil },
+			scope:           func(m model) string { return scopeLedgerPicker },
+			handler:         func(m model, msg tea.KeyMsg) (tea.Model, tea.Cmd) { return m.updateLedgerPicker(msg) },
+			forFooter:       true,
+			forCommandScope: true,
+		},
 		{
 			name:            "managerActionPicker",
 			guard:           func(m model) bool { return m.managerActionPicker != nil },
@@ -303,8 +327,25 @@ var interactionContracts = map[string]InteractionContract{
 			hideHint(IntentCancel, actionClose),
 			showHint(IntentApply, actionImportAll, "all"),
 			showHint(IntentApply, actionSkipDupes, "skip"),
+			showHint(IntentToggle, actionToggleSelect, "dupe?"),
+			showHint(IntentEdit, actionQuickCategory, "cat"),
+			showHint(IntentEdit, actionQuickTag, "tag"),
 			showHint(IntentToggle, actionImportPreviewToggle, "preview"),
 			showHint(IntentApply, actionImportRawView, "rules"),
+			showHint(IntentToggle, actionQuarantineRejects, "quarantine"),
+		},
+	},
+	scopeImportRejects: {
+		Scope: scopeImportRejects,
+		Kind:  ContextForm,
+		Hints: []InteractionHint{
+			hideHint(IntentMovePrev, actionUp),
+			hideHint(IntentMoveNext, actionDown),
+			hideHint(IntentEdit, actionLeft),
+			hideHint(IntentEdit, actionRight),
+			showHint(IntentSave, actionSave, "promote"),
+			showHint(IntentDelete, actionDelete, "discard"),
+			showHint(IntentCancel, actionClose, "close"),
 		},
 	},
 	scopeFilePicker: {
@@ -315,9 +356,22 @@ var interactionContracts = map[string]InteractionContract{
 			hideHint(IntentMoveNext, actionDown),
 			hideHint(IntentSelect, actionSelect),
 			hideHint(IntentCancel, actionClose),
+			showHint(IntentEdit, actionFormatWizard, "new format"),
+			showHint(IntentApply, actionImportPending, "import all"),
 			showHint(IntentCancel, actionQuit, "quit"),
 		},
 	},
+	scopeFormatWizard: {
+		Scope: scopeFormatWizard,
+		Kind:  ContextForm,
+		Hints: []InteractionHint{
+			hideHint(IntentEdit, actionLeft),
+			hideHint(IntentEdit, actionRight),
+			hideHint(IntentToggle, actionToggleSelect),
+			hideHint(IntentSave, actionSave),
+			hideHint(IntentCancel, actionClose),
+		},
+	},
 	scopeCategoryPicker: {
 		Scope: scopeCategoryPicker,
 		Kind:  ContextList,
@@ -362,6 +416,16 @@ var interactionContracts = map[string]InteractionContract{
 			hideHint(IntentApply, actionSelect),
 		},
 	},
+	scopeLedgerPicker: {
+		Scope: scopeLedgerPicker,
+		Kind:  ContextList,
+		Hints: []InteractionHint{
+			hideHint(IntentMovePrev, actionUp),
+			hideHint(IntentMoveNext, actionDown),
+			hideHint(IntentSelect, actionSelect),
+			hideHint(IntentCancel, actionClose),
+		},
+	},
 	scopeManagerAccountAction: {
 		Scope: scopeManagerAccountAction,
 		Kind:  ContextList,
@@ -642,6 +706,8 @@ var interactionContracts = map[string]InteractionContract{
 			showHint(IntentApply, actionCommandDefault, "default"),
 			showHint(IntentDelete, actionClearDB, "clear"),
 			showHint(IntentApply, actionImport, "import"),
+			showHint(IntentApply, actionLoadFXRates, "fx rates"),
+			showHint(IntentApply, actionRerunMerchants, "merchants"),
 			showHint(IntentApply, actionResetKeybindings, "reset"),
 		},
 	},
@@ -653,6 +719,18 @@ var interactionContracts = map[string]InteractionContract{
 			hideHint(IntentMoveNext, actionDown),
 			hideHint(IntentCancel, actionBack),
 			hideHint(IntentSelect, actionSelect),
+			showHint(IntentDelete, actionDelete, "undo import"),
+			showHint(IntentEdit, actionReviewRejects, "rejects"),
+		},
+	},
+	scopeSettingsActiveBackups: {
+		Scope: scopeSettingsActiveBackups,
+		Kind:  ContextList,
+		Hints: []InteractionHint{
+			hideHint(IntentMovePrev, actionUp),
+			hideHint(IntentMoveNext, actionDown),
+			hideHint(IntentCancel, actionBack),
+			showHint(IntentApply, actionRestoreBackup, "restore"),
 		},
 	},
 	scopeGlobal: {
@@ -666,6 +744,8 @@ var interactionContracts = map[string]InteractionContract{
 			showHint(IntentApply, actionJumpMode, "jump"),
 			showHint(IntentApply, actionCommandPalette, "commands"),
 			showHint(IntentApply, actionCommandMode, "command"),
+			showHint(IntentApply, actionUndo, "undo"),
+			showHint(IntentApply, actionRedo, "redo"),
 			showHint(IntentCancel, actionQuit, "quit"),
 		},
 	},
@@ -818,6 +898,8 @@ var modalTextContracts = map[string]modalTextBehavior{
 	scopeSettingsModeTag:      {cursorAware: true, printableFirst: true, vimNavSuppressed: true},
 	scopeQuickOffset:          {cursorAware: true, printableFirst: true, vimNavSuppressed: true},
 	scopeManagerModal:         {cursorAware: true, printableFirst: true, vimNavSuppressed: true},
+	scopeFormatWizard:         {cursorAware: true, printableFirst: true, vimNavSuppressed: true},
+	scopeImportRejects:        {cursorAware: true, printableFirst: true, vimNavSuppressed: true},
 	scopeDetailModal:          {cursorAware: true, printableFirst: true, vimNavSuppressed: false}, // detail modal uses dedicated updateDetailNotes handler when editing; j/k needed for non-editing scroll
 	scopeFilterInput:          {cursorAware: true, printableFirst: true, vimNavSuppressed: false},
 	scopeDashboardCustomInput: {cursorAware: false, printableFirst: true, vimNavSuppressed: false},
@@ -17,10 +17,12 @@ func TestPhase7ActiveInteractionContractResolvesScopes(t *testing.T) {
 		{name: "detail", setup: func(m *model) { m.showDetail = true }, wantScope: scopeDetailModal},
 		{name: "import_preview", setup: func(m *model) { m.importPreviewOpen = true }, wantScope: scopeImportPreview},
 		{name: "file_picker", setup: func(m *model) { m.importPicking = true }, wantScope: scopeFilePicker},
+		{name: "format_wizard", setup: func(m *model) { m.formatWizard = &formatWizardState{} }, wantScope: scopeFormatWizard},
 		{name: "category_picker", setup: func(m *model) { m.catPicker = &pickerState{} }, wantScope: scopeCategoryPicker},
 		{name: "tag_picker", setup: func(m *model) { m.tagPicker = &pickerState{} }, wantScope: scopeTagPicker},
 		{name: "quick_offset", setup: func(m *model) { m.allocationModalOpen = true }, wantScope: scopeQuickOffset},
 		{name: "filter_apply_picker", setup: func(m *model) { m.filterApplyPicker = &pickerState{} }, wantScope: scopeFilterApplyPicker},
+		{name: "ledger_picker", setup: func(m *model) { m.ledgerPicker = &pickerState{} }, wantScope: scopeLedgerPicker},
 		{name: "manager_action_picker", setup: func(m *model) { m.managerActionPicker = &pickerState{} }, wantScope: scopeManagerAccountAction},
 		{name: "filter_edit", setup: func(m *model) { m.filterEditOpen = true }, wantScope: scopeFilterEdit},
 		{name: "manager_modal", setup: func(m *model) { m.managerModalOpen = true }, wantScope: scopeManagerModal},
@@ -42,7 +44,7 @@ func TestPhase7ActiveInteractionContractResolvesScopes(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			m := newModel()
+			m := newModel("")
 			m.keys = NewKeyRegistry()
 			tt.setup(&m)
 			got := m.activeInteractionContract()
@@ -92,6 +94,7 @@ func TestPhase7InteractionContractVisibleHintsMapToBoundKeys(t *testing.T) {
 		confirmActionDeleteRule,
 		confirmActionDeleteFilter,
 		confirmActionClearDB,
+		confirmActionUndoImport,
 	}
 	for _, action := range confirmActions {
 		spec, ok := settingsConfirmSpecFor(action)
@@ -161,7 +164,7 @@ func TestPhase7InteractionContractKindDefaults(t *testing.T) {
 }
 
 func TestPhase7RenderFooterFromContract(t *testing.T) {
-	m := newModel()
+	m := newModel("")
 	m.width = 140
 	keys := NewKeyRegistry()
 
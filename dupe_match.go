@@ -0,0 +1,210 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// maxDupeMatchDays caps the likely-duplicate date window.
+const maxDupeMatchDays = 14
+
+// dupeMatchSettings configures likely-duplicate matching for import previews.
+// A zero windowDays disables it; exact duplicates are still detected.
+type dupeMatchSettings struct {
+	windowDays int
+	similarity float64
+}
+
+func (s appSettings) dupeMatch() dupeMatchSettings {
+	return dupeMatchSettings{windowDays: s.DupeMatchDays, similarity: s.DupeMatchSimilarity}
+}
+
+func (s dupeMatchSettings) enabled() bool {
+	return s.windowDays > 0 && s.similarity > 0
+}
+
+// importDupeMatch is the existing transaction a preview row likely
+// duplicates (e.g. the pending copy of a now-posted charge).
+type importDupeMatch struct {
+	txnID       int
+	dateISO     string
+	amount      money
+	description string
+	externalRef string
+	similarity  float64
+}
+
+// descriptionTokens splits a description into lower-cased alphanumeric words.
+func descriptionTokens(desc string) []string {
+	return strings.FieldsFunc(strings.ToLower(desc), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// descriptionSimilarity scores two descriptions 0..1 as the share of the
+// shorter description's words that also appear in the longer one. Banks
+// usually append to a description between pending and posted ("DAN MURPHYS"
+// -> "DAN MURPHYS SYDNEY AU"), which this scores as a full match.
+func descriptionSimilarity(a, b string) float64 {
+	ta, tb := descriptionTokens(a), descriptionTokens(b)
+	if len(ta) == 0 || len(tb) == 0 {
+		if len(ta) == len(tb) {
+			return 1
+		}
+		return 0
+	}
+	if len(ta) > len(tb) {
+		ta, tb = tb, ta
+	}
+	longer := make(map[string]int, len(tb))
+	for _, tok := range tb {
+		longer[tok]++
+	}
+	shared := 0
+	for _, tok := range ta {
+		if longer[tok] > 0 {
+			longer[tok]--
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(ta))
+}
+
+func daysBetweenISO(a, b string) (int, bool) {
+	ta, errA := time.Parse("2006-01-02", a)
+	tb, errB := time.Parse("2006-01-02", b)
+	if errA != nil || errB != nil {
+		return 0, false
+	}
+	days := int(math.Round(ta.Sub(tb).Hours() / 24))
+	if days < 0 {
+		days = -days
+	}
+	return days, true
+}
+
+// flagLikelyDuplicates marks preview rows that are not exact duplicates but
+// share an account and amount with an existing transaction dated within the
+// window and described similarly enough. Each existing transaction matches
+// at most one row. Flagged rows default to being skipped.
+func flagLikelyDuplicates(db *sql.DB, rows []importPreviewRow, accountID int, settings dupeMatchSettings) error {
+	if !settings.enabled() || len(rows) == 0 {
+		return nil
+	}
+	minDate, maxDate := "", ""
+	for _, row := range rows {
+		if row.isDupe {
+			continue
+		}
+		if minDate == "" || row.dateISO < minDate {
+			minDate = row.dateISO
+		}
+		if row.dateISO > maxDate {
+			maxDate = row.dateISO
+		}
+	}
+	if minDate == "" {
+		return nil
+	}
+	lo, errLo := time.Parse("2006-01-02", minDate)
+	hi, errHi := time.Parse("2006-01-02", maxDate)
+	if errLo != nil || errHi != nil {
+		return nil
+	}
+	window := time.Duration(settings.windowDays) * 24 * time.Hour
+
+	dbRows, err := db.Query(`
+		SELECT id, date_iso, amount, description, external_ref
+		FROM transactions
+		WHERE account_id = ? AND date_iso >= ? AND date_iso <= ?
+	`, accountID, lo.Add(-window).Format("2006-01-02"), hi.Add(window).Format("2006-01-02"))
+	if err != nil {
+		return fmt.Errorf("load likely duplicate candidates: %w", err)
+	}
+	defer dbRows.Close()
+	byAmount := make(map[money][]importDupeMatch)
+	for dbRows.Next() {
+		var c importDupeMatch
+		if err := dbRows.Scan(&c.txnID, &c.dateISO, &c.amount, &c.description, &c.externalRef); err != nil {
+			return fmt.Errorf("scan likely duplicate candidate: %w", err)
+		}
+		byAmount[c.amount] = append(byAmount[c.amount], c)
+	}
+	if err := dbRows.Err(); err != nil {
+		return err
+	}
+
+	// Pending transactions being settled by a row aren't duplicates of
+	// another one.
+	used := make(map[int]bool)
+	for _, row := range rows {
+		if row.replacesPending != nil {
+			used[row.replacesPending.txnID] = true
+		}
+	}
+	for i := range rows {
+		row := &rows[i]
+		if row.isDupe || row.replacesPending != nil {
+			continue
+		}
+		var best *importDupeMatch
+		bestDays := 0
+		for _, c := range byAmount[row.amount] {
+			if used[c.txnID] {
+				continue
+			}
+			if row.externalRef != "" && c.externalRef != "" {
+				// Both carry bank references that differ; distinct transactions.
+				continue
+			}
+			days, ok := daysBetweenISO(row.dateISO, c.dateISO)
+			if !ok || days > settings.windowDays {
+				continue
+			}
+			c.similarity = descriptionSimilarity(row.description, c.description)
+			if c.similarity < settings.similarity {
+				continue
+			}
+			if best == nil || c.similarity > best.similarity || (c.similarity == best.similarity && days < bestDays) {
+				match := c
+				best, bestDays = &match, days
+			}
+		}
+		if best != nil {
+			used[best.txnID] = true
+			row.likelyDupe = best
+			row.skipLikelyDupe = true
+		}
+	}
+	return nil
+}
+
+// importPreviewDisplayedIndices returns the snapshot row indices shown by the
+// preview: every row, or only exact and likely duplicates.
+func importPreviewDisplayedIndices(snapshot *importPreviewSnapshot, showAll bool) []int {
+	if snapshot == nil {
+		return nil
+	}
+	out := make([]int, 0, len(snapshot.rows))
+	for i, row := range snapshot.rows {
+		if showAll || row.isDupe || row.likelyDupe != nil {
+			out = append(out, i)
+		}
+	}
+	return out
+}
+
+// likelyDupeSkipCount counts flagged rows the user left marked as duplicates.
+func likelyDupeSkipCount(snapshot *importPreviewSnapshot) int {
+	n := 0
+	for _, row := range snapshot.rows {
+		if row.likelyDupe != nil && row.skipLikelyDupe {
+			n++
+		}
+	}
+	return n
+}
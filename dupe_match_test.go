@@ -0,0 +1,110 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestDescriptionSimilarity(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want float64
+	}{
+		{"DAN MURPHYS", "DAN MURPHYS SYDNEY AU", 1},
+		{"Uber *Trip", "UBER TRIP HELP.UBER.COM", 1},
+		{"WOOLWORTHS 1234", "COLES 5678", 0},
+		{"KMART PENRITH", "KMART BLACKTOWN", 0.5},
+	}
+	for _, tt := range tests {
+		if got := descriptionSimilarity(tt.a, tt.b); got != tt.want {
+			t.Errorf("descriptionSimilarity(%q, %q) = %v, want %v", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
+
+func TestScanFlagsLikelyDuplicatesAndSkipsThemByDefault(t *testing.T) {
+	db, cleanup := testDB(t)
+	defer cleanup()
+
+	acctID, err := insertAccount(db, "ANZ", "debit", true)
+	if err != nil {
+		t.Fatalf("insertAccount: %v", err)
+	}
+	// Pending copies imported earlier: one matches, one is outside the window.
+	for _, stmt := range []string{
+		`INSERT INTO transactions (date_raw, date_iso, amount, description, account_id) VALUES ('1/02/2026', '2026-02-01', -2000, 'DAN MURPHYS', ?)`,
+		`INSERT INTO transactions (date_raw, date_iso, amount, description, account_id) VALUES ('1/01/2026', '2026-01-01', -1500, 'NETFLIX', ?)`,
+	} {
+		if _, err := db.Exec(stmt, acctID); err != nil {
+			t.Fatalf("seed: %v", err)
+		}
+	}
+
+	format := testANZFormat()
+	format.Account = "ANZ"
+	dir := t.TempDir()
+	file := "anz-posted.csv"
+	csv := "3/02/2026,-20.00,DAN MURPHYS SYDNEY AU\n3/02/2026,-15.00,NETFLIX\n4/02/2026,-20.00,DAN MURPHYS SYDNEY AU\n"
+	if err := os.WriteFile(filepath.Join(dir, file), []byte(csv), 0o644); err != nil {
+		t.Fatalf("write csv: %v", err)
+	}
+
+	done := scanDupesCmd(db, file, dir, []csvFormat{format}, nil, defaultSettings().dupeMatch())().(importPreviewMsg)
+	if done.err != nil {
+		t.Fatalf("scan: %v", done.err)
+	}
+	snap := done.snapshot
+	if snap.likelyDupeCount != 1 || snap.newCount != 2 || snap.dupeCount != 0 {
+		t.Fatalf("counts likely=%d new=%d dupes=%d, want 1/2/0", snap.likelyDupeCount, snap.newCount, snap.dupeCount)
+	}
+	first := snap.rows[0]
+	if first.likelyDupe == nil || first.likelyDupe.description != "DAN MURPHYS" || !first.skipLikelyDupe {
+		t.Fatalf("first row match = %+v skip=%v", first.likelyDupe, first.skipLikelyDupe)
+	}
+	if snap.rows[2].likelyDupe != nil {
+		t.Fatal("an existing transaction should match at most one row")
+	}
+
+	_, inserted, dupes, _, _, _, err := importSnapshotRows(db, snap, true)
+	if err != nil {
+		t.Fatalf("importSnapshotRows: %v", err)
+	}
+	if inserted != 2 || dupes != 1 {
+		t.Fatalf("inserted=%d dupes=%d, want 2/1", inserted, dupes)
+	}
+
+	disabled := scanDupesCmd(db, file, dir, []csvFormat{format}, nil, dupeMatchSettings{})().(importPreviewMsg)
+	if disabled.err != nil || disabled.snapshot.likelyDupeCount != 0 {
+		t.Fatalf("disabled matcher flagged rows: %+v", disabled.snapshot)
+	}
+}
+
+func TestImportPreviewToggleLikelyDuplicate(t *testing.T) {
+	m := newModel("")
+	m.ready = true
+	m.importPreviewOpen = true
+	m.importPreviewSnapshot = &importPreviewSnapshot{
+		rows: []importPreviewRow{
+			{index: 1, dateISO: "2026-02-03", amount: -500, description: "NEW"},
+			{index: 2, dateISO: "2026-02-03", amount: -2000, description: "DAN MURPHYS SYDNEY",
+				likelyDupe: &importDupeMatch{txnID: 7, dateISO: "2026-02-01", amount: -2000, description: "DAN MURPHYS", similarity: 1}, skipLikelyDupe: true},
+		},
+		newCount:        1,
+		likelyDupeCount: 1,
+	}
+
+	// The compact view lists only the flagged row.
+	if got := importPreviewDisplayedCount(m.importPreviewSnapshot, false); got != 1 {
+		t.Fatalf("displayed = %d, want 1", got)
+	}
+	toggle := bindingKeyMsg(m.primaryActionKey(scopeImportPreview, actionToggleSelect, "space"))
+	next, _ := m.Update(toggle)
+	got := next.(model)
+	if got.importPreviewSnapshot.rows[1].skipLikelyDupe {
+		t.Fatal("toggle should mark the likely duplicate for import")
+	}
+	if likelyDupeSkipCount(got.importPreviewSnapshot) != 0 {
+		t.Fatal("skip count should drop to 0")
+	}
+}
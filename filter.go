@@ -335,13 +335,23 @@ func (p *filterParser) parseFieldPredicate() (*filterNode, error) {
 			return nil, fmt.Errorf("type expects debit|credit at %d", fieldTok.pos+1)
 		}
 		return &filterNode{kind: filterNodeField, field: field, op: "=", value: v}, nil
+	case "status":
+		raw, err := p.collectFieldValue(field, false)
+		if err != nil {
+			return nil, err
+		}
+		v := strings.ToLower(strings.TrimSpace(raw))
+		if v != txnStatusPending && v != txnStatusPosted {
+			return nil, fmt.Errorf("status expects pending|posted at %d", fieldTok.pos+1)
+		}
+		return &filterNode{kind: filterNodeField, field: field, op: "=", value: v}, nil
 	case "cat", "tag", "acc":
 		raw, err := p.collectFieldValue(field, true)
 		if err != nil {
 			return nil, err
 		}
 		return &filterNode{kind: filterNodeField, field: field, op: "=", value: strings.TrimSpace(raw)}, nil
-	case "desc", "note":
+	case "desc", "merchant", "note":
 		raw, err := p.collectFieldValue(field, true)
 		if err != nil {
 			return nil, err
@@ -412,7 +422,7 @@ func (p *filterParser) consume() filterToken {
 
 func isFilterField(v string) bool {
 	switch strings.ToLower(strings.TrimSpace(v)) {
-	case "desc", "cat", "tag", "acc", "amt", "type", "note", "date":
+	case "desc", "merchant", "cat", "tag", "acc", "amt", "type", "note", "date", "status":
 		return true
 	default:
 		return false
@@ -711,6 +721,8 @@ func evalFieldFilter(node *filterNode, t transaction, tags []tag) bool {
 	switch field {
 	case "desc":
 		return strings.Contains(strings.ToLower(t.description), strings.ToLower(node.value))
+	case "merchant":
+		return strings.Contains(strings.ToLower(txnMerchant(t)), strings.ToLower(node.value))
 	case "note":
 		return strings.Contains(strings.ToLower(t.notes), strings.ToLower(node.value))
 	case "cat":
@@ -734,8 +746,10 @@ func evalFieldFilter(node *filterNode, t transaction, tags []tag) bool {
 			return t.amount > 0
 		}
 		return false
+	case "status":
+		return txnStatusOrPosted(t.status) == node.value
 	case "amt":
-		return evalAmountField(node, t.amount)
+		return evalAmountField(node, t.amount.float())
 	case "date":
 		return evalDateField(node, t.dateISO)
 	default:
@@ -860,7 +874,7 @@ func renderFieldNode(node *filterNode) string {
 			}
 			return field + ":" + node.op + node.value
 		}
-		if field == "type" || field == "date" {
+		if field == "type" || field == "date" || field == "status" {
 			if node.op == "=" {
 				return field + ":" + node.value
 			}
@@ -260,7 +260,7 @@ func (m model) updateFilterApplyPicker(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				next.setError(fmt.Sprintf("Custom mode update failed: %v", err))
 				return next, nil
 			}
-			return next, saveCustomPaneModesCmd(next.customPaneModes)
+			return next, saveCustomPaneModesCmd(next.profile, next.customPaneModes)
 		}
 		next, err := m.applySavedFilterByID(id)
 		next.filterApplyPicker = nil
@@ -373,7 +373,7 @@ func (m model) saveFilterEditor() (model, error) {
 		}
 	}
 
-	if err := saveSavedFilters(updated); err != nil {
+	if err := saveSavedFilters(m.profile, updated); err != nil {
 		return m, err
 	}
 	m.savedFilters = updated
@@ -403,7 +403,7 @@ func (m model) deleteSavedFilterByID(id string) (model, error) {
 	if !removed {
 		return m, fmt.Errorf("filter %q not found", normalized)
 	}
-	if err := saveSavedFilters(updated); err != nil {
+	if err := saveSavedFilters(m.profile, updated); err != nil {
 		return m, err
 	}
 	m.savedFilters = updated
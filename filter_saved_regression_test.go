@@ -17,10 +17,10 @@ func TestSettingsConfirmDeleteFilterUsesCapturedFilterID(t *testing.T) {
 	}
 	defer db.Close()
 
-	m := newModel()
+	m := newModel("")
 	m.db = db
 	m.savedFilters = []savedFilter{{ID: "groceries", Name: "Groceries", Expr: "cat:Groceries"}}
-	if err := saveSavedFilters(m.savedFilters); err != nil {
+	if err := saveSavedFilters("", m.savedFilters); err != nil {
 		t.Fatalf("saveSavedFilters: %v", err)
 	}
 
@@ -44,9 +44,9 @@ func TestSettingsConfirmDeleteFilterUsesCapturedFilterID(t *testing.T) {
 func TestSaveFilterEditorAllowsRenamingFilterID(t *testing.T) {
 	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
 
-	m := newModel()
+	m := newModel("")
 	m.savedFilters = []savedFilter{{ID: "groceries", Name: "Groceries", Expr: "cat:Groceries"}}
-	if err := saveSavedFilters(m.savedFilters); err != nil {
+	if err := saveSavedFilters("", m.savedFilters); err != nil {
 		t.Fatalf("saveSavedFilters: %v", err)
 	}
 
@@ -73,7 +73,7 @@ func TestSaveFilterEditorAllowsRenamingFilterID(t *testing.T) {
 }
 
 func TestOpenFilterEditorNewUsesAutoIDAndBlankName(t *testing.T) {
-	m := newModel()
+	m := newModel("")
 	m.savedFilters = []savedFilter{{ID: "filter-2", Name: "Existing", Expr: "cat:Groceries"}}
 	m.filterInput = "cat:Food AND amt:<0"
 
@@ -94,7 +94,7 @@ func TestOpenFilterEditorNewUsesAutoIDAndBlankName(t *testing.T) {
 }
 
 func TestApplyPickerSearchMatchesFilterName(t *testing.T) {
-	m := newModel()
+	m := newModel("")
 	m.savedFilters = []savedFilter{
 		{ID: "groceries", Name: "Groceries Monthly", Expr: "cat:Groceries"},
 		{ID: "utilities", Name: "Utilities", Expr: "cat:Bills"},
@@ -113,7 +113,7 @@ func TestApplyPickerSearchMatchesFilterName(t *testing.T) {
 }
 
 func TestCtrlLFromManagerAccountsOpensApplyPicker(t *testing.T) {
-	m := newModel()
+	m := newModel("")
 	m.ready = true
 	m.activeTab = tabManager
 	m.managerMode = managerModeAccounts
@@ -128,7 +128,7 @@ func TestCtrlLFromManagerAccountsOpensApplyPicker(t *testing.T) {
 }
 
 func TestFilterEditorPrintableJKAreLiteralText(t *testing.T) {
-	m := newModel()
+	m := newModel("")
 	m.filterEditOpen = true
 	m.filterEditFocus = 1 // name field
 	m.filterEditName = "a"
@@ -154,7 +154,7 @@ func TestFilterEditorPrintableJKAreLiteralText(t *testing.T) {
 }
 
 func TestApplyPickerPreservesRecencyOrder(t *testing.T) {
-	m := newModel()
+	m := newModel("")
 	m.savedFilters = []savedFilter{
 		{ID: "alpha", Name: "Alpha", Expr: "cat:A"},
 		{ID: "beta", Name: "Beta", Expr: "cat:B"},
@@ -105,7 +105,7 @@ func TestFilterParserQuotedValues(t *testing.T) {
 func TestFilterEvalAllFields(t *testing.T) {
 	txn := transaction{
 		dateISO:      "2025-03-15",
-		amount:       -120.50,
+		amount:       -12050,
 		description:  "Coffee Shop",
 		categoryName: "Food",
 		accountName:  "ANZ Savings",
@@ -163,7 +163,7 @@ func TestFilterStrictInvalidTokenNeverFallsBack(t *testing.T) {
 }
 
 func TestFilterInputPermissiveFallbackOnParseError(t *testing.T) {
-	m := newModel()
+	m := newModel("")
 	m.filterInput = "cat:"
 	m.reparseFilterInput()
 	if m.filterExpr == nil {
@@ -191,7 +191,7 @@ func TestFilterSerializerCanonicalUppercaseAndMinimalParens(t *testing.T) {
 }
 
 func TestFilterBuildersComposeScopes(t *testing.T) {
-	m := newModel()
+	m := newModel("")
 	m.filterInput = "cat:Food"
 	m.reparseFilterInput()
 	m.accounts = []account{{id: 1, name: "ANZ"}, {id: 2, name: "Cash"}}
@@ -211,7 +211,7 @@ func TestFilterBuildersComposeScopes(t *testing.T) {
 }
 
 func TestBuildCustomModeFilterFromConfigModes(t *testing.T) {
-	m := newModel()
+	m := newModel("")
 	m.customPaneModes = []customPaneMode{{Pane: "net_cashflow", Name: "Renovation", Expr: "cat:Home AND amt:<0"}}
 	n := m.buildCustomModeFilter("net_cashflow", "Renovation")
 	if n == nil {
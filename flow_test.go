@@ -59,21 +59,31 @@ func flowKeyForIntent(t *testing.T, keys *KeyRegistry, contract InteractionContr
 
 func flowDrainCmd(t *testing.T, m model, cmd tea.Cmd) model {
 	t.Helper()
-	for i := 0; cmd != nil && i < 32; i++ {
-		msg := cmd()
+	queue := []tea.Cmd{cmd}
+	for i := 0; len(queue) > 0; i++ {
+		if i >= 64 {
+			t.Fatal("command chain exceeded max depth")
+		}
+		next := queue[0]
+		queue = queue[1:]
+		if next == nil {
+			continue
+		}
+		msg := next()
+		if batch, ok := msg.(tea.BatchMsg); ok {
+			queue = append(queue, batch...)
+			continue
+		}
 		if msg == nil {
-			return m
+			continue
 		}
-		next, nextCmd := m.Update(msg)
-		got, ok := next.(model)
+		updated, nextCmd := m.Update(msg)
+		got, ok := updated.(model)
 		if !ok {
-			t.Fatalf("command update returned %T, want model", next)
+			t.Fatalf("command update returned %T, want model", updated)
 		}
 		m = got
-		cmd = nextCmd
-	}
-	if cmd != nil {
-		t.Fatal("command chain exceeded max depth")
+		queue = append(queue, nextCmd)
 	}
 	return m
 }
@@ -91,7 +101,7 @@ func newFlowModelWithDB(t *testing.T) (model, func()) {
 	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
 
 	db, cleanupDB := testDB(t)
-	m := newModel()
+	m := newModel("")
 	m.db = db
 	m.ready = true
 	m.width = 120
@@ -198,6 +208,10 @@ func TestFlowSettingsImportWithDupesSkipPersistsAndRecordsImport(t *testing.T) {
 		t.Fatalf("dupe counts = %d/%d, want 2/2", m.importPreviewSnapshot.dupeCount, m.importPreviewSnapshot.totalRows)
 	}
 
+	m = flowPress(t, m, "s")
+	if !m.importPreviewOpen || !strings.Contains(m.status, "already imported") {
+		t.Fatalf("expected re-import warning with preview still open, status %q", m.status)
+	}
 	m = flowPress(t, m, "s")
 	if m.statusErr {
 		t.Fatalf("skip-dupes status error: %q", m.status)
@@ -263,6 +277,68 @@ func TestFlowImportPreviewParseErrorsBlockDecisionWithoutWrites(t *testing.T) {
 	}
 }
 
+func TestFlowImportQuarantinesBadRowsAndPromotesFix(t *testing.T) {
+	m, cleanup := newFlowModelWithDB(t)
+	defer cleanup()
+
+	base := t.TempDir()
+	m.basePath = base
+	m.activeTab = tabSettings
+	writeFlowCSV(t, base, "ANZ-quarantine.csv", "3/02/2026,-20.00,VALID\nnot-a-date,-11.00,BAD DATE\n")
+
+	m = flowPress(t, m, "i")
+	m = flowPress(t, m, "enter")
+	if m.importPreviewSnapshot == nil || !m.importPreviewSnapshot.canQuarantine() {
+		t.Fatal("expected a preview with quarantinable parse errors")
+	}
+	m = flowPress(t, m, "x")
+	m = flowPress(t, m, "s")
+	if m.importPreviewOpen || m.statusErr || !strings.Contains(m.status, "1 rows quarantined") {
+		t.Fatalf("quarantine import: open=%v status=%q", m.importPreviewOpen, m.status)
+	}
+	rows, err := loadRows(m.db)
+	if err != nil {
+		t.Fatalf("loadRows: %v", err)
+	}
+	if len(rows) != 1 || len(m.importRejects) != 1 {
+		t.Fatalf("rows=%d rejects=%d, want 1 and 1", len(rows), len(m.importRejects))
+	}
+	rej := m.importRejects[0]
+	if rej.sourceLine != 2 || rej.raw != "not-a-date,-11.00,BAD DATE" || rej.amountRaw != "-11.00" || rej.importID == nil {
+		t.Fatalf("reject = %+v", rej)
+	}
+
+	m.openRejectsReview()
+	for range "not-a-date" {
+		m = flowPress(t, m, "backspace")
+	}
+	m = flowType(t, m, "31/02/2026")
+	m = flowPress(t, m, "enter")
+	if m.rejectsReview == nil || !strings.Contains(m.rejectsReview.err, "date") {
+		t.Fatal("an invalid date should keep the reject in review with an error")
+	}
+	for range "31/02/2026" {
+		m = flowPress(t, m, "backspace")
+	}
+	m = flowType(t, m, "4/02/2026")
+	m = flowPress(t, m, "enter")
+	if m.rejectsReview != nil || len(m.importRejects) != 0 {
+		t.Fatalf("review should close once quarantine is empty; rejects=%d", len(m.importRejects))
+	}
+	rows, err = loadRows(m.db)
+	if err != nil {
+		t.Fatalf("loadRows after promote: %v", err)
+	}
+	if len(rows) != 2 || len(m.imports) != 1 || m.imports[0].rowCount != 2 {
+		t.Fatalf("rows=%d imports=%+v, want the promoted row on the original import", len(rows), m.imports)
+	}
+	for _, r := range rows {
+		if r.description == "BAD DATE" && (r.dateISO != "2026-02-04" || r.amount != -1100) {
+			t.Fatalf("promoted row = %+v", r)
+		}
+	}
+}
+
 func TestFlowImportPreviewEscCancelsAndBlocksCommandOpen(t *testing.T) {
 	m, cleanup := newFlowModelWithDB(t)
 	defer cleanup()
@@ -304,6 +380,56 @@ func TestFlowImportPreviewEscCancelsAndBlocksCommandOpen(t *testing.T) {
 	}
 }
 
+func TestFlowImportAllPendingPreviewsEachFileAndArchives(t *testing.T) {
+	m, cleanup := newFlowModelWithDB(t)
+	defer cleanup()
+
+	inbox := t.TempDir()
+	m.basePath = inbox
+	m.importInbox = importInboxSettings{dir: inbox, recursive: true}
+	m.activeTab = tabSettings
+	writeFlowCSV(t, inbox, "ANZ-a.csv", "3/02/2026,-20.00,QUEUE A\n")
+	if err := os.MkdirAll(filepath.Join(inbox, "sub"), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	writeFlowCSV(t, filepath.Join(inbox, "sub"), "ANZ-b.csv", "4/02/2026,-30.00,QUEUE B\n")
+
+	m = flowPress(t, m, "i")
+	if !m.importPicking || len(m.importFiles) != 2 {
+		t.Fatalf("picking=%v files=%v", m.importPicking, m.importFiles)
+	}
+	m = flowPress(t, m, m.primaryActionKey(scopeFilePicker, actionImportPending, "a"))
+	if !m.importPreviewOpen || m.importPreviewSnapshot.fileName != "ANZ-a.csv" {
+		t.Fatalf("expected first preview open, got open=%v", m.importPreviewOpen)
+	}
+
+	m = flowPress(t, m, m.primaryActionKey(scopeImportPreview, actionSkipDupes, "s"))
+	if !m.importPreviewOpen || m.importPreviewSnapshot.fileName != "ANZ-b.csv" {
+		t.Fatalf("expected second preview after first import, status=%q", m.status)
+	}
+	if !fileExists(filepath.Join(inbox, "archive", "ANZ-a.csv")) || fileExists(filepath.Join(inbox, "ANZ-a.csv")) {
+		t.Fatal("first file should be moved to archive/")
+	}
+
+	m = flowPress(t, m, "esc")
+	if m.importPreviewOpen || m.importQueue != nil {
+		t.Fatal("queue should finish after the last preview is cancelled")
+	}
+	if !strings.Contains(m.status, "1 imported, 1 skipped, 0 failed") {
+		t.Fatalf("status = %q", m.status)
+	}
+	if !fileExists(filepath.Join(inbox, "sub", "ANZ-b.csv")) {
+		t.Fatal("skipped file should stay in the inbox")
+	}
+	rows, err := loadRows(m.db)
+	if err != nil {
+		t.Fatalf("loadRows: %v", err)
+	}
+	if len(rows) != 1 {
+		t.Fatalf("rows = %d, want 1", len(rows))
+	}
+}
+
 func TestFlowManagerQuickCategoryAndTagPersistAfterRefresh(t *testing.T) {
 	m, cleanup := newFlowModelWithDB(t)
 	defer cleanup()
@@ -311,8 +437,8 @@ func TestFlowManagerQuickCategoryAndTagPersistAfterRefresh(t *testing.T) {
 	_, err := m.db.Exec(`
 		INSERT INTO transactions (date_raw, date_iso, amount, description, notes)
 		VALUES
-			('03/02/2026', '2026-02-03', -10.00, 'FLOW A', ''),
-			('03/02/2026', '2026-02-03', -20.00, 'FLOW B', '')
+			('03/02/2026', '2026-02-03', -1000, 'FLOW A', ''),
+			('03/02/2026', '2026-02-03', -2000, 'FLOW B', '')
 	`)
 	if err != nil {
 		t.Fatalf("seed transactions: %v", err)
@@ -417,7 +543,7 @@ func TestFlowCommandPaletteImportCommandCompletesImport(t *testing.T) {
 func TestFlowSettingsRowsPerPageSaveAndReload(t *testing.T) {
 	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
 
-	m := newModel()
+	m := newModel("")
 	m.activeTab = tabSettings
 	m.settColumn = settColRight
 	m.settSection = settSecDBImport
@@ -432,7 +558,7 @@ func TestFlowSettingsRowsPerPageSaveAndReload(t *testing.T) {
 		t.Fatalf("unexpected settings save error: %q", m.status)
 	}
 
-	reloaded := newModel()
+	reloaded := newModel("")
 	if reloaded.maxVisibleRows != m.maxVisibleRows {
 		t.Fatalf("reloaded maxVisibleRows = %d, want %d", reloaded.maxVisibleRows, m.maxVisibleRows)
 	}
@@ -444,7 +570,7 @@ func TestFlowDetailEditSaveAndReopenShowsPersistedNotes(t *testing.T) {
 
 	res, err := m.db.Exec(`
 		INSERT INTO transactions (date_raw, date_iso, amount, description, notes)
-		VALUES ('03/02/2026', '2026-02-03', -12.34, 'DETAIL FLOW', 'seed')
+		VALUES ('03/02/2026', '2026-02-03', -1234, 'DETAIL FLOW', 'seed')
 	`)
 	if err != nil {
 		t.Fatalf("insert detail txn: %v", err)
@@ -500,7 +626,7 @@ func TestFlowImportMissingMappedAccountShowsErrorAndNoPartialState(t *testing.T)
 	base := t.TempDir()
 	writeFlowCSV(t, base, "ANZ-missing.csv", "3/02/2026,-9.99,MISSING ACCOUNT FLOW\n")
 
-	m := newModel()
+	m := newModel("")
 	m.db = db
 	m.ready = true
 	m.basePath = base
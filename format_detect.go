@@ -0,0 +1,127 @@
+package main
+
+import (
+	"bytes"
+	"encoding/csv"
+	"os"
+	"sort"
+	"strings"
+)
+
+// formatDetectSampleRows is how many data records are scored per format.
+const formatDetectSampleRows = 20
+
+// formatMatch is one configured format's fit against a file's content.
+type formatMatch struct {
+	formatIndex int     // index into the formats slice
+	name        string  // format name, for display
+	score       float64 // 0..1: share of column, date and amount checks that passed
+	prefix      bool    // filename matched the format's import prefix
+}
+
+// formatPrefixMatches reports whether filename starts with the format's
+// import prefix (or its name when no prefix is set), ignoring case.
+func formatPrefixMatches(format csvFormat, filename string) bool {
+	prefix := strings.ToLower(strings.TrimSpace(format.ImportPrefix))
+	if prefix == "" {
+		prefix = strings.ToLower(strings.TrimSpace(format.Name))
+	}
+	return prefix != "" && strings.HasPrefix(strings.ToLower(filename), prefix)
+}
+
+// rankFormatsForFile scores every format against the first records of the
+// CSV at path and returns the plausible ones best first. Each sampled record
+// earns a point for fitting the columns, a parsable date and a parsable
+// amount. Equal scores prefer a filename prefix match, then config order.
+func rankFormatsForFile(path, fileName string, formats []csvFormat) ([]formatMatch, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	matches := make([]formatMatch, 0, len(formats))
+	for i, format := range formats {
+		score := scoreFormatSample(data, format)
+		if score <= 0 {
+			continue
+		}
+		matches = append(matches, formatMatch{
+			formatIndex: i,
+			name:        format.Name,
+			score:       score,
+			prefix:      formatPrefixMatches(format, fileName),
+		})
+	}
+	sort.SliceStable(matches, func(i, j int) bool {
+		if matches[i].score != matches[j].score {
+			return matches[i].score > matches[j].score
+		}
+		if matches[i].prefix != matches[j].prefix {
+			return matches[i].prefix
+		}
+		return matches[i].formatIndex < matches[j].formatIndex
+	})
+	return matches, nil
+}
+
+func scoreFormatSample(data []byte, format csvFormat) float64 {
+	r := csv.NewReader(bytes.NewReader(data))
+	r.FieldsPerRecord = -1
+	if format.Delimiter != "" {
+		r.Comma = rune(format.Delimiter[0])
+	}
+
+	minCols := csvMinCols(format)
+	firstRow := true
+	sampled, points := 0, 0
+	for sampled < formatDetectSampleRows {
+		rec, err := r.Read()
+		if err != nil {
+			// EOF, or a malformed record under this format's delimiter.
+			break
+		}
+		if firstRow && format.HasHeader {
+			firstRow = false
+			if format.usesHeaderNames() {
+				resolved, headerErr := resolveCSVHeaderColumns(format, rec)
+				if headerErr != nil {
+					return 0
+				}
+				format = resolved
+				minCols = csvMinCols(format)
+			}
+			continue
+		}
+		firstRow = false
+		if csvRecordIsBlank(rec) {
+			continue
+		}
+
+		sampled++
+		if len(rec) < minCols {
+			continue
+		}
+		points++
+		if _, err := parseDateISO(strings.TrimSpace(rec[format.DateCol]), format.DateFormat); err == nil {
+			points++
+		}
+		if csvRecordHasAmount(rec, format) {
+			if _, err := parseCSVAmount(rec, format); err == nil {
+				points++
+			}
+		}
+	}
+	if sampled == 0 {
+		return 0
+	}
+	return float64(points) / float64(sampled*3)
+}
+
+// detectFormatForFile picks the best content match for a CSV, falling back
+// to filename-prefix detection when the file can't be read or nothing fits.
+func detectFormatForFile(formats []csvFormat, path, fileName string) *csvFormat {
+	matches, err := rankFormatsForFile(path, fileName, formats)
+	if err == nil && len(matches) > 0 {
+		return &formats[matches[0].formatIndex]
+	}
+	return detectFormat(formats, fileName)
+}
@@ -0,0 +1,59 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRankFormatsForFileScoresContent(t *testing.T) {
+	formats := []csvFormat{
+		testANZFormat(),
+		{Name: "CBA", ImportPrefix: "cba", DateFormat: "02/01/2006", HasHeader: true, Delimiter: ",", DateCol: 0, AmountCol: 1, DescCol: 2},
+		{Name: "Semicolon", ImportPrefix: "semi", DateFormat: "2006-01-02", Delimiter: ";", DateCol: 0, AmountCol: 1, DescCol: 2},
+	}
+	formats[0].ImportPrefix = "anz"
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "download (3).csv")
+	csv := "Date,Amount,Description\n03/02/2026,-20.00,DAN MURPHYS\n04/02/2026,203.92,PAYMENT\n"
+	if err := os.WriteFile(path, []byte(csv), 0o644); err != nil {
+		t.Fatalf("write csv: %v", err)
+	}
+
+	matches, err := rankFormatsForFile(path, "download (3).csv", formats)
+	if err != nil {
+		t.Fatalf("rankFormatsForFile: %v", err)
+	}
+	if len(matches) == 0 || matches[0].name != "CBA" || matches[0].score != 1 {
+		t.Fatalf("matches = %+v, want CBA first with full score", matches)
+	}
+	for _, m := range matches {
+		if m.name == "Semicolon" {
+			t.Fatalf("semicolon format should not match: %+v", matches)
+		}
+	}
+	if got := detectFormatForFile(formats, path, "download (3).csv"); got == nil || got.Name != "CBA" {
+		t.Fatalf("detectFormatForFile = %+v, want CBA", got)
+	}
+}
+
+func TestRankFormatsForFilePrefixBreaksTies(t *testing.T) {
+	a := testANZFormat()
+	a.Name, a.ImportPrefix = "First", "first"
+	b := testANZFormat()
+	b.Name, b.ImportPrefix = "Second", "second"
+
+	dir := t.TempDir()
+	path := filepath.Join(dir, "second-feb.csv")
+	if err := os.WriteFile(path, []byte("3/02/2026,-20.00,DAN MURPHYS\n"), 0o644); err != nil {
+		t.Fatalf("write csv: %v", err)
+	}
+	matches, err := rankFormatsForFile(path, "second-feb.csv", []csvFormat{a, b})
+	if err != nil {
+		t.Fatalf("rankFormatsForFile: %v", err)
+	}
+	if len(matches) != 2 || matches[0].name != "Second" || !matches[0].prefix {
+		t.Fatalf("matches = %+v, want Second first via prefix", matches)
+	}
+}
@@ -0,0 +1,418 @@
+package main
+
+import (
+	"bytes"
+	"database/sql"
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// formatWizardSampleRows is how many file records the wizard grid shows.
+const formatWizardSampleRows = 8
+
+// Column roles a wizard column can be marked with, in toggle order.
+const (
+	wizardRoleNone = iota
+	wizardRoleDate
+	wizardRoleAmount
+	wizardRoleDesc
+	wizardRoleCount
+)
+
+// wizardDelimiters are the delimiter candidates tried by autodetection and
+// cycled by the user.
+var wizardDelimiters = []string{",", ";", "\t", "|"}
+
+// wizardDateLayouts are the Go layouts tried against the marked date column.
+// Day-first layouts come before month-first ones so ambiguous samples
+// resolve the way most non-US bank exports are written.
+var wizardDateLayouts = []string{
+	"2/01/2006",
+	"02/01/2006",
+	"2006-01-02",
+	"2006/01/02",
+	"2-01-2006",
+	"02.01.2006",
+	"2 Jan 2006",
+	"02 Jan 2006",
+	"2-Jan-2006",
+	"2/01/06",
+	"1/2/2006",
+	"01/02/2006",
+	"Jan 2, 2006",
+	"20060102",
+}
+
+// formatWizardState backs the CSV format wizard modal. The sample is parsed
+// once per delimiter; column marks index into sample records.
+type formatWizardState struct {
+	path     string
+	fileName string
+	data     []byte
+	account  textField
+	focus    int // 0=column grid, 1=account name
+
+	delimiter string
+	hasHeader bool
+	records   [][]string
+	colCount  int
+	cursorCol int
+
+	dateCol    int // -1 when unmarked
+	amountCol  int
+	descCol    int
+	dateLayout string // "" when no candidate parses every sample date
+	err        string
+}
+
+type formatWizardSavedMsg struct {
+	formats  []csvFormat
+	format   csvFormat
+	path     string // sampled file, rescanned with the saved format
+	fileName string
+	err      error
+}
+
+// newFormatWizard loads a sample of path and pre-fills delimiter, header
+// row, column roles and date layout from the content.
+func newFormatWizard(path, accountName string) (*formatWizardState, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read sample: %w", err)
+	}
+	w := &formatWizardState{
+		path:      path,
+		fileName:  filepath.Base(path),
+		data:      data,
+		delimiter: detectCSVDelimiter(data),
+		dateCol:   -1,
+		amountCol: -1,
+		descCol:   -1,
+	}
+	if strings.TrimSpace(accountName) == "" {
+		accountName = wizardAccountNameFromFile(w.fileName)
+	}
+	w.account.set(accountName)
+	w.reload()
+	w.hasHeader = detectHeaderRow(w.records)
+	w.suggestColumns()
+	return w, nil
+}
+
+// wizardAccountNameFromFile guesses an account name from the leading word of
+// a file name ("anz-feb.csv" -> "anz").
+func wizardAccountNameFromFile(fileName string) string {
+	stem := strings.TrimSuffix(fileName, filepath.Ext(fileName))
+	if idx := strings.IndexAny(stem, " -_.(0123456789"); idx > 0 {
+		stem = stem[:idx]
+	}
+	return strings.TrimSpace(stem)
+}
+
+// reload re-reads the sample with the current delimiter and clamps marks.
+func (w *formatWizardState) reload() {
+	w.records = readCSVSample(w.data, w.delimiter, formatWizardSampleRows)
+	w.colCount = 0
+	for _, rec := range w.records {
+		w.colCount = max(w.colCount, len(rec))
+	}
+	for _, col := range []*int{&w.dateCol, &w.amountCol, &w.descCol} {
+		if *col >= w.colCount {
+			*col = -1
+		}
+	}
+	w.cursorCol = min(w.cursorCol, max(0, w.colCount-1))
+	w.refreshDateLayout()
+}
+
+func (w *formatWizardState) dataRecords() [][]string {
+	if w.hasHeader && len(w.records) > 0 {
+		return w.records[1:]
+	}
+	return w.records
+}
+
+func (w *formatWizardState) columnValues(col int) []string {
+	var out []string
+	for _, rec := range w.dataRecords() {
+		if col >= 0 && col < len(rec) && strings.TrimSpace(rec[col]) != "" {
+			out = append(out, strings.TrimSpace(rec[col]))
+		}
+	}
+	return out
+}
+
+func (w *formatWizardState) refreshDateLayout() {
+	w.dateLayout = ""
+	if w.dateCol >= 0 {
+		w.dateLayout = detectDateLayout(w.columnValues(w.dateCol))
+	}
+}
+
+// suggestColumns marks the first column whose values all parse as dates,
+// the first other column whose values all parse as amounts, and the widest
+// remaining text column as the description.
+func (w *formatWizardState) suggestColumns() {
+	for col := 0; col < w.colCount && w.dateCol < 0; col++ {
+		if detectDateLayout(w.columnValues(col)) != "" {
+			w.dateCol = col
+		}
+	}
+	for col := 0; col < w.colCount && w.amountCol < 0; col++ {
+		if col != w.dateCol && columnParsesAsAmounts(w.columnValues(col)) {
+			w.amountCol = col
+		}
+	}
+	bestWidth := 0
+	for col := 0; col < w.colCount; col++ {
+		if col == w.dateCol || col == w.amountCol {
+			continue
+		}
+		values := w.columnValues(col)
+		if len(values) == 0 || columnParsesAsAmounts(values) {
+			continue
+		}
+		width := 0
+		for _, v := range values {
+			width += len(v)
+		}
+		if width > bestWidth {
+			bestWidth = width
+			w.descCol = col
+		}
+	}
+	w.refreshDateLayout()
+}
+
+func (w *formatWizardState) roleOf(col int) int {
+	switch col {
+	case w.dateCol:
+		return wizardRoleDate
+	case w.amountCol:
+		return wizardRoleAmount
+	case w.descCol:
+		return wizardRoleDesc
+	}
+	return wizardRoleNone
+}
+
+// cycleRole advances the cursor column to the next role. A role moves off
+// whichever column held it before, so each role marks at most one column.
+func (w *formatWizardState) cycleRole() {
+	col := w.cursorCol
+	next := (w.roleOf(col) + 1) % wizardRoleCount
+	for _, mark := range []*int{&w.dateCol, &w.amountCol, &w.descCol} {
+		if *mark == col {
+			*mark = -1
+		}
+	}
+	switch next {
+	case wizardRoleDate:
+		w.dateCol = col
+	case wizardRoleAmount:
+		w.amountCol = col
+	case wizardRoleDesc:
+		w.descCol = col
+	}
+	w.refreshDateLayout()
+}
+
+func (w *formatWizardState) cycleDelimiter() {
+	idx := 0
+	for i, d := range wizardDelimiters {
+		if d == w.delimiter {
+			idx = i
+		}
+	}
+	w.delimiter = wizardDelimiters[(idx+1)%len(wizardDelimiters)]
+	w.reload()
+}
+
+func (w *formatWizardState) toggleHeader() {
+	w.hasHeader = !w.hasHeader
+	w.refreshDateLayout()
+}
+
+// buildFormat validates the marks and returns the format fields the wizard
+// controls. Identity fields are filled in when saving.
+func (w *formatWizardState) buildFormat() (csvFormat, error) {
+	switch {
+	case strings.TrimSpace(w.account.Value) == "":
+		return csvFormat{}, fmt.Errorf("account name is required")
+	case w.dateCol < 0 || w.amountCol < 0 || w.descCol < 0:
+		return csvFormat{}, fmt.Errorf("mark date, amount and description columns")
+	case w.dateLayout == "":
+		return csvFormat{}, fmt.Errorf("date column does not match a known date layout")
+	}
+	return csvFormat{
+		DateFormat:  w.dateLayout,
+		HasHeader:   w.hasHeader,
+		Delimiter:   w.delimiter,
+		DateCol:     w.dateCol,
+		AmountCol:   w.amountCol,
+		DescCol:     w.descCol,
+		AmountStrip: ",",
+	}, nil
+}
+
+func readCSVSample(data []byte, delimiter string, limit int) [][]string {
+	r := csv.NewReader(bytes.NewReader(data))
+	r.FieldsPerRecord = -1
+	r.LazyQuotes = true
+	if delimiter != "" {
+		r.Comma = rune(delimiter[0])
+	}
+	var out [][]string
+	for len(out) < limit {
+		rec, err := r.Read()
+		if err != nil {
+			break
+		}
+		if csvRecordIsBlank(rec) {
+			continue
+		}
+		out = append(out, rec)
+	}
+	return out
+}
+
+// detectCSVDelimiter picks the candidate that splits the sample into the
+// most columns while keeping the column count consistent across records.
+func detectCSVDelimiter(data []byte) string {
+	best, bestScore := wizardDelimiters[0], 0
+	for _, delim := range wizardDelimiters {
+		records := readCSVSample(data, delim, formatWizardSampleRows)
+		if len(records) == 0 {
+			continue
+		}
+		counts := make(map[int]int)
+		for _, rec := range records {
+			counts[len(rec)]++
+		}
+		modeCols, modeHits := 0, 0
+		for cols, hits := range counts {
+			if hits > modeHits || (hits == modeHits && cols > modeCols) {
+				modeCols, modeHits = cols, hits
+			}
+		}
+		if modeCols < 2 {
+			continue
+		}
+		if score := modeCols * modeHits; score > bestScore {
+			best, bestScore = delim, score
+		}
+	}
+	return best
+}
+
+// detectHeaderRow reports whether the first record looks like column names:
+// none of its cells parse as a date or amount while the next record's do.
+func detectHeaderRow(records [][]string) bool {
+	if len(records) < 2 {
+		return false
+	}
+	typed := func(rec []string) int {
+		n := 0
+		for _, cell := range rec {
+			cell = strings.TrimSpace(cell)
+			if cell == "" {
+				continue
+			}
+			if columnParsesAsAmounts([]string{cell}) || detectDateLayout([]string{cell}) != "" {
+				n++
+			}
+		}
+		return n
+	}
+	return typed(records[0]) == 0 && typed(records[1]) > 0
+}
+
+// detectDateLayout returns the first candidate layout that parses every
+// value, or "" when none does.
+func detectDateLayout(values []string) string {
+	if len(values) == 0 {
+		return ""
+	}
+	for _, layout := range wizardDateLayouts {
+		ok := true
+		for _, v := range values {
+			if _, err := parseDateISO(v, layout); err != nil {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return layout
+		}
+	}
+	return ""
+}
+
+func columnParsesAsAmounts(values []string) bool {
+	if len(values) == 0 {
+		return false
+	}
+	for _, v := range values {
+		if _, err := parseAmount(v); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+// saveFormatWizardCmd writes the wizard result to config.toml through
+// upsertFormatForAccount/saveFormats, creating the account if needed. path is
+// the sampled file, kept whole so nested inbox files rescan where they live.
+func saveFormatWizardCmd(db *sql.DB, profile, name, path string, wizard csvFormat) tea.Cmd {
+	return func() tea.Msg {
+		if db == nil {
+			return formatWizardSavedMsg{err: fmt.Errorf("database not ready")}
+		}
+		name = strings.TrimSpace(name)
+		acctType := "debit"
+		acct, err := loadAccountByNameCI(db, name)
+		if err != nil {
+			return formatWizardSavedMsg{err: err}
+		}
+		if acct != nil {
+			name = acct.name
+			acctType = acct.acctType
+		} else if _, err := insertAccount(db, name, acctType, true); err != nil {
+			return formatWizardSavedMsg{err: err}
+		}
+		if err := upsertFormatForAccount(profile, name, acctType); err != nil {
+			return formatWizardSavedMsg{err: err}
+		}
+		formats, _, err := loadAppConfig(profile)
+		if err != nil {
+			return formatWizardSavedMsg{err: err}
+		}
+		var saved csvFormat
+		for i := range formats {
+			f := &formats[i]
+			if !strings.EqualFold(f.Account, name) && !strings.EqualFold(f.Name, name) {
+				continue
+			}
+			f.DateFormat = wizard.DateFormat
+			f.HasHeader = wizard.HasHeader
+			f.Delimiter = wizard.Delimiter
+			f.DateCol = wizard.DateCol
+			f.AmountCol = wizard.AmountCol
+			f.DescCol = wizard.DescCol
+			f.DescJoin = false
+			f.AmountStrip = wizard.AmountStrip
+			f.DebitCol, f.CreditCol = nil, nil
+			f.DateHeader, f.AmountHeader, f.DescHeader, f.DebitHeader, f.CreditHeader = "", "", "", "", ""
+			saved = *f
+		}
+		if err := saveFormats(profile, formats); err != nil {
+			return formatWizardSavedMsg{err: err}
+		}
+		return formatWizardSavedMsg{formats: formats, format: saved, path: path, fileName: filepath.Base(path)}
+	}
+}
@@ -0,0 +1,157 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+const testWizardCSV = "Posted;Narrative;Debit Amount;Balance\n" +
+	"2026-02-03;DAN MURPHYS SYDNEY;-20.00;100.00\n" +
+	"2026-02-04;PAYMENT RECEIVED THANK YOU;203.92;303.92\n"
+
+func writeWizardSample(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatalf("write sample: %v", err)
+	}
+	return path
+}
+
+func TestNewFormatWizardAutodetects(t *testing.T) {
+	path := writeWizardSample(t, "download (3).csv", testWizardCSV)
+	w, err := newFormatWizard(path, "")
+	if err != nil {
+		t.Fatalf("newFormatWizard: %v", err)
+	}
+	if w.delimiter != ";" || !w.hasHeader || w.colCount != 4 {
+		t.Fatalf("delimiter=%q header=%v cols=%d", w.delimiter, w.hasHeader, w.colCount)
+	}
+	if w.dateCol != 0 || w.amountCol != 2 || w.descCol != 1 || w.dateLayout != "2006-01-02" {
+		t.Fatalf("marks date=%d amount=%d desc=%d layout=%q", w.dateCol, w.amountCol, w.descCol, w.dateLayout)
+	}
+	if w.account.Value != "download" {
+		t.Fatalf("account guess = %q", w.account.Value)
+	}
+}
+
+func TestFormatWizardCycleRoleMovesMarks(t *testing.T) {
+	path := writeWizardSample(t, "anz.csv", "3/02/2026,-20.00,DAN MURPHYS\n4/02/2026,203.92,PAYMENT\n")
+	w, err := newFormatWizard(path, "ANZ")
+	if err != nil {
+		t.Fatalf("newFormatWizard: %v", err)
+	}
+	if w.hasHeader || w.dateLayout != "2/01/2006" {
+		t.Fatalf("header=%v layout=%q", w.hasHeader, w.dateLayout)
+	}
+
+	// Cycling column 2 (description) to none then date moves the date mark.
+	w.cursorCol = 2
+	w.cycleRole()
+	if w.descCol != -1 || w.roleOf(2) != wizardRoleNone {
+		t.Fatalf("desc=%d after cycling past last role", w.descCol)
+	}
+	w.cycleRole()
+	if w.dateCol != 2 || w.dateLayout != "" {
+		t.Fatalf("date=%d layout=%q; text column should not yield a layout", w.dateCol, w.dateLayout)
+	}
+	if _, err := w.buildFormat(); err == nil {
+		t.Fatal("expected validation error with unmarked description and no date layout")
+	}
+}
+
+func TestSaveFormatWizardCmdWritesConfigAndAccount(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	db, cleanup := testDB(t)
+	defer cleanup()
+
+	path := writeWizardSample(t, "westpac-feb.csv", testWizardCSV)
+	w, err := newFormatWizard(path, "Westpac")
+	if err != nil {
+		t.Fatalf("newFormatWizard: %v", err)
+	}
+	format, err := w.buildFormat()
+	if err != nil {
+		t.Fatalf("buildFormat: %v", err)
+	}
+	msg := saveFormatWizardCmd(db, "", w.account.Value, w.path, format)().(formatWizardSavedMsg)
+	if msg.err != nil {
+		t.Fatalf("save: %v", msg.err)
+	}
+	if msg.format.Account != "Westpac" || msg.format.Delimiter != ";" || msg.format.DateFormat != "2006-01-02" || msg.format.AmountCol != 2 {
+		t.Fatalf("saved format = %+v", msg.format)
+	}
+
+	formats, _, err := loadAppConfig("")
+	if err != nil {
+		t.Fatalf("loadAppConfig: %v", err)
+	}
+	found := findFormat(formats, "Westpac")
+	if found == nil || !found.HasHeader || found.DescCol != 1 {
+		t.Fatalf("config format = %+v", found)
+	}
+	acct, err := loadAccountByNameCI(db, "westpac")
+	if err != nil || acct == nil {
+		t.Fatalf("account not created: %v", err)
+	}
+}
+
+func TestFilePickerOpensFormatWizard(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "bank.csv"), []byte(testWizardCSV), 0o644); err != nil {
+		t.Fatalf("write csv: %v", err)
+	}
+	m := newModel("")
+	m.ready = true
+	m.basePath = dir
+	m.importPicking = true
+	m.importFiles = []string{"bank.csv"}
+
+	next, _ := m.Update(keyMsg("w"))
+	got := next.(model)
+	if got.importPicking || got.formatWizard == nil {
+		t.Fatalf("picking=%v wizard=%v, want wizard open", got.importPicking, got.formatWizard)
+	}
+	if view := renderFormatWizard(got.formatWizard, got.keys); !strings.Contains(view, "DATE") || !strings.Contains(view, "semicolon") {
+		t.Fatalf("wizard view missing marks or delimiter:\n%s", view)
+	}
+
+	next, _ = got.Update(keyMsg("esc"))
+	if next.(model).formatWizard != nil {
+		t.Fatal("esc should close the wizard")
+	}
+}
+
+func TestFormatWizardSaveRescansNestedInboxFile(t *testing.T) {
+	m, cleanup := newFlowModelWithDB(t)
+	defer cleanup()
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "westpac"), 0o755); err != nil {
+		t.Fatalf("mkdir: %v", err)
+	}
+	writeFlowCSV(t, dir, filepath.Join("westpac", "feb.csv"), testWizardCSV)
+	m.basePath = dir
+	m.importPicking = true
+	m.importFiles = []string{filepath.Join("westpac", "feb.csv")}
+	m.importWizardAcct = "Westpac"
+
+	m = flowPress(t, m, "w")
+	if m.formatWizard == nil {
+		t.Fatal("wizard did not open for nested inbox file")
+	}
+	m = flowApplyMsg(t, m, tea.KeyMsg{Type: tea.KeyEnter})
+	if m.formatWizard != nil {
+		t.Fatalf("wizard still open: %q", m.formatWizard.err)
+	}
+	snap := m.importPreviewSnapshot
+	if !m.importPreviewOpen || snap == nil {
+		t.Fatalf("preview not opened after save; status=%q", m.status)
+	}
+	if want := filepath.Join(dir, "westpac", "feb.csv"); snap.sourcePath != want || len(snap.rows) != 2 {
+		t.Fatalf("rescanned %q with %d rows, want %q with 2", snap.sourcePath, len(snap.rows), want)
+	}
+}
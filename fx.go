@@ -0,0 +1,347 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// resolveBaseCurrency returns the configured base currency or, when
+// base_currency is unset, the currency most accounts are in (ties go to the
+// alphabetically first code). Accounts without a currency don't count.
+func resolveBaseCurrency(configured string, accounts []account) string {
+	if configured != "" {
+		return configured
+	}
+	counts := make(map[string]int)
+	for _, a := range accounts {
+		if a.currency != "" {
+			counts[a.currency]++
+		}
+	}
+	best := ""
+	for currency, n := range counts {
+		if n > counts[best] || (n == counts[best] && currency < best) {
+			best = currency
+		}
+	}
+	return best
+}
+
+// reportingCurrency is the currency dashboards and budgets are shown in.
+func (m model) reportingCurrency() string {
+	return resolveBaseCurrency(m.baseCurrency, m.accounts)
+}
+
+// normalizeCurrencyCode upper-cases an ISO 4217 code, reporting false for
+// anything that isn't three letters. Empty stays empty (the base currency).
+func normalizeCurrencyCode(raw string) (string, bool) {
+	code := strings.ToUpper(strings.TrimSpace(raw))
+	if code == "" {
+		return "", true
+	}
+	return code, isCurrencyCode(code)
+}
+
+// fxRate is one row of the fx_rates table: on dateISO, one unit of currency
+// was worth rate units of base.
+type fxRate struct {
+	dateISO  string
+	base     string
+	currency string
+	rate     float64
+}
+
+type fxRatesLoadedMsg struct {
+	path    string
+	count   int
+	missing []string // foreign account currencies still without a rate
+	err     error
+}
+
+// parseFXRatesCSV reads a rates file with a header naming date, currency and
+// rate columns (any order, case-insensitive). An optional base column sets
+// the base per row; otherwise defaultBase is used.
+func parseFXRatesCSV(r io.Reader, defaultBase string) ([]fxRate, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+	header, err := reader.Read()
+	if err == io.EOF {
+		return nil, fmt.Errorf("rates file is empty")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read rates header: %w", err)
+	}
+	cols := map[string]int{"date": -1, "currency": -1, "rate": -1, "base": -1}
+	for i, name := range header {
+		key := strings.ToLower(strings.TrimSpace(name))
+		if _, ok := cols[key]; ok {
+			cols[key] = i
+		}
+	}
+	for _, required := range []string{"date", "currency", "rate"} {
+		if cols[required] < 0 {
+			return nil, fmt.Errorf("rates header is missing a %q column", required)
+		}
+	}
+	cell := func(rec []string, col string) string {
+		i := cols[col]
+		if i < 0 || i >= len(rec) {
+			return ""
+		}
+		return strings.TrimSpace(rec[i])
+	}
+
+	var out []fxRate
+	for line := 2; ; line++ {
+		rec, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("rates line %d: %w", line, err)
+		}
+		if len(rec) == 1 && strings.TrimSpace(rec[0]) == "" {
+			continue
+		}
+		date := cell(rec, "date")
+		if _, err := time.Parse("2006-01-02", date); err != nil {
+			return nil, fmt.Errorf("rates line %d: date %q is not YYYY-MM-DD", line, date)
+		}
+		currency, ok := normalizeCurrencyCode(cell(rec, "currency"))
+		if !ok || currency == "" {
+			return nil, fmt.Errorf("rates line %d: invalid currency %q", line, cell(rec, "currency"))
+		}
+		base, ok := normalizeCurrencyCode(cell(rec, "base"))
+		if !ok {
+			return nil, fmt.Errorf("rates line %d: invalid base %q", line, cell(rec, "base"))
+		}
+		if base == "" {
+			base = defaultBase
+		}
+		if base == "" {
+			return nil, fmt.Errorf("rates line %d: no base currency; add a base column or set base_currency", line)
+		}
+		rate, err := strconv.ParseFloat(cell(rec, "rate"), 64)
+		if err != nil || rate <= 0 {
+			return nil, fmt.Errorf("rates line %d: rate %q must be a positive number", line, cell(rec, "rate"))
+		}
+		out = append(out, fxRate{dateISO: date, base: base, currency: currency, rate: rate})
+	}
+	return out, nil
+}
+
+// upsertFXRates stores rates, replacing any existing rate for the same day
+// and currency pair.
+func upsertFXRates(db *sql.DB, rates []fxRate) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin fx rates: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+	for _, r := range rates {
+		if _, err := tx.Exec(`
+			INSERT INTO fx_rates (date_iso, base, currency, rate)
+			VALUES (?, ?, ?, ?)
+			ON CONFLICT(date_iso, base, currency) DO UPDATE SET rate = excluded.rate
+		`, r.dateISO, r.base, r.currency, r.rate); err != nil {
+			return fmt.Errorf("insert fx rate %s %s/%s: %w", r.dateISO, r.currency, r.base, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit fx rates: %w", err)
+	}
+	return nil
+}
+
+func loadFXRates(db *sql.DB) ([]fxRate, error) {
+	rows, err := db.Query(`SELECT date_iso, base, currency, rate FROM fx_rates ORDER BY date_iso`)
+	if err != nil {
+		return nil, fmt.Errorf("query fx rates: %w", err)
+	}
+	defer rows.Close()
+	var out []fxRate
+	for rows.Next() {
+		var r fxRate
+		if err := rows.Scan(&r.dateISO, &r.base, &r.currency, &r.rate); err != nil {
+			return nil, fmt.Errorf("scan fx rate: %w", err)
+		}
+		out = append(out, r)
+	}
+	return out, rows.Err()
+}
+
+// loadFXRatesFileCmd imports a rates CSV into fx_rates and reports which
+// foreign account currencies still have no rate to base. An empty base is
+// resolved from the accounts, as for dashboards.
+func loadFXRatesFileCmd(db *sql.DB, path, base string) tea.Cmd {
+	return func() tea.Msg {
+		accounts, err := loadAccounts(db)
+		if err != nil {
+			return fxRatesLoadedMsg{path: path, err: err}
+		}
+		base = resolveBaseCurrency(base, accounts)
+		f, err := os.Open(expandHomePath(path))
+		if err != nil {
+			return fxRatesLoadedMsg{path: path, err: fmt.Errorf("open rates file: %w", err)}
+		}
+		defer f.Close()
+		rates, err := parseFXRatesCSV(f, base)
+		if err != nil {
+			return fxRatesLoadedMsg{path: path, err: err}
+		}
+		if err := upsertFXRates(db, rates); err != nil {
+			return fxRatesLoadedMsg{path: path, err: err}
+		}
+		stored, err := loadFXRates(db)
+		if err != nil {
+			return fxRatesLoadedMsg{path: path, err: err}
+		}
+		return fxRatesLoadedMsg{
+			path:    path,
+			count:   len(rates),
+			missing: newFXConverter(base, accounts, stored).missingRates(),
+		}
+	}
+}
+
+// fxDayRate is a rate to base on one day.
+type fxDayRate struct {
+	dateISO string
+	rate    float64
+}
+
+// fxConverter turns amounts in an account's currency into the base currency.
+// A nil converter (no foreign-currency accounts) leaves amounts unchanged.
+type fxConverter struct {
+	base     string
+	accounts map[int]string         // account id -> currency, foreign accounts only
+	rates    map[string][]fxDayRate // currency -> rates to base, oldest first
+}
+
+// newFXConverter indexes rates to base, inverting rates stored the other way
+// round (base quoted in the foreign currency) when no direct rate exists for
+// that day. It returns nil when every account is in the base currency.
+func newFXConverter(base string, accounts []account, rates []fxRate) *fxConverter {
+	foreign := make(map[int]string)
+	for _, a := range accounts {
+		if a.currency != "" && a.currency != base {
+			foreign[a.id] = a.currency
+		}
+	}
+	if len(foreign) == 0 {
+		return nil
+	}
+	byDay := make(map[string]map[string]float64)
+	set := func(currency, date string, rate float64, direct bool) {
+		if byDay[currency] == nil {
+			byDay[currency] = make(map[string]float64)
+		}
+		if _, exists := byDay[currency][date]; exists && !direct {
+			return
+		}
+		byDay[currency][date] = rate
+	}
+	for _, r := range rates {
+		if r.base == base {
+			set(r.currency, r.dateISO, r.rate, true)
+		}
+	}
+	for _, r := range rates {
+		if r.currency == base && r.base != base {
+			set(r.base, r.dateISO, 1/r.rate, false)
+		}
+	}
+	c := &fxConverter{base: base, accounts: foreign, rates: make(map[string][]fxDayRate, len(byDay))}
+	for currency, days := range byDay {
+		list := make([]fxDayRate, 0, len(days))
+		for date, rate := range days {
+			list = append(list, fxDayRate{dateISO: date, rate: rate})
+		}
+		sort.Slice(list, func(i, j int) bool { return list[i].dateISO < list[j].dateISO })
+		c.rates[currency] = list
+	}
+	return c
+}
+
+// rateOn returns the latest rate on or before dateISO, falling back to the
+// earliest rate after it for transactions older than the rates file.
+func (c *fxConverter) rateOn(currency, dateISO string) (float64, bool) {
+	list := c.rates[currency]
+	if len(list) == 0 {
+		return 0, false
+	}
+	i := sort.Search(len(list), func(i int) bool { return list[i].dateISO > dateISO })
+	if i == 0 {
+		return list[0].rate, true
+	}
+	return list[i-1].rate, true
+}
+
+// toBase converts an amount booked in the given account on dateISO, rounded
+// to the cent. It reports false when the account's currency has no rate to
+// base; the amount is then unconverted and must not be added to base totals.
+func (c *fxConverter) toBase(amount money, accountID int, dateISO string) (money, bool) {
+	if c == nil {
+		return amount, true
+	}
+	currency, ok := c.accounts[accountID]
+	if !ok {
+		return amount, true
+	}
+	rate, ok := c.rateOn(currency, dateISO)
+	if !ok {
+		return amount, false
+	}
+	return moneyFromFloat(amount.float() * rate), true
+}
+
+// convertRows rewrites the amounts of rows, which the caller owns, into the
+// base currency and returns them. Rows with no rate to base are dropped so
+// they can't be summed as base amounts.
+func (c *fxConverter) convertRows(rows []transaction) []transaction {
+	if c == nil {
+		return rows
+	}
+	out := rows[:0]
+	for _, row := range rows {
+		if row.accountID != nil {
+			id := *row.accountID
+			amount, ok := c.toBase(row.amount, id, row.dateISO)
+			if !ok {
+				continue
+			}
+			row.amount = amount
+			row.fullAmount, _ = c.toBase(row.fullAmount, id, row.dateISO)
+		}
+		out = append(out, row)
+	}
+	return out
+}
+
+// missingRates lists the foreign account currencies with no rate to base.
+func (c *fxConverter) missingRates() []string {
+	if c == nil {
+		return nil
+	}
+	seen := make(map[string]bool)
+	var out []string
+	for _, currency := range c.accounts {
+		if seen[currency] || len(c.rates[currency]) > 0 {
+			continue
+		}
+		seen[currency] = true
+		out = append(out, currency)
+	}
+	sort.Strings(out)
+	return out
+}
@@ -0,0 +1,232 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseFXRatesCSV(t *testing.T) {
+	input := "Rate,Date,Currency,Base\n" +
+		"1.50,2026-01-02,usd,\n" +
+		"0.0100,2026-01-02,JPY,AUD\n" +
+		"\n" +
+		"0.60,2026-01-03,AUD,USD\n"
+	rates, err := parseFXRatesCSV(strings.NewReader(input), "AUD")
+	if err != nil {
+		t.Fatalf("parseFXRatesCSV: %v", err)
+	}
+	want := []fxRate{
+		{dateISO: "2026-01-02", base: "AUD", currency: "USD", rate: 1.5},
+		{dateISO: "2026-01-02", base: "AUD", currency: "JPY", rate: 0.01},
+		{dateISO: "2026-01-03", base: "USD", currency: "AUD", rate: 0.6},
+	}
+	if len(rates) != len(want) {
+		t.Fatalf("got %d rates, want %d: %+v", len(rates), len(want), rates)
+	}
+	for i := range want {
+		if rates[i] != want[i] {
+			t.Fatalf("rates[%d] = %+v, want %+v", i, rates[i], want[i])
+		}
+	}
+
+	bad := map[string]string{
+		"date,rate\n2026-01-02,1.5\n":               "currency",
+		"date,currency,rate\n02/01/2026,USD,1.5\n":  "YYYY-MM-DD",
+		"date,currency,rate\n2026-01-02,DOLLAR,1\n": "invalid currency",
+		"date,currency,rate\n2026-01-02,USD,-1\n":   "positive",
+		"date,currency,rate\n2026-01-02,USD,abc\n":  "positive",
+		"": "empty",
+	}
+	for in, wantErr := range bad {
+		if _, err := parseFXRatesCSV(strings.NewReader(in), "AUD"); err == nil || !strings.Contains(err.Error(), wantErr) {
+			t.Fatalf("parseFXRatesCSV(%q) err = %v, want containing %q", in, err, wantErr)
+		}
+	}
+}
+
+func TestFXConverterPicksRateByDate(t *testing.T) {
+	accounts := []account{
+		{id: 1, name: "Home"},
+		{id: 2, name: "US Card", currency: "USD"},
+		{id: 3, name: "Euro", currency: "EUR"},
+		{id: 4, name: "Home Explicit", currency: "AUD"},
+	}
+	rates := []fxRate{
+		{dateISO: "2026-01-10", base: "AUD", currency: "USD", rate: 1.5},
+		{dateISO: "2026-02-01", base: "AUD", currency: "USD", rate: 1.6},
+		{dateISO: "2026-01-10", base: "EUR", currency: "AUD", rate: 0.5}, // inverse: 1 EUR = 2 AUD
+	}
+	fx := newFXConverter("AUD", accounts, rates)
+	if fx == nil {
+		t.Fatal("expected a converter for foreign accounts")
+	}
+	cases := []struct {
+		amount  money
+		account int
+		date    string
+		want    money
+	}{
+		{-1000, 1, "2026-01-15", -1000},  // base account untouched
+		{-1000, 4, "2026-01-15", -1000},  // explicit base currency untouched
+		{-1000, 2, "2026-01-15", -1500},  // latest rate on or before
+		{-1000, 2, "2026-02-01", -1600},  // same-day rate
+		{-1000, 2, "2025-12-01", -1500},  // before the first rate: earliest rate
+		{-1000, 3, "2026-03-01", -2000},  // inverted rate
+		{-1000, 99, "2026-01-15", -1000}, // unknown account
+		{-1, 2, "2026-02-01", -2},        // rounded to the cent
+	}
+	for _, tc := range cases {
+		if got, ok := fx.toBase(tc.amount, tc.account, tc.date); !ok || got != tc.want {
+			t.Fatalf("toBase(%s, %d, %s) = %s, %v, want %s", tc.amount, tc.account, tc.date, got, ok, tc.want)
+		}
+	}
+	if missing := fx.missingRates(); len(missing) != 0 {
+		t.Fatalf("missingRates = %v, want none", missing)
+	}
+
+	noRates := newFXConverter("AUD", accounts, nil)
+	if _, ok := noRates.toBase(-1000, 2, "2026-01-15"); ok {
+		t.Fatal("toBase without rates reported a conversion")
+	}
+	if got, ok := noRates.toBase(-1000, 1, "2026-01-15"); !ok || got != -1000 {
+		t.Fatalf("toBase for base account without rates = %s, %v, want -10.00", got, ok)
+	}
+	home, travel := 1, 2
+	kept := noRates.convertRows([]transaction{
+		{id: 10, amount: -1000, accountID: &home, dateISO: "2026-01-15"},
+		{id: 11, amount: -1000, accountID: &travel, dateISO: "2026-01-15"},
+		{id: 12, amount: -500, dateISO: "2026-01-15"},
+	})
+	if len(kept) != 2 || kept[0].id != 10 || kept[1].id != 12 {
+		t.Fatalf("convertRows without rates kept %+v, want rows 10 and 12", kept)
+	}
+	if missing := noRates.missingRates(); strings.Join(missing, ",") != "EUR,USD" {
+		t.Fatalf("missingRates = %v, want [EUR USD]", missing)
+	}
+
+	if newFXConverter("AUD", accounts[:1], rates) != nil {
+		t.Fatal("expected nil converter when every account is in base currency")
+	}
+	var none *fxConverter
+	if got, ok := none.toBase(-1000, 2, "2026-01-15"); !ok || got != -1000 {
+		t.Fatalf("nil converter toBase = %s, %v, want -10.00", got, ok)
+	}
+}
+
+func TestResolveBaseCurrencyFallsBackToMostCommonAccountCurrency(t *testing.T) {
+	accounts := []account{
+		{id: 1, name: "Home"},
+		{id: 2, name: "Cheque", currency: "NZD"},
+		{id: 3, name: "Savings", currency: "NZD"},
+		{id: 4, name: "Travel", currency: "USD"},
+	}
+	if got := resolveBaseCurrency("GBP", accounts); got != "GBP" {
+		t.Fatalf("configured base = %q, want GBP", got)
+	}
+	if got := resolveBaseCurrency("", accounts); got != "NZD" {
+		t.Fatalf("inferred base = %q, want NZD", got)
+	}
+	if got := resolveBaseCurrency("", accounts[2:]); got != "NZD" {
+		t.Fatalf("tied base = %q, want alphabetically first NZD", got)
+	}
+	if got := resolveBaseCurrency("", accounts[:1]); got != "" {
+		t.Fatalf("base with no account currencies = %q, want empty", got)
+	}
+	if _, err := parseFXRatesCSV(strings.NewReader("date,currency,rate\n2026-01-02,USD,1.5\n"), ""); err == nil || !strings.Contains(err.Error(), "base_currency") {
+		t.Fatalf("rates without any base err = %v, want base_currency hint", err)
+	}
+}
+
+func TestComputeBudgetLinesConvertsForeignAccounts(t *testing.T) {
+	db, cleanup := testDB(t)
+	defer cleanup()
+
+	if err := syncAccountsFromFormats(db, []csvFormat{
+		{Name: "Home", Account: "Home", IsActive: true},
+		{Name: "Travel", Account: "Travel", IsActive: true, Currency: "USD"},
+	}); err != nil {
+		t.Fatalf("syncAccountsFromFormats: %v", err)
+	}
+	home, _ := loadAccountByNameCI(db, "Home")
+	travel, _ := loadAccountByNameCI(db, "Travel")
+	if home == nil || travel == nil || travel.currency != "USD" {
+		t.Fatalf("synced accounts = %+v / %+v, want Travel in USD", home, travel)
+	}
+	var groceries int
+	if err := db.QueryRow(`SELECT id FROM categories WHERE name = 'Groceries'`).Scan(&groceries); err != nil {
+		t.Fatalf("lookup groceries: %v", err)
+	}
+	if err := upsertCategoryBudget(db, groceries, 20000); err != nil {
+		t.Fatalf("upsertCategoryBudget: %v", err)
+	}
+	if _, err := db.Exec(`
+		INSERT INTO transactions (date_raw, date_iso, amount, description, category_id, account_id)
+		VALUES ('05/03/2026', '2026-03-05', -4000, 'LOCAL', ?, ?),
+		       ('06/03/2026', '2026-03-06', -2000, 'ABROAD', ?, ?)
+	`, groceries, home.id, groceries, travel.id); err != nil {
+		t.Fatalf("insert transactions: %v", err)
+	}
+	if err := upsertFXRates(db, []fxRate{{dateISO: "2026-03-01", base: "AUD", currency: "USD", rate: 1.5}}); err != nil {
+		t.Fatalf("upsertFXRates: %v", err)
+	}
+	rates, err := loadFXRates(db)
+	if err != nil {
+		t.Fatalf("loadFXRates: %v", err)
+	}
+	accounts, err := loadAccounts(db)
+	if err != nil {
+		t.Fatalf("loadAccounts: %v", err)
+	}
+	budgets, err := loadCategoryBudgets(db)
+	if err != nil {
+		t.Fatalf("loadCategoryBudgets: %v", err)
+	}
+
+	spentFor := func(fx *fxConverter) money {
+		t.Helper()
+		lines, err := computeBudgetLines(db, budgets, nil, "2026-03", nil, fx)
+		if err != nil {
+			t.Fatalf("computeBudgetLines: %v", err)
+		}
+		for _, line := range lines {
+			if line.categoryID == groceries {
+				return line.spent
+			}
+		}
+		t.Fatal("missing groceries line")
+		return 0
+	}
+	if got := spentFor(nil); got != 6000 {
+		t.Fatalf("unconverted spent = %s, want 60.00", got)
+	}
+	if got := spentFor(newFXConverter("AUD", accounts, rates)); got != 7000 {
+		t.Fatalf("converted spent = %s, want 40 + 20*1.5 = 70.00", got)
+	}
+	if got := spentFor(newFXConverter("AUD", accounts, nil)); got != 4000 {
+		t.Fatalf("spent without rates = %s, want only the local 40.00", got)
+	}
+}
+
+func TestCSVOriginalAmountTakesAccountSign(t *testing.T) {
+	amountCol, curCol := 3, 4
+	format := csvFormat{OrigAmountCol: &amountCol, OrigCurrencyCol: &curCol}
+	rec := []string{"05/03/2026", "-30.00", "HOTEL", "20.00", "usd"}
+	v, currency, err := csvOriginalAmount(rec, format, -3000)
+	if err != nil {
+		t.Fatalf("csvOriginalAmount: %v", err)
+	}
+	if v == nil || *v != -2000 || currency != "USD" {
+		t.Fatalf("got (%v, %q), want (-20, USD)", v, currency)
+	}
+
+	format.OrigCurrencyCol = nil
+	rec[3] = "20.00 EUR"
+	if v, currency, err = csvOriginalAmount(rec, format, -3000); err != nil || v == nil || *v != -2000 || currency != "EUR" {
+		t.Fatalf("inline code: got (%v, %q, %v), want (-20, EUR)", v, currency, err)
+	}
+
+	rec[3] = ""
+	if v, _, err = csvOriginalAmount(rec, format, -3000); err != nil || v != nil {
+		t.Fatalf("blank cell: got (%v, %v), want nil", v, err)
+	}
+}
@@ -0,0 +1,268 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Change sources recorded in transaction_history.
+const (
+	changeManual = "manual"
+	changeRule   = "rule"
+	changeImport = "import"
+	changeUndo   = "undo"
+	changeRedo   = "redo"
+)
+
+// changeSource says who made a change: a person, a rule, an import, or
+// undo/redo replaying the journal. id is the rule or import id.
+type changeSource struct {
+	kind string
+	id   int
+}
+
+var manualChange = changeSource{kind: changeManual}
+
+// sourceResolver attributes one field change to its source. Rule runs use
+// it to credit each category and tag change to the rule that made it.
+type sourceResolver func(c txnChange) changeSource
+
+func fixedSource(src changeSource) sourceResolver {
+	return func(txnChange) changeSource { return src }
+}
+
+// txnChange is one field of one transaction changing, as stored in
+// transaction_history. Values are display text (category and tag names,
+// allocation summaries) so history reads the same after renames.
+type txnChange struct {
+	id        int
+	txnID     int
+	field     string // category, notes, tag, allocation
+	oldValue  string
+	newValue  string
+	source    changeSource
+	changedAt string
+
+	refID int // tag or allocation id, used to attribute the change
+}
+
+// ledgerNames resolves category and tag ids to names for history values.
+type ledgerNames struct {
+	categories map[int]string
+	tags       map[int]string
+}
+
+func loadLedgerNamesTx(tx *sql.Tx) (ledgerNames, error) {
+	names := ledgerNames{categories: make(map[int]string), tags: make(map[int]string)}
+	for _, q := range []struct {
+		query string
+		into  map[int]string
+	}{
+		{`SELECT id, name FROM categories`, names.categories},
+		{`SELECT id, name FROM tags`, names.tags},
+	} {
+		rows, err := tx.Query(q.query)
+		if err != nil {
+			return names, fmt.Errorf("load history names: %w", err)
+		}
+		for rows.Next() {
+			var id int
+			var name string
+			if err := rows.Scan(&id, &name); err != nil {
+				rows.Close()
+				return names, fmt.Errorf("scan history name: %w", err)
+			}
+			q.into[id] = name
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return names, err
+		}
+	}
+	return names, nil
+}
+
+func (n ledgerNames) category(id *int) string {
+	if id == nil {
+		return ""
+	}
+	if name, ok := n.categories[*id]; ok {
+		return name
+	}
+	return fmt.Sprintf("Category %d", *id)
+}
+
+func (n ledgerNames) tag(id int) string {
+	if name, ok := n.tags[id]; ok {
+		return name
+	}
+	return fmt.Sprintf("Tag %d", id)
+}
+
+// allocation summarises an allocation as "-5.00 Groceries [TRIP] share".
+func (n ledgerNames) allocation(a ledgerAllocState) string {
+	parts := []string{a.Amount.String()}
+	if cat := n.category(a.CategoryID); cat != "" {
+		parts = append(parts, cat)
+	}
+	if len(a.TagIDs) > 0 {
+		tags := make([]string, len(a.TagIDs))
+		for i, id := range a.TagIDs {
+			tags[i] = n.tag(id)
+		}
+		parts = append(parts, "["+strings.Join(tags, " ")+"]")
+	}
+	if note := strings.TrimSpace(a.Note); note != "" {
+		parts = append(parts, note)
+	}
+	return strings.Join(parts, " ")
+}
+
+// diffLedgerState lists the field changes between two states of one
+// transaction. Edit timestamps are not history.
+func diffLedgerState(before, after ledgerTxnState, names ledgerNames) []txnChange {
+	var out []txnChange
+	add := func(field, oldValue, newValue string, refID int) {
+		out = append(out, txnChange{txnID: after.ID, field: field, oldValue: oldValue, newValue: newValue, refID: refID})
+	}
+	if !intPtrEqual(before.CategoryID, after.CategoryID) {
+		add("category", names.category(before.CategoryID), names.category(after.CategoryID), 0)
+	}
+	if before.Notes != after.Notes {
+		add("notes", before.Notes, after.Notes, 0)
+	}
+	oldTags, newTags := make(map[int]bool), make(map[int]bool)
+	for _, id := range before.TagIDs {
+		oldTags[id] = true
+	}
+	for _, id := range after.TagIDs {
+		newTags[id] = true
+	}
+	added, removed := diffTagSets(oldTags, newTags)
+	for _, id := range added {
+		add("tag", "", names.tag(id), id)
+	}
+	for _, id := range removed {
+		add("tag", names.tag(id), "", id)
+	}
+
+	oldAllocs := make(map[int]ledgerAllocState, len(before.Allocations))
+	for _, a := range before.Allocations {
+		oldAllocs[a.ID] = a
+	}
+	seen := make(map[int]bool, len(after.Allocations))
+	for _, a := range after.Allocations {
+		seen[a.ID] = true
+		prev, ok := oldAllocs[a.ID]
+		switch {
+		case !ok:
+			add("allocation", "", names.allocation(a), a.ID)
+		case names.allocation(prev) != names.allocation(a):
+			add("allocation", names.allocation(prev), names.allocation(a), a.ID)
+		}
+	}
+	for _, a := range before.Allocations {
+		if !seen[a.ID] {
+			add("allocation", names.allocation(a), "", a.ID)
+		}
+	}
+	return out
+}
+
+// recordLedgerHistoryTx writes a history row for every field that changed
+// between the before and after states. Transactions missing from before
+// are treated as new, so their initial state is recorded.
+func recordLedgerHistoryTx(tx *sql.Tx, before, after map[int]*ledgerTxnState, source sourceResolver) error {
+	ids := make([]int, 0, len(after))
+	for id := range after {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	var names *ledgerNames
+	for _, id := range ids {
+		prev := ledgerTxnState{ID: id}
+		if b := before[id]; b != nil {
+			if b.sameContent(*after[id]) {
+				continue
+			}
+			prev = *b
+		}
+		if names == nil {
+			loaded, err := loadLedgerNamesTx(tx)
+			if err != nil {
+				return err
+			}
+			names = &loaded
+		}
+		for _, c := range diffLedgerState(prev, *after[id], *names) {
+			src := source(c)
+			var sourceID *int
+			if src.id > 0 {
+				sourceID = &src.id
+			}
+			if _, err := tx.Exec(`
+				INSERT INTO transaction_history (txn_id, field, old_value, new_value, source, source_id)
+				VALUES (?, ?, ?, ?, ?, ?)
+			`, c.txnID, c.field, c.oldValue, c.newValue, src.kind, sourceID); err != nil {
+				return fmt.Errorf("record txn %d %s history: %w", c.txnID, c.field, err)
+			}
+		}
+	}
+	return nil
+}
+
+// recordImportHistoryTx records the state an import gave its new
+// transactions: categories, tags and allocations picked in the preview.
+func recordImportHistoryTx(tx *sql.Tx, importID int, txnIDs []int) error {
+	after, err := captureLedgerStateTx(tx, normalizeIDList(txnIDs))
+	if err != nil {
+		return err
+	}
+	return recordLedgerHistoryTx(tx, nil, after, fixedSource(changeSource{kind: changeImport, id: importID}))
+}
+
+// loadTransactionHistory returns a transaction's changes, newest first.
+func loadTransactionHistory(db *sql.DB, txnID int) ([]txnChange, error) {
+	rows, err := db.Query(`
+		SELECT id, txn_id, field, old_value, new_value, source, source_id, changed_at
+		FROM transaction_history
+		WHERE txn_id = ?
+		ORDER BY id DESC
+	`, txnID)
+	if err != nil {
+		return nil, fmt.Errorf("query transaction history: %w", err)
+	}
+	defer rows.Close()
+
+	var out []txnChange
+	for rows.Next() {
+		var c txnChange
+		var sourceID sql.NullInt64
+		if err := rows.Scan(&c.id, &c.txnID, &c.field, &c.oldValue, &c.newValue, &c.source.kind, &sourceID, &c.changedAt); err != nil {
+			return nil, fmt.Errorf("scan transaction history: %w", err)
+		}
+		c.source.id = int(sourceID.Int64)
+		out = append(out, c)
+	}
+	return out, rows.Err()
+}
+
+type txnHistoryMsg struct {
+	txnID   int
+	changes []txnChange
+	err     error
+}
+
+func loadTxnHistoryCmd(db *sql.DB, txnID int) tea.Cmd {
+	if db == nil || txnID <= 0 {
+		return nil
+	}
+	return func() tea.Msg {
+		changes, err := loadTransactionHistory(db, txnID)
+		return txnHistoryMsg{txnID: txnID, changes: changes, err: err}
+	}
+}
@@ -0,0 +1,140 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func TestHistoryRecordsManualCategoryAndNotes(t *testing.T) {
+	db, cleanup := testDB(t)
+	defer cleanup()
+	ids := seedJournalTxns(t, db)
+	groceries := 2
+	if err := updateTransactionDetail(db, ids[0], &groceries, "weekly shop"); err != nil {
+		t.Fatalf("updateTransactionDetail: %v", err)
+	}
+
+	changes, err := loadTransactionHistory(db, ids[0])
+	if err != nil {
+		t.Fatalf("loadTransactionHistory: %v", err)
+	}
+	if len(changes) != 2 {
+		t.Fatalf("history = %+v, want category and notes", changes)
+	}
+	byField := make(map[string]txnChange)
+	for _, c := range changes {
+		byField[c.field] = c
+	}
+	if c := byField["category"]; c.oldValue != "" || c.newValue != "Groceries" || c.source.kind != changeManual {
+		t.Fatalf("category change = %+v, want manual → Groceries", c)
+	}
+	if c := byField["notes"]; c.newValue != "weekly shop" || c.changedAt == "" {
+		t.Fatalf("notes change = %+v, want weekly shop with a timestamp", c)
+	}
+	if other, _ := loadTransactionHistory(db, ids[1]); len(other) != 0 {
+		t.Fatalf("untouched txn history = %+v, want none", other)
+	}
+}
+
+func TestHistoryCreditsRuleForCategoryAndTags(t *testing.T) {
+	db, cleanup := testDB(t)
+	defer cleanup()
+	ids := seedJournalTxns(t, db)
+	tagID, err := insertTag(db, "food", "", nil)
+	if err != nil {
+		t.Fatalf("insertTag: %v", err)
+	}
+	groceries := 2
+	ruleID, err := insertRuleV2(db, ruleV2{name: "Supermarkets", savedFilterID: "super", setCategoryID: &groceries, addTagIDs: []int{tagID}, enabled: true})
+	if err != nil {
+		t.Fatalf("insertRuleV2: %v", err)
+	}
+	rules, err := loadRulesV2(db)
+	if err != nil {
+		t.Fatalf("loadRulesV2: %v", err)
+	}
+	filters := []savedFilter{{ID: "super", Name: "Supermarkets", Expr: `desc:woolworths`}}
+	if _, _, _, _, err := applyRulesV2ToScope(db, rules, loadTransactionTagsOrEmpty(db), nil, filters); err != nil {
+		t.Fatalf("applyRulesV2ToScope: %v", err)
+	}
+
+	changes, err := loadTransactionHistory(db, ids[0])
+	if err != nil {
+		t.Fatalf("loadTransactionHistory: %v", err)
+	}
+	if len(changes) != 2 {
+		t.Fatalf("history = %+v, want category and tag", changes)
+	}
+	for _, c := range changes {
+		if c.source.kind != changeRule || c.source.id != ruleID {
+			t.Fatalf("%s change source = %+v, want rule %d", c.field, c.source, ruleID)
+		}
+	}
+
+	// Undoing the run is recorded as an undo, not as the rule.
+	if _, err := undoLedgerEdit(db); err != nil {
+		t.Fatalf("undoLedgerEdit: %v", err)
+	}
+	changes, _ = loadTransactionHistory(db, ids[0])
+	if len(changes) != 4 || changes[0].source.kind != changeUndo {
+		t.Fatalf("history after undo = %+v, want undo entries on top", changes)
+	}
+}
+
+func TestHistoryRecordsImportAndAllocations(t *testing.T) {
+	db, cleanup := testDB(t)
+	defer cleanup()
+	ids := seedJournalTxns(t, db)
+	if _, err := db.Exec(`UPDATE transactions SET category_id = 2 WHERE id = ?`, ids[1]); err != nil {
+		t.Fatalf("set category: %v", err)
+	}
+	importID, err := insertImportRecord(db, "feb.csv", 2)
+	if err != nil {
+		t.Fatalf("insertImportRecord: %v", err)
+	}
+	if err := linkTransactionsToImport(db, importID, ids[:2]); err != nil {
+		t.Fatalf("linkTransactionsToImport: %v", err)
+	}
+	changes, _ := loadTransactionHistory(db, ids[1])
+	if len(changes) != 1 || changes[0].source != (changeSource{kind: changeImport, id: importID}) || changes[0].newValue != "Groceries" {
+		t.Fatalf("imported history = %+v, want Groceries from import %d", changes, importID)
+	}
+
+	if _, err := insertTransactionAllocation(db, ids[1], 500, nil, "share", nil); err != nil {
+		t.Fatalf("insertTransactionAllocation: %v", err)
+	}
+	changes, _ = loadTransactionHistory(db, ids[1])
+	if len(changes) != 2 || changes[0].field != "allocation" || changes[0].newValue != "-5.00 share" {
+		t.Fatalf("history after allocation = %+v, want allocation -5.00 share on top", changes)
+	}
+
+	if _, err := deleteImport(db, importID); err != nil {
+		t.Fatalf("deleteImport: %v", err)
+	}
+	if changes, _ := loadTransactionHistory(db, ids[1]); len(changes) != 0 {
+		t.Fatalf("history after rollback = %+v, want none", changes)
+	}
+}
+
+func TestDetailModalShowsHistory(t *testing.T) {
+	m, cleanup := testPhase5Model(t)
+	defer cleanup()
+
+	targetID := m.getFilteredRows()[m.cursor].id
+	groceries := 2
+	if err := updateTransactionCategory(m.db, targetID, &groceries); err != nil {
+		t.Fatalf("updateTransactionCategory: %v", err)
+	}
+
+	next, cmd := m.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	got := runCmdUpdate(t, next.(model), cmd)
+	if !got.showDetail || len(got.detailHistory) != 1 {
+		t.Fatalf("detail open=%v history=%+v, want one change", got.showDetail, got.detailHistory)
+	}
+	out := renderDetailWithAllocations(got, got.keys)
+	if !strings.Contains(out, "History") || !strings.Contains(out, "manual") || !strings.Contains(out, "category: + Groceries") {
+		t.Fatalf("detail modal missing history:\n%s", out)
+	}
+}
@@ -0,0 +1,287 @@
+package main
+
+import (
+	"database/sql"
+	"flag"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+)
+
+// importCLIOptions are the flags of `jaskmoney import`.
+type importCLIOptions struct {
+	account   string // CSV rows go to this account
+	format    string // CSV format name, bypassing detection
+	skipDupes bool
+	noRules   bool
+	dryRun    bool
+	db        string // database file, overriding the profile's
+	profile   string // named ledger profile whose config and database to use
+	// quarantine imports a file's good rows and saves rows that fail to
+	// parse to import_rejects instead of skipping the whole file.
+	quarantine bool
+	// force imports files that were already imported, or whose date range
+	// an earlier import of the account covers.
+	force bool
+}
+
+const importUsage = `usage: jaskmoney import <file...> [--account NAME] [--format NAME] [--skip-dupes] [--no-rules] [--quarantine] [--force] [--dry-run] [--profile NAME] [--db PATH]`
+
+// parseImportArgs parses `import` arguments, allowing flags before, between
+// or after the file names.
+func parseImportArgs(args []string, stderr io.Writer) (importCLIOptions, []string, error) {
+	var opts importCLIOptions
+	fs := flag.NewFlagSet("import", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintln(stderr, importUsage)
+		fs.PrintDefaults()
+	}
+	fs.StringVar(&opts.account, "account", "", "import CSV rows into this account (OFX/QIF files must already map to it)")
+	fs.StringVar(&opts.format, "format", "", "use this CSV format instead of detecting one")
+	fs.BoolVar(&opts.skipDupes, "skip-dupes", false, "skip exact and likely duplicates")
+	fs.BoolVar(&opts.noRules, "no-rules", false, "don't apply categorisation rules to imported rows")
+	fs.BoolVar(&opts.quarantine, "quarantine", false, "import the good rows and quarantine rows that fail to parse for review")
+	fs.BoolVar(&opts.force, "force", false, "import files already imported or covered by an earlier import")
+	fs.BoolVar(&opts.dryRun, "dry-run", false, "scan and report without writing to the database")
+	fs.StringVar(&opts.profile, "profile", "", "use this ledger profile's config and database")
+	fs.StringVar(&opts.db, "db", "", "import into this database file")
+
+	var files []string
+	for {
+		if err := fs.Parse(args); err != nil {
+			return opts, nil, err
+		}
+		args = fs.Args()
+		if len(args) == 0 {
+			break
+		}
+		files = append(files, args[0])
+		args = args[1:]
+	}
+	if len(files) == 0 {
+		fs.Usage()
+		return opts, nil, fmt.Errorf("no files given")
+	}
+	return opts, files, nil
+}
+
+// runImportCommand is the `jaskmoney import` entry point. It returns the
+// process exit code: 0 on success, 1 when any file failed or had parse
+// errors, 2 for usage and setup errors. An empty dbPath uses --db or the
+// ledger's configured database.
+func runImportCommand(args []string, dbPath string, stdout, stderr io.Writer) int {
+	opts, files, err := parseImportArgs(args, stderr)
+	if err != nil {
+		if err != flag.ErrHelp {
+			fmt.Fprintln(stderr, "import:", err)
+		}
+		return 2
+	}
+	profile := ""
+	if opts.profile != "" {
+		profile, err = normalizeProfileName(opts.profile)
+		if err != nil {
+			fmt.Fprintln(stderr, "import:", err)
+			return 2
+		}
+	}
+	formats, settings, savedFilters, _, warnings, err := loadAppConfigExtended(profile)
+	if err != nil {
+		fmt.Fprintln(stderr, "import: load config:", err)
+		return 2
+	}
+	for _, warning := range warnings {
+		fmt.Fprintf(stderr, "Config warning: %s\n", warning)
+	}
+	if opts.db != "" || dbPath == "" {
+		dbPath, err = resolveDatabasePath(profile, opts.db, settings)
+		if err != nil {
+			fmt.Fprintln(stderr, "import:", err)
+			return 2
+		}
+	}
+	// A dry run must not create, migrate or sync the database it reports on.
+	open := openDB
+	if opts.dryRun {
+		open = openDBReadOnly
+	}
+	db, err := open(dbPath)
+	if err != nil {
+		fmt.Fprintln(stderr, "import: open database:", err)
+		return 2
+	}
+	defer db.Close()
+	if !opts.dryRun {
+		if err := syncAccountsFromFormats(db, formats); err != nil {
+			fmt.Fprintln(stderr, "import: sync accounts:", err)
+			return 2
+		}
+	}
+	merchants, err := newMerchantNormalizer(settings.MerchantRules)
+	if err != nil {
+		fmt.Fprintln(stderr, "import:", err)
+		return 2
+	}
+	imp := headlessImporter{
+		db:           db,
+		formats:      formats,
+		savedFilters: savedFilters,
+		match:        settings.dupeMatch(),
+		merchants:    merchants,
+		out:          stdout,
+	}
+	if err := imp.run(opts, files); err != nil {
+		fmt.Fprintln(stderr, "import:", err)
+		return 1
+	}
+	return 0
+}
+
+// headlessImporter drives the same scan and import steps as the TUI import
+// preview, printing a summary per file instead of opening the preview.
+type headlessImporter struct {
+	db           *sql.DB
+	formats      []csvFormat
+	savedFilters []savedFilter
+	match        dupeMatchSettings
+	merchants    *merchantNormalizer
+	out          io.Writer
+}
+
+// run imports each file in turn. One file failing doesn't stop the rest;
+// the returned error counts the failures.
+func (h headlessImporter) run(opts importCLIOptions, files []string) error {
+	accounts, err := loadAccounts(h.db)
+	if err != nil {
+		return err
+	}
+	accountNames := make(map[int]string, len(accounts))
+	for _, a := range accounts {
+		accountNames[a.id] = a.name
+	}
+	failed := 0
+	for _, path := range files {
+		if err := h.importFile(opts, path, accountNames); err != nil {
+			fmt.Fprintf(h.out, "%s: %v\n", filepath.Base(path), err)
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d files failed", failed, len(files))
+	}
+	return nil
+}
+
+func (h headlessImporter) importFile(opts importCLIOptions, path string, accountNames map[int]string) error {
+	snapshot, err := h.scan(opts, path)
+	if err != nil {
+		return err
+	}
+	snapshot.setMerchants(h.merchants)
+	name := filepath.Base(path)
+	account := accountNames[snapshot.accountID]
+	if opts.account != "" && !strings.EqualFold(account, opts.account) {
+		return fmt.Errorf("file maps to account %q, not %q", account, opts.account)
+	}
+
+	fmt.Fprintf(h.out, "%s -> %s: %d rows, %d new, %d duplicates, %d likely duplicates, %d errors\n",
+		name, account, snapshot.totalRows, snapshot.newCount, snapshot.dupeCount, snapshot.likelyDupeCount, snapshot.errorCount)
+	for _, prev := range snapshot.sameFileImports {
+		fmt.Fprintf(h.out, "  warning: same file already imported as %s on %s\n", prev.filename, prev.importedAt)
+	}
+	for _, prev := range snapshot.coveringImports {
+		fmt.Fprintf(h.out, "  warning: %s already covered %s\n", prev.filename, importDateRangeLabel(prev.dateFrom, prev.dateTo))
+	}
+	snapshot.reimportConfirmed = opts.force
+	if snapshot.needsReimportConfirm() {
+		return fmt.Errorf("not imported: already imported; pass --force to import anyway")
+	}
+	if snapshot.pendingSettleCount > 0 {
+		fmt.Fprintf(h.out, "  settles %d pending transactions\n", snapshot.pendingSettleCount)
+	}
+	if len(snapshot.parseErrors) > 0 {
+		for _, pe := range snapshot.parseErrors {
+			fmt.Fprintf(h.out, "  line %d: %s: %s\n", pe.sourceLine, pe.field, pe.message)
+		}
+		if !opts.quarantine || !snapshot.canQuarantine() {
+			return fmt.Errorf("not imported: %d parse errors", len(snapshot.parseErrors))
+		}
+		snapshot.quarantine = true
+	}
+	if opts.dryRun {
+		fmt.Fprintln(h.out, "  dry run: nothing imported")
+		return nil
+	}
+
+	done, ok := ingestSnapshotCmd(h.db, snapshot, opts.skipDupes, !opts.noRules)().(ingestDoneMsg)
+	if !ok {
+		return fmt.Errorf("unexpected import result")
+	}
+	if done.err != nil {
+		return done.err
+	}
+	fmt.Fprintf(h.out, "  imported %d, skipped %d duplicates\n", done.count, done.dupes)
+	if done.quarantined > 0 {
+		fmt.Fprintf(h.out, "  quarantined %d rows for review\n", done.quarantined)
+	}
+	if done.rulesApplied {
+		fmt.Fprintf(h.out, "  rules: %d transactions updated, %d category changes, %d tag changes\n",
+			done.rulesTxnUpdated, done.rulesCatChanges, done.rulesTagChanges)
+	}
+	return nil
+}
+
+// scan builds the preview snapshot for one file, honouring --format and
+// --account for CSVs.
+func (h headlessImporter) scan(opts importCLIOptions, path string) (*importPreviewSnapshot, error) {
+	base := filepath.Base(path)
+	if isOFXFileName(base) || isQIFFileName(base) {
+		if opts.format != "" {
+			return nil, fmt.Errorf("--format only applies to CSV files")
+		}
+		msg, ok := scanDupesCmd(h.db, path, "", h.formats, h.savedFilters, h.match)().(importPreviewMsg)
+		if !ok || (msg.err == nil && msg.snapshot == nil) {
+			return nil, fmt.Errorf("missing import preview snapshot")
+		}
+		return msg.snapshot, msg.err
+	}
+
+	var format *csvFormat
+	switch {
+	case opts.format != "":
+		for i := range h.formats {
+			if strings.EqualFold(h.formats[i].Name, opts.format) {
+				format = &h.formats[i]
+				break
+			}
+		}
+		if format == nil {
+			return nil, fmt.Errorf("no format named %q", opts.format)
+		}
+	case opts.account != "":
+		var candidates []csvFormat
+		for _, f := range h.formats {
+			if strings.EqualFold(f.Account, opts.account) {
+				candidates = append(candidates, f)
+			}
+		}
+		if len(candidates) == 0 {
+			return nil, fmt.Errorf("no format imports into account %q; pass --format", opts.account)
+		}
+		if format = detectFormatForFile(candidates, path, base); format == nil {
+			format = &candidates[0]
+		}
+	default:
+		if format = detectFormatForFile(h.formats, path, base); format == nil {
+			return nil, fmt.Errorf("no matching format for %q", base)
+		}
+	}
+	chosen := *format
+	if opts.account != "" {
+		chosen.Account = opts.account
+	}
+	msg := withImportSource(h.db, scanCSVWithFormat(h.db, path, base, chosen, h.savedFilters, h.match), path)
+	return msg.snapshot, msg.err
+}
@@ -0,0 +1,135 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseImportArgsAllowsInterspersedFlags(t *testing.T) {
+	opts, files, err := parseImportArgs([]string{"a.csv", "--dry-run", "b.ofx", "--account", "ANZ", "--force"}, io.Discard)
+	if err != nil {
+		t.Fatalf("parseImportArgs: %v", err)
+	}
+	if !opts.dryRun || !opts.force || opts.account != "ANZ" || strings.Join(files, ",") != "a.csv,b.ofx" {
+		t.Fatalf("opts=%+v files=%v", opts, files)
+	}
+	if _, _, err := parseImportArgs([]string{"--skip-dupes"}, io.Discard); err == nil {
+		t.Fatal("expected error without files")
+	}
+}
+
+func TestHeadlessImportDryRunThenImport(t *testing.T) {
+	db, cleanup := testDB(t)
+	defer cleanup()
+
+	format := testANZFormat()
+	format.Account = "ANZ"
+	format.ImportPrefix = "anz"
+	if err := syncAccountsFromFormats(db, []csvFormat{format}); err != nil {
+		t.Fatalf("syncAccountsFromFormats: %v", err)
+	}
+	dir := t.TempDir()
+	good := filepath.Join(dir, "anz-feb.csv")
+	bad := filepath.Join(dir, "anz-bad.csv")
+	if err := os.WriteFile(good, []byte("3/02/2026,-20.00,VISA PURCHASE 1234 WOOLWORTHS 3021 SYDNEY AU\n4/02/2026,203.92,PAYMENT RECEIVED\n"), 0o644); err != nil {
+		t.Fatalf("write csv: %v", err)
+	}
+	if err := os.WriteFile(bad, []byte("3/02/2026,abc,BROKEN\n"), 0o644); err != nil {
+		t.Fatalf("write csv: %v", err)
+	}
+	merchants, err := newMerchantNormalizer(defaultMerchantRules())
+	if err != nil {
+		t.Fatalf("newMerchantNormalizer: %v", err)
+	}
+	var out bytes.Buffer
+	imp := headlessImporter{
+		db:        db,
+		formats:   []csvFormat{format},
+		match:     defaultSettings().dupeMatch(),
+		merchants: merchants,
+		out:       &out,
+	}
+	count := func() int {
+		t.Helper()
+		var n int
+		if err := db.QueryRow(`SELECT COUNT(*) FROM transactions`).Scan(&n); err != nil {
+			t.Fatalf("count: %v", err)
+		}
+		return n
+	}
+
+	if err := imp.run(importCLIOptions{dryRun: true}, []string{good}); err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	if count() != 0 || !strings.Contains(out.String(), "anz-feb.csv -> ANZ: 2 rows, 2 new") {
+		t.Fatalf("dry run wrote rows or bad summary:\n%s", out.String())
+	}
+
+	out.Reset()
+	err = imp.run(importCLIOptions{skipDupes: true}, []string{good, bad})
+	if err == nil || !strings.Contains(err.Error(), "1 of 2 files failed") {
+		t.Fatalf("err = %v, want one failed file", err)
+	}
+	if count() != 2 || !strings.Contains(out.String(), "imported 2, skipped 0 duplicates") || !strings.Contains(out.String(), "parse errors") {
+		t.Fatalf("import summary:\n%s", out.String())
+	}
+	var merchant string
+	if err := db.QueryRow(`SELECT merchant FROM transactions WHERE amount = -2000`).Scan(&merchant); err != nil || merchant != "WOOLWORTHS" {
+		t.Fatalf("merchant = %q (%v), want WOOLWORTHS", merchant, err)
+	}
+
+	out.Reset()
+	if err := imp.run(importCLIOptions{skipDupes: true}, []string{good}); err == nil || !strings.Contains(out.String(), "pass --force") {
+		t.Fatalf("re-import without --force: err=%v\n%s", err, out.String())
+	}
+	out.Reset()
+	if err := imp.run(importCLIOptions{skipDupes: true, force: true}, []string{good}); err != nil {
+		t.Fatalf("re-import: %v", err)
+	}
+	if count() != 2 || !strings.Contains(out.String(), "imported 0, skipped 2 duplicates") {
+		t.Fatalf("re-import summary:\n%s", out.String())
+	}
+
+	if err := imp.run(importCLIOptions{account: "Nope"}, []string{good}); err == nil {
+		t.Fatal("expected error for an account without a format")
+	}
+}
+
+func TestImportCommandDryRunDoesNotCreateDatabase(t *testing.T) {
+	useTestConfigHome(t)
+	dir := t.TempDir()
+	csvPath := filepath.Join(dir, "anz-feb.csv")
+	if err := os.WriteFile(csvPath, []byte("3/02/2026,-20.00,COFFEE\n"), 0o644); err != nil {
+		t.Fatalf("write csv: %v", err)
+	}
+	dbPath := filepath.Join(dir, "ledger.db")
+	var stderr bytes.Buffer
+	if code := runImportCommand([]string{csvPath, "--dry-run"}, dbPath, io.Discard, &stderr); code != 2 {
+		t.Fatalf("dry run on a missing database exit %d, want 2", code)
+	}
+	if !strings.Contains(stderr.String(), "does not exist") || fileExists(dbPath) {
+		t.Fatalf("dry run created the database or gave no reason: %s", stderr.String())
+	}
+
+	defer os.RemoveAll(backupDir(dbPath))
+	db, err := openDB(dbPath)
+	if err != nil {
+		t.Fatalf("openDB: %v", err)
+	}
+	if err := syncAccountsFromFormats(db, []csvFormat{testANZFormat()}); err != nil {
+		t.Fatalf("syncAccountsFromFormats: %v", err)
+	}
+	db.Close()
+	var stdout bytes.Buffer
+	stderr.Reset()
+	if code := runImportCommand([]string{csvPath, "--dry-run"}, dbPath, &stdout, &stderr); code != 0 {
+		t.Fatalf("dry run exit %d: %s%s", code, stdout.String(), stderr.String())
+	}
+	if !strings.Contains(stdout.String(), "dry run: nothing imported") {
+		t.Fatalf("dry run output:\n%s", stdout.String())
+	}
+}
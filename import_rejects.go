@@ -0,0 +1,278 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// importReject is a quarantined row that failed to parse during an import.
+// It stays in import_rejects until it is fixed and promoted to a
+// transaction, or discarded.
+type importReject struct {
+	id          int
+	importID    *int
+	accountID   *int
+	accountName string
+	filename    string
+	sourceLine  int
+	raw         string
+	field       string
+	message     string
+
+	// Best-effort values from the record, the starting point for a fix.
+	dateRaw     string
+	dateFormat  string
+	amountRaw   string
+	description string
+	createdAt   string
+}
+
+// canQuarantine reports whether every parse error is tied to a data row, so
+// the good rows can be imported and the bad ones quarantined. File-level
+// errors such as a missing header still block the import.
+func (s *importPreviewSnapshot) canQuarantine() bool {
+	if len(s.parseErrors) == 0 {
+		return false
+	}
+	for _, pe := range s.parseErrors {
+		if pe.rowIndex == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// importBlocked reports whether parse errors stop the snapshot importing.
+func (s *importPreviewSnapshot) importBlocked() bool {
+	if s.errorCount == 0 && len(s.parseErrors) == 0 {
+		return false
+	}
+	return !s.quarantine || !s.canQuarantine()
+}
+
+// loadImportRejects returns quarantined rows, oldest first.
+func loadImportRejects(db *sql.DB) ([]importReject, error) {
+	rows, err := db.Query(`
+		SELECT r.id, r.import_id, r.account_id, COALESCE(a.name, ''), r.filename, r.source_line,
+		       r.raw, r.field, r.error, r.date_raw, r.date_format, r.amount_raw, r.description, r.created_at
+		FROM import_rejects r
+		LEFT JOIN accounts a ON a.id = r.account_id
+		ORDER BY r.id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("query import rejects: %w", err)
+	}
+	defer rows.Close()
+
+	var out []importReject
+	for rows.Next() {
+		var r importReject
+		var importID, accountID sql.NullInt64
+		if err := rows.Scan(&r.id, &importID, &accountID, &r.accountName, &r.filename, &r.sourceLine,
+			&r.raw, &r.field, &r.message, &r.dateRaw, &r.dateFormat, &r.amountRaw, &r.description, &r.createdAt); err != nil {
+			return nil, fmt.Errorf("scan import reject: %w", err)
+		}
+		if importID.Valid {
+			id := int(importID.Int64)
+			r.importID = &id
+		}
+		if accountID.Valid {
+			id := int(accountID.Int64)
+			r.accountID = &id
+		}
+		out = append(out, r)
+	}
+	return out, rows.Err()
+}
+
+// insertImportRejectsTx quarantines a snapshot's row-level parse errors
+// under the given import. Rows already in quarantine for the same account,
+// line and content are skipped, so re-importing a file doesn't repeat them.
+func insertImportRejectsTx(tx *sql.Tx, importID int, snapshot *importPreviewSnapshot) (int, error) {
+	var accountID *int
+	if snapshot.accountID > 0 {
+		accountID = &snapshot.accountID
+	}
+	added := 0
+	for _, pe := range snapshot.parseErrors {
+		var exists int
+		err := tx.QueryRow(`
+			SELECT COUNT(*) FROM import_rejects
+			WHERE account_id IS ? AND raw = ? AND source_line = ?
+		`, accountID, pe.raw, pe.sourceLine).Scan(&exists)
+		if err != nil {
+			return added, fmt.Errorf("check quarantined line %d: %w", pe.sourceLine, err)
+		}
+		if exists > 0 {
+			continue
+		}
+		if _, err := tx.Exec(`
+			INSERT INTO import_rejects (import_id, account_id, filename, source_line, raw, field, error, date_raw, date_format, amount_raw, description)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`, importID, accountID, snapshot.fileName, pe.sourceLine, pe.raw, pe.field, pe.message,
+			pe.dateRaw, snapshot.dateFormat, pe.amountRaw, pe.description); err != nil {
+			return added, fmt.Errorf("quarantine line %d: %w", pe.sourceLine, err)
+		}
+		added++
+	}
+	return added, nil
+}
+
+// rejectFix is a hand-corrected reject, ready to become a transaction.
+type rejectFix struct {
+	dateRaw     string
+	dateISO     string
+	amount      money
+	description string
+}
+
+// parseRejectFix validates the edited fields of a reject. Dates are read in
+// the source format's layout, falling back to ISO.
+func parseRejectFix(r importReject, dateRaw, amountRaw, description string) (rejectFix, error) {
+	fix := rejectFix{dateRaw: strings.TrimSpace(dateRaw), description: strings.TrimSpace(description)}
+	if fix.dateRaw == "" {
+		return fix, fmt.Errorf("date is required")
+	}
+	var err error
+	if r.dateFormat != "" {
+		fix.dateISO, err = parseDateISO(fix.dateRaw, r.dateFormat)
+	}
+	if fix.dateISO == "" {
+		if fix.dateISO, err = parseDateISO(fix.dateRaw, "2006-01-02"); err != nil {
+			layout := "YYYY-MM-DD"
+			if r.dateFormat != "" {
+				layout = r.dateFormat + " or " + layout
+			}
+			return fix, fmt.Errorf("date %q doesn't match %s", fix.dateRaw, layout)
+		}
+	}
+	if fix.amount, err = parseMoney(amountRaw); err != nil {
+		return fix, fmt.Errorf("amount: %w", err)
+	}
+	if fix.description == "" {
+		return fix, fmt.Errorf("description is required")
+	}
+	return fix, nil
+}
+
+// promoteImportReject turns a reject into a transaction on its account and
+// import, then removes it from quarantine.
+func promoteImportReject(db *sql.DB, r importReject, fix rejectFix, merchant string) (int, error) {
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("begin promote reject: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	res, err := tx.Exec(`
+		INSERT INTO transactions (date_raw, date_iso, amount, description, account_id, import_id, merchant)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+	`, fix.dateRaw, fix.dateISO, fix.amount, fix.description, r.accountID, r.importID, merchant)
+	if err != nil {
+		return 0, fmt.Errorf("insert promoted reject: %w", err)
+	}
+	id, err := res.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("last insert id: %w", err)
+	}
+	if r.importID != nil {
+		if _, err := tx.Exec(`UPDATE imports SET row_count = row_count + 1 WHERE id = ?`, *r.importID); err != nil {
+			return 0, fmt.Errorf("update import row count: %w", err)
+		}
+	}
+	if err := deleteImportRejectTx(tx, r.id); err != nil {
+		return 0, err
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("commit promote reject: %w", err)
+	}
+	return int(id), nil
+}
+
+func deleteImportReject(db *sql.DB, id int) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin discard reject: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+	if err := deleteImportRejectTx(tx, id); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit discard reject: %w", err)
+	}
+	return nil
+}
+
+func deleteImportRejectTx(tx *sql.Tx, id int) error {
+	res, err := tx.Exec(`DELETE FROM import_rejects WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("delete reject %d: %w", id, err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return fmt.Errorf("reject %d not found", id)
+	}
+	return nil
+}
+
+// ---------------------------------------------------------------------------
+// Review modal
+// ---------------------------------------------------------------------------
+
+// importRejectsReview is the state of the rejected-rows modal: the list
+// cursor and the editable fields of the selected reject.
+type importRejectsReview struct {
+	cursor      int
+	rejectID    int // reject the fields were loaded from
+	focus       int // 0=date, 1=amount, 2=description
+	date        textField
+	amount      textField
+	description textField
+	err         string
+}
+
+const importRejectFieldCount = 3
+
+// load fills the edit fields from a reject.
+func (v *importRejectsReview) load(r importReject) {
+	v.rejectID = r.id
+	v.date.set(r.dateRaw)
+	v.amount.set(r.amountRaw)
+	v.description.set(r.description)
+	v.err = ""
+}
+
+func (v *importRejectsReview) focusedField() *textField {
+	switch v.focus {
+	case 1:
+		return &v.amount
+	case 2:
+		return &v.description
+	}
+	return &v.date
+}
+
+type importRejectResolvedMsg struct {
+	promoted bool // false when discarded
+	txnID    int
+	line     int
+	file     string
+	err      error
+}
+
+func promoteImportRejectCmd(db *sql.DB, r importReject, fix rejectFix, merchant string) tea.Cmd {
+	return func() tea.Msg {
+		id, err := promoteImportReject(db, r, fix, merchant)
+		return importRejectResolvedMsg{promoted: true, txnID: id, line: r.sourceLine, file: r.filename, err: err}
+	}
+}
+
+func discardImportRejectCmd(db *sql.DB, r importReject) tea.Cmd {
+	return func() tea.Msg {
+		err := deleteImportReject(db, r.id)
+		return importRejectResolvedMsg{line: r.sourceLine, file: r.filename, err: err}
+	}
+}
@@ -0,0 +1,179 @@
+package main
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// importArchiveDirName is the inbox subfolder imported files are moved into.
+const importArchiveDirName = "archive"
+
+// importInboxSettings configures where the import picker looks for files.
+// An empty dir means the working directory, which is never archived.
+type importInboxSettings struct {
+	dir       string
+	recursive bool
+	globs     []string
+}
+
+func (s appSettings) importInbox() importInboxSettings {
+	return importInboxSettings{
+		dir:       s.ImportInbox,
+		recursive: s.ImportInboxRecursive,
+		globs:     append([]string(nil), s.ImportInboxGlobs...),
+	}
+}
+
+// archives reports whether imported files are moved out of the inbox.
+func (s importInboxSettings) archives() bool {
+	return s.dir != ""
+}
+
+// root returns the directory to scan, falling back to cwd.
+func (s importInboxSettings) root(cwd string) string {
+	if s.dir == "" {
+		return cwd
+	}
+	return expandHomePath(s.dir)
+}
+
+// expandHomePath expands a leading "~/" to the user's home directory.
+func expandHomePath(p string) string {
+	if p != "~" && !strings.HasPrefix(p, "~/") {
+		return p
+	}
+	home, err := os.UserHomeDir()
+	if err != nil || home == "" {
+		return p
+	}
+	return filepath.Join(home, strings.TrimPrefix(p, "~"))
+}
+
+// validInboxGlob reports whether pattern is a well-formed path.Match pattern.
+func validInboxGlob(pattern string) bool {
+	_, err := path.Match(pattern, "")
+	return err == nil
+}
+
+// matchesInboxGlobs reports whether the slash-separated relative path matches
+// any glob, tried against both the full relative path and the base name. No
+// globs matches every file.
+func matchesInboxGlobs(rel string, globs []string) bool {
+	if len(globs) == 0 {
+		return true
+	}
+	base := path.Base(rel)
+	for _, g := range globs {
+		if ok, _ := path.Match(g, rel); ok {
+			return true
+		}
+		if ok, _ := path.Match(g, base); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// listInboxFiles returns importable files under root as sorted
+// slash-separated relative paths. Subdirectories are walked when recursive;
+// the archive folder and hidden directories are always skipped.
+func listInboxFiles(root string, recursive bool, globs []string) ([]string, error) {
+	var names []string
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, walkErr error) error {
+		if walkErr != nil {
+			if p == root {
+				return walkErr
+			}
+			// Unreadable subdirectories shouldn't hide the rest of the inbox.
+			return nil
+		}
+		if d.IsDir() {
+			if p == root {
+				return nil
+			}
+			if !recursive || d.Name() == importArchiveDirName || strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if !isImportableFileName(d.Name()) {
+			return nil
+		}
+		rel, relErr := filepath.Rel(root, p)
+		if relErr != nil {
+			return nil
+		}
+		rel = filepath.ToSlash(rel)
+		if matchesInboxGlobs(rel, globs) {
+			names = append(names, rel)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// archiveImportedFile moves src (inside root) to root/archive, keeping its
+// relative subfolder and adding a numeric suffix rather than overwriting an
+// earlier archived file of the same name. It returns the destination path.
+func archiveImportedFile(root, src string) (string, error) {
+	rel, err := filepath.Rel(root, src)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", fmt.Errorf("%s is outside the import inbox", src)
+	}
+	dest := filepath.Join(root, importArchiveDirName, rel)
+	if err := os.MkdirAll(filepath.Dir(dest), 0o755); err != nil {
+		return "", fmt.Errorf("create archive dir: %w", err)
+	}
+	ext := filepath.Ext(dest)
+	stem := strings.TrimSuffix(dest, ext)
+	for n := 1; fileExists(dest); n++ {
+		dest = fmt.Sprintf("%s (%d)%s", stem, n, ext)
+	}
+	if err := os.Rename(src, dest); err != nil {
+		return "", fmt.Errorf("archive %s: %w", filepath.Base(src), err)
+	}
+	return dest, nil
+}
+
+func archiveImportedFileCmd(root, src string) tea.Cmd {
+	return func() tea.Msg {
+		dest, err := archiveImportedFile(root, src)
+		return importArchivedMsg{file: filepath.Base(src), dest: dest, err: err}
+	}
+}
+
+// importQueueState tracks an "import all pending" run: each file is scanned
+// into its own preview, and the next one opens once that preview is imported
+// or cancelled.
+type importQueueState struct {
+	pending  []string
+	total    int
+	imported int
+	skipped  int
+	failed   int
+	lastErr  string
+}
+
+func (q *importQueueState) fail(err error) {
+	q.failed++
+	q.lastErr = err.Error()
+}
+
+func (q *importQueueState) position() int {
+	return q.total - len(q.pending)
+}
+
+func (q *importQueueState) summary() string {
+	return fmt.Sprintf("Import all pending finished: %d imported, %d skipped, %d failed.", q.imported, q.skipped, q.failed)
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeInboxFiles(t *testing.T, root string, names ...string) {
+	t.Helper()
+	for _, name := range names {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatalf("mkdir: %v", err)
+		}
+		if err := os.WriteFile(path, []byte("3/02/2026,-20.00,TEST\n"), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+}
+
+func TestListInboxFilesRecursionAndGlobs(t *testing.T) {
+	root := t.TempDir()
+	writeInboxFiles(t, root,
+		"anz-feb.csv",
+		"notes.txt",
+		"westpac/feb.ofx",
+		"westpac/deep/mar.csv",
+		"archive/anz-jan.csv",
+		".hidden/x.csv",
+	)
+
+	flat, err := listInboxFiles(root, false, nil)
+	if err != nil {
+		t.Fatalf("listInboxFiles: %v", err)
+	}
+	if strings.Join(flat, ",") != "anz-feb.csv" {
+		t.Fatalf("flat = %v", flat)
+	}
+
+	all, err := listInboxFiles(root, true, nil)
+	if err != nil {
+		t.Fatalf("listInboxFiles recursive: %v", err)
+	}
+	if strings.Join(all, ",") != "anz-feb.csv,westpac/deep/mar.csv,westpac/feb.ofx" {
+		t.Fatalf("recursive = %v (archive and hidden dirs must be skipped)", all)
+	}
+
+	globbed, err := listInboxFiles(root, true, []string{"*.ofx", "westpac/*/*.csv"})
+	if err != nil {
+		t.Fatalf("listInboxFiles globs: %v", err)
+	}
+	if strings.Join(globbed, ",") != "westpac/deep/mar.csv,westpac/feb.ofx" {
+		t.Fatalf("globbed = %v", globbed)
+	}
+}
+
+func TestArchiveImportedFileKeepsSubfolderAndAvoidsOverwrite(t *testing.T) {
+	root := t.TempDir()
+	writeInboxFiles(t, root, "westpac/feb.csv", "archive/westpac/feb.csv")
+
+	dest, err := archiveImportedFile(root, filepath.Join(root, "westpac", "feb.csv"))
+	if err != nil {
+		t.Fatalf("archiveImportedFile: %v", err)
+	}
+	if want := filepath.Join(root, "archive", "westpac", "feb (1).csv"); dest != want {
+		t.Fatalf("dest = %q, want %q", dest, want)
+	}
+	if fileExists(filepath.Join(root, "westpac", "feb.csv")) {
+		t.Fatal("source should be moved out of the inbox")
+	}
+	if _, err := archiveImportedFile(root, filepath.Join(t.TempDir(), "other.csv")); err == nil {
+		t.Fatal("expected error archiving a file outside the inbox")
+	}
+}
+
+func TestNormalizeSettingsDropsInvalidInboxGlobs(t *testing.T) {
+	s := defaultSettings()
+	s.ImportInbox = "  ~/bank  "
+	s.ImportInboxGlobs = []string{" *.csv ", "", "[bad"}
+	got := normalizeSettings(s)
+	if got.ImportInbox != "~/bank" {
+		t.Fatalf("inbox = %q", got.ImportInbox)
+	}
+	if len(got.ImportInboxGlobs) != 1 || got.ImportInboxGlobs[0] != "*.csv" {
+		t.Fatalf("globs = %v", got.ImportInboxGlobs)
+	}
+}
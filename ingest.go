@@ -1,13 +1,15 @@
 package main
 
 import (
+	"crypto/sha256"
 	"database/sql"
 	"encoding/csv"
+	"encoding/hex"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
-	"strconv"
+	"slices"
 	"strings"
 	"time"
 
@@ -17,8 +19,14 @@ import (
 type parsedCSVRow struct {
 	dateRaw     string
 	dateISO     string
-	amount      float64
+	amount      money
 	description string
+	externalRef string // from ref_col; "" when the format has none
+	balance     *money // from balance_col; nil when absent or blank
+
+	origAmount   *money // from orig_amount_col, signed like amount
+	origCurrency string
+	status       string // txnStatusPending or txnStatusPosted
 }
 
 // ingestCmd returns a Bubble Tea command that imports a CSV file into the DB,
@@ -46,13 +54,10 @@ func ingestCmd(db *sql.DB, filename, basePath string, formats []csvFormat, saved
 				file: base,
 			}
 		}
-		count, dupes, txnIDs, err := importCSVForAccountWithTxnIDs(db, path, *format, &acct.id, skipDupes)
+		count, dupes, txnIDs, err := importCSVForAccountWithTxnIDs(db, path, *format, &acct.id, skipDupes, base)
 		if err != nil {
 			return ingestDoneMsg{count: count, dupes: dupes, err: err, file: base}
 		}
-		if _, err := insertImportRecord(db, base, count); err != nil {
-			return ingestDoneMsg{count: count, dupes: dupes, err: err, file: base}
-		}
 		if len(txnIDs) > 0 {
 			rules, err := loadRulesV2(db)
 			if err != nil {
@@ -95,22 +100,19 @@ func ingestSnapshotCmd(db *sql.DB, snapshot *importPreviewSnapshot, skipDupes, a
 		if snapshot == nil {
 			return ingestDoneMsg{err: fmt.Errorf("missing import preview snapshot")}
 		}
-		if snapshot.errorCount > 0 || len(snapshot.parseErrors) > 0 {
+		if snapshot.importBlocked() {
 			return ingestDoneMsg{
 				file: snapshot.fileName,
 				err:  fmt.Errorf("snapshot has %d parse/normalize errors", max(snapshot.errorCount, len(snapshot.parseErrors))),
 			}
 		}
 
-		count, dupes, txnIDs, err := importSnapshotRows(db, snapshot, skipDupes)
+		_, count, dupes, quarantined, txnIDs, lockedIDs, err := importSnapshotRows(db, snapshot, skipDupes)
 		if err != nil {
 			return ingestDoneMsg{count: count, dupes: dupes, err: err, file: snapshot.fileName}
 		}
-		if _, err := insertImportRecord(db, snapshot.fileName, count); err != nil {
-			return ingestDoneMsg{count: count, dupes: dupes, err: err, file: snapshot.fileName}
-		}
 
-		done := ingestDoneMsg{count: count, dupes: dupes, file: snapshot.fileName}
+		done := ingestDoneMsg{count: count, dupes: dupes, quarantined: quarantined, file: snapshot.fileName, sourcePath: snapshot.sourcePath}
 		if len(txnIDs) == 0 {
 			return done
 		}
@@ -120,7 +122,7 @@ func ingestSnapshotCmd(db *sql.DB, snapshot *importPreviewSnapshot, skipDupes, a
 				done.err = err
 				return done
 			}
-			updatedTxns, catChanges, tagChanges, err := applyResolvedRulesV2ToTxnIDs(db, snapshot.lockedRules.resolved, txnTags, txnIDs)
+			updatedTxns, catChanges, tagChanges, err := applyResolvedRulesV2ToTxnIDs(db, snapshot.lockedRules.resolved, txnTags, withoutIDs(txnIDs, lockedIDs))
 			if err != nil {
 				done.err = err
 				return done
@@ -136,7 +138,7 @@ func ingestSnapshotCmd(db *sql.DB, snapshot *importPreviewSnapshot, skipDupes, a
 
 // scanDupesCmd scans a CSV file and builds an immutable preview snapshot
 // without importing or writing to the database.
-func scanDupesCmd(db *sql.DB, filename, basePath string, formats []csvFormat, savedFilters []savedFilter) tea.Cmd {
+func scanDupesCmd(db *sql.DB, filename, basePath string, formats []csvFormat, savedFilters []savedFilter, match dupeMatchSettings) tea.Cmd {
 	return func() tea.Msg {
 		if db == nil {
 			return importPreviewMsg{err: fmt.Errorf("database not ready")}
@@ -146,56 +148,232 @@ func scanDupesCmd(db *sql.DB, filename, basePath string, formats []csvFormat, sa
 			path = filepath.Join(basePath, path)
 		}
 		base := filepath.Base(path)
-		format := detectFormat(formats, base)
+		if isOFXFileName(base) {
+			snapshot, err := buildOFXImportPreviewSnapshot(db, path, base, formats, savedFilters, match)
+			if err != nil {
+				return importPreviewMsg{err: err}
+			}
+			return withImportSource(db, importPreviewMsg{snapshot: snapshot}, path)
+		}
+		if isQIFFileName(base) {
+			snapshot, err := buildQIFImportPreviewSnapshot(db, path, base, formats, savedFilters, match)
+			if err != nil {
+				return importPreviewMsg{err: err}
+			}
+			return withImportSource(db, importPreviewMsg{snapshot: snapshot}, path)
+		}
+		format := detectFormatForFile(formats, path, base)
 		if format == nil {
 			return importPreviewMsg{err: fmt.Errorf("no matching format for %q", base)}
 		}
-		acct, err := loadAccountByNameCI(db, format.Account)
-		if err != nil {
-			return importPreviewMsg{err: fmt.Errorf("resolve account %q: %w", format.Account, err)}
-		}
-		if acct == nil {
-			return importPreviewMsg{err: fmt.Errorf("account %q not found; create or sync it in Manager", format.Account)}
+		return withImportSource(db, scanCSVWithFormat(db, path, base, *format, savedFilters, match), path)
+	}
+}
+
+// scanDupesForFormatCmd is scanDupesCmd for a CSV with an explicitly chosen
+// format, e.g. a non-top candidate picked in the file picker.
+func scanDupesForFormatCmd(db *sql.DB, filename, basePath string, format csvFormat, savedFilters []savedFilter, match dupeMatchSettings) tea.Cmd {
+	return func() tea.Msg {
+		if db == nil {
+			return importPreviewMsg{err: fmt.Errorf("database not ready")}
 		}
-		snapshot, err := buildImportPreviewSnapshot(db, path, base, *format, *acct, savedFilters)
-		if err != nil {
-			return importPreviewMsg{err: err}
+		path := filename
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(basePath, path)
 		}
-		return importPreviewMsg{snapshot: snapshot}
+		return withImportSource(db, scanCSVWithFormat(db, path, filepath.Base(path), format, savedFilters, match), path)
 	}
 }
 
-func importSnapshotRows(db *sql.DB, snapshot *importPreviewSnapshot, skipDupes bool) (inserted int, dupes int, txnIDs []int, err error) {
+// withImportSource records the scanned file's location, hash and size on a
+// preview snapshot and looks up earlier imports of the same file or date
+// range.
+func withImportSource(db *sql.DB, msg importPreviewMsg, path string) importPreviewMsg {
+	if msg.snapshot == nil {
+		return msg
+	}
+	s := msg.snapshot
+	s.sourcePath = path
+	hash, size, err := hashImportFile(path)
+	if err != nil {
+		return importPreviewMsg{err: err}
+	}
+	s.contentHash, s.fileSize = hash, size
+	s.sameFileImports, s.coveringImports, err = loadPriorImports(db, hash, s.accountID, s.dateFrom, s.dateTo)
+	if err != nil {
+		return importPreviewMsg{err: err}
+	}
+	return msg
+}
+
+// hashImportFile returns the hex SHA-256 and size of a file.
+func hashImportFile(path string) (string, int64, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", 0, fmt.Errorf("open %s: %w", filepath.Base(path), err)
+	}
+	defer f.Close()
+	h := sha256.New()
+	size, err := io.Copy(h, f)
+	if err != nil {
+		return "", 0, fmt.Errorf("hash %s: %w", filepath.Base(path), err)
+	}
+	return hex.EncodeToString(h.Sum(nil)), size, nil
+}
+
+// importRecord is the imports row written for this snapshot.
+func (s *importPreviewSnapshot) importRecord(rowCount int) importRecord {
+	rec := importRecord{
+		filename:    s.fileName,
+		rowCount:    rowCount,
+		contentHash: s.contentHash,
+		fileSize:    s.fileSize,
+		format:      s.formatName,
+		dateFrom:    s.dateFrom,
+		dateTo:      s.dateTo,
+	}
+	if s.accountID > 0 {
+		id := s.accountID
+		rec.accountID = &id
+	}
+	return rec
+}
+
+// needsReimportConfirm reports whether importing should stop once to warn
+// that the file, or its whole date range, was imported before.
+func (s *importPreviewSnapshot) needsReimportConfirm() bool {
+	return !s.reimportConfirmed && (len(s.sameFileImports) > 0 || len(s.coveringImports) > 0)
+}
+
+func scanCSVWithFormat(db *sql.DB, path, base string, format csvFormat, savedFilters []savedFilter, match dupeMatchSettings) importPreviewMsg {
+	acct, err := loadAccountByNameCI(db, format.Account)
+	if err != nil {
+		return importPreviewMsg{err: fmt.Errorf("resolve account %q: %w", format.Account, err)}
+	}
+	if acct == nil {
+		return importPreviewMsg{err: fmt.Errorf("account %q not found; create or sync it in Manager", format.Account)}
+	}
+	snapshot, err := buildImportPreviewSnapshot(db, path, base, format, *acct, savedFilters, match)
+	if err != nil {
+		return importPreviewMsg{err: err}
+	}
+	return importPreviewMsg{snapshot: snapshot}
+}
+
+// importSnapshotRows records the import and inserts the snapshot's rows,
+// linked to it, in one transaction, so a failed import leaves neither
+// orphaned rows nor an empty record. Quarantined snapshots save their bad
+// rows to import_rejects in the same transaction. Rows settling a pending transaction
+// absorb its edits, and rows classified by hand in the preview keep the
+// user's category and tags; both kinds are also returned in lockedIDs so
+// rules don't overwrite them.
+func importSnapshotRows(db *sql.DB, snapshot *importPreviewSnapshot, skipDupes bool) (importID int, inserted int, dupes int, quarantined int, txnIDs []int, lockedIDs []int, err error) {
 	tx, err := db.Begin()
 	if err != nil {
-		return 0, 0, nil, fmt.Errorf("begin tx: %w", err)
+		return 0, 0, 0, 0, nil, nil, fmt.Errorf("begin tx: %w", err)
 	}
 	defer tx.Rollback() //nolint:errcheck // rollback is a no-op after commit
 
+	importID, err = recordImportTx(tx, snapshot.importRecord(0))
+	if err != nil {
+		return 0, 0, 0, 0, nil, nil, err
+	}
+
+	categoryIDs := make(map[string]int)
+	resolveCategory := func(name string) (*int, error) {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return nil, nil
+		}
+		key := strings.ToLower(name)
+		if id, ok := categoryIDs[key]; ok {
+			return &id, nil
+		}
+		id, catErr := ensureCategoryByNameTx(tx, name)
+		if catErr != nil {
+			return nil, catErr
+		}
+		categoryIDs[key] = id
+		return &id, nil
+	}
+
 	insertedIDs := make([]int, 0, len(snapshot.rows))
 	for _, row := range snapshot.rows {
-		if skipDupes && row.isDupe {
+		if skipDupes && (row.isDupe || (row.likelyDupe != nil && row.skipLikelyDupe)) {
 			dupes++
 			continue
 		}
+		catName := row.categoryName
+		if row.edited {
+			catName = row.editCategory
+		}
+		categoryID, catErr := resolveCategory(catName)
+		if catErr != nil {
+			return importID, inserted, dupes, quarantined, insertedIDs, lockedIDs, catErr
+		}
 		res, execErr := tx.Exec(`
-			INSERT INTO transactions (date_raw, date_iso, amount, description, notes, account_id)
-			VALUES (?, ?, ?, ?, '', ?)
-		`, row.dateRaw, row.dateISO, row.amount, row.description, snapshot.accountID)
+			INSERT INTO transactions (date_raw, date_iso, amount, description, category_id, notes, account_id, external_ref, bank_balance, original_amount, original_currency, status, merchant, import_id)
+			VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		`, row.dateRaw, row.dateISO, row.amount, row.description, categoryID, row.notes, snapshot.accountID, row.externalRef, row.balance, row.origAmount, row.origCurrency, txnStatusOrPosted(row.status), row.merchant, importID)
 		if execErr != nil {
-			return inserted, dupes, insertedIDs, fmt.Errorf("insert row: %w", execErr)
+			return importID, inserted, dupes, quarantined, insertedIDs, lockedIDs, fmt.Errorf("insert row: %w", execErr)
 		}
 		lastID, idErr := res.LastInsertId()
 		if idErr != nil {
-			return inserted, dupes, insertedIDs, fmt.Errorf("last insert id: %w", idErr)
+			return importID, inserted, dupes, quarantined, insertedIDs, lockedIDs, fmt.Errorf("last insert id: %w", idErr)
+		}
+		if row.replacesPending != nil {
+			if settleErr := settlePendingTx(tx, row.replacesPending.txnID, int(lastID)); settleErr != nil {
+				return importID, inserted, dupes, quarantined, insertedIDs, lockedIDs, settleErr
+			}
+			if row.edited {
+				// The preview pick wins over the pending row's category.
+				if _, execErr := tx.Exec(`UPDATE transactions SET category_id = ? WHERE id = ?`, categoryID, lastID); execErr != nil {
+					return importID, inserted, dupes, quarantined, insertedIDs, lockedIDs, fmt.Errorf("restore category: %w", execErr)
+				}
+			}
+		}
+		if row.edited {
+			for _, tg := range row.editTags {
+				if _, execErr := tx.Exec(`INSERT OR IGNORE INTO transaction_tags (transaction_id, tag_id) VALUES (?, ?)`, lastID, tg.id); execErr != nil {
+					return importID, inserted, dupes, quarantined, insertedIDs, lockedIDs, fmt.Errorf("insert tag: %w", execErr)
+				}
+			}
+		}
+		if row.replacesPending != nil || row.edited {
+			lockedIDs = append(lockedIDs, int(lastID))
+		}
+		for _, alloc := range row.allocations {
+			allocCatID, allocErr := resolveCategory(alloc.categoryName)
+			if allocErr != nil {
+				return importID, inserted, dupes, quarantined, insertedIDs, lockedIDs, allocErr
+			}
+			amount, normErr := normalizeAllocationAmount(row.amount, alloc.amount)
+			if normErr != nil {
+				return importID, inserted, dupes, quarantined, insertedIDs, lockedIDs, fmt.Errorf("row %d allocation: %w", row.index, normErr)
+			}
+			if _, execErr := tx.Exec(`
+				INSERT INTO transaction_allocations (parent_txn_id, amount, category_id, note)
+				VALUES (?, ?, ?, ?)
+			`, lastID, amount, allocCatID, strings.TrimSpace(alloc.note)); execErr != nil {
+				return importID, inserted, dupes, quarantined, insertedIDs, lockedIDs, fmt.Errorf("insert allocation: %w", execErr)
+			}
 		}
 		inserted++
 		insertedIDs = append(insertedIDs, int(lastID))
 	}
+	if snapshot.quarantine {
+		if quarantined, err = insertImportRejectsTx(tx, importID, snapshot); err != nil {
+			return importID, inserted, dupes, quarantined, insertedIDs, lockedIDs, err
+		}
+	}
+	if err := finishImportTx(tx, importID, inserted, insertedIDs); err != nil {
+		return importID, inserted, dupes, quarantined, insertedIDs, lockedIDs, err
+	}
 	if err := tx.Commit(); err != nil {
-		return inserted, dupes, insertedIDs, fmt.Errorf("commit tx: %w", err)
+		return importID, inserted, dupes, quarantined, insertedIDs, lockedIDs, fmt.Errorf("commit tx: %w", err)
 	}
-	return inserted, dupes, insertedIDs, nil
+	return importID, inserted, dupes, quarantined, insertedIDs, lockedIDs, nil
 }
 
 func applyResolvedRulesV2ToTxnIDs(db *sql.DB, resolved []resolvedRuleV2, txnTags map[int][]tag, txnIDs []int) (updatedTxns, catChanges, tagChanges int, err error) {
@@ -209,7 +387,25 @@ func applyResolvedRulesV2ToTxnIDs(db *sql.DB, resolved []resolvedRuleV2, txnTags
 	return applyResolvedRulesV2ToRows(db, resolved, txnTags, rows)
 }
 
-func buildImportPreviewSnapshot(db *sql.DB, path, fileName string, format csvFormat, account account, savedFilters []savedFilter) (*importPreviewSnapshot, error) {
+// withoutIDs returns ids minus those in drop, keeping order.
+func withoutIDs(ids, drop []int) []int {
+	if len(drop) == 0 {
+		return ids
+	}
+	skip := make(map[int]bool, len(drop))
+	for _, id := range drop {
+		skip[id] = true
+	}
+	out := make([]int, 0, len(ids))
+	for _, id := range ids {
+		if !skip[id] {
+			out = append(out, id)
+		}
+	}
+	return out
+}
+
+func buildImportPreviewSnapshot(db *sql.DB, path, fileName string, format csvFormat, account account, savedFilters []savedFilter, match dupeMatchSettings) (*importPreviewSnapshot, error) {
 	existingSet, err := loadDuplicateSet(db)
 	if err != nil {
 		return nil, fmt.Errorf("load duplicates: %w", err)
@@ -227,18 +423,57 @@ func buildImportPreviewSnapshot(db *sql.DB, path, fileName string, format csvFor
 		parseErrors: parseErrors,
 		errorCount:  len(parseErrors),
 		accountID:   account.id,
+		formatName:  format.Name,
+		dateFormat:  format.DateFormat,
 	}
-	for _, row := range rows {
-		if row.isDupe {
+	if err := finalizeImportPreviewSnapshot(db, snapshot, account, savedFilters, match); err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// finalizeImportPreviewSnapshot matches posted rows to the pending
+// transactions they settle, flags likely duplicates, tallies
+// new/duplicate counts, locks the current rule set and projects post-rule
+// categories and tags onto the rows. Every importer (CSV, OFX, QIF) funnels
+// through here so previews stay identical.
+func finalizeImportPreviewSnapshot(db *sql.DB, snapshot *importPreviewSnapshot, account account, savedFilters []savedFilter, match dupeMatchSettings) error {
+	if err := matchPendingTransactions(db, snapshot.rows, account.id); err != nil {
+		return err
+	}
+	if err := flagLikelyDuplicates(db, snapshot.rows, account.id, match); err != nil {
+		return err
+	}
+	gaps, err := checkBalanceChain(db, snapshot.rows, account.id)
+	if err != nil {
+		return err
+	}
+	snapshot.balanceGaps = gaps
+	for _, row := range snapshot.rows {
+		if row.dateISO != "" {
+			if snapshot.dateFrom == "" || row.dateISO < snapshot.dateFrom {
+				snapshot.dateFrom = row.dateISO
+			}
+			if row.dateISO > snapshot.dateTo {
+				snapshot.dateTo = row.dateISO
+			}
+		}
+		switch {
+		case row.isDupe:
 			snapshot.dupeCount++
-		} else {
+		case row.likelyDupe != nil:
+			snapshot.likelyDupeCount++
+		default:
 			snapshot.newCount++
+			if row.replacesPending != nil {
+				snapshot.pendingSettleCount++
+			}
 		}
 	}
 
 	rules, err := loadRulesV2(db)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	ruleIDs := make([]int, 0, len(rules))
 	for _, rule := range rules {
@@ -252,11 +487,7 @@ func buildImportPreviewSnapshot(db *sql.DB, path, fileName string, format csvFor
 		resolved:   resolved,
 	}
 	snapshot.rows, err = projectImportPreviewRows(db, snapshot.rows, account.name, account.id, resolved)
-	if err != nil {
-		return nil, err
-	}
-
-	return snapshot, nil
+	return err
 }
 
 func parseImportPreviewRows(path string, format csvFormat, accountID int, existingSet map[string]bool) (rows []importPreviewRow, parseErrors []importPreviewParseError, totalRows int, err error) {
@@ -271,11 +502,12 @@ func parseImportPreviewRows(path string, format csvFormat, accountID int, existi
 	if format.Delimiter != "" {
 		r.Comma = rune(format.Delimiter[0])
 	}
-	minCols := max(format.DateCol, format.AmountCol, format.DescCol) + 1
+	minCols := csvMinCols(format)
 
 	firstRow := true
 	sourceLine := 0
 	rowIndex := 0
+	seenRefs := make(map[string]bool)
 	for {
 		rec, readErr := r.Read()
 		if readErr != nil {
@@ -287,6 +519,19 @@ func parseImportPreviewRows(path string, format csvFormat, accountID int, existi
 		sourceLine++
 		if firstRow && format.HasHeader {
 			firstRow = false
+			if format.usesHeaderNames() {
+				resolved, headerErr := resolveCSVHeaderColumns(format, rec)
+				if headerErr != nil {
+					// Column positions are unknown; parsing rows would misread them.
+					return nil, []importPreviewParseError{{
+						sourceLine: sourceLine,
+						field:      "header",
+						message:    headerErr.Error(),
+					}}, 0, nil
+				}
+				format = resolved
+				minCols = csvMinCols(format)
+			}
 			continue
 		}
 		firstRow = false
@@ -301,19 +546,27 @@ func parseImportPreviewRows(path string, format csvFormat, accountID int, existi
 
 		parsed, issue := parseCSVRecordForPreview(rec, format, minCols)
 		if issue != nil {
-			parseErrors = append(parseErrors, importPreviewParseError{
+			pe := importPreviewParseError{
 				rowIndex:   rowIndex,
 				sourceLine: sourceLine,
 				field:      issue.field,
 				message:    issue.message,
-			})
+				raw:        strings.Join(rec, string(r.Comma)),
+			}
+			pe.dateRaw, pe.amountRaw, pe.description = csvRejectFields(rec, format)
+			parseErrors = append(parseErrors, pe)
 			continue
 		}
 
-		key := duplicateKeyForAccount(parsed.dateISO, parsed.amount, parsed.description, &accountID)
-		// Import preview duplicate detection is DB-scoped only.
-		// Repeated rows within the same file are treated as unique first-import rows.
+		key := csvDuplicateKey(parsed, &accountID)
+		// Import preview duplicate detection is DB-scoped: repeated rows
+		// within the same file are treated as unique first-import rows,
+		// unless they repeat a bank reference, which names one transaction.
 		isDupe := existingSet[key]
+		if parsed.externalRef != "" {
+			isDupe = isDupe || seenRefs[parsed.externalRef]
+			seenRefs[parsed.externalRef] = true
+		}
 		rows = append(rows, importPreviewRow{
 			index:       rowIndex,
 			sourceLine:  sourceLine,
@@ -321,12 +574,43 @@ func parseImportPreviewRows(path string, format csvFormat, accountID int, existi
 			dateISO:     parsed.dateISO,
 			amount:      parsed.amount,
 			description: parsed.description,
+			externalRef: parsed.externalRef,
+			balance:     parsed.balance,
 			isDupe:      isDupe,
+
+			origAmount:   parsed.origAmount,
+			origCurrency: parsed.origCurrency,
+			status:       parsed.status,
 		})
 	}
 	return rows, parseErrors, totalRows, nil
 }
 
+// csvRejectFields picks the date, amount and description out of a record
+// that failed to parse, leaving blank any column the record doesn't reach.
+// A debit/credit pair becomes one signed amount string.
+func csvRejectFields(rec []string, format csvFormat) (dateRaw, amountRaw, description string) {
+	field := func(col int) string {
+		if col < 0 || col >= len(rec) {
+			return ""
+		}
+		return strings.TrimSpace(rec[col])
+	}
+	dateRaw = field(format.DateCol)
+	if format.DebitCol == nil && format.CreditCol == nil {
+		amountRaw = field(format.AmountCol)
+	} else if format.DebitCol != nil && field(*format.DebitCol) != "" {
+		amountRaw = "-" + strings.TrimPrefix(field(*format.DebitCol), "-")
+	} else if format.CreditCol != nil {
+		amountRaw = field(*format.CreditCol)
+	}
+	description = field(format.DescCol)
+	if format.DescJoin && format.DescCol >= 0 && format.DescCol < len(rec) {
+		description = strings.TrimSpace(strings.Join(rec[format.DescCol:], ","))
+	}
+	return dateRaw, amountRaw, description
+}
+
 func csvRecordIsBlank(rec []string) bool {
 	if len(rec) == 0 {
 		return true
@@ -349,11 +633,10 @@ func parseCSVRecordForPreview(rec []string, format csvFormat, minCols int) (pars
 		return parsedCSVRow{}, &previewParseIssue{field: "row", message: fmt.Sprintf("expected at least %d columns", minCols)}
 	}
 	dateRaw := strings.TrimSpace(rec[format.DateCol])
-	amountRaw := strings.TrimSpace(rec[format.AmountCol])
 	if dateRaw == "" {
 		return parsedCSVRow{}, &previewParseIssue{field: "date", message: "date is required"}
 	}
-	if amountRaw == "" {
+	if !csvRecordHasAmount(rec, format) {
 		return parsedCSVRow{}, &previewParseIssue{field: "amount", message: "amount is required"}
 	}
 
@@ -362,25 +645,32 @@ func parseCSVRecordForPreview(rec []string, format csvFormat, minCols int) (pars
 		description = strings.TrimSpace(strings.Join(rec[format.DescCol:], ","))
 	}
 
-	if format.AmountStrip != "" {
-		for _, ch := range format.AmountStrip {
-			amountRaw = strings.ReplaceAll(amountRaw, string(ch), "")
-		}
-	}
-
 	dateISO, err := parseDateISO(dateRaw, format.DateFormat)
 	if err != nil {
 		return parsedCSVRow{}, &previewParseIssue{field: "date", message: err.Error()}
 	}
-	amount, err := parseAmount(amountRaw)
+	amount, err := parseCSVAmount(rec, format)
 	if err != nil {
 		return parsedCSVRow{}, &previewParseIssue{field: "amount", message: err.Error()}
 	}
+	balance, err := csvBalance(rec, format)
+	if err != nil {
+		return parsedCSVRow{}, &previewParseIssue{field: "balance", message: err.Error()}
+	}
+	origAmount, origCurrency, err := csvOriginalAmount(rec, format, amount)
+	if err != nil {
+		return parsedCSVRow{}, &previewParseIssue{field: "orig_amount", message: err.Error()}
+	}
 	return parsedCSVRow{
-		dateRaw:     dateRaw,
-		dateISO:     dateISO,
-		amount:      amount,
-		description: description,
+		dateRaw:      dateRaw,
+		dateISO:      dateISO,
+		amount:       amount,
+		description:  description,
+		externalRef:  csvExternalRef(rec, format),
+		balance:      balance,
+		origAmount:   origAmount,
+		origCurrency: origCurrency,
+		status:       csvStatus(rec, format),
 	}, nil
 }
 
@@ -407,17 +697,26 @@ func projectImportPreviewRows(db *sql.DB, rows []importPreviewRow, accountName s
 	out := make([]importPreviewRow, 0, len(rows))
 	for _, row := range rows {
 		workCat := (*int)(nil)
+		sourceCat := strings.TrimSpace(row.categoryName)
+		if c, ok := catByName[strings.ToLower(sourceCat)]; ok && sourceCat != "" {
+			workCat = &c.id
+		}
 		workTagSet := make(map[int]bool)
 		workTxn := transaction{
 			dateRaw:      row.dateRaw,
 			dateISO:      row.dateISO,
 			amount:       row.amount,
 			description:  row.description,
-			categoryID:   nil,
-			categoryName: categoryNameForPtr(nil, catNames),
+			notes:        row.notes,
+			categoryID:   copyIntPtr(workCat),
+			categoryName: categoryNameForPtr(workCat, catNames),
 			accountID:    &accountIDCopy,
 			accountName:  accountName,
 		}
+		if workCat == nil && sourceCat != "" {
+			// Not created until import; match rules against the source name.
+			workTxn.categoryName = sourceCat
+		}
 		for _, rule := range resolved {
 			if rule.parsed == nil || !evalFilter(rule.parsed, workTxn, tagStateToSlice(workTagSet, tagByID)) {
 				continue
@@ -434,6 +733,9 @@ func projectImportPreviewRows(db *sql.DB, rows []importPreviewRow, accountName s
 			}
 		}
 		row.previewCat = categoryNameForPtr(workCat, catNames)
+		if workCat == nil && sourceCat != "" {
+			row.previewCat = sourceCat
+		}
 		previewTags := tagStateToSlice(workTagSet, tagByID)
 		row.previewTags = make([]string, 0, len(previewTags))
 		row.previewTagObjs = make([]tag, 0, len(previewTags))
@@ -454,13 +756,8 @@ func projectImportPreviewRows(db *sql.DB, rows []importPreviewRow, accountName s
 // detectFormat picks the first format whose name appears as a prefix (case-insensitive)
 // in the filename. Falls back to the first format if none match.
 func detectFormat(formats []csvFormat, filename string) *csvFormat {
-	lower := strings.ToLower(filename)
 	for i := range formats {
-		prefix := strings.ToLower(strings.TrimSpace(formats[i].ImportPrefix))
-		if prefix == "" {
-			prefix = strings.ToLower(strings.TrimSpace(formats[i].Name))
-		}
-		if strings.HasPrefix(lower, prefix) {
+		if formatPrefixMatches(formats[i], filename) {
 			return &formats[i]
 		}
 	}
@@ -479,17 +776,29 @@ func importCSV(db *sql.DB, path string, format csvFormat, skipDupes bool) (inser
 // importCSVForAccount reads a CSV file using the given format and inserts valid rows.
 // When skipDupes is true, rows matching (date_iso, amount, description, account_id) are skipped.
 func importCSVForAccount(db *sql.DB, path string, format csvFormat, accountID *int, skipDupes bool) (inserted int, dupes int, err error) {
-	inserted, dupes, _, err = importCSVForAccountWithTxnIDs(db, path, format, accountID, skipDupes)
+	inserted, dupes, _, err = importCSVForAccountWithTxnIDs(db, path, format, accountID, skipDupes, "")
 	return inserted, dupes, err
 }
 
-func importCSVForAccountWithTxnIDs(db *sql.DB, path string, format csvFormat, accountID *int, skipDupes bool) (inserted int, dupes int, txnIDs []int, err error) {
+// importCSVForAccountWithTxnIDs inserts a CSV file's rows in one
+// transaction. With a non-empty importName the imports row is written in
+// the same transaction and the rows are linked to it.
+func importCSVForAccountWithTxnIDs(db *sql.DB, path string, format csvFormat, accountID *int, skipDupes bool, importName string) (inserted int, dupes int, txnIDs []int, err error) {
 	tx, err := db.Begin()
 	if err != nil {
 		return 0, 0, nil, fmt.Errorf("begin tx: %w", err)
 	}
 	defer tx.Rollback() //nolint:errcheck // rollback is a no-op after commit
 
+	var importID *int
+	if importName != "" {
+		id, recErr := recordImportTx(tx, importRecord{filename: importName})
+		if recErr != nil {
+			return 0, 0, nil, recErr
+		}
+		importID = &id
+	}
+
 	var existingSet map[string]bool
 	if skipDupes {
 		existingSet, err = loadDuplicateSet(db)
@@ -502,7 +811,7 @@ func importCSVForAccountWithTxnIDs(db *sql.DB, path string, format csvFormat, ac
 	walkErr := walkParsedCSVRows(path, format,
 		func(row parsedCSVRow) error {
 			if skipDupes {
-				key := duplicateKeyForAccount(row.dateISO, row.amount, row.description, accountID)
+				key := csvDuplicateKey(row, accountID)
 				if existingSet[key] {
 					dupes++
 					return nil
@@ -510,9 +819,9 @@ func importCSVForAccountWithTxnIDs(db *sql.DB, path string, format csvFormat, ac
 				existingSet[key] = true
 			}
 			res, execErr := tx.Exec(`
-				INSERT INTO transactions (date_raw, date_iso, amount, description, notes, account_id)
-				VALUES (?, ?, ?, ?, '', ?)
-			`, row.dateRaw, row.dateISO, row.amount, row.description, accountID)
+				INSERT INTO transactions (date_raw, date_iso, amount, description, notes, account_id, external_ref, bank_balance, original_amount, original_currency, status, import_id)
+				VALUES (?, ?, ?, ?, '', ?, ?, ?, ?, ?, ?, ?)
+			`, row.dateRaw, row.dateISO, row.amount, row.description, accountID, row.externalRef, row.balance, row.origAmount, row.origCurrency, txnStatusOrPosted(row.status), importID)
 			if execErr != nil {
 				return fmt.Errorf("insert row: %w", execErr)
 			}
@@ -529,6 +838,11 @@ func importCSVForAccountWithTxnIDs(db *sql.DB, path string, format csvFormat, ac
 	if walkErr != nil {
 		return inserted, dupes, insertedIDs, walkErr
 	}
+	if importID != nil {
+		if err := finishImportTx(tx, *importID, inserted, insertedIDs); err != nil {
+			return inserted, dupes, insertedIDs, err
+		}
+	}
 	if commitErr := tx.Commit(); commitErr != nil {
 		return inserted, dupes, insertedIDs, fmt.Errorf("commit tx: %w", commitErr)
 	}
@@ -550,7 +864,7 @@ func countDuplicatesForAccount(db *sql.DB, path string, format csvFormat, accoun
 	walkErr := walkParsedCSVRows(path, format,
 		func(row parsedCSVRow) error {
 			total++
-			key := duplicateKeyForAccount(row.dateISO, row.amount, row.description, accountID)
+			key := csvDuplicateKey(row, accountID)
 			if existingSet[key] || seenInFile[key] {
 				dupes++
 			}
@@ -586,7 +900,7 @@ func walkParsedCSVRows(
 		r.Comma = rune(format.Delimiter[0])
 	}
 
-	minCols := max(format.DateCol, format.AmountCol, format.DescCol) + 1
+	minCols := csvMinCols(format)
 	firstRow := true
 	for {
 		rec, readErr := r.Read()
@@ -598,6 +912,14 @@ func walkParsedCSVRows(
 		}
 		if firstRow && format.HasHeader {
 			firstRow = false
+			if format.usesHeaderNames() {
+				resolved, headerErr := resolveCSVHeaderColumns(format, rec)
+				if headerErr != nil {
+					return headerErr
+				}
+				format = resolved
+				minCols = csvMinCols(format)
+			}
 			continue
 		}
 		firstRow = false
@@ -629,8 +951,7 @@ func parseCSVRecord(rec []string, format csvFormat, minCols int) (parsedCSVRow,
 		return parsedCSVRow{}, false, nil
 	}
 	dateRaw := strings.TrimSpace(rec[format.DateCol])
-	amountRaw := strings.TrimSpace(rec[format.AmountCol])
-	if dateRaw == "" || amountRaw == "" {
+	if dateRaw == "" || !csvRecordHasAmount(rec, format) {
 		return parsedCSVRow{}, false, nil
 	}
 
@@ -639,80 +960,363 @@ func parseCSVRecord(rec []string, format csvFormat, minCols int) (parsedCSVRow,
 		description = strings.TrimSpace(strings.Join(rec[format.DescCol:], ","))
 	}
 
-	if format.AmountStrip != "" {
-		for _, ch := range format.AmountStrip {
-			amountRaw = strings.ReplaceAll(amountRaw, string(ch), "")
-		}
-	}
-
 	dateISO, err := parseDateISO(dateRaw, format.DateFormat)
 	if err != nil {
 		return parsedCSVRow{}, true, fmt.Errorf("parse date %q: %w", dateRaw, err)
 	}
-	amount, err := parseAmount(amountRaw)
+	amount, err := parseCSVAmount(rec, format)
+	if err != nil {
+		return parsedCSVRow{}, true, err
+	}
+	balance, err := csvBalance(rec, format)
+	if err != nil {
+		return parsedCSVRow{}, true, err
+	}
+	origAmount, origCurrency, err := csvOriginalAmount(rec, format, amount)
 	if err != nil {
-		return parsedCSVRow{}, true, fmt.Errorf("parse amount %q: %w", amountRaw, err)
+		return parsedCSVRow{}, true, err
 	}
 
 	return parsedCSVRow{
-		dateRaw:     dateRaw,
-		dateISO:     dateISO,
-		amount:      amount,
-		description: description,
+		dateRaw:      dateRaw,
+		dateISO:      dateISO,
+		amount:       amount,
+		description:  description,
+		externalRef:  csvExternalRef(rec, format),
+		balance:      balance,
+		origAmount:   origAmount,
+		origCurrency: origCurrency,
+		status:       csvStatus(rec, format),
 	}, true, nil
 }
 
+// csvStatus reads the record's pending/posted marker; formats without a
+// status_col import everything as posted.
+func csvStatus(rec []string, format csvFormat) string {
+	if format.StatusCol == nil || *format.StatusCol >= len(rec) {
+		return txnStatusPosted
+	}
+	return txnStatusFromText(rec[*format.StatusCol])
+}
+
+// csvOriginalAmount parses the foreign amount a bank converted from. Banks
+// often export it unsigned, so it takes the sign of the account amount. The
+// currency comes from orig_currency_col or, failing that, a code written
+// into the amount cell ("12.50 USD").
+func csvOriginalAmount(rec []string, format csvFormat, amount money) (*money, string, error) {
+	if format.OrigAmountCol == nil || *format.OrigAmountCol >= len(rec) {
+		return nil, "", nil
+	}
+	raw := strings.TrimSpace(rec[*format.OrigAmountCol])
+	if raw == "" {
+		return nil, "", nil
+	}
+	v, err := parseMoneyLocale(raw, format.NumberLocale)
+	if err != nil {
+		return nil, "", fmt.Errorf("parse original amount %q: %w", raw, err)
+	}
+	if (v < 0) != (amount < 0) {
+		v = -v
+	}
+	currency := ""
+	if format.OrigCurrencyCol != nil && *format.OrigCurrencyCol < len(rec) {
+		code, ok := normalizeCurrencyCode(rec[*format.OrigCurrencyCol])
+		if !ok {
+			return nil, "", fmt.Errorf("invalid original currency %q", strings.TrimSpace(rec[*format.OrigCurrencyCol]))
+		}
+		currency = code
+	}
+	if currency == "" {
+		for _, field := range strings.Fields(raw) {
+			if isCurrencyCode(field) {
+				currency = field
+				break
+			}
+		}
+	}
+	return &v, currency, nil
+}
+
+// csvBalance parses the record's running balance. A missing or blank cell
+// yields nil; invert_sign flips it along with the amount so the balance
+// chain stays consistent.
+func csvBalance(rec []string, format csvFormat) (*money, error) {
+	if format.BalanceCol == nil || *format.BalanceCol >= len(rec) {
+		return nil, nil
+	}
+	raw := csvAmountField(rec, *format.BalanceCol, format)
+	if raw == "" {
+		return nil, nil
+	}
+	v, err := parseMoneyLocale(raw, format.NumberLocale)
+	if err != nil {
+		return nil, fmt.Errorf("parse balance %q: %w", raw, err)
+	}
+	if format.InvertSign {
+		v = -v
+	}
+	return &v, nil
+}
+
+// csvExternalRef returns the record's bank reference, or "" when the format
+// has no ref_col or the record is too short to carry one.
+func csvExternalRef(rec []string, format csvFormat) string {
+	if format.RefCol == nil || *format.RefCol >= len(rec) {
+		return ""
+	}
+	return strings.TrimSpace(rec[*format.RefCol])
+}
+
+// csvDuplicateKey keys a parsed row for duplicate detection: by reference
+// when the row carries one, so same-day identical purchases stay distinct,
+// otherwise by date, amount and description.
+func csvDuplicateKey(row parsedCSVRow, accountID *int) string {
+	if row.externalRef != "" {
+		return duplicateRefKeyForAccount(row.externalRef, accountID)
+	}
+	return duplicateKeyForAccount(row.dateISO, row.amount, row.description, accountID)
+}
+
+// usesHeaderNames reports whether any column is addressed by header text.
+func (f csvFormat) usesHeaderNames() bool {
+	return f.DateHeader != "" || f.AmountHeader != "" || f.DescHeader != "" ||
+		f.DebitHeader != "" || f.CreditHeader != "" || f.RefHeader != "" || f.BalanceHeader != "" ||
+		f.StatusHeader != ""
+}
+
+// resolveCSVHeaderColumns returns a copy of format with every *_header name
+// replaced by its index in header. Matching ignores case and surrounding
+// whitespace; a missing header is an error naming the expected column.
+func resolveCSVHeaderColumns(format csvFormat, header []string) (csvFormat, error) {
+	index := make(map[string]int, len(header))
+	for i, name := range header {
+		key := strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff")))
+		if _, seen := index[key]; !seen {
+			index[key] = i
+		}
+	}
+	lookup := func(field, name string) (int, error) {
+		idx, ok := index[strings.ToLower(strings.TrimSpace(name))]
+		if !ok {
+			return 0, fmt.Errorf("%s column %q not found in header", field, name)
+		}
+		return idx, nil
+	}
+
+	var err error
+	if format.DateHeader != "" {
+		if format.DateCol, err = lookup("date", format.DateHeader); err != nil {
+			return format, err
+		}
+	}
+	if format.AmountHeader != "" {
+		if format.AmountCol, err = lookup("amount", format.AmountHeader); err != nil {
+			return format, err
+		}
+	}
+	if format.DescHeader != "" {
+		if format.DescCol, err = lookup("description", format.DescHeader); err != nil {
+			return format, err
+		}
+	}
+	if format.DebitHeader != "" {
+		idx, lookupErr := lookup("debit", format.DebitHeader)
+		if lookupErr != nil {
+			return format, lookupErr
+		}
+		format.DebitCol = &idx
+	}
+	if format.CreditHeader != "" {
+		idx, lookupErr := lookup("credit", format.CreditHeader)
+		if lookupErr != nil {
+			return format, lookupErr
+		}
+		format.CreditCol = &idx
+	}
+	if format.RefHeader != "" {
+		idx, lookupErr := lookup("reference", format.RefHeader)
+		if lookupErr != nil {
+			return format, lookupErr
+		}
+		format.RefCol = &idx
+	}
+	if format.BalanceHeader != "" {
+		idx, lookupErr := lookup("balance", format.BalanceHeader)
+		if lookupErr != nil {
+			return format, lookupErr
+		}
+		format.BalanceCol = &idx
+	}
+	if format.StatusHeader != "" {
+		idx, lookupErr := lookup("status", format.StatusHeader)
+		if lookupErr != nil {
+			return format, lookupErr
+		}
+		format.StatusCol = &idx
+	}
+	return format, nil
+}
+
+// csvMinCols is the number of columns a record needs for every column the
+// format reads.
+func csvMinCols(format csvFormat) int {
+	cols := []int{format.DateCol, format.DescCol}
+	if format.DebitCol != nil || format.CreditCol != nil {
+		if format.DebitCol != nil {
+			cols = append(cols, *format.DebitCol)
+		}
+		if format.CreditCol != nil {
+			cols = append(cols, *format.CreditCol)
+		}
+	} else {
+		cols = append(cols, format.AmountCol)
+	}
+	return slices.Max(cols) + 1
+}
+
+// csvAmountField returns the trimmed, stripped value of an amount column.
+func csvAmountField(rec []string, col int, format csvFormat) string {
+	raw := strings.TrimSpace(rec[col])
+	for _, ch := range format.AmountStrip {
+		raw = strings.ReplaceAll(raw, string(ch), "")
+	}
+	return raw
+}
+
+// csvRecordHasAmount reports whether any of the format's amount columns
+// carries a value.
+func csvRecordHasAmount(rec []string, format csvFormat) bool {
+	if format.DebitCol == nil && format.CreditCol == nil {
+		return strings.TrimSpace(rec[format.AmountCol]) != ""
+	}
+	for _, col := range []*int{format.DebitCol, format.CreditCol} {
+		if col != nil && strings.TrimSpace(rec[*col]) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// parseCSVAmount derives the signed amount for a record. With debit_col and
+// credit_col the debit magnitude is negative and the credit magnitude
+// positive; a row populating both nets them. invert_sign flips the result.
+func parseCSVAmount(rec []string, format csvFormat) (money, error) {
+	var amount money
+	if format.DebitCol == nil && format.CreditCol == nil {
+		raw := csvAmountField(rec, format.AmountCol, format)
+		v, err := parseMoneyLocale(raw, format.NumberLocale)
+		if err != nil {
+			return 0, fmt.Errorf("parse amount %q: %w", raw, err)
+		}
+		amount = v
+	} else {
+		for _, side := range []struct {
+			col  *int
+			sign money
+			name string
+		}{{format.DebitCol, -1, "debit"}, {format.CreditCol, 1, "credit"}} {
+			if side.col == nil {
+				continue
+			}
+			raw := csvAmountField(rec, *side.col, format)
+			if raw == "" {
+				continue
+			}
+			v, err := parseMoneyLocale(raw, format.NumberLocale)
+			if err != nil {
+				return 0, fmt.Errorf("parse %s %q: %w", side.name, raw, err)
+			}
+			amount += side.sign * v.abs()
+		}
+	}
+	if format.InvertSign {
+		amount = -amount
+	}
+	return amount, nil
+}
+
 // duplicateKey builds a composite key for duplicate detection.
-func duplicateKey(dateISO string, amount float64, description string) string {
+func duplicateKey(dateISO string, amount money, description string) string {
 	return duplicateKeyForAccount(dateISO, amount, description, nil)
 }
 
-func duplicateKeyForAccount(dateISO string, amount float64, description string, accountID *int) string {
+func duplicateKeyForAccount(dateISO string, amount money, description string, accountID *int) string {
+	acc := 0
+	if accountID != nil {
+		acc = *accountID
+	}
+	return fmt.Sprintf("%s|%s|%s|%d", dateISO, amount, strings.ToLower(description), acc)
+}
+
+// duplicateRefKeyForAccount builds the duplicate key for rows that carry a
+// bank-issued reference. The "ref|" prefix keeps these keys disjoint from
+// date/amount/description keys so both can share one set.
+func duplicateRefKeyForAccount(externalRef string, accountID *int) string {
 	acc := 0
 	if accountID != nil {
 		acc = *accountID
 	}
-	return fmt.Sprintf("%s|%.2f|%s|%d", dateISO, amount, strings.ToLower(description), acc)
+	return fmt.Sprintf("ref|%d|%s", acc, strings.TrimSpace(externalRef))
 }
 
 // loadDuplicateSet returns a set of existing transaction keys for fast lookup.
+// Rows with an external reference contribute both their reference key and
+// their date/amount/description key.
 func loadDuplicateSet(db *sql.DB) (map[string]bool, error) {
-	rows, err := db.Query("SELECT date_iso, amount, description, account_id FROM transactions")
+	rows, err := db.Query("SELECT date_iso, amount, description, account_id, external_ref FROM transactions")
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
 	set := make(map[string]bool)
 	for rows.Next() {
-		var dateISO, desc string
-		var amount float64
+		var dateISO, desc, externalRef string
+		var amount money
 		var accountID *int
-		if err := rows.Scan(&dateISO, &amount, &desc, &accountID); err != nil {
+		if err := rows.Scan(&dateISO, &amount, &desc, &accountID, &externalRef); err != nil {
 			return nil, err
 		}
 		set[duplicateKeyForAccount(dateISO, amount, desc, accountID)] = true
+		if strings.TrimSpace(externalRef) != "" {
+			set[duplicateRefKeyForAccount(externalRef, accountID)] = true
+		}
 	}
 	return set, rows.Err()
 }
 
-// loadFilesCmd returns a Bubble Tea command that scans basePath for CSV files.
-func loadFilesCmd(basePath string) tea.Cmd {
+// importableFileExts lists the file extensions offered by the import picker.
+var importableFileExts = []string{".csv", ".ofx", ".qfx", ".qif"}
+
+func isImportableFileName(name string) bool {
+	ext := strings.ToLower(filepath.Ext(name))
+	for _, want := range importableFileExts {
+		if ext == want {
+			return true
+		}
+	}
+	return false
+}
+
+// loadFilesCmd returns a Bubble Tea command that lists importable statement
+// files (CSV, OFX, QFX, QIF) under basePath, recursing and filtering by glob
+// per the inbox settings. CSV files are ranked against formats so the picker
+// can propose a format for each.
+func loadFilesCmd(basePath string, inbox importInboxSettings, formats []csvFormat) tea.Cmd {
 	return func() tea.Msg {
-		entries, err := os.ReadDir(basePath)
+		names, err := listInboxFiles(basePath, inbox.recursive, inbox.globs)
 		if err != nil {
 			return filesLoadedMsg{err: fmt.Errorf("read dir: %w", err)}
 		}
-		var names []string
-		for _, entry := range entries {
-			if entry.IsDir() {
-				continue
-			}
-			name := entry.Name()
-			if strings.HasSuffix(strings.ToLower(name), ".csv") {
-				names = append(names, name)
+		matches := make(map[string][]formatMatch)
+		for _, name := range names {
+			if strings.EqualFold(filepath.Ext(name), ".csv") && len(formats) > 0 {
+				local := filepath.FromSlash(name)
+				if ranked, rankErr := rankFormatsForFile(filepath.Join(basePath, local), filepath.Base(local), formats); rankErr == nil {
+					matches[name] = ranked
+				}
 			}
 		}
-		return filesLoadedMsg{files: names, err: nil}
+		return filesLoadedMsg{files: names, matches: matches, err: nil}
 	}
 }
 
@@ -724,9 +1328,3 @@ func parseDateISO(input, dateFormat string) (string, error) {
 	}
 	return parsed.Format("2006-01-02"), nil
 }
-
-// parseAmount converts a string like "1,234.56" to float64.
-func parseAmount(input string) (float64, error) {
-	input = strings.ReplaceAll(input, ",", "")
-	return strconv.ParseFloat(input, 64)
-}
@@ -1,8 +1,10 @@
 package main
 
 import (
+	"math"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -278,6 +280,55 @@ func TestImportCSVJoinsDescriptionColumns(t *testing.T) {
 	}
 }
 
+func TestParseCSVRecordDebitCreditColumns(t *testing.T) {
+	debitCol, creditCol := 2, 3
+	format := csvFormat{DateFormat: "2/01/2006", DateCol: 0, DescCol: 1, DebitCol: &debitCol, CreditCol: &creditCol, AmountStrip: ","}
+	minCols := csvMinCols(format)
+	if minCols != 4 {
+		t.Fatalf("minCols=%d want 4", minCols)
+	}
+
+	cases := []struct {
+		rec  []string
+		want money
+	}{
+		{[]string{"3/02/2026", "GROCER", "45.10", ""}, -4510},
+		{[]string{"4/02/2026", "SALARY", "", "1,500.00"}, 150000},
+		{[]string{"5/02/2026", "REFUND NET", "-10.00", "25.00"}, 1500},
+	}
+	for _, tc := range cases {
+		row, keep, err := parseCSVRecord(tc.rec, format, minCols)
+		if err != nil || !keep {
+			t.Fatalf("parseCSVRecord(%v) keep=%v err=%v", tc.rec, keep, err)
+		}
+		if row.amount != tc.want {
+			t.Fatalf("amount(%v)=%s want %s", tc.rec, row.amount, tc.want)
+		}
+	}
+
+	if _, keep, _ := parseCSVRecord([]string{"6/02/2026", "EMPTY", "", ""}, format, minCols); keep {
+		t.Fatal("row with no debit or credit should be skipped")
+	}
+	if _, issue := parseCSVRecordForPreview([]string{"6/02/2026", "EMPTY", "", ""}, format, minCols); issue == nil || issue.field != "amount" {
+		t.Fatalf("preview issue = %+v, want amount", issue)
+	}
+	if _, issue := parseCSVRecordForPreview([]string{"6/02/2026", "BAD", "x", ""}, format, minCols); issue == nil || issue.field != "amount" {
+		t.Fatalf("preview issue = %+v, want amount", issue)
+	}
+}
+
+func TestParseCSVRecordInvertSign(t *testing.T) {
+	format := testANZFormat()
+	format.InvertSign = true
+	row, _, err := parseCSVRecord([]string{"3/02/2026", "89.95", "CARD PURCHASE"}, format, csvMinCols(format))
+	if err != nil {
+		t.Fatalf("parseCSVRecord: %v", err)
+	}
+	if row.amount != -8995 {
+		t.Fatalf("amount=%s want -89.95", row.amount)
+	}
+}
+
 func TestImportCSVFileNotFound(t *testing.T) {
 	db, cleanup := testDB(t)
 	defer cleanup()
@@ -348,10 +399,10 @@ func TestImportCSVForAccountNoSignFlipByAccountType(t *testing.T) {
 	foundPositive := false
 	foundNegative := false
 	for _, row := range rows {
-		if row.amount == 203.92 {
+		if row.amount == 20392 {
 			foundPositive = true
 		}
-		if row.amount == -20.00 {
+		if row.amount == -2000 {
 			foundNegative = true
 		}
 	}
@@ -523,7 +574,7 @@ func TestScanDupesCmdDoesNotMutateDB(t *testing.T) {
 		t.Fatalf("write scan csv: %v", err)
 	}
 
-	msg := scanDupesCmd(db, file, dir, []csvFormat{format}, nil)()
+	msg := scanDupesCmd(db, file, dir, []csvFormat{format}, nil, defaultSettings().dupeMatch())()
 	done, ok := msg.(importPreviewMsg)
 	if !ok {
 		t.Fatalf("unexpected message type: %T", msg)
@@ -584,7 +635,7 @@ func TestScanDupesCmdCollectsParseErrors(t *testing.T) {
 		t.Fatalf("write csv: %v", err)
 	}
 
-	msg := scanDupesCmd(db, file, dir, []csvFormat{format}, nil)()
+	msg := scanDupesCmd(db, file, dir, []csvFormat{format}, nil, defaultSettings().dupeMatch())()
 	done, ok := msg.(importPreviewMsg)
 	if !ok {
 		t.Fatalf("unexpected message type: %T", msg)
@@ -612,6 +663,89 @@ func TestScanDupesCmdCollectsParseErrors(t *testing.T) {
 	}
 }
 
+func TestIngestSnapshotQuarantinesRejectsOnce(t *testing.T) {
+	db, cleanup := testDB(t)
+	defer cleanup()
+
+	if _, err := insertAccount(db, "ANZ", "debit", true); err != nil {
+		t.Fatalf("insertAccount: %v", err)
+	}
+	format := testANZFormat()
+	format.Account = "ANZ"
+	format.ImportPrefix = "anz"
+
+	dir := t.TempDir()
+	file := "ANZ-quarantine.csv"
+	if err := os.WriteFile(filepath.Join(dir, file), []byte("3/02/2026,-20.00,VALID\nnot-a-date,-11.00,BAD DATE\n"), 0o644); err != nil {
+		t.Fatalf("write csv: %v", err)
+	}
+
+	for i, want := range []int{1, 0} {
+		preview := scanDupesCmd(db, file, dir, []csvFormat{format}, nil, defaultSettings().dupeMatch())().(importPreviewMsg)
+		if preview.err != nil {
+			t.Fatalf("scan %d: %v", i, preview.err)
+		}
+		preview.snapshot.quarantine = true
+		done := ingestSnapshotCmd(db, preview.snapshot, true, false)().(ingestDoneMsg)
+		if done.err != nil || done.quarantined != want {
+			t.Fatalf("import %d: quarantined=%d err=%v, want %d", i, done.quarantined, done.err, want)
+		}
+	}
+	rejects, err := loadImportRejects(db)
+	if err != nil {
+		t.Fatalf("loadImportRejects: %v", err)
+	}
+	if len(rejects) != 1 || rejects[0].sourceLine != 2 {
+		t.Fatalf("rejects = %+v, want the bad line once", rejects)
+	}
+}
+
+func TestScanDupesCmdResolvesColumnsByHeader(t *testing.T) {
+	db, cleanup := testDB(t)
+	defer cleanup()
+
+	if _, err := insertAccount(db, "ANZ", "debit", true); err != nil {
+		t.Fatalf("insertAccount: %v", err)
+	}
+	format := csvFormat{
+		Name: "ANZ", Account: "ANZ", ImportPrefix: "anz",
+		DateFormat: "2/01/2006", HasHeader: true,
+		DateHeader: "Transaction Date", AmountHeader: "Amount", DescHeader: "Details",
+	}
+
+	dir := t.TempDir()
+	file := "anz-headers.csv"
+	csv := "\ufeffAmount,Balance,Details,Transaction Date\n-20.00,100.00,DAN MURPHYS,3/02/2026\n"
+	if err := os.WriteFile(filepath.Join(dir, file), []byte(csv), 0o644); err != nil {
+		t.Fatalf("write csv: %v", err)
+	}
+	done := scanDupesCmd(db, file, dir, []csvFormat{format}, nil, defaultSettings().dupeMatch())().(importPreviewMsg)
+	if done.err != nil || done.snapshot.errorCount != 0 || len(done.snapshot.rows) != 1 {
+		t.Fatalf("scan err=%v snapshot=%+v", done.err, done.snapshot)
+	}
+	row := done.snapshot.rows[0]
+	if row.dateISO != "2026-02-03" || row.amount != -2000 || row.description != "DAN MURPHYS" {
+		t.Fatalf("row = %+v", row)
+	}
+
+	// A renamed column blocks the import instead of mis-parsing rows.
+	csv = "Amount,Balance,Narrative,Transaction Date\n-20.00,100.00,DAN MURPHYS,3/02/2026\n"
+	if err := os.WriteFile(filepath.Join(dir, file), []byte(csv), 0o644); err != nil {
+		t.Fatalf("rewrite csv: %v", err)
+	}
+	done = scanDupesCmd(db, file, dir, []csvFormat{format}, nil, defaultSettings().dupeMatch())().(importPreviewMsg)
+	if done.err != nil {
+		t.Fatalf("scan err=%v", done.err)
+	}
+	if done.snapshot.errorCount != 1 || len(done.snapshot.rows) != 0 {
+		t.Fatalf("errors=%d rows=%d, want 1/0", done.snapshot.errorCount, len(done.snapshot.rows))
+	}
+	pe := done.snapshot.parseErrors[0]
+	if pe.field != "header" || !strings.Contains(pe.message, `"Details"`) {
+		t.Fatalf("parse error = %+v", pe)
+	}
+}
+
 func TestScanDupesCmdIgnoresBlankTrailingRows(t *testing.T) {
 	db, cleanup := testDB(t)
 	defer cleanup()
@@ -632,7 +766,7 @@ func TestScanDupesCmdIgnoresBlankTrailingRows(t *testing.T) {
 		t.Fatalf("write csv: %v", err)
 	}
 
-	msg := scanDupesCmd(db, file, dir, []csvFormat{format}, nil)()
+	msg := scanDupesCmd(db, file, dir, []csvFormat{format}, nil, defaultSettings().dupeMatch())()
 	done, ok := msg.(importPreviewMsg)
 	if !ok {
 		t.Fatalf("unexpected message type: %T", msg)
@@ -671,7 +805,7 @@ func TestScanDupesCmdDoesNotMarkWithinFileRepeatsAsDupesOnEmptyDB(t *testing.T)
 		t.Fatalf("write csv: %v", err)
 	}
 
-	msg := scanDupesCmd(db, file, dir, []csvFormat{format}, nil)()
+	msg := scanDupesCmd(db, file, dir, []csvFormat{format}, nil, defaultSettings().dupeMatch())()
 	done, ok := msg.(importPreviewMsg)
 	if !ok {
 		t.Fatalf("unexpected message type: %T", msg)
@@ -712,7 +846,7 @@ func TestIngestSnapshotCmdSkipDupesUsesSnapshotRows(t *testing.T) {
 		t.Fatalf("write csv: %v", err)
 	}
 
-	previewMsg := scanDupesCmd(db, file, dir, []csvFormat{format}, nil)()
+	previewMsg := scanDupesCmd(db, file, dir, []csvFormat{format}, nil, defaultSettings().dupeMatch())()
 	preview, ok := previewMsg.(importPreviewMsg)
 	if !ok {
 		t.Fatalf("unexpected message type: %T", previewMsg)
@@ -746,6 +880,83 @@ func TestIngestSnapshotCmdSkipDupesUsesSnapshotRows(t *testing.T) {
 	if len(rows) != 2 {
 		t.Fatalf("rows len=%d, want 2", len(rows))
 	}
+	imports, err := loadImports(db)
+	if err != nil || len(imports) != 1 || imports[0].rowCount != 1 {
+		t.Fatalf("imports = %+v, %v; want one record of 1 row", imports, err)
+	}
+	var linked int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM transactions WHERE import_id = ?`, imports[0].id).Scan(&linked); err != nil {
+		t.Fatalf("count linked: %v", err)
+	}
+	if linked != 1 {
+		t.Fatalf("linked rows = %d, want 1", linked)
+	}
+}
+
+func TestScanDupesCmdDedupesOnReferenceColumn(t *testing.T) {
+	db, cleanup := testDB(t)
+	defer cleanup()
+
+	accountID, err := insertAccount(db, "ANZ", "debit", true)
+	if err != nil {
+		t.Fatalf("insertAccount: %v", err)
+	}
+	refCol := 3
+	format := testANZFormat()
+	format.Account = "ANZ"
+	format.ImportPrefix = "anz"
+	format.DescJoin = false
+	format.RefCol = &refCol
+
+	seedPath := writeTestCSV(t, "3/02/2026,-4.50,COFFEE,TX100\n")
+	if _, _, err := importCSVForAccount(db, seedPath, format, &accountID, true); err != nil {
+		t.Fatalf("seed import: %v", err)
+	}
+
+	// Two identical same-day coffees are distinct purchases; only the one
+	// whose reference is already stored is a duplicate.
+	dir := t.TempDir()
+	file := "ANZ-refs.csv"
+	csv := "3/02/2026,-4.50,COFFEE,TX100\n3/02/2026,-4.50,COFFEE,TX101\n3/02/2026,-4.50,COFFEE,TX101\n"
+	if err := os.WriteFile(filepath.Join(dir, file), []byte(csv), 0o644); err != nil {
+		t.Fatalf("write csv: %v", err)
+	}
+	preview, ok := scanDupesCmd(db, file, dir, []csvFormat{format}, nil, defaultSettings().dupeMatch())().(importPreviewMsg)
+	if !ok || preview.err != nil || preview.snapshot == nil {
+		t.Fatalf("scanDupesCmd: ok=%v err=%v", ok, preview.err)
+	}
+	// The stored TX100 and the repeated TX101 are both duplicates, so the
+	// preview agrees with what the import skips.
+	if preview.snapshot.dupeCount != 2 {
+		t.Fatalf("dupeCount=%d, want 2", preview.snapshot.dupeCount)
+	}
+	if preview.snapshot.likelyDupeCount != 0 {
+		t.Fatalf("likelyDupeCount=%d, want 0 (references differ)", preview.snapshot.likelyDupeCount)
+	}
+
+	done, ok := ingestSnapshotCmd(db, preview.snapshot, true, true)().(ingestDoneMsg)
+	if !ok || done.err != nil {
+		t.Fatalf("ingestSnapshotCmd: ok=%v err=%v", ok, done.err)
+	}
+	if done.count != 1 || done.dupes != 2 {
+		t.Fatalf("count=%d dupes=%d, want 1 and 2", done.count, done.dupes)
+	}
+	var refs []string
+	dbRows, err := db.Query(`SELECT external_ref FROM transactions ORDER BY external_ref`)
+	if err != nil {
+		t.Fatalf("query refs: %v", err)
+	}
+	defer dbRows.Close()
+	for dbRows.Next() {
+		var ref string
+		if err := dbRows.Scan(&ref); err != nil {
+			t.Fatalf("scan ref: %v", err)
+		}
+		refs = append(refs, ref)
+	}
+	if strings.Join(refs, ",") != "TX100,TX101" {
+		t.Fatalf("stored refs = %v, want [TX100 TX101]", refs)
+	}
 }
 
 func TestIngestSnapshotCmdUsesLockedRules(t *testing.T) {
@@ -796,7 +1007,7 @@ func TestIngestSnapshotCmdUsesLockedRules(t *testing.T) {
 	savedFilters := []savedFilter{
 		{ID: "filter-grocery", Name: "Groceries", Expr: `desc:woolworths`},
 	}
-	previewMsg := scanDupesCmd(db, file, dir, []csvFormat{format}, savedFilters)()
+	previewMsg := scanDupesCmd(db, file, dir, []csvFormat{format}, savedFilters, defaultSettings().dupeMatch())()
 	preview, ok := previewMsg.(importPreviewMsg)
 	if !ok {
 		t.Fatalf("unexpected message type: %T", previewMsg)
@@ -868,7 +1079,7 @@ func TestIngestSnapshotCmdBlocksOnParseErrorsWithoutWrites(t *testing.T) {
 		t.Fatalf("write csv: %v", err)
 	}
 
-	previewMsg := scanDupesCmd(db, file, dir, []csvFormat{format}, nil)()
+	previewMsg := scanDupesCmd(db, file, dir, []csvFormat{format}, nil, defaultSettings().dupeMatch())()
 	preview, ok := previewMsg.(importPreviewMsg)
 	if !ok {
 		t.Fatalf("unexpected message type: %T", previewMsg)
@@ -960,6 +1171,19 @@ func TestParseAmount(t *testing.T) {
 		{"203.92", 203.92, false},
 		{"1,234.56", 1234.56, false},
 		{"-1,234.56", -1234.56, false},
+		{"(45.00)", -45, false},
+		{"45.00-", -45, false},
+		{"45.00 DR", -45, false},
+		{"45.00CR", 45, false},
+		{"$1,234.56", 1234.56, false},
+		{"-$12.50", -12.5, false},
+		{"AUD 12.50", 12.5, false},
+		{"12.50 €", 12.5, false},
+		{"+7", 7, false},
+		{"1.234,56", 0, true},
+		{"12.5.0", 0, true},
+		{"1e5", 0, true},
+		{"DR", 0, true},
 		{"abc", 0, true},
 		{"", 0, true},
 	}
@@ -981,6 +1205,69 @@ func TestParseAmount(t *testing.T) {
 	}
 }
 
+func TestParseAmountLocaleEU(t *testing.T) {
+	tests := []struct {
+		input string
+		want  float64
+	}{
+		{"1.234,56", 1234.56},
+		{"-1.234,56", -1234.56},
+		{"1 234,56 €", 1234.56},
+		{"(12,30)", -12.3},
+		{"12,30-", -12.3},
+		{"1.234", 1234},
+	}
+	for _, tt := range tests {
+		got, err := parseAmountLocale(tt.input, numberLocaleEU)
+		if err != nil {
+			t.Errorf("parseAmountLocale(%q, eu) unexpected error: %v", tt.input, err)
+			continue
+		}
+		if math.Abs(got-tt.want) > 1e-9 {
+			t.Errorf("parseAmountLocale(%q, eu) = %f, want %f", tt.input, got, tt.want)
+		}
+	}
+	if _, err := parseAmountLocale("1,234.56", numberLocaleEU); err == nil {
+		t.Error("expected en-style amount to fail under the eu locale")
+	}
+}
+
+func TestScanDupesCmdReportsLocaleAmountFailuresAsParseIssues(t *testing.T) {
+	db, cleanup := testDB(t)
+	defer cleanup()
+	if _, err := insertAccount(db, "ING", "debit", true); err != nil {
+		t.Fatalf("insertAccount: %v", err)
+	}
+	format := csvFormat{
+		Name:         "ING",
+		Account:      "ING",
+		ImportPrefix: "ing",
+		DateFormat:   "02.01.2006",
+		Delimiter:    ";",
+		DateCol:      0,
+		AmountCol:    1,
+		DescCol:      2,
+		NumberLocale: numberLocaleEU,
+	}
+	dir := t.TempDir()
+	file := "ING-feb.csv"
+	csv := "03.02.2026;-1.234,56;MIETE\n04.02.2026;12,30 DR;BAECKEREI\n05.02.2026;n/a;BROKEN\n"
+	if err := os.WriteFile(filepath.Join(dir, file), []byte(csv), 0o644); err != nil {
+		t.Fatalf("write csv: %v", err)
+	}
+	preview, ok := scanDupesCmd(db, file, dir, []csvFormat{format}, nil, defaultSettings().dupeMatch())().(importPreviewMsg)
+	if !ok || preview.err != nil || preview.snapshot == nil {
+		t.Fatalf("scanDupesCmd: ok=%v err=%v", ok, preview.err)
+	}
+	snap := preview.snapshot
+	if len(snap.rows) != 2 || snap.rows[0].amount != -123456 || snap.rows[1].amount != -1230 {
+		t.Fatalf("rows = %+v", snap.rows)
+	}
+	if snap.errorCount != 1 || snap.parseErrors[0].field != "amount" || snap.parseErrors[0].sourceLine != 3 {
+		t.Fatalf("parse errors = %+v", snap.parseErrors)
+	}
+}
+
 // ---------------------------------------------------------------------------
 // Format detection tests
 // ---------------------------------------------------------------------------
@@ -1033,3 +1320,60 @@ func TestDetectFormatSelectsCorrect(t *testing.T) {
 		t.Errorf("expected CBA format, got %v", f)
 	}
 }
+
+func TestImportProvenanceFlagsRepeatAndCoveredFiles(t *testing.T) {
+	db, cleanup := testDB(t)
+	defer cleanup()
+
+	if _, err := insertAccount(db, "ANZ", "debit", true); err != nil {
+		t.Fatalf("insertAccount: %v", err)
+	}
+	format := testANZFormat()
+	format.Account = "ANZ"
+	format.ImportPrefix = "anz"
+	dir := t.TempDir()
+	scan := func(name, content string) *importPreviewSnapshot {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("write csv: %v", err)
+		}
+		msg, ok := scanDupesCmd(db, name, dir, []csvFormat{format}, nil, defaultSettings().dupeMatch())().(importPreviewMsg)
+		if !ok || msg.err != nil {
+			t.Fatalf("scanDupesCmd(%s): ok=%v err=%v", name, ok, msg.err)
+		}
+		return msg.snapshot
+	}
+
+	jan := "1/01/2026,-5.00,CAFE\n31/01/2026,-9.00,BAKERY\n"
+	first := scan("anz-jan.csv", jan)
+	if first.formatName != "ANZ" || len(first.contentHash) != 64 || first.fileSize != int64(len(jan)) {
+		t.Fatalf("provenance = %q/%q/%d", first.formatName, first.contentHash, first.fileSize)
+	}
+	if first.dateFrom != "2026-01-01" || first.dateTo != "2026-01-31" || first.needsReimportConfirm() {
+		t.Fatalf("range %s..%s confirm=%v, want January and no warning", first.dateFrom, first.dateTo, first.needsReimportConfirm())
+	}
+	if done := ingestSnapshotCmd(db, first, true, false)().(ingestDoneMsg); done.err != nil {
+		t.Fatalf("ingest: %v", done.err)
+	}
+	imports, err := loadImports(db)
+	if err != nil {
+		t.Fatalf("loadImports: %v", err)
+	}
+	if len(imports) != 1 || imports[0].contentHash != first.contentHash || imports[0].accountName != "ANZ" ||
+		imports[0].format != "ANZ" || imports[0].dateFrom != "2026-01-01" || imports[0].dateTo != "2026-01-31" {
+		t.Fatalf("import record = %+v", imports)
+	}
+
+	again := scan("anz-jan-copy.csv", jan)
+	if len(again.sameFileImports) != 1 || len(again.coveringImports) != 0 || !again.needsReimportConfirm() {
+		t.Fatalf("same file: %d same, %d covering", len(again.sameFileImports), len(again.coveringImports))
+	}
+	inside := scan("anz-mid.csv", "10/01/2026,-7.00,NEWSAGENT\n20/01/2026,-3.00,CAFE\n")
+	if len(inside.sameFileImports) != 0 || len(inside.coveringImports) != 1 || inside.coveringImports[0].filename != "anz-jan.csv" {
+		t.Fatalf("covered range: %d same, %d covering", len(inside.sameFileImports), len(inside.coveringImports))
+	}
+	straddle := scan("anz-feb.csv", "20/01/2026,-3.00,CAFE\n5/02/2026,-4.00,CAFE\n")
+	if straddle.needsReimportConfirm() {
+		t.Fatal("a partially overlapping range should not warn")
+	}
+}
@@ -0,0 +1,460 @@
+package main
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// ledgerJournalLimit is how many operations undo can walk back through.
+const ledgerJournalLimit = 200
+
+// ledgerQueryChunk bounds the ids bound into one IN (...) list while
+// capturing journal state, keeping whole-ledger rule runs under SQLite's
+// variable limit.
+const ledgerQueryChunk = 500
+
+// ledgerTxnState is the editable state of one transaction: its category,
+// notes, tags and allocations. Undo and redo put it back wholesale.
+type ledgerTxnState struct {
+	ID          int                `json:"id"`
+	CategoryID  *int               `json:"category_id"`
+	Notes       string             `json:"notes"`
+	EditedAt    string             `json:"edited_at"`
+	TagIDs      []int              `json:"tag_ids"`
+	Allocations []ledgerAllocState `json:"allocations"`
+}
+
+type ledgerAllocState struct {
+	ID         int    `json:"id"`
+	Amount     money  `json:"amount"`
+	CategoryID *int   `json:"category_id"`
+	Note       string `json:"note"`
+	CreatedAt  string `json:"created_at"`
+	UpdatedAt  string `json:"updated_at"`
+	TagIDs     []int  `json:"tag_ids"`
+}
+
+// sameContent compares two states ignoring edit timestamps, so an edit that
+// sets a row to what it already was isn't journaled.
+func (s ledgerTxnState) sameContent(o ledgerTxnState) bool {
+	s.EditedAt, o.EditedAt = "", ""
+	s.Allocations = stripAllocTimestamps(s.Allocations)
+	o.Allocations = stripAllocTimestamps(o.Allocations)
+	return reflect.DeepEqual(s, o)
+}
+
+func stripAllocTimestamps(allocs []ledgerAllocState) []ledgerAllocState {
+	out := make([]ledgerAllocState, len(allocs))
+	for i, a := range allocs {
+		a.UpdatedAt = ""
+		out[i] = a
+	}
+	return out
+}
+
+// ledgerScope names the rows an edit may touch. Allocations are journaled
+// through their parent transaction.
+type ledgerScope struct {
+	txnIDs        []int
+	allocationIDs []int
+}
+
+// resolveTx returns the transaction ids in scope, sorted and de-duplicated.
+func (s ledgerScope) resolveTx(tx *sql.Tx) ([]int, error) {
+	ids := append([]int(nil), s.txnIDs...)
+	allocs := normalizeIDList(s.allocationIDs)
+	for start := 0; start < len(allocs); start += ledgerQueryChunk {
+		chunk := allocs[start:min(start+ledgerQueryChunk, len(allocs))]
+		placeholders, args := inPlaceholders(chunk)
+		rows, err := tx.Query(fmt.Sprintf(`SELECT DISTINCT parent_txn_id FROM transaction_allocations WHERE id IN (%s)`, placeholders), args...)
+		if err != nil {
+			return nil, fmt.Errorf("query allocation parents: %w", err)
+		}
+		for rows.Next() {
+			var id int
+			if err := rows.Scan(&id); err != nil {
+				rows.Close()
+				return nil, fmt.Errorf("scan allocation parent: %w", err)
+			}
+			ids = append(ids, id)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return nil, err
+		}
+	}
+	return normalizeIDList(ids), nil
+}
+
+func inPlaceholders(ids []int) (string, []any) {
+	placeholders := make([]string, len(ids))
+	args := make([]any, len(ids))
+	for i, id := range ids {
+		placeholders[i] = "?"
+		args[i] = id
+	}
+	return strings.Join(placeholders, ","), args
+}
+
+// editLedger runs edit in one database transaction and journals the before
+// and after state of every transaction in scope that it changed, so the
+// whole edit can be undone and redone as a single operation. The changes
+// are also recorded in each transaction's history as manual edits.
+func editLedger(db *sql.DB, label string, scope ledgerScope, edit func(tx *sql.Tx) error) error {
+	return editLedgerBy(db, label, fixedSource(manualChange), scope, edit)
+}
+
+// editLedgerBy is editLedger with history attributed by source.
+func editLedgerBy(db *sql.DB, label string, source sourceResolver, scope ledgerScope, edit func(tx *sql.Tx) error) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("begin %s: %w", label, err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	txnIDs, err := scope.resolveTx(tx)
+	if err != nil {
+		return err
+	}
+	before, err := captureLedgerStateTx(tx, txnIDs)
+	if err != nil {
+		return err
+	}
+	if err := edit(tx); err != nil {
+		return err
+	}
+	after, err := captureLedgerStateTx(tx, txnIDs)
+	if err != nil {
+		return err
+	}
+	if err := journalLedgerEditTx(tx, label, before, after); err != nil {
+		return err
+	}
+	if err := recordLedgerHistoryTx(tx, before, after, source); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit %s: %w", label, err)
+	}
+	return nil
+}
+
+// captureLedgerStateTx loads the editable state of the given transactions,
+// keyed by id. Missing transactions are left out.
+func captureLedgerStateTx(tx *sql.Tx, txnIDs []int) (map[int]*ledgerTxnState, error) {
+	states := make(map[int]*ledgerTxnState, len(txnIDs))
+	for start := 0; start < len(txnIDs); start += ledgerQueryChunk {
+		chunk := txnIDs[start:min(start+ledgerQueryChunk, len(txnIDs))]
+		if err := captureLedgerChunkTx(tx, chunk, states); err != nil {
+			return nil, err
+		}
+	}
+	return states, nil
+}
+
+func captureLedgerChunkTx(tx *sql.Tx, txnIDs []int, states map[int]*ledgerTxnState) error {
+	placeholders, args := inPlaceholders(txnIDs)
+	rows, err := tx.Query(fmt.Sprintf(`
+		SELECT id, category_id, notes, edited_at FROM transactions WHERE id IN (%s)
+	`, placeholders), args...)
+	if err != nil {
+		return fmt.Errorf("query journal transactions: %w", err)
+	}
+	for rows.Next() {
+		s := &ledgerTxnState{}
+		if err := rows.Scan(&s.ID, &s.CategoryID, &s.Notes, &s.EditedAt); err != nil {
+			rows.Close()
+			return fmt.Errorf("scan journal transaction: %w", err)
+		}
+		states[s.ID] = s
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	rows, err = tx.Query(fmt.Sprintf(`
+		SELECT transaction_id, tag_id FROM transaction_tags
+		WHERE transaction_id IN (%s) ORDER BY transaction_id, tag_id
+	`, placeholders), args...)
+	if err != nil {
+		return fmt.Errorf("query journal transaction tags: %w", err)
+	}
+	for rows.Next() {
+		var txnID, tagID int
+		if err := rows.Scan(&txnID, &tagID); err != nil {
+			rows.Close()
+			return fmt.Errorf("scan journal transaction tag: %w", err)
+		}
+		if s := states[txnID]; s != nil {
+			s.TagIDs = append(s.TagIDs, tagID)
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	rows, err = tx.Query(fmt.Sprintf(`
+		SELECT id, parent_txn_id, amount, category_id, note, created_at, updated_at
+		FROM transaction_allocations
+		WHERE parent_txn_id IN (%s) ORDER BY parent_txn_id, id
+	`, placeholders), args...)
+	if err != nil {
+		return fmt.Errorf("query journal allocations: %w", err)
+	}
+	allocParent := make(map[int]int)
+	for rows.Next() {
+		var a ledgerAllocState
+		var parentID int
+		if err := rows.Scan(&a.ID, &parentID, &a.Amount, &a.CategoryID, &a.Note, &a.CreatedAt, &a.UpdatedAt); err != nil {
+			rows.Close()
+			return fmt.Errorf("scan journal allocation: %w", err)
+		}
+		if s := states[parentID]; s != nil {
+			s.Allocations = append(s.Allocations, a)
+			allocParent[a.ID] = parentID
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+	if len(allocParent) == 0 {
+		return nil
+	}
+
+	rows, err = tx.Query(fmt.Sprintf(`
+		SELECT at.allocation_id, at.tag_id
+		FROM transaction_allocation_tags at
+		JOIN transaction_allocations a ON a.id = at.allocation_id
+		WHERE a.parent_txn_id IN (%s) ORDER BY at.allocation_id, at.tag_id
+	`, placeholders), args...)
+	if err != nil {
+		return fmt.Errorf("query journal allocation tags: %w", err)
+	}
+	defer rows.Close()
+	for rows.Next() {
+		var allocID, tagID int
+		if err := rows.Scan(&allocID, &tagID); err != nil {
+			return fmt.Errorf("scan journal allocation tag: %w", err)
+		}
+		s := states[allocParent[allocID]]
+		if s == nil {
+			continue
+		}
+		for i := range s.Allocations {
+			if s.Allocations[i].ID == allocID {
+				s.Allocations[i].TagIDs = append(s.Allocations[i].TagIDs, tagID)
+				break
+			}
+		}
+	}
+	return rows.Err()
+}
+
+// journalLedgerEditTx records the rows an edit changed. Recording a new
+// operation drops anything that was undone, and the journal keeps only the
+// latest ledgerJournalLimit operations.
+func journalLedgerEditTx(tx *sql.Tx, label string, before, after map[int]*ledgerTxnState) error {
+	var beforeRows, afterRows []ledgerTxnState
+	ids := make([]int, 0, len(before))
+	for id := range before {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	for _, id := range ids {
+		b, a := before[id], after[id]
+		if a == nil || b.sameContent(*a) {
+			continue
+		}
+		beforeRows = append(beforeRows, *b)
+		afterRows = append(afterRows, *a)
+	}
+	if len(beforeRows) == 0 {
+		return nil
+	}
+	beforeJSON, err := json.Marshal(beforeRows)
+	if err != nil {
+		return fmt.Errorf("encode journal state: %w", err)
+	}
+	afterJSON, err := json.Marshal(afterRows)
+	if err != nil {
+		return fmt.Errorf("encode journal state: %w", err)
+	}
+	if _, err := tx.Exec(`DELETE FROM ledger_journal WHERE undone = 1`); err != nil {
+		return fmt.Errorf("clear redo history: %w", err)
+	}
+	if _, err := tx.Exec(`
+		INSERT INTO ledger_journal (label, before_state, after_state, row_count)
+		VALUES (?, ?, ?, ?)
+	`, label, string(beforeJSON), string(afterJSON), len(beforeRows)); err != nil {
+		return fmt.Errorf("journal %s: %w", label, err)
+	}
+	if _, err := tx.Exec(`
+		DELETE FROM ledger_journal
+		WHERE id NOT IN (SELECT id FROM ledger_journal ORDER BY id DESC LIMIT ?)
+	`, ledgerJournalLimit); err != nil {
+		return fmt.Errorf("trim journal: %w", err)
+	}
+	return nil
+}
+
+// restoreLedgerStateTx writes journaled states back. Transactions deleted
+// since the edit are skipped; categories and tags deleted since are dropped
+// rather than failing the restore.
+func restoreLedgerStateTx(tx *sql.Tx, states []ledgerTxnState) error {
+	for _, s := range states {
+		res, err := tx.Exec(`
+			UPDATE transactions
+			SET category_id = (SELECT id FROM categories WHERE id = ?), notes = ?, edited_at = ?
+			WHERE id = ?
+		`, s.CategoryID, s.Notes, s.EditedAt, s.ID)
+		if err != nil {
+			return fmt.Errorf("restore txn %d: %w", s.ID, err)
+		}
+		if n, _ := res.RowsAffected(); n == 0 {
+			continue
+		}
+		if _, err := tx.Exec(`DELETE FROM transaction_tags WHERE transaction_id = ?`, s.ID); err != nil {
+			return fmt.Errorf("clear txn %d tags: %w", s.ID, err)
+		}
+		for _, tagID := range s.TagIDs {
+			if _, err := tx.Exec(`
+				INSERT INTO transaction_tags (transaction_id, tag_id)
+				SELECT ?, id FROM tags WHERE id = ?
+			`, s.ID, tagID); err != nil {
+				return fmt.Errorf("restore txn %d tag %d: %w", s.ID, tagID, err)
+			}
+		}
+		if _, err := tx.Exec(`
+			DELETE FROM transaction_allocation_tags
+			WHERE allocation_id IN (SELECT id FROM transaction_allocations WHERE parent_txn_id = ?)
+		`, s.ID); err != nil {
+			return fmt.Errorf("clear txn %d allocation tags: %w", s.ID, err)
+		}
+		if _, err := tx.Exec(`DELETE FROM transaction_allocations WHERE parent_txn_id = ?`, s.ID); err != nil {
+			return fmt.Errorf("clear txn %d allocations: %w", s.ID, err)
+		}
+		for _, a := range s.Allocations {
+			if _, err := tx.Exec(`
+				INSERT INTO transaction_allocations (id, parent_txn_id, amount, category_id, note, created_at, updated_at)
+				VALUES (?, ?, ?, (SELECT id FROM categories WHERE id = ?), ?, ?, ?)
+			`, a.ID, s.ID, a.Amount, a.CategoryID, a.Note, a.CreatedAt, a.UpdatedAt); err != nil {
+				return fmt.Errorf("restore allocation %d: %w", a.ID, err)
+			}
+			for _, tagID := range a.TagIDs {
+				if _, err := tx.Exec(`
+					INSERT INTO transaction_allocation_tags (allocation_id, tag_id)
+					SELECT ?, id FROM tags WHERE id = ?
+				`, a.ID, tagID); err != nil {
+					return fmt.Errorf("restore allocation %d tag %d: %w", a.ID, tagID, err)
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// ledgerJournalEntry is an operation reversed or replayed by undo/redo.
+type ledgerJournalEntry struct {
+	id       int
+	label    string
+	rowCount int
+}
+
+// undoLedgerEdit reverses the latest operation that hasn't been undone.
+// It returns nil when there is nothing to undo.
+func undoLedgerEdit(db *sql.DB) (*ledgerJournalEntry, error) {
+	return stepLedgerJournal(db, true)
+}
+
+// redoLedgerEdit replays the operation undone most recently. It returns nil
+// when there is nothing to redo.
+func redoLedgerEdit(db *sql.DB) (*ledgerJournalEntry, error) {
+	return stepLedgerJournal(db, false)
+}
+
+// stepLedgerJournal undoes the newest live entry (restoring its before
+// state) or redoes the oldest undone one (restoring its after state).
+func stepLedgerJournal(db *sql.DB, undo bool) (*ledgerJournalEntry, error) {
+	verb, query, undone := "redo", `
+		SELECT id, label, row_count, after_state FROM ledger_journal
+		WHERE undone = 1 ORDER BY id ASC LIMIT 1
+	`, 0
+	if undo {
+		verb, query, undone = "undo", `
+			SELECT id, label, row_count, before_state FROM ledger_journal
+			WHERE undone = 0 ORDER BY id DESC LIMIT 1
+		`, 1
+	}
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("begin %s: %w", verb, err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+
+	var entry ledgerJournalEntry
+	var raw string
+	if err := tx.QueryRow(query).Scan(&entry.id, &entry.label, &entry.rowCount, &raw); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("load journal entry: %w", err)
+	}
+	var states []ledgerTxnState
+	if err := json.Unmarshal([]byte(raw), &states); err != nil {
+		return nil, fmt.Errorf("decode journal entry %d: %w", entry.id, err)
+	}
+	txnIDs := make([]int, len(states))
+	for i, st := range states {
+		txnIDs[i] = st.ID
+	}
+	before, err := captureLedgerStateTx(tx, txnIDs)
+	if err != nil {
+		return nil, err
+	}
+	if err := restoreLedgerStateTx(tx, states); err != nil {
+		return nil, err
+	}
+	after, err := captureLedgerStateTx(tx, txnIDs)
+	if err != nil {
+		return nil, err
+	}
+	if err := recordLedgerHistoryTx(tx, before, after, fixedSource(changeSource{kind: verb})); err != nil {
+		return nil, err
+	}
+	if _, err := tx.Exec(`UPDATE ledger_journal SET undone = ? WHERE id = ?`, undone, entry.id); err != nil {
+		return nil, fmt.Errorf("mark journal entry %d: %w", entry.id, err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("commit %s: %w", verb, err)
+	}
+	return &entry, nil
+}
+
+type ledgerStepMsg struct {
+	undo  bool
+	entry *ledgerJournalEntry // nil when there was nothing to step
+	err   error
+}
+
+func undoLedgerCmd(db *sql.DB) tea.Cmd {
+	return func() tea.Msg {
+		entry, err := undoLedgerEdit(db)
+		return ledgerStepMsg{undo: true, entry: entry, err: err}
+	}
+}
+
+func redoLedgerCmd(db *sql.DB) tea.Cmd {
+	return func() tea.Msg {
+		entry, err := redoLedgerEdit(db)
+		return ledgerStepMsg{entry: entry, err: err}
+	}
+}
@@ -0,0 +1,233 @@
+package main
+
+import (
+	"database/sql"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+func seedJournalTxns(t *testing.T, db *sql.DB) []int {
+	t.Helper()
+	if _, err := db.Exec(`
+		INSERT INTO transactions (date_raw, date_iso, amount, description, notes)
+		VALUES ('03/02/2026', '2026-02-03', -1000, 'WOOLWORTHS', ''),
+		       ('04/02/2026', '2026-02-04', -2000, 'COLES', ''),
+		       ('05/02/2026', '2026-02-05', -3000, 'NETFLIX', '')
+	`); err != nil {
+		t.Fatalf("insert txns: %v", err)
+	}
+	return []int{1, 2, 3}
+}
+
+func txnCategoryIDs(t *testing.T, db *sql.DB) map[int]*int {
+	t.Helper()
+	rows, err := loadRows(db)
+	if err != nil {
+		t.Fatalf("loadRows: %v", err)
+	}
+	out := make(map[int]*int, len(rows))
+	for _, r := range rows {
+		out[r.id] = r.categoryID
+	}
+	return out
+}
+
+func TestUndoRedoBulkCategoryEdit(t *testing.T) {
+	db, cleanup := testDB(t)
+	defer cleanup()
+	ids := seedJournalTxns(t, db)
+	dining, err := insertCategory(db, "Dining Out", "#fff")
+	if err != nil {
+		t.Fatalf("insertCategory: %v", err)
+	}
+	if err := updateTransactionCategory(db, ids[0], &dining); err != nil {
+		t.Fatalf("updateTransactionCategory: %v", err)
+	}
+	groceries := 2
+	if n, err := updateTransactionsCategory(db, ids, &groceries); err != nil || n != 3 {
+		t.Fatalf("updateTransactionsCategory = %d (%v)", n, err)
+	}
+
+	entry, err := undoLedgerEdit(db)
+	if err != nil || entry == nil || entry.label != "set category" || entry.rowCount != 3 {
+		t.Fatalf("undo = %+v (%v), want set category on 3 rows", entry, err)
+	}
+	cats := txnCategoryIDs(t, db)
+	if cats[ids[0]] == nil || *cats[ids[0]] != dining || cats[ids[1]] != nil || cats[ids[2]] != nil {
+		t.Fatalf("after undo categories = %v, want only the first row in Dining Out", cats)
+	}
+
+	if entry, err := redoLedgerEdit(db); err != nil || entry == nil {
+		t.Fatalf("redo = %+v (%v)", entry, err)
+	}
+	for id, cat := range txnCategoryIDs(t, db) {
+		if cat == nil || *cat != groceries {
+			t.Fatalf("after redo txn %d category = %v, want %d", id, cat, groceries)
+		}
+	}
+	if entry, err := redoLedgerEdit(db); err != nil || entry != nil {
+		t.Fatalf("second redo = %+v (%v), want nothing to redo", entry, err)
+	}
+
+	// Undo twice, then a fresh edit drops the redo history.
+	for i := 0; i < 2; i++ {
+		if _, err := undoLedgerEdit(db); err != nil {
+			t.Fatalf("undo %d: %v", i, err)
+		}
+	}
+	if err := updateTransactionNotes(db, ids[2], "streaming"); err != nil {
+		t.Fatalf("updateTransactionNotes: %v", err)
+	}
+	if entry, err := redoLedgerEdit(db); err != nil || entry != nil {
+		t.Fatalf("redo after new edit = %+v (%v), want nothing to redo", entry, err)
+	}
+}
+
+func TestUndoNoOpEditIsNotJournaled(t *testing.T) {
+	db, cleanup := testDB(t)
+	defer cleanup()
+	ids := seedJournalTxns(t, db)
+	if _, err := updateTransactionsCategory(db, ids, nil); err != nil {
+		t.Fatalf("updateTransactionsCategory: %v", err)
+	}
+	if entry, err := undoLedgerEdit(db); err != nil || entry != nil {
+		t.Fatalf("undo = %+v (%v), want nothing to undo", entry, err)
+	}
+}
+
+func TestUndoRestoresDeletedAllocationWithTags(t *testing.T) {
+	db, cleanup := testDB(t)
+	defer cleanup()
+	ids := seedJournalTxns(t, db)
+	tagID, err := insertTag(db, "trip", "", nil)
+	if err != nil {
+		t.Fatalf("insertTag: %v", err)
+	}
+	allocID, err := insertTransactionAllocation(db, ids[1], 500, nil, "share", []int{tagID})
+	if err != nil {
+		t.Fatalf("insertTransactionAllocation: %v", err)
+	}
+	if err := deleteTransactionAllocation(db, allocID); err != nil {
+		t.Fatalf("deleteTransactionAllocation: %v", err)
+	}
+
+	if entry, err := undoLedgerEdit(db); err != nil || entry == nil || entry.label != "delete allocation" {
+		t.Fatalf("undo = %+v (%v), want delete allocation", entry, err)
+	}
+	allocs, err := loadTransactionAllocationsForParents(db, []int{ids[1]})
+	if err != nil {
+		t.Fatalf("loadTransactionAllocationsForParents: %v", err)
+	}
+	if len(allocs) != 1 || allocs[0].id != allocID || allocs[0].amount != -500 || allocs[0].note != "share" {
+		t.Fatalf("restored allocations = %+v, want id %d for -5.00", allocs, allocID)
+	}
+	allocTags, err := loadTransactionAllocationTagsByAllocationIDs(db, []int{allocID})
+	if err != nil {
+		t.Fatalf("loadTransactionAllocationTagsByAllocationIDs: %v", err)
+	}
+	if len(allocTags[allocID]) != 1 || allocTags[allocID][0].id != tagID {
+		t.Fatalf("restored allocation tags = %+v, want trip", allocTags[allocID])
+	}
+
+	// Undoing the insert removes the allocation again.
+	if entry, err := undoLedgerEdit(db); err != nil || entry == nil || entry.label != "add allocation" {
+		t.Fatalf("undo = %+v (%v), want add allocation", entry, err)
+	}
+	if allocs, _ := loadTransactionAllocationsForParents(db, []int{ids[1]}); len(allocs) != 0 {
+		t.Fatalf("allocations after undoing insert = %+v, want none", allocs)
+	}
+}
+
+func TestUndoMixedRowTargetsIsOneOperation(t *testing.T) {
+	db, cleanup := testDB(t)
+	defer cleanup()
+	ids := seedJournalTxns(t, db)
+	allocID, err := insertTransactionAllocation(db, ids[2], 1000, nil, "", nil)
+	if err != nil {
+		t.Fatalf("insertTransactionAllocation: %v", err)
+	}
+	tagID, err := insertTag(db, "review", "", nil)
+	if err != nil {
+		t.Fatalf("insertTag: %v", err)
+	}
+	if n, err := addTagsToRowTargets(db, []int{ids[0], ids[1], -allocID}, []int{tagID}); err != nil || n != 3 {
+		t.Fatalf("addTagsToRowTargets = %d (%v), want 3", n, err)
+	}
+
+	entry, err := undoLedgerEdit(db)
+	if err != nil || entry == nil || entry.label != "add tags" || entry.rowCount != 3 {
+		t.Fatalf("undo = %+v (%v), want add tags on 3 transactions", entry, err)
+	}
+	txnTags, err := loadTransactionTags(db)
+	if err != nil {
+		t.Fatalf("loadTransactionTags: %v", err)
+	}
+	allocTags, err := loadTransactionAllocationTags(db)
+	if err != nil {
+		t.Fatalf("loadTransactionAllocationTags: %v", err)
+	}
+	if len(txnTags[ids[0]])+len(txnTags[ids[1]])+len(allocTags[allocID]) != 0 {
+		t.Fatalf("tags survived undo: txn=%v alloc=%v", txnTags, allocTags)
+	}
+	// The allocation itself predates the tag edit and stays.
+	if allocs, _ := loadTransactionAllocationsForParents(db, []int{ids[2]}); len(allocs) != 1 {
+		t.Fatalf("allocations = %+v, want the original allocation kept", allocs)
+	}
+}
+
+func TestUndoRuleApplyAcrossScope(t *testing.T) {
+	db, cleanup := testDB(t)
+	defer cleanup()
+	seedJournalTxns(t, db)
+	groceries := 2
+	if _, err := insertRuleV2(db, ruleV2{name: "Supermarkets", savedFilterID: "super", setCategoryID: &groceries, enabled: true}); err != nil {
+		t.Fatalf("insertRuleV2: %v", err)
+	}
+	rules, err := loadRulesV2(db)
+	if err != nil {
+		t.Fatalf("loadRulesV2: %v", err)
+	}
+	filters := []savedFilter{{ID: "super", Name: "Supermarkets", Expr: `desc:woolworths OR desc:coles`}}
+	updated, _, _, _, err := applyRulesV2ToScope(db, rules, loadTransactionTagsOrEmpty(db), nil, filters)
+	if err != nil || updated != 2 {
+		t.Fatalf("applyRulesV2ToScope updated %d (%v), want 2", updated, err)
+	}
+
+	entry, err := undoLedgerEdit(db)
+	if err != nil || entry == nil || entry.label != "apply rules" || entry.rowCount != 2 {
+		t.Fatalf("undo = %+v (%v), want apply rules on 2 transactions", entry, err)
+	}
+	for id, cat := range txnCategoryIDs(t, db) {
+		if cat != nil {
+			t.Fatalf("txn %d category = %d after undo, want none", id, *cat)
+		}
+	}
+}
+
+func TestUndoKeyReversesQuickCategory(t *testing.T) {
+	m, cleanup := testPhase5Model(t)
+	defer cleanup()
+
+	targetID := m.getFilteredRows()[m.cursor].id
+	groceries := 2
+	if err := updateTransactionCategory(m.db, targetID, &groceries); err != nil {
+		t.Fatalf("updateTransactionCategory: %v", err)
+	}
+
+	next, cmd := m.Update(tea.KeyMsg{Type: tea.KeyCtrlZ})
+	got := runCmdUpdate(t, next.(model), cmd)
+	if !strings.Contains(got.status, "Undo: set category (1 transactions)") {
+		t.Fatalf("status = %q, want undo summary", got.status)
+	}
+	if cat := txnCategoryIDs(t, got.db)[targetID]; cat != nil {
+		t.Fatalf("category after ctrl+z = %d, want none", *cat)
+	}
+
+	next, cmd = got.Update(tea.KeyMsg{Type: tea.KeyCtrlY})
+	got = runCmdUpdate(t, next.(model), cmd)
+	if cat := txnCategoryIDs(t, got.db)[targetID]; cat == nil || *cat != groceries {
+		t.Fatalf("category after ctrl+y = %v, want %d", cat, groceries)
+	}
+}
@@ -43,9 +43,12 @@ const (
 	scopeTagPicker                = "tag_picker"
 	scopeQuickOffset              = "quick_offset"
 	scopeFilterApplyPicker        = "filter_apply_picker"
+	scopeLedgerPicker             = "ledger_picker"
 	scopeFilterEdit               = "filter_edit"
 	scopeFilePicker               = "file_picker"
+	scopeFormatWizard             = "format_wizard"
 	scopeImportPreview            = "import_preview"
+	scopeImportRejects            = "import_rejects"
 	scopeFilterInput              = "filter_input"
 	scopeSettingsNav              = "settings_nav"
 	scopeSettingsModeCat          = "settings_mode_cat"
@@ -61,6 +64,7 @@ const (
 	scopeSettingsActiveChart      = "settings_active_chart"
 	scopeSettingsActiveDBImport   = "settings_active_db_import"
 	scopeSettingsActiveImportHist = "settings_active_import_history"
+	scopeSettingsActiveBackups    = "settings_active_backups"
 )
 
 const (
@@ -103,6 +107,14 @@ const (
 	actionClearDB                  Action = "clear_db"
 	actionImport                   Action = "import"
 	actionResetKeybindings         Action = "reset_keybindings"
+	actionLoadFXRates              Action = "load_fx_rates"
+	actionRerunMerchants           Action = "rerun_merchants"
+	actionUndo                     Action = "undo"
+	actionRedo                     Action = "redo"
+	actionReviewRejects            Action = "review_rejects"
+	actionQuarantineRejects        Action = "quarantine_rejects"
+	actionRestoreBackup            Action = "restore_backup"
+	actionSwitchLedger             Action = "switch_ledger"
 	actionFocusAccounts            Action = "focus_accounts"
 	actionJumpTop                  Action = "jump_top"
 	actionJumpBottom               Action = "jump_bottom"
@@ -138,6 +150,10 @@ const (
 	actionBudgetPrevYear           Action = "budget_prev_year"
 	actionBudgetNextYear           Action = "budget_next_year"
 	actionTimeframeThisMonth       Action = "timeframe_this_month"
+	actionFormatWizard             Action = "format_wizard"
+	actionImportPending            Action = "import_pending"
+	actionWizardToggleHeader       Action = "wizard_toggle_header"
+	actionWizardCycleDelimiter     Action = "wizard_cycle_delimiter"
 )
 
 func NewKeyRegistry() *KeyRegistry {
@@ -161,6 +177,9 @@ func NewKeyRegistry() *KeyRegistry {
 	reg(scopeGlobal, actionJumpMode, "jump:activate", []string{"v"}, "jump")
 	reg(scopeGlobal, actionCommandPalette, "palette:open", []string{"ctrl+k"}, "commands")
 	reg(scopeGlobal, actionCommandMode, "cmd:open", []string{":"}, "command")
+	reg(scopeGlobal, actionUndo, "ledger:undo", []string{"ctrl+z"}, "undo")
+	reg(scopeGlobal, actionRedo, "ledger:redo", []string{"ctrl+y"}, "redo")
+	reg(scopeGlobal, actionSwitchLedger, "ledger:switch", []string{"ctrl+o"}, "ledger")
 
 	reg(scopeCommandPalette, actionUp, "", []string{"up", "ctrl+p", "k"}, "")
 	reg(scopeCommandPalette, actionDown, "", []string{"down", "ctrl+n", "j"}, "")
@@ -191,6 +210,7 @@ func NewKeyRegistry() *KeyRegistry {
 	reg(scopeManagerModal, actionLeft, "", []string{"left"}, "")
 	reg(scopeManagerModal, actionRight, "", []string{"right"}, "")
 	reg(scopeManagerModal, actionToggleSelect, "", []string{"space"}, "")
+	reg(scopeManagerModal, actionFormatWizard, "", []string{"ctrl+f"}, "")
 	reg(scopeManagerModal, actionConfirm, "", []string{"enter"}, "")
 	reg(scopeManagerModal, actionClose, "", []string{"esc"}, "")
 	reg(scopeManagerAccountAction, actionUp, "", []string{"k", "up", "ctrl+p"}, "")
@@ -281,6 +301,10 @@ func NewKeyRegistry() *KeyRegistry {
 	reg(scopeFilterApplyPicker, actionDown, "", []string{"down", "ctrl+n", "j"}, "")
 	reg(scopeFilterApplyPicker, actionSelect, "", []string{"enter"}, "")
 	reg(scopeFilterApplyPicker, actionClose, "", []string{"esc"}, "")
+	reg(scopeLedgerPicker, actionUp, "", []string{"up", "ctrl+p"}, "")
+	reg(scopeLedgerPicker, actionDown, "", []string{"down", "ctrl+n"}, "")
+	reg(scopeLedgerPicker, actionSelect, "", []string{"enter"}, "")
+	reg(scopeLedgerPicker, actionClose, "", []string{"esc"}, "")
 
 	// Detail / file picker footers: enter, esc, up/down, q
 	reg(scopeDetailModal, actionSelect, "", []string{"enter"}, "")
@@ -293,6 +317,17 @@ func NewKeyRegistry() *KeyRegistry {
 	reg(scopeFilePicker, actionClose, "", []string{"esc"}, "")
 	reg(scopeFilePicker, actionUp, "", []string{"k", "up", "ctrl+p"}, "")
 	reg(scopeFilePicker, actionDown, "", []string{"j", "down", "ctrl+n"}, "")
+	reg(scopeFilePicker, actionLeft, "", []string{"h", "left"}, "")
+	reg(scopeFilePicker, actionRight, "", []string{"l", "right"}, "")
+	reg(scopeFilePicker, actionFormatWizard, "", []string{"w"}, "new format")
+	reg(scopeFilePicker, actionImportPending, "import:pending", []string{"a"}, "import all")
+	reg(scopeFormatWizard, actionLeft, "", []string{"left"}, "")
+	reg(scopeFormatWizard, actionRight, "", []string{"right"}, "")
+	reg(scopeFormatWizard, actionToggleSelect, "", []string{"space"}, "mark")
+	reg(scopeFormatWizard, actionWizardToggleHeader, "", []string{"ctrl+t"}, "header")
+	reg(scopeFormatWizard, actionWizardCycleDelimiter, "", []string{"ctrl+d"}, "delimiter")
+	reg(scopeFormatWizard, actionSave, "", []string{"enter"}, "save")
+	reg(scopeFormatWizard, actionClose, "", []string{"esc"}, "cancel")
 	reg(scopeFilePicker, actionQuit, "", []string{"q", "ctrl+c"}, "")
 
 	// Import preview footer.
@@ -300,10 +335,23 @@ func NewKeyRegistry() *KeyRegistry {
 	reg(scopeImportPreview, actionDown, "", []string{"j", "down", "ctrl+n"}, "")
 	reg(scopeImportPreview, actionImportAll, "import:all", []string{"a"}, "all")
 	reg(scopeImportPreview, actionSkipDupes, "import:skip-dupes", []string{"s"}, "skip")
+	reg(scopeImportPreview, actionToggleSelect, "", []string{"space"}, "dupe?")
+	reg(scopeImportPreview, actionQuickCategory, "", []string{"c"}, "cat")
+	reg(scopeImportPreview, actionQuickTag, "", []string{"t"}, "tag")
 	reg(scopeImportPreview, actionImportRawView, "import:raw-view", []string{"r"}, "rules")
 	reg(scopeImportPreview, actionImportPreviewToggle, "import:preview-toggle", []string{"p"}, "preview")
+	reg(scopeImportPreview, actionQuarantineRejects, "import:quarantine", []string{"x"}, "quarantine")
 	reg(scopeImportPreview, actionClose, "import:cancel", []string{"esc"}, "")
 
+	// Rejected rows review modal.
+	reg(scopeImportRejects, actionUp, "", []string{"up", "ctrl+p"}, "")
+	reg(scopeImportRejects, actionDown, "", []string{"down", "ctrl+n"}, "")
+	reg(scopeImportRejects, actionLeft, "", []string{"left"}, "")
+	reg(scopeImportRejects, actionRight, "", []string{"right"}, "")
+	reg(scopeImportRejects, actionSave, "", []string{"enter"}, "promote")
+	reg(scopeImportRejects, actionDelete, "", []string{"del"}, "discard")
+	reg(scopeImportRejects, actionClose, "", []string{"esc"}, "close")
+
 	// Filter input footer.
 	reg(scopeFilterInput, actionFilterSave, "filter:save", []string{"ctrl+s"}, "save")
 	reg(scopeFilterInput, actionFilterLoad, "filter:apply", []string{"ctrl+l"}, "load")
@@ -379,10 +427,18 @@ func NewKeyRegistry() *KeyRegistry {
 	reg(scopeSettingsActiveDBImport, actionCommandDefault, "", []string{"o"}, "default")
 	reg(scopeSettingsActiveDBImport, actionClearDB, "settings:clear-db", []string{"c"}, "clear")
 	reg(scopeSettingsActiveDBImport, actionImport, "import:start", []string{"i"}, "import")
+	reg(scopeSettingsActiveDBImport, actionLoadFXRates, "fx:load", []string{"x"}, "fx rates")
+	reg(scopeSettingsActiveDBImport, actionRerunMerchants, "merchant:rerun", []string{"m"}, "merchants")
 	reg(scopeSettingsActiveDBImport, actionResetKeybindings, "", []string{"r"}, "reset")
 	reg(scopeSettingsActiveImportHist, actionBack, "", []string{"esc"}, "")
 	reg(scopeSettingsActiveImportHist, actionUp, "", []string{"k", "up", "ctrl+p"}, "")
 	reg(scopeSettingsActiveImportHist, actionDown, "", []string{"j", "down", "ctrl+n"}, "")
+	reg(scopeSettingsActiveImportHist, actionDelete, "", []string{"del"}, "undo import")
+	reg(scopeSettingsActiveImportHist, actionReviewRejects, "import:rejects", []string{"r"}, "rejects")
+	reg(scopeSettingsActiveBackups, actionBack, "", []string{"esc"}, "")
+	reg(scopeSettingsActiveBackups, actionUp, "", []string{"k", "up", "ctrl+p"}, "")
+	reg(scopeSettingsActiveBackups, actionDown, "", []string{"j", "down", "ctrl+n"}, "")
+	reg(scopeSettingsActiveBackups, actionRestoreBackup, "", []string{"r"}, "restore")
 	reg(scopeFilterEdit, actionUp, "", []string{"up", "ctrl+p"}, "")
 	reg(scopeFilterEdit, actionDown, "", []string{"down", "ctrl+n"}, "")
 	reg(scopeFilterEdit, actionLeft, "", []string{"left"}, "")
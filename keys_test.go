@@ -320,6 +320,7 @@ func TestGlobalShortcutNonShadowInTabScopes(t *testing.T) {
 		scopeSettingsActiveChart,
 		scopeSettingsActiveDBImport,
 		scopeSettingsActiveImportHist,
+		scopeSettingsActiveBackups,
 	}
 	for _, scope := range tabScopes {
 		for _, forbidden := range []string{"1", "2", "3", "v"} {
@@ -398,7 +399,7 @@ func TestDispatchTableOverlayGuardsAreMutuallyExclusiveWithTabs(t *testing.T) {
 	// Verify that the overlay table produces a scope for every known
 	// overlay boolean combination on a fresh model, and returns "" when
 	// no overlay is active.
-	m := newModel()
+	m := newModel("")
 	m.keys = NewKeyRegistry()
 
 	// No overlay active — should return empty.
@@ -418,9 +419,11 @@ func TestDispatchTableOverlayGuardsAreMutuallyExclusiveWithTabs(t *testing.T) {
 		{"detail", func(m *model) { m.showDetail = true }, scopeDetailModal},
 		{"importPreview", func(m *model) { m.importPreviewOpen = true }, scopeImportPreview},
 		{"filePicker", func(m *model) { m.importPicking = true }, scopeFilePicker},
+		{"formatWizard", func(m *model) { m.formatWizard = &formatWizardState{} }, scopeFormatWizard},
 		{"catPicker", func(m *model) { m.catPicker = &pickerState{} }, scopeCategoryPicker},
 		{"tagPicker", func(m *model) { m.tagPicker = &pickerState{} }, scopeTagPicker},
 		{"filterApplyPicker", func(m *model) { m.filterApplyPicker = &pickerState{} }, scopeFilterApplyPicker},
+		{"ledgerPicker", func(m *model) { m.ledgerPicker = &pickerState{} }, scopeLedgerPicker},
 		{"managerActionPicker", func(m *model) { m.managerActionPicker = &pickerState{} }, scopeManagerAccountAction},
 		{"filterEdit", func(m *model) { m.filterEditOpen = true }, scopeFilterEdit},
 		{"managerModal", func(m *model) { m.managerModalOpen = true }, scopeManagerModal},
@@ -429,7 +432,7 @@ func TestDispatchTableOverlayGuardsAreMutuallyExclusiveWithTabs(t *testing.T) {
 		{"filterInput", func(m *model) { m.filterInputMode = true }, scopeFilterInput},
 	}
 	for _, tt := range tests {
-		fresh := newModel()
+		fresh := newModel("")
 		fresh.keys = NewKeyRegistry()
 		tt.setup(&fresh)
 		got := fresh.activeOverlayScope(true)
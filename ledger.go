@@ -0,0 +1,269 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// A ledger is one database with its own config.toml (accounts, formats,
+// settings and saved filters). The default ledger keeps its config at the
+// config root; named profiles such as "household" live under
+// profiles/<name>/ and keep their database there unless database_path says
+// otherwise. Keybindings are shared by every ledger.
+
+const defaultDBName = "transactions.db"
+
+// normalizeProfileName lowercases a profile name and checks it is usable as
+// a directory name.
+func normalizeProfileName(raw string) (string, error) {
+	name := strings.ToLower(strings.TrimSpace(raw))
+	if name == "" {
+		return "", fmt.Errorf("profile name is required")
+	}
+	for _, r := range name {
+		if (r < 'a' || r > 'z') && (r < '0' || r > '9') && r != '-' && r != '_' {
+			return "", fmt.Errorf("profile name %q: use letters, digits, - and _", raw)
+		}
+	}
+	return name, nil
+}
+
+// profileDir is the config directory of a profile; "" is the default ledger.
+func profileDir(profile string) (string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return "", err
+	}
+	if profile == "" {
+		return dir, nil
+	}
+	return filepath.Join(dir, "profiles", profile), nil
+}
+
+// listProfiles returns the named profiles that have a config directory.
+func listProfiles() ([]string, error) {
+	dir, err := configDir()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(filepath.Join(dir, "profiles"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("read profiles: %w", err)
+	}
+	var out []string
+	for _, e := range entries {
+		if !e.IsDir() {
+			continue
+		}
+		if name, err := normalizeProfileName(e.Name()); err == nil && name == e.Name() {
+			out = append(out, name)
+		}
+	}
+	sort.Strings(out)
+	return out, nil
+}
+
+// resolveDatabasePath picks the database for a ledger profile: the --db
+// flag, then database_path from the ledger's config (relative paths are
+// against its config directory), then the profile's own transactions.db.
+// A default ledger with no database yet adopts ./transactions.db, for
+// databases created before the location was configurable, by recording it
+// as database_path; after that the working directory no longer matters.
+func resolveDatabasePath(profile, flagPath string, settings appSettings) (string, error) {
+	if p := strings.TrimSpace(flagPath); p != "" {
+		return expandHomePath(p), nil
+	}
+	dir, err := profileDir(profile)
+	if err != nil {
+		return "", err
+	}
+	if p := strings.TrimSpace(settings.DatabasePath); p != "" {
+		p = expandHomePath(p)
+		if !filepath.IsAbs(p) {
+			p = filepath.Join(dir, p)
+		}
+		return p, nil
+	}
+	own := filepath.Join(dir, defaultDBName)
+	if profile == "" && !fileExists(own) && fileExists(defaultDBName) {
+		legacy, err := filepath.Abs(defaultDBName)
+		if err != nil {
+			return "", err
+		}
+		settings.DatabasePath = legacy
+		if err := saveAppSettings(profile, settings); err != nil {
+			return "", fmt.Errorf("record database_path: %w", err)
+		}
+		return legacy, nil
+	}
+	return own, nil
+}
+
+// withDBOverride points the model at the --db database and remembers it for
+// the current ledger, so switching away and back reopens the same file.
+func (m model) withDBOverride(path string) model {
+	if strings.TrimSpace(path) == "" {
+		return m
+	}
+	m.dbOverride = expandHomePath(path)
+	m.dbOverrideProfile = m.profile
+	m.dbPath = m.dbOverride
+	return m
+}
+
+// dbOverrideFor returns the --db path for profile, or "" when it was given
+// for another ledger.
+func (m model) dbOverrideFor(profile string) string {
+	if m.dbOverride == "" || profile != m.dbOverrideProfile {
+		return ""
+	}
+	return m.dbOverride
+}
+
+// ledgerLabel names the open ledger for the header: the profile, or the
+// database file when running the default ledger.
+func (m model) ledgerLabel() string {
+	if m.profile != "" {
+		return m.profile
+	}
+	if m.dbPath == "" {
+		return ""
+	}
+	return filepath.Base(m.dbPath)
+}
+
+// ---------------------------------------------------------------------------
+// Switching ledgers
+// ---------------------------------------------------------------------------
+
+const defaultLedgerLabel = "default"
+
+// openLedgerPicker lists the default ledger and every profile. Typing a new
+// name offers to create that profile.
+func (m *model) openLedgerPicker() error {
+	profiles, err := listProfiles()
+	if err != nil {
+		return err
+	}
+	m.ledgerOrder = append([]string{""}, profiles...)
+	items := make([]pickerItem, 0, len(m.ledgerOrder))
+	for i, name := range m.ledgerOrder {
+		label, meta := name, ""
+		if name == "" {
+			label = defaultLedgerLabel
+		}
+		if name == m.profile {
+			meta = "current"
+		}
+		items = append(items, pickerItem{ID: i + 1, Label: label, Meta: meta})
+	}
+	m.ledgerPicker = newPicker("Switch Ledger", items, false, "Create profile")
+	return nil
+}
+
+func (m *model) closeLedgerPicker() {
+	m.ledgerPicker = nil
+	m.ledgerOrder = nil
+}
+
+func (m model) updateLedgerPicker(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.ledgerPicker == nil {
+		return m, nil
+	}
+	res := m.ledgerPicker.HandleMsg(msg, func(action Action, in tea.KeyMsg) bool {
+		return m.isAction(scopeLedgerPicker, action, in)
+	})
+	switch res.Action {
+	case pickerActionCancelled:
+		m.closeLedgerPicker()
+		m.setStatus("Switch ledger cancelled.")
+		return m, nil
+	case pickerActionSelected:
+		idx := res.ItemID - 1
+		if idx < 0 || idx >= len(m.ledgerOrder) {
+			return m, nil
+		}
+		profile := m.ledgerOrder[idx]
+		m.closeLedgerPicker()
+		if profile == m.profile {
+			m.setStatus("Already on this ledger.")
+			return m, nil
+		}
+		return m, switchLedgerCmd(profile, m.dbOverrideFor(profile))
+	case pickerActionCreate:
+		profile, err := normalizeProfileName(res.CreatedQuery)
+		if err != nil {
+			m.setError(err.Error())
+			return m, nil
+		}
+		m.closeLedgerPicker()
+		return m, switchLedgerCmd(profile, m.dbOverrideFor(profile))
+	}
+	return m, nil
+}
+
+type ledgerSwitchedMsg struct {
+	profile string
+	dbPath  string
+	db      *sql.DB
+	err     error
+}
+
+// switchLedgerCmd opens the profile's database, or dbFlag when --db was
+// given for it, before anything is torn down, so a failure leaves the
+// current ledger in place. A new profile gets a default config.toml the
+// first time it is loaded.
+func switchLedgerCmd(profile, dbFlag string) tea.Cmd {
+	return func() tea.Msg {
+		_, settings, _, _, _, err := loadAppConfigExtended(profile)
+		if err != nil {
+			return ledgerSwitchedMsg{profile: profile, err: err}
+		}
+		dbPath, err := resolveDatabasePath(profile, dbFlag, settings)
+		if err != nil {
+			return ledgerSwitchedMsg{profile: profile, err: err}
+		}
+		db, err := openDB(dbPath)
+		if err != nil {
+			return ledgerSwitchedMsg{profile: profile, err: err}
+		}
+		return ledgerSwitchedMsg{profile: profile, dbPath: dbPath, db: db}
+	}
+}
+
+// handleLedgerSwitched rebuilds the model from the new ledger's config and
+// hands it the already-open database. The old database is closed only once
+// the new one is in place.
+func (m model) handleLedgerSwitched(msg ledgerSwitchedMsg) (tea.Model, tea.Cmd) {
+	if msg.err != nil {
+		m.setError(fmt.Sprintf("Switch ledger failed: %v", msg.err))
+		return m, nil
+	}
+	next := newModel(msg.profile)
+	next.dbPath = msg.dbPath
+	next.dbOverride, next.dbOverrideProfile = m.dbOverride, m.dbOverrideProfile
+	next.width = m.width
+	next.height = m.height
+	next.ready = m.ready
+	name := msg.profile
+	if name == "" {
+		name = defaultLedgerLabel
+	}
+	if !next.statusErr {
+		next.setStatusf("Switched to ledger %s (%s).", name, msg.dbPath)
+	}
+	switched, cmd := next.handleDBReady(dbReadyMsg{db: msg.db})
+	if m.db != nil && m.db != msg.db {
+		_ = m.db.Close()
+	}
+	return switched, cmd
+}
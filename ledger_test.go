@@ -0,0 +1,197 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// useTestConfigHome points the config directory at a temp dir.
+func useTestConfigHome(t *testing.T) string {
+	t.Helper()
+	xdg := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", xdg)
+	return filepath.Join(xdg, "jaskmoney")
+}
+
+func TestResolveDatabasePathPrecedence(t *testing.T) {
+	root := useTestConfigHome(t)
+	t.Chdir(t.TempDir())
+
+	got, err := resolveDatabasePath("", "", appSettings{})
+	if err != nil || got != filepath.Join(root, defaultDBName) {
+		t.Fatalf("default ledger = %q, %v; want config dir database", got, err)
+	}
+	got, _ = resolveDatabasePath("household", "", appSettings{})
+	if want := filepath.Join(root, "profiles", "household", defaultDBName); got != want {
+		t.Fatalf("profile database = %q, want %q", got, want)
+	}
+	got, _ = resolveDatabasePath("household", "", appSettings{DatabasePath: "shared/money.db"})
+	if want := filepath.Join(root, "profiles", "household", "shared", "money.db"); got != want {
+		t.Fatalf("relative database_path = %q, want %q", got, want)
+	}
+	abs := filepath.Join(t.TempDir(), "abs.db")
+	got, _ = resolveDatabasePath("household", "", appSettings{DatabasePath: abs})
+	if got != abs {
+		t.Fatalf("absolute database_path = %q, want %q", got, abs)
+	}
+	got, _ = resolveDatabasePath("household", "flag.db", appSettings{DatabasePath: abs})
+	if got != "flag.db" {
+		t.Fatalf("--db = %q, want it to win over database_path", got)
+	}
+}
+
+func TestResolveDatabasePathAdoptsWorkingDirectoryDatabaseOnce(t *testing.T) {
+	useTestConfigHome(t)
+	legacyDir := t.TempDir()
+	t.Chdir(legacyDir)
+	if err := os.WriteFile(defaultDBName, nil, 0o644); err != nil {
+		t.Fatalf("write legacy db: %v", err)
+	}
+	_, settings, _, _, _, err := loadAppConfigExtended("")
+	if err != nil {
+		t.Fatalf("loadAppConfigExtended: %v", err)
+	}
+	want, _ := filepath.Abs(defaultDBName)
+	if got, err := resolveDatabasePath("", "", settings); err != nil || got != want {
+		t.Fatalf("default ledger = %q, %v; want the working directory database %q", got, err, want)
+	}
+
+	// Later launches from elsewhere keep the adopted database.
+	t.Chdir(t.TempDir())
+	_, settings, _, _, _, err = loadAppConfigExtended("")
+	if err != nil {
+		t.Fatalf("reload config: %v", err)
+	}
+	if got, err := resolveDatabasePath("", "", settings); err != nil || got != want {
+		t.Fatalf("default ledger after chdir = %q, %v; want %q", got, err, want)
+	}
+
+	// A ledger already living in the config directory is never swapped for
+	// a stray ./transactions.db.
+	root2 := useTestConfigHome(t)
+	if err := os.MkdirAll(root2, 0o755); err != nil {
+		t.Fatalf("mkdir config: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(root2, defaultDBName), nil, 0o644); err != nil {
+		t.Fatalf("write config db: %v", err)
+	}
+	t.Chdir(legacyDir)
+	if got, _ := resolveDatabasePath("", "", appSettings{}); got != filepath.Join(root2, defaultDBName) {
+		t.Fatalf("default ledger = %q, want the config directory database", got)
+	}
+}
+
+func TestProfilesKeepSeparateConfig(t *testing.T) {
+	useTestConfigHome(t)
+	formats, err := loadFormats("business")
+	if err != nil {
+		t.Fatalf("loadFormats: %v", err)
+	}
+	formats = append(formats, csvFormat{Name: "Biz Card", DateFormat: "2006-01-02"})
+	if err := saveFormats("business", formats); err != nil {
+		t.Fatalf("saveFormats: %v", err)
+	}
+
+	defaults, err := loadFormats("")
+	if err != nil {
+		t.Fatalf("loadFormats default: %v", err)
+	}
+	for _, f := range defaults {
+		if f.Name == "Biz Card" {
+			t.Fatal("business format leaked into the default ledger")
+		}
+	}
+	profiles, err := listProfiles()
+	if err != nil || len(profiles) != 1 || profiles[0] != "business" {
+		t.Fatalf("listProfiles = %v, %v; want [business]", profiles, err)
+	}
+}
+
+func TestNormalizeProfileName(t *testing.T) {
+	if got, err := normalizeProfileName(" Household "); err != nil || got != "household" {
+		t.Fatalf("normalizeProfileName = %q, %v", got, err)
+	}
+	for _, bad := range []string{"", "../x", "my ledger"} {
+		if _, err := normalizeProfileName(bad); err == nil {
+			t.Errorf("normalizeProfileName(%q) accepted", bad)
+		}
+	}
+}
+
+func TestRenderHeaderShowsLedger(t *testing.T) {
+	output := renderHeader("Jaskmoney", "household", 0, 120, "")
+	if !strings.Contains(output, "[household]") {
+		t.Fatalf("header missing ledger label:\n%s", output)
+	}
+}
+
+func TestSwitchLedgerCreatesProfile(t *testing.T) {
+	root := useTestConfigHome(t)
+	m, cleanup := testPhase5Model(t)
+	defer cleanup()
+
+	next, _ := m.Update(keyMsg(m.primaryActionKey(scopeGlobal, actionSwitchLedger, "ctrl+o")))
+	picking := next.(model)
+	if picking.ledgerPicker == nil {
+		t.Fatalf("ledger picker not open; status = %q", picking.status)
+	}
+	for _, r := range "biz" {
+		next, _ = picking.Update(keyMsg(string(r)))
+		picking = next.(model)
+	}
+	next, cmd := picking.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	if cmd == nil {
+		t.Fatal("expected switch command from create")
+	}
+	msg, ok := cmd().(ledgerSwitchedMsg)
+	if !ok || msg.err != nil {
+		t.Fatalf("switch msg = %+v, want success", msg)
+	}
+	defer msg.db.Close()
+	want := filepath.Join(root, "profiles", "biz", defaultDBName)
+	if msg.dbPath != want {
+		t.Fatalf("dbPath = %q, want %q", msg.dbPath, want)
+	}
+
+	next, _ = next.(model).Update(msg)
+	got := next.(model)
+	if got.profile != "biz" || got.db != msg.db || got.ledgerLabel() != "biz" {
+		t.Fatalf("switched model profile=%q label=%q", got.profile, got.ledgerLabel())
+	}
+	if !got.ready || !fileExists(filepath.Join(root, "profiles", "biz", "config.toml")) {
+		t.Fatalf("ready=%v, want profile config written", got.ready)
+	}
+	if err := m.db.Ping(); err == nil {
+		t.Fatal("previous ledger's database still open after the switch")
+	}
+}
+
+func TestSwitchLedgerKeepsDBOverride(t *testing.T) {
+	root := useTestConfigHome(t)
+	override := filepath.Join(t.TempDir(), "mine.db")
+	m := newModel("").withDBOverride(override)
+	if m.dbPath != override || m.dbOverrideFor("biz") != "" {
+		t.Fatalf("dbPath=%q biz override=%q, want %q for the default ledger only", m.dbPath, m.dbOverrideFor("biz"), override)
+	}
+
+	biz, ok := switchLedgerCmd("biz", m.dbOverrideFor("biz"))().(ledgerSwitchedMsg)
+	if !ok || biz.err != nil || biz.dbPath != filepath.Join(root, "profiles", "biz", defaultDBName) {
+		t.Fatalf("switch to biz = %+v", biz)
+	}
+	next, _ := m.Update(biz)
+	onBiz := next.(model)
+	defer onBiz.db.Close()
+
+	back, ok := switchLedgerCmd("", onBiz.dbOverrideFor(""))().(ledgerSwitchedMsg)
+	if !ok || back.err != nil {
+		t.Fatalf("switch back = %+v", back)
+	}
+	defer back.db.Close()
+	if back.dbPath != override {
+		t.Fatalf("switching back opened %q, want the --db file %q", back.dbPath, override)
+	}
+}
@@ -9,28 +9,46 @@ import (
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "import" {
+		os.Exit(runImportCommand(os.Args[2:], "", os.Stdout, os.Stderr))
+	}
+	if len(os.Args) > 1 && os.Args[1] == "archive" {
+		os.Exit(runArchiveCommand(os.Args[2:], "", promptPassphrase(os.Stderr), os.Stdout, os.Stderr))
+	}
 	validate := flag.Bool("validate", false, "run non-TUI validation")
 	startupCheck := flag.Bool("startup-check", false, "run startup diagnostics harness (prints startup status)")
+	dbFlag := flag.String("db", "", "open this database file instead of the ledger's configured one")
+	profileFlag := flag.String("profile", "", "use a named ledger profile (e.g. personal, household)")
 	flag.Parse()
+	profile := ""
+	if *profileFlag != "" {
+		var err error
+		profile, err = normalizeProfileName(*profileFlag)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, "invalid -profile:", err)
+			os.Exit(2)
+		}
+	}
 	if *validate && *startupCheck {
 		fmt.Fprintln(os.Stderr, "cannot use -validate and -startup-check together")
 		os.Exit(2)
 	}
 	if *startupCheck {
-		if err := runStartupHarness(os.Stdout); err != nil {
+		if err := runStartupHarness(profile, *dbFlag, os.Stdout); err != nil {
 			os.Exit(1)
 		}
 		return
 	}
 	if *validate {
-		if err := runValidation(); err != nil {
+		if err := runValidation(profile, *dbFlag); err != nil {
 			fmt.Fprintln(os.Stderr, "validation failed:", err)
 			os.Exit(1)
 		}
 		fmt.Println("validation ok")
 		return
 	}
-	p := tea.NewProgram(newModel(), tea.WithAltScreen())
+	m := newModel(profile).withDBOverride(*dbFlag)
+	p := tea.NewProgram(m, tea.WithAltScreen())
 	if _, err := p.Run(); err != nil {
 		fmt.Println("error:", err)
 		os.Exit(1)
@@ -16,7 +16,7 @@ func testKeyForAction(m model, scope string, action Action, fallback string) str
 }
 
 func testPhase3Model() model {
-	m := newModel()
+	m := newModel("")
 	m.ready = true
 	m.activeTab = tabManager
 	m.managerMode = managerModeTransactions
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"regexp"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// merchantRule is one step of the merchant cleanup pipeline: every match of
+// Pattern (Go regexp syntax) in the description is replaced with Replace,
+// which may reference capture groups as $1.
+type merchantRule struct {
+	Pattern string `toml:"pattern"`
+	Replace string `toml:"replace"`
+}
+
+// defaultMerchantRules turn "VISA PURCHASE 1234 WOOLWORTHS 3021 SYDNEY AU"
+// into "WOOLWORTHS": card and payment prefixes, masked card numbers, store
+// and reference numbers, then trailing city/state/country suffixes.
+func defaultMerchantRules() []merchantRule {
+	return []merchantRule{
+		{Pattern: `(?i)^(visa|mastercard|eftpos|debit card|credit card|card)\s+(purchase|debit|payment|refund|transaction)\s+`},
+		{Pattern: `(?i)^(pos|eftpos|direct debit|dd)\s+`},
+		{Pattern: `(?i)^(sq|sp|paypal)\s*\*\s*`},
+		{Pattern: `(?i)\bcard\s+[x*]*\d+\b`},
+		{Pattern: `(?i)\b[x*]{2,}\d+\b`},
+		{Pattern: `\b\d{3,}\b`},
+		{Pattern: `(?i)\s+(sydney|melbourne|brisbane|perth|adelaide|hobart|darwin|canberra)(\s.*)?$`},
+		{Pattern: `(?i)(\s+(nsw|vic|qld|wa|sa|tas|act|nt|au|aus))+$`},
+	}
+}
+
+type compiledMerchantRule struct {
+	re      *regexp.Regexp
+	replace string
+}
+
+// merchantNormalizer applies compiled merchant rules in order. A nil
+// normalizer only tidies whitespace.
+type merchantNormalizer struct {
+	rules []compiledMerchantRule
+}
+
+func newMerchantNormalizer(rules []merchantRule) (*merchantNormalizer, error) {
+	n := &merchantNormalizer{rules: make([]compiledMerchantRule, 0, len(rules))}
+	for i, r := range rules {
+		re, err := regexp.Compile(r.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("merchant rule %d: %w", i+1, err)
+		}
+		n.rules = append(n.rules, compiledMerchantRule{re: re, replace: r.Replace})
+	}
+	return n, nil
+}
+
+// normalize derives a merchant name from a raw description. When the rules
+// strip everything, the tidied description is kept instead.
+func (n *merchantNormalizer) normalize(description string) string {
+	raw := strings.Join(strings.Fields(description), " ")
+	if n == nil {
+		return raw
+	}
+	out := raw
+	for _, r := range n.rules {
+		out = r.re.ReplaceAllString(out, r.replace)
+	}
+	out = strings.Trim(strings.Join(strings.Fields(out), " "), " -*#/,.")
+	if out == "" {
+		return raw
+	}
+	return out
+}
+
+// txnMerchant is the name a transaction is grouped under: the stored
+// merchant, or the description for rows imported before merchants existed.
+func txnMerchant(t transaction) string {
+	if m := strings.TrimSpace(t.merchant); m != "" {
+		return m
+	}
+	return strings.TrimSpace(t.description)
+}
+
+// setMerchants fills in each preview row's merchant before import.
+func (s *importPreviewSnapshot) setMerchants(n *merchantNormalizer) {
+	if s == nil {
+		return
+	}
+	for i := range s.rows {
+		s.rows[i].merchant = n.normalize(s.rows[i].description)
+	}
+}
+
+type merchantsRerunMsg struct {
+	updated   int
+	rules     []merchantRule
+	merchants *merchantNormalizer
+	err       error
+}
+
+// rerunMerchantsCmd re-derives the merchant of every transaction from its
+// untouched description, e.g. after editing merchant_rules. The rules are
+// re-read from config.toml so edits made outside the app take effect.
+func rerunMerchantsCmd(db *sql.DB, profile string) tea.Cmd {
+	return func() tea.Msg {
+		_, settings, _, _, _, err := loadAppConfigExtended(profile)
+		if err != nil {
+			return merchantsRerunMsg{err: fmt.Errorf("load config: %w", err)}
+		}
+		n, err := newMerchantNormalizer(settings.MerchantRules)
+		if err != nil {
+			return merchantsRerunMsg{err: err}
+		}
+		updated, err := rerunMerchants(db, n)
+		return merchantsRerunMsg{updated: updated, rules: settings.MerchantRules, merchants: n, err: err}
+	}
+}
+
+func rerunMerchants(db *sql.DB, n *merchantNormalizer) (int, error) {
+	rows, err := db.Query(`SELECT id, description, merchant FROM transactions`)
+	if err != nil {
+		return 0, fmt.Errorf("query merchants: %w", err)
+	}
+	changed := make(map[int]string)
+	for rows.Next() {
+		var id int
+		var desc, merchant string
+		if err := rows.Scan(&id, &desc, &merchant); err != nil {
+			rows.Close()
+			return 0, fmt.Errorf("scan merchant: %w", err)
+		}
+		if next := n.normalize(desc); next != merchant {
+			changed[id] = next
+		}
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+	if len(changed) == 0 {
+		return 0, nil
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return 0, fmt.Errorf("begin merchant rerun: %w", err)
+	}
+	defer tx.Rollback() //nolint:errcheck
+	for id, merchant := range changed {
+		if _, err := tx.Exec(`UPDATE transactions SET merchant = ? WHERE id = ?`, merchant, id); err != nil {
+			return 0, fmt.Errorf("update merchant for txn %d: %w", id, err)
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return 0, fmt.Errorf("commit merchant rerun: %w", err)
+	}
+	return len(changed), nil
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"os"
+	"strings"
+	"testing"
+)
+
+func TestDefaultMerchantRulesNormalize(t *testing.T) {
+	n, err := newMerchantNormalizer(defaultMerchantRules())
+	if err != nil {
+		t.Fatalf("newMerchantNormalizer: %v", err)
+	}
+	cases := map[string]string{
+		"VISA PURCHASE 1234 WOOLWORTHS 3021 SYDNEY AU": "WOOLWORTHS",
+		"EFTPOS DEBIT  WOOLWORTHS 1187 MELBOURNE VIC":  "WOOLWORTHS",
+		"WOOLWORTHS 0455 PARRAMATTA NSW":               "WOOLWORTHS PARRAMATTA",
+		"SQ *BLUE BOTTLE COFFEE":                       "BLUE BOTTLE COFFEE",
+		"POSTMATES ORDER":                              "POSTMATES ORDER",
+		"NETFLIX.COM":                                  "NETFLIX.COM",
+		"1234":                                         "1234", // everything stripped: keep the description
+	}
+	for in, want := range cases {
+		if got := n.normalize(in); got != want {
+			t.Fatalf("normalize(%q) = %q, want %q", in, got, want)
+		}
+	}
+	var none *merchantNormalizer
+	if got := none.normalize("  A   B "); got != "A B" {
+		t.Fatalf("nil normalize = %q, want whitespace tidied", got)
+	}
+	if _, err := newMerchantNormalizer([]merchantRule{{Pattern: "("}}); err == nil {
+		t.Fatal("expected error for invalid pattern")
+	}
+}
+
+func TestParseConfigMerchantRules(t *testing.T) {
+	account := "[account.ANZ]\ndate_format = \"2/01/2006\"\n"
+	data := []byte(account + `
+[settings]
+[[settings.merchant_rules]]
+pattern = "^AMZN MKTP.*"
+replace = "AMAZON"
+[[settings.merchant_rules]]
+pattern = "(unclosed"
+`)
+	_, settings, _, _, _, warnings, err := parseConfigExt(data)
+	if err != nil {
+		t.Fatalf("parseConfigExt: %v", err)
+	}
+	if len(settings.MerchantRules) != 1 || settings.MerchantRules[0].Replace != "AMAZON" {
+		t.Fatalf("merchant rules = %+v, want only the valid rule", settings.MerchantRules)
+	}
+	if len(warnings) != 1 || !strings.Contains(warnings[0], "merchant_rules[1]") {
+		t.Fatalf("warnings = %v, want one for merchant_rules[1]", warnings)
+	}
+
+	_, settings, _, _, _, _, err = parseConfigExt([]byte(account + "[settings]\nrows_per_page = 20\n"))
+	if err != nil {
+		t.Fatalf("parseConfigExt defaults: %v", err)
+	}
+	if len(settings.MerchantRules) != len(defaultMerchantRules()) {
+		t.Fatalf("got %d merchant rules, want the %d defaults", len(settings.MerchantRules), len(defaultMerchantRules()))
+	}
+}
+
+func TestRerunMerchantsKeepsDescription(t *testing.T) {
+	db, cleanup := testDB(t)
+	defer cleanup()
+
+	if _, err := db.Exec(`
+		INSERT INTO transactions (date_raw, date_iso, amount, description)
+		VALUES ('3/02/2026', '2026-02-03', -2000, 'VISA PURCHASE 1234 WOOLWORTHS 3021 SYDNEY AU'),
+		       ('4/02/2026', '2026-02-04', -1500, 'WOOLWORTHS 0099 SYDNEY'),
+		       ('5/02/2026', '2026-02-05', -900, 'CAFE')
+	`); err != nil {
+		t.Fatalf("insert transactions: %v", err)
+	}
+	n, err := newMerchantNormalizer(defaultMerchantRules())
+	if err != nil {
+		t.Fatalf("newMerchantNormalizer: %v", err)
+	}
+	updated, err := rerunMerchants(db, n)
+	if err != nil {
+		t.Fatalf("rerunMerchants: %v", err)
+	}
+	if updated != 3 {
+		t.Fatalf("updated = %d, want 3", updated)
+	}
+	if again, err := rerunMerchants(db, n); err != nil || again != 0 {
+		t.Fatalf("second rerun = %d (%v), want 0", again, err)
+	}
+
+	rows, err := loadRows(db)
+	if err != nil {
+		t.Fatalf("loadRows: %v", err)
+	}
+	node, err := parseFilterStrict(`merchant:woolworths`)
+	if err != nil {
+		t.Fatalf("parse merchant filter: %v", err)
+	}
+	matched := 0
+	for _, r := range rows {
+		if strings.HasPrefix(r.description, "VISA") && r.merchant != "WOOLWORTHS" {
+			t.Fatalf("merchant = %q, want WOOLWORTHS", r.merchant)
+		}
+		if !strings.Contains(r.description, "WOOLWORTHS") && r.description != "CAFE" {
+			t.Fatalf("description rewritten: %q", r.description)
+		}
+		if evalFilter(node, r, nil) {
+			matched++
+		}
+	}
+	if matched != 2 {
+		t.Fatalf("merchant:woolworths matched %d rows, want 2", matched)
+	}
+
+	chart := renderDashboardTopMerchants(rows, 60)
+	if strings.Count(chart, "WOOLWORTHS") != 1 || !strings.Contains(chart, "35.00") {
+		t.Fatalf("top merchants should group both Woolworths rows:\n%s", chart)
+	}
+}
+
+func TestRerunMerchantsCmdReloadsConfigRules(t *testing.T) {
+	useTestConfigHome(t)
+	db, cleanup := testDB(t)
+	defer cleanup()
+
+	m := newModel("")
+	m.db = db
+	path, err := configPath("")
+	if err != nil {
+		t.Fatalf("configPath: %v", err)
+	}
+	// Edit config.toml after the model compiled its rules.
+	cfg := "[account.ANZ]\ndate_format = \"2/01/2006\"\n\n[settings]\n[[settings.merchant_rules]]\npattern = \"^AMZN MKTP.*\"\nreplace = \"AMAZON\"\n"
+	if err := os.WriteFile(path, []byte(cfg), 0o644); err != nil {
+		t.Fatalf("write config: %v", err)
+	}
+	if _, err := db.Exec(`
+		INSERT INTO transactions (date_raw, date_iso, amount, description)
+		VALUES ('3/02/2026', '2026-02-03', -2000, 'AMZN MKTP AU 1234')
+	`); err != nil {
+		t.Fatalf("insert transaction: %v", err)
+	}
+
+	msg, ok := rerunMerchantsCmd(db, m.profile)().(merchantsRerunMsg)
+	if !ok || msg.err != nil || msg.updated != 1 {
+		t.Fatalf("rerun = %+v, want 1 update", msg)
+	}
+	rows, err := loadRows(db)
+	if err != nil || len(rows) != 1 || rows[0].merchant != "AMAZON" {
+		t.Fatalf("rows = %+v (%v), want merchant AMAZON", rows, err)
+	}
+	next, _ := m.Update(msg)
+	got := next.(model)
+	if got.merchants.normalize("AMZN MKTP US") != "AMAZON" || len(got.merchantRules) != 1 {
+		t.Fatalf("model kept stale merchant rules: %+v", got.merchantRules)
+	}
+}
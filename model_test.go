@@ -19,11 +19,11 @@ func testTransactions() []transaction {
 	catDining := 3
 	catTransport := 4
 	return []transaction{
-		{id: 1, dateRaw: "03/02/2026", dateISO: "2026-02-03", amount: -20.00, description: "DAN MURPHYS", categoryID: &catDining, categoryName: "Dining & Drinks", categoryColor: "#fab387"},
-		{id: 2, dateRaw: "03/02/2026", dateISO: "2026-02-03", amount: 203.92, description: "PAYMENT RECEIVED", categoryID: nil, categoryName: "Uncategorised", categoryColor: "#7f849c"},
-		{id: 3, dateRaw: "04/02/2026", dateISO: "2026-02-04", amount: -55.30, description: "WOOLWORTHS 1234", categoryID: &catGroceries, categoryName: "Groceries", categoryColor: "#94e2d5"},
-		{id: 4, dateRaw: "15/01/2026", dateISO: "2026-01-15", amount: -12.50, description: "UBER TRIP", categoryID: &catTransport, categoryName: "Transport", categoryColor: "#89b4fa"},
-		{id: 5, dateRaw: "20/12/2025", dateISO: "2025-12-20", amount: 500.00, description: "SALARY PAYMENT", categoryID: nil, categoryName: "Uncategorised", categoryColor: "#7f849c"},
+		{id: 1, dateRaw: "03/02/2026", dateISO: "2026-02-03", amount: -2000, description: "DAN MURPHYS", categoryID: &catDining, categoryName: "Dining & Drinks", categoryColor: "#fab387"},
+		{id: 2, dateRaw: "03/02/2026", dateISO: "2026-02-03", amount: 20392, description: "PAYMENT RECEIVED", categoryID: nil, categoryName: "Uncategorised", categoryColor: "#7f849c"},
+		{id: 3, dateRaw: "04/02/2026", dateISO: "2026-02-04", amount: -5530, description: "WOOLWORTHS 1234", categoryID: &catGroceries, categoryName: "Groceries", categoryColor: "#94e2d5"},
+		{id: 4, dateRaw: "15/01/2026", dateISO: "2026-01-15", amount: -1250, description: "UBER TRIP", categoryID: &catTransport, categoryName: "Transport", categoryColor: "#89b4fa"},
+		{id: 5, dateRaw: "20/12/2025", dateISO: "2025-12-20", amount: 50000, description: "SALARY PAYMENT", categoryID: nil, categoryName: "Uncategorised", categoryColor: "#7f849c"},
 	}
 }
 
@@ -103,11 +103,11 @@ func TestSortByAmount(t *testing.T) {
 	rows := testTransactions()
 	result := filteredRows(rows, nil, nil, sortByAmount, true)
 	// Ascending: most negative first
-	if result[0].amount != -55.30 {
-		t.Errorf("first row amount = %.2f, want -55.30", result[0].amount)
+	if result[0].amount != -5530 {
+		t.Errorf("first row amount = %s, want -55.30", result[0].amount)
 	}
-	if result[4].amount != 500.00 {
-		t.Errorf("last row amount = %.2f, want 500.00", result[4].amount)
+	if result[4].amount != 50000 {
+		t.Errorf("last row amount = %s, want 500.00", result[4].amount)
 	}
 }
 
@@ -115,14 +115,14 @@ func TestManagerRowsUnfilteredSortByAmountUsesRemainder(t *testing.T) {
 	catGroceries := 2
 	m := model{
 		rows: []transaction{
-			{id: 1, dateISO: "2026-02-01", amount: -100, description: "A", categoryID: &catGroceries, categoryName: "Groceries"},
-			{id: 2, dateISO: "2026-02-01", amount: -60, description: "B", categoryID: &catGroceries, categoryName: "Groceries"},
+			{id: 1, dateISO: "2026-02-01", amount: -10000, description: "A", categoryID: &catGroceries, categoryName: "Groceries"},
+			{id: 2, dateISO: "2026-02-01", amount: -6000, description: "B", categoryID: &catGroceries, categoryName: "Groceries"},
 		},
 		sortColumn:    sortByAmount,
 		sortAscending: false, // descending
 		allocationsByParent: map[int][]transactionAllocation{
 			1: {
-				{id: 101, parentTxnID: 1, amount: -90, note: "split"},
+				{id: 101, parentTxnID: 1, amount: -9000, note: "split"},
 			},
 		},
 	}
@@ -134,8 +134,8 @@ func TestManagerRowsUnfilteredSortByAmountUsesRemainder(t *testing.T) {
 	if rows[0].id != 1 || rows[0].isAllocation {
 		t.Fatalf("first row = %#v, want parent txn id 1", rows[0])
 	}
-	if rows[0].amount != -10 {
-		t.Fatalf("parent remainder amount = %.2f, want -10", rows[0].amount)
+	if rows[0].amount != -1000 {
+		t.Fatalf("parent remainder amount = %s, want -10.00", rows[0].amount)
 	}
 	if rows[1].id != -101 || !rows[1].isAllocation || rows[1].parentTxnID != 1 {
 		t.Fatalf("second row = %#v, want allocation child for txn 1", rows[1])
@@ -147,9 +147,9 @@ func TestManagerRowsUnfilteredSortByAmountUsesRemainder(t *testing.T) {
 
 func TestSortByAmountDescendingStableOnEqualValues(t *testing.T) {
 	rows := []transaction{
-		{id: 10, amount: 42.0, description: "A"},
-		{id: 11, amount: 42.0, description: "B"},
-		{id: 12, amount: 1.0, description: "C"},
+		{id: 10, amount: 4200, description: "A"},
+		{id: 11, amount: 4200, description: "B"},
+		{id: 12, amount: 100, description: "C"},
 	}
 	sortTransactions(rows, sortByAmount, false)
 	if rows[0].id != 10 || rows[1].id != 11 || rows[2].id != 12 {
@@ -250,8 +250,8 @@ func TestSortColumnName(t *testing.T) {
 
 func TestDashboardSpendRowsExcludesIgnoreTag(t *testing.T) {
 	rows := []transaction{
-		{id: 1, amount: -10, description: "A"},
-		{id: 2, amount: -20, description: "B"},
+		{id: 1, amount: -1000, description: "A"},
+		{id: 2, amount: -2000, description: "B"},
 	}
 	txnTags := map[int][]tag{
 		2: {{id: 1, name: "IGNORE"}},
@@ -264,12 +264,12 @@ func TestDashboardSpendRowsExcludesIgnoreTag(t *testing.T) {
 
 func TestDashboardSpendRowsKeepsRawDebitAmounts(t *testing.T) {
 	rows := []transaction{
-		{id: 1, amount: -50, description: "A"},
+		{id: 1, amount: -5000, description: "A"},
 		{id: 2, amount: +20, description: "B"},
 	}
 	out := dashboardSpendRows(rows, nil)
-	if out[0].amount != -50 {
-		t.Fatalf("debit amount = %.2f, want -50", out[0].amount)
+	if out[0].amount != -5000 {
+		t.Fatalf("debit amount = %s, want -50.00", out[0].amount)
 	}
 }
 
@@ -278,7 +278,7 @@ func TestDashboardSpendRowsKeepsRawDebitAmounts(t *testing.T) {
 // ---------------------------------------------------------------------------
 
 func testSettingsModel() model {
-	m := newModel()
+	m := newModel("")
 	m.activeTab = tabSettings
 	m.ready = true
 	m.categories = []category{
@@ -585,6 +585,47 @@ func TestSettingsDBClearConfirm(t *testing.T) {
 	}
 }
 
+func TestSettingsImportHistoryUndoConfirm(t *testing.T) {
+	db, cleanup := testDB(t)
+	defer cleanup()
+	importID, err := insertImportRecord(db, "bank.csv", 0)
+	if err != nil {
+		t.Fatalf("insertImportRecord: %v", err)
+	}
+
+	m := testSettingsModel()
+	m.db = db
+	m.imports = []importRecord{{id: importID, filename: "bank.csv", rowCount: 0}}
+	m.settSection = settSecImportHistory
+	m.settColumn = settColRight
+	m.settActive = true
+
+	delKey := bindingKeyMsg(m.primaryActionKey(scopeSettingsActiveImportHist, actionDelete, "del"))
+	next, cmd := m.updateSettings(delKey)
+	if cmd == nil {
+		t.Fatal("expected summary command")
+	}
+	next, _ = next.(model).Update(cmd())
+	got := next.(model)
+	if got.confirmAction != confirmActionUndoImport || got.confirmID != importID {
+		t.Fatalf("confirm = %q/%d, want undo import %d", got.confirmAction, got.confirmID, importID)
+	}
+	if !strings.Contains(got.status, "0 rows") || !strings.Contains(got.status, "no manual edits") {
+		t.Fatalf("status = %q, want undo summary", got.status)
+	}
+
+	next, cmd = got.updateSettings(delKey)
+	if cmd == nil {
+		t.Fatal("expected undo command")
+	}
+	if done, ok := cmd().(importUndoneMsg); !ok || done.err != nil {
+		t.Fatalf("undo msg = %+v", done)
+	}
+	if imports, _ := loadImports(db); len(imports) != 0 {
+		t.Fatalf("imports = %+v, want none", imports)
+	}
+}
+
 func TestSettingsChartToggleWeekBoundary(t *testing.T) {
 	m := testSettingsModel()
 	m.settSection = settSecChart
@@ -922,7 +963,7 @@ func TestSettingsRowsPerPageCustomDirectionalOverride(t *testing.T) {
 }
 
 func TestSettingsVisibleRowsUsesMax(t *testing.T) {
-	m := newModel()
+	m := newModel("")
 	m.maxVisibleRows = 10
 	m.height = 100 // plenty of space
 
@@ -937,7 +978,7 @@ func TestSettingsVisibleRowsUsesMax(t *testing.T) {
 // ---------------------------------------------------------------------------
 
 func TestTabCycleForward(t *testing.T) {
-	m := newModel()
+	m := newModel("")
 	m.ready = true
 	m.activeTab = tabDashboard
 
@@ -968,7 +1009,7 @@ func TestTabCycleForward(t *testing.T) {
 }
 
 func TestTabCycleBackward(t *testing.T) {
-	m := newModel()
+	m := newModel("")
 	m.ready = true
 	m.activeTab = tabDashboard
 
@@ -986,7 +1027,7 @@ func TestTabCycleBackward(t *testing.T) {
 }
 
 func TestSettingsFirstEnterDefaultsToCategoriesUnfocused(t *testing.T) {
-	m := newModel()
+	m := newModel("")
 	m.ready = true
 	m.activeTab = tabManager
 	m.settSection = -1
@@ -1012,7 +1053,7 @@ func TestSettingsFirstEnterDefaultsToCategoriesUnfocused(t *testing.T) {
 }
 
 func TestSettingsReturnPreservesSelectionAndDefocuses(t *testing.T) {
-	m := newModel()
+	m := newModel("")
 	m.ready = true
 	m.activeTab = tabSettings
 	m.settSection = settSecTags
@@ -1044,7 +1085,7 @@ func TestSettingsReturnPreservesSelectionAndDefocuses(t *testing.T) {
 }
 
 func TestTabNumberShortcuts(t *testing.T) {
-	m := newModel()
+	m := newModel("")
 	m.ready = true
 	m.managerMode = managerModeAccounts
 	tests := []struct {
@@ -1112,13 +1153,13 @@ func TestTabNumberShortcutsWorkInSettingsMenu(t *testing.T) {
 // ---------------------------------------------------------------------------
 
 func TestImportPreviewCancelFromCompact(t *testing.T) {
-	m := newModel()
+	m := newModel("")
 	m.ready = true
 	m.importPreviewOpen = true
 	m.importPreviewSnapshot = &importPreviewSnapshot{
 		fileName: "test.csv",
 		rows: []importPreviewRow{
-			{index: 1, sourceLine: 1, dateRaw: "3/02/2026", dateISO: "2026-02-03", amount: -10, description: "row"},
+			{index: 1, sourceLine: 1, dateRaw: "3/02/2026", dateISO: "2026-02-03", amount: -1000, description: "row"},
 		},
 	}
 
@@ -1134,13 +1175,13 @@ func TestImportPreviewCancelFromCompact(t *testing.T) {
 }
 
 func TestImportPreviewEscCancels(t *testing.T) {
-	m := newModel()
+	m := newModel("")
 	m.ready = true
 	m.importPreviewOpen = true
 	m.importPreviewSnapshot = &importPreviewSnapshot{
 		fileName: "test.csv",
 		rows: []importPreviewRow{
-			{index: 1, sourceLine: 1, dateRaw: "3/02/2026", dateISO: "2026-02-03", amount: -10, description: "row"},
+			{index: 1, sourceLine: 1, dateRaw: "3/02/2026", dateISO: "2026-02-03", amount: -1000, description: "row"},
 		},
 	}
 
@@ -1152,7 +1193,7 @@ func TestImportPreviewEscCancels(t *testing.T) {
 }
 
 func TestFilePickerEsc(t *testing.T) {
-	m := newModel()
+	m := newModel("")
 	m.ready = true
 	m.importPicking = true
 	m.importFiles = []string{"a.csv", "b.csv"}
@@ -1164,8 +1205,36 @@ func TestFilePickerEsc(t *testing.T) {
 	}
 }
 
+func TestFilePickerCyclesFormatCandidates(t *testing.T) {
+	m := newModel("")
+	m.ready = true
+	m.importPicking = true
+	m.importFiles = []string{"download (3).csv", "b.csv"}
+	m.importFileMatches = map[string][]formatMatch{
+		"download (3).csv": {{formatIndex: 1, name: "CBA", score: 1}, {formatIndex: 0, name: "ANZ", score: 0.5}},
+	}
+
+	next, _ := m.Update(keyMsg("l"))
+	got := next.(model)
+	if got.importFormatChoice != 1 {
+		t.Fatalf("after l: importFormatChoice = %d, want 1", got.importFormatChoice)
+	}
+	next, _ = got.Update(keyMsg("l"))
+	got = next.(model)
+	if got.importFormatChoice != 0 {
+		t.Fatalf("choice should wrap, got %d", got.importFormatChoice)
+	}
+	next, _ = got.Update(keyMsg("h"))
+	got = next.(model)
+	next, _ = got.Update(keyMsg("j"))
+	got = next.(model)
+	if got.importFormatChoice != 0 {
+		t.Fatalf("moving to another file should reset choice, got %d", got.importFormatChoice)
+	}
+}
+
 func TestFilePickerNavigation(t *testing.T) {
-	m := newModel()
+	m := newModel("")
 	m.ready = true
 	m.importPicking = true
 	m.importFiles = []string{"a.csv", "b.csv", "c.csv"}
@@ -1484,7 +1553,7 @@ func assertScrollableWindowInvariant(
 }
 
 func TestTransactionsTableDownNavigationViewportStable(t *testing.T) {
-	m := newModel()
+	m := newModel("")
 	m.ready = true
 	m.activeTab = tabManager
 	m.managerMode = managerModeTransactions
@@ -1496,7 +1565,7 @@ func TestTransactionsTableDownNavigationViewportStable(t *testing.T) {
 		rows = append(rows, transaction{
 			id:           i + 1,
 			dateISO:      "2026-02-10",
-			amount:       float64(-i - 1),
+			amount:       money(-100 * (i + 1)),
 			description:  "TXN",
 			categoryName: "Uncategorised",
 		})
@@ -1516,7 +1585,7 @@ func TestTransactionsTableDownNavigationViewportStable(t *testing.T) {
 }
 
 func TestManagerTransactionsDownNavigationDoesNotShrinkVisibleRows(t *testing.T) {
-	m := newModel()
+	m := newModel("")
 	m.ready = true
 	m.activeTab = tabManager
 	m.managerMode = managerModeTransactions
@@ -1530,7 +1599,7 @@ func TestManagerTransactionsDownNavigationDoesNotShrinkVisibleRows(t *testing.T)
 		rows = append(rows, transaction{
 			id:           i + 1,
 			dateISO:      "2026-02-10",
-			amount:       float64(-i - 1),
+			amount:       money(-100 * (i + 1)),
 			description:  "TXN",
 			categoryName: "Uncategorised",
 		})
@@ -1549,7 +1618,7 @@ func TestManagerTransactionsDownNavigationDoesNotShrinkVisibleRows(t *testing.T)
 }
 
 func TestFilePickerCursorClampsWithRepeatedNavigation(t *testing.T) {
-	m := newModel()
+	m := newModel("")
 	m.ready = true
 	m.importPicking = true
 	m.importFiles = []string{"a.csv", "b.csv", "c.csv"}
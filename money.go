@@ -0,0 +1,95 @@
+package main
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// money is an amount in minor units (cents). Amounts are stored as SQLite
+// INTEGERs and summed as integers, so totals, budget remainders and
+// allocation capacity are exact. Floats only appear at the edges: parsing
+// bank files, FX conversion and chart scaling.
+type money int64
+
+// moneyFromFloat rounds a decimal amount to the nearest cent.
+func moneyFromFloat(v float64) money {
+	return money(math.Round(v * 100))
+}
+
+// float returns the amount in major units, for ratios and FX maths.
+func (m money) float() float64 {
+	return float64(m) / 100
+}
+
+func (m money) abs() money {
+	if m < 0 {
+		return -m
+	}
+	return m
+}
+
+// String formats the amount as a plain signed decimal ("-1234.50"), the
+// form used in edit fields and duplicate keys.
+func (m money) String() string {
+	sign := ""
+	v := int64(m)
+	if v < 0 {
+		sign = "-"
+		v = -v
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, v/100, v%100)
+}
+
+// Value stores money as integer cents.
+func (m money) Value() (driver.Value, error) {
+	return int64(m), nil
+}
+
+// Scan reads integer cents. A REAL, as computed expressions such as AVG()
+// return, is rounded to the nearest cent.
+func (m *money) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		*m = 0
+	case int64:
+		*m = money(v)
+	case float64:
+		*m = money(math.Round(v))
+	case []byte:
+		return m.scanText(string(v))
+	case string:
+		return m.scanText(v)
+	default:
+		return fmt.Errorf("scan money: unsupported type %T", src)
+	}
+	return nil
+}
+
+func (m *money) scanText(s string) error {
+	n, err := strconv.ParseInt(s, 10, 64)
+	if err != nil {
+		return fmt.Errorf("scan money %q: %w", s, err)
+	}
+	*m = money(n)
+	return nil
+}
+
+// parseMoney parses a bank or user-entered amount to the cent.
+func parseMoney(input string) (money, error) {
+	v, err := parseAmount(input)
+	if err != nil {
+		return 0, err
+	}
+	return moneyFromFloat(v), nil
+}
+
+// parseMoneyLocale is parseMoney in the given number locale.
+func parseMoneyLocale(input, locale string) (money, error) {
+	v, err := parseAmountLocale(input, locale)
+	if err != nil {
+		return 0, err
+	}
+	return moneyFromFloat(v), nil
+}
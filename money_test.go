@@ -0,0 +1,50 @@
+package main
+
+import "testing"
+
+func TestMoneyStringAndParse(t *testing.T) {
+	tests := []struct {
+		in   string
+		want money
+		str  string
+	}{
+		{"-12.34", -1234, "-12.34"},
+		{"0.1", 10, "0.10"},
+		{"-0.07", -7, "-0.07"},
+		{"1,234.5", 123450, "1234.50"},
+	}
+	for _, tt := range tests {
+		got, err := parseMoney(tt.in)
+		if err != nil {
+			t.Fatalf("parseMoney(%q): %v", tt.in, err)
+		}
+		if got != tt.want || got.String() != tt.str {
+			t.Errorf("parseMoney(%q) = %d (%s), want %d (%s)", tt.in, got, got, tt.want, tt.str)
+		}
+	}
+}
+
+func TestMoneyScan(t *testing.T) {
+	tests := []struct {
+		src  any
+		want money
+	}{
+		{int64(-1234), -1234},
+		{float64(1049.6), 1050},
+		{[]byte("250"), 250},
+		{nil, 0},
+	}
+	for _, tt := range tests {
+		var m money
+		if err := m.Scan(tt.src); err != nil {
+			t.Fatalf("Scan(%v): %v", tt.src, err)
+		}
+		if m != tt.want {
+			t.Errorf("Scan(%v) = %d, want %d", tt.src, m, tt.want)
+		}
+	}
+	var m money
+	if err := m.Scan("12.50"); err == nil {
+		t.Fatal("expected error scanning a decimal string")
+	}
+}
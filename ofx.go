@@ -0,0 +1,380 @@
+package main
+
+import (
+	"bytes"
+	"database/sql"
+	"fmt"
+	"html"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ofxStatement is one bank or credit-card statement aggregate (STMTRS or
+// CCSTMTRS) extracted from an OFX/QFX file.
+type ofxStatement struct {
+	accountID    string
+	transactions []ofxTransaction
+
+	ledgerBalanceRaw string
+	ledgerDateRaw    string
+}
+
+// ofxTransaction is a single STMTTRN record. Values are kept raw so parse
+// failures can be reported per row in the import preview.
+type ofxTransaction struct {
+	line       int
+	trnType    string
+	datePosted string
+	amount     string
+	fitID      string
+	name       string
+	memo       string
+}
+
+type ofxToken struct {
+	name    string // upper-case element name
+	closing bool
+	text    string // character data following the tag, trimmed and unescaped
+	line    int
+}
+
+func isOFXFileName(name string) bool {
+	ext := strings.ToLower(filepath.Ext(name))
+	return ext == ".ofx" || ext == ".qfx"
+}
+
+// tokenizeOFX splits an OFX body into element tokens. It handles both SGML
+// (OFX 1.x, leaf elements without end tags) and XML (OFX 2.x) documents by
+// treating leaf values as the text between a start tag and the next tag.
+// Headers, processing instructions and comments are skipped.
+func tokenizeOFX(data []byte) ([]ofxToken, error) {
+	start := bytes.Index(bytes.ToUpper(data), []byte("<OFX"))
+	if start < 0 {
+		return nil, fmt.Errorf("not an OFX document: missing <OFX> element")
+	}
+	line := 1 + bytes.Count(data[:start], []byte("\n"))
+	body := data[start:]
+
+	var tokens []ofxToken
+	for i := 0; i < len(body); {
+		if body[i] != '<' {
+			if body[i] == '\n' {
+				line++
+			}
+			i++
+			continue
+		}
+		end := bytes.IndexByte(body[i:], '>')
+		if end < 0 {
+			return nil, fmt.Errorf("line %d: unterminated tag", line)
+		}
+		raw := string(body[i+1 : i+end])
+		i += end + 1
+		if raw == "" || raw[0] == '?' || raw[0] == '!' {
+			line += strings.Count(raw, "\n")
+			continue
+		}
+		tok := ofxToken{line: line}
+		if raw[0] == '/' {
+			tok.closing = true
+			raw = raw[1:]
+		}
+		raw = strings.TrimSuffix(strings.TrimSpace(raw), "/")
+		if fields := strings.Fields(raw); len(fields) > 0 {
+			tok.name = strings.ToUpper(fields[0])
+		}
+		line += strings.Count(raw, "\n")
+
+		next := bytes.IndexByte(body[i:], '<')
+		if next < 0 {
+			next = len(body) - i
+		}
+		text := string(body[i : i+next])
+		if !tok.closing {
+			tok.text = html.UnescapeString(strings.TrimSpace(text))
+		}
+		line += strings.Count(text, "\n")
+		i += next
+		if tok.name != "" {
+			tokens = append(tokens, tok)
+		}
+	}
+	return tokens, nil
+}
+
+// parseOFX extracts every statement aggregate from an OFX/QFX document.
+func parseOFX(data []byte) ([]ofxStatement, error) {
+	tokens, err := tokenizeOFX(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var (
+		statements  []ofxStatement
+		stmt        *ofxStatement
+		txn         *ofxTransaction
+		inAcctFrom  bool
+		inLedgerBal bool
+	)
+	for _, tok := range tokens {
+		if tok.closing {
+			switch tok.name {
+			case "STMTRS", "CCSTMTRS":
+				if stmt != nil {
+					statements = append(statements, *stmt)
+					stmt = nil
+				}
+			case "STMTTRN":
+				if stmt != nil && txn != nil {
+					stmt.transactions = append(stmt.transactions, *txn)
+				}
+				txn = nil
+			case "BANKACCTFROM", "CCACCTFROM":
+				inAcctFrom = false
+			case "LEDGERBAL":
+				inLedgerBal = false
+			}
+			continue
+		}
+
+		if tok.name == "STMTRS" || tok.name == "CCSTMTRS" {
+			stmt = &ofxStatement{}
+			continue
+		}
+		if stmt == nil {
+			continue
+		}
+		switch tok.name {
+		case "STMTTRN":
+			txn = &ofxTransaction{line: tok.line}
+		case "BANKACCTFROM", "CCACCTFROM":
+			inAcctFrom = true
+		case "LEDGERBAL":
+			inLedgerBal = true
+		case "ACCTID":
+			if inAcctFrom {
+				stmt.accountID = tok.text
+			}
+		case "BALAMT":
+			if inLedgerBal {
+				stmt.ledgerBalanceRaw = tok.text
+			}
+		case "DTASOF":
+			if inLedgerBal {
+				stmt.ledgerDateRaw = tok.text
+			}
+		}
+		if txn == nil {
+			continue
+		}
+		switch tok.name {
+		case "TRNTYPE":
+			txn.trnType = tok.text
+		case "DTPOSTED":
+			txn.datePosted = tok.text
+		case "TRNAMT":
+			txn.amount = tok.text
+		case "FITID":
+			txn.fitID = tok.text
+		case "NAME", "PAYEE":
+			if txn.name == "" {
+				txn.name = tok.text
+			}
+		case "MEMO":
+			txn.memo = tok.text
+		}
+	}
+	if stmt != nil {
+		// SGML files occasionally omit the closing statement tag.
+		statements = append(statements, *stmt)
+	}
+	if len(statements) == 0 {
+		return nil, fmt.Errorf("no bank or credit card statement found")
+	}
+	return statements, nil
+}
+
+// parseOFXDate converts an OFX datetime (YYYYMMDD[HHMMSS[.XXX]][[TZ]]) to ISO.
+func parseOFXDate(raw string) (string, error) {
+	raw = strings.TrimSpace(raw)
+	if len(raw) < 8 {
+		return "", fmt.Errorf("invalid OFX date %q", raw)
+	}
+	parsed, err := time.Parse("20060102", raw[:8])
+	if err != nil {
+		return "", fmt.Errorf("invalid OFX date %q", raw)
+	}
+	return parsed.Format("2006-01-02"), nil
+}
+
+// parseOFXAmount parses a TRNAMT/BALAMT value. OFX permits a comma as the
+// decimal separator, so a lone comma is treated as the decimal point.
+func parseOFXAmount(raw string) (money, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return 0, fmt.Errorf("amount is required")
+	}
+	if strings.Contains(raw, ",") && !strings.Contains(raw, ".") {
+		raw = strings.ReplaceAll(raw, ",", ".")
+	}
+	v, err := strconv.ParseFloat(strings.TrimPrefix(raw, "+"), 64)
+	if err != nil {
+		return 0, err
+	}
+	return moneyFromFloat(v), nil
+}
+
+// ofxDescription joins NAME and MEMO, skipping MEMO when it repeats NAME.
+func ofxDescription(txn ofxTransaction) string {
+	name := strings.TrimSpace(txn.name)
+	memo := strings.TrimSpace(txn.memo)
+	switch {
+	case name == "":
+		return memo
+	case memo == "" || strings.EqualFold(name, memo):
+		return name
+	default:
+		return name + " " + memo
+	}
+}
+
+// formatForOFXAccount maps a statement's ACCTID to a configured format.
+// A format matches when its ofx_account_id equals the statement ID or is a
+// suffix of it (banks often mask leading digits). Without a match, the
+// filename prefix rules of detectFormat apply.
+func formatForOFXAccount(formats []csvFormat, acctID, fileName string) *csvFormat {
+	acctID = strings.TrimSpace(acctID)
+	if acctID != "" {
+		for i := range formats {
+			want := strings.TrimSpace(formats[i].OFXAccountID)
+			if want != "" && strings.EqualFold(want, acctID) {
+				return &formats[i]
+			}
+		}
+		for i := range formats {
+			want := strings.TrimSpace(formats[i].OFXAccountID)
+			if want != "" && strings.HasSuffix(strings.ToUpper(acctID), strings.ToUpper(want)) {
+				return &formats[i]
+			}
+		}
+	}
+	return detectFormat(formats, fileName)
+}
+
+// buildOFXImportPreviewSnapshot parses an OFX/QFX file into the same
+// immutable preview snapshot used for CSV imports. FITIDs are carried as
+// external references so re-downloaded statements dedupe exactly.
+func buildOFXImportPreviewSnapshot(db *sql.DB, path, fileName string, formats []csvFormat, savedFilters []savedFilter, match dupeMatchSettings) (*importPreviewSnapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("open ofx: %w", err)
+	}
+	statements, err := parseOFX(data)
+	if err != nil {
+		return nil, fmt.Errorf("parse ofx: %w", err)
+	}
+	if len(statements) > 1 {
+		return nil, fmt.Errorf("%s contains %d statements; export one account per file", fileName, len(statements))
+	}
+	stmt := statements[0]
+
+	format := formatForOFXAccount(formats, stmt.accountID, fileName)
+	if format == nil {
+		return nil, fmt.Errorf("no account mapped for OFX account %q; set ofx_account_id in config", stmt.accountID)
+	}
+	acct, err := loadAccountByNameCI(db, format.Account)
+	if err != nil {
+		return nil, fmt.Errorf("resolve account %q: %w", format.Account, err)
+	}
+	if acct == nil {
+		return nil, fmt.Errorf("account %q not found; create or sync it in Manager", format.Account)
+	}
+
+	existingSet, err := loadDuplicateSet(db)
+	if err != nil {
+		return nil, fmt.Errorf("load duplicates: %w", err)
+	}
+	rows, parseErrors := ofxPreviewRows(stmt, acct.id, existingSet)
+
+	snapshot := &importPreviewSnapshot{
+		fileName:    fileName,
+		createdAt:   time.Now(),
+		totalRows:   len(stmt.transactions),
+		rows:        rows,
+		parseErrors: parseErrors,
+		errorCount:  len(parseErrors),
+		accountID:   acct.id,
+		formatName:  "OFX",
+		dateFormat:  "20060102",
+	}
+	if stmt.ledgerBalanceRaw != "" {
+		if bal, balErr := parseOFXAmount(stmt.ledgerBalanceRaw); balErr == nil {
+			snapshot.statementBalance = &bal
+			if iso, dateErr := parseOFXDate(stmt.ledgerDateRaw); dateErr == nil {
+				snapshot.statementBalanceDate = iso
+			}
+		}
+	}
+	if err := finalizeImportPreviewSnapshot(db, snapshot, *acct, savedFilters, match); err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
+
+// ofxParseError describes a transaction that failed to parse, keeping its
+// fields for quarantine.
+func ofxParseError(txn ofxTransaction, rowIndex int, field string, err error) importPreviewParseError {
+	return importPreviewParseError{
+		rowIndex:    rowIndex,
+		sourceLine:  txn.line,
+		field:       field,
+		message:     err.Error(),
+		raw:         fmt.Sprintf("TRNTYPE=%s DTPOSTED=%s TRNAMT=%s FITID=%s NAME=%s MEMO=%s", txn.trnType, txn.datePosted, txn.amount, txn.fitID, txn.name, txn.memo),
+		dateRaw:     txn.datePosted,
+		amountRaw:   txn.amount,
+		description: ofxDescription(txn),
+	}
+}
+
+func ofxPreviewRows(stmt ofxStatement, accountID int, existingSet map[string]bool) ([]importPreviewRow, []importPreviewParseError) {
+	var rows []importPreviewRow
+	var parseErrors []importPreviewParseError
+	seenRefs := make(map[string]bool)
+	for i, txn := range stmt.transactions {
+		rowIndex := i + 1
+		dateISO, err := parseOFXDate(txn.datePosted)
+		if err != nil {
+			parseErrors = append(parseErrors, ofxParseError(txn, rowIndex, "date", err))
+			continue
+		}
+		amount, err := parseOFXAmount(txn.amount)
+		if err != nil {
+			parseErrors = append(parseErrors, ofxParseError(txn, rowIndex, "amount", err))
+			continue
+		}
+		description := ofxDescription(txn)
+		ref := strings.TrimSpace(txn.fitID)
+		var isDupe bool
+		if ref != "" {
+			// A FITID repeated within the file is the same transaction.
+			isDupe = existingSet[duplicateRefKeyForAccount(ref, &accountID)] || seenRefs[ref]
+			seenRefs[ref] = true
+		} else {
+			isDupe = existingSet[duplicateKeyForAccount(dateISO, amount, description, &accountID)]
+		}
+		rows = append(rows, importPreviewRow{
+			index:       rowIndex,
+			sourceLine:  txn.line,
+			dateRaw:     strings.TrimSpace(txn.datePosted),
+			dateISO:     dateISO,
+			amount:      amount,
+			description: description,
+			externalRef: ref,
+			isDupe:      isDupe,
+		})
+	}
+	return rows, parseErrors
+}
@@ -0,0 +1,220 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testOFXSGML = `OFXHEADER:100
+DATA:OFXSGML
+VERSION:102
+SECURITY:NONE
+ENCODING:USASCII
+
+<OFX>
+<BANKMSGSRSV1>
+<STMTTRNRS>
+<STMTRS>
+<CURDEF>AUD
+<BANKACCTFROM>
+<BANKID>012345
+<ACCTID>123456789
+<ACCTTYPE>CHECKING
+</BANKACCTFROM>
+<BANKTRANLIST>
+<DTSTART>20260201
+<DTEND>20260228
+<STMTTRN>
+<TRNTYPE>DEBIT
+<DTPOSTED>20260203120000.000[+11:AEDT]
+<TRNAMT>-20.00
+<FITID>FIT-001
+<NAME>DAN MURPHYS
+<MEMO>CARD 1234
+</STMTTRN>
+<STMTTRN>
+<TRNTYPE>CREDIT
+<DTPOSTED>20260204
+<TRNAMT>203,92
+<FITID>FIT-002
+<NAME>PAYMENT RECEIVED
+</STMTTRN>
+</BANKTRANLIST>
+<LEDGERBAL>
+<BALAMT>1500.25
+<DTASOF>20260228
+</LEDGERBAL>
+</STMTRS>
+</STMTTRNRS>
+</BANKMSGSRSV1>
+</OFX>
+`
+
+const testOFXXML = `<?xml version="1.0" encoding="UTF-8"?>
+<?OFX OFXHEADER="200" VERSION="220" SECURITY="NONE"?>
+<OFX>
+  <CREDITCARDMSGSRSV1>
+    <CCSTMTTRNRS>
+      <CCSTMTRS>
+        <CURDEF>USD</CURDEF>
+        <CCACCTFROM><ACCTID>XXXXXXXXXXXX4321</ACCTID></CCACCTFROM>
+        <BANKTRANLIST>
+          <STMTTRN>
+            <TRNTYPE>DEBIT</TRNTYPE>
+            <DTPOSTED>20260210</DTPOSTED>
+            <TRNAMT>-12.50</TRNAMT>
+            <FITID>CC-9</FITID>
+            <NAME>BEANS &amp; CO</NAME>
+          </STMTTRN>
+        </BANKTRANLIST>
+        <LEDGERBAL><BALAMT>-12.50</BALAMT><DTASOF>20260211</DTASOF></LEDGERBAL>
+      </CCSTMTRS>
+    </CCSTMTTRNRS>
+  </CREDITCARDMSGSRSV1>
+</OFX>
+`
+
+func TestParseOFXSGML(t *testing.T) {
+	statements, err := parseOFX([]byte(testOFXSGML))
+	if err != nil {
+		t.Fatalf("parseOFX: %v", err)
+	}
+	if len(statements) != 1 {
+		t.Fatalf("statements=%d want 1", len(statements))
+	}
+	stmt := statements[0]
+	if stmt.accountID != "123456789" {
+		t.Fatalf("statement header = %+v", stmt)
+	}
+	if stmt.ledgerBalanceRaw != "1500.25" || stmt.ledgerDateRaw != "20260228" {
+		t.Fatalf("ledger = %q @ %q", stmt.ledgerBalanceRaw, stmt.ledgerDateRaw)
+	}
+	if len(stmt.transactions) != 2 {
+		t.Fatalf("transactions=%d want 2", len(stmt.transactions))
+	}
+	first := stmt.transactions[0]
+	if first.fitID != "FIT-001" || first.amount != "-20.00" || ofxDescription(first) != "DAN MURPHYS CARD 1234" {
+		t.Fatalf("first txn = %+v", first)
+	}
+	if first.line != 20 {
+		t.Fatalf("first txn line=%d want 20", first.line)
+	}
+	amount, err := parseOFXAmount(stmt.transactions[1].amount)
+	if err != nil || amount != 20392 {
+		t.Fatalf("comma decimal amount = %v (%v), want 203.92", amount, err)
+	}
+}
+
+func TestParseOFXXML(t *testing.T) {
+	statements, err := parseOFX([]byte(testOFXXML))
+	if err != nil {
+		t.Fatalf("parseOFX: %v", err)
+	}
+	stmt := statements[0]
+	if stmt.accountID != "XXXXXXXXXXXX4321" {
+		t.Fatalf("statement header = %+v", stmt)
+	}
+	if len(stmt.transactions) != 1 || stmt.transactions[0].name != "BEANS & CO" {
+		t.Fatalf("transactions = %+v", stmt.transactions)
+	}
+	iso, err := parseOFXDate(stmt.transactions[0].datePosted)
+	if err != nil || iso != "2026-02-10" {
+		t.Fatalf("date = %q (%v)", iso, err)
+	}
+}
+
+func TestParseOFXRejectsNonOFX(t *testing.T) {
+	if _, err := parseOFX([]byte("3/02/2026,-20.00,NOT OFX\n")); err == nil {
+		t.Fatal("expected error for non-OFX content")
+	}
+}
+
+func TestFormatForOFXAccountMatchesSuffix(t *testing.T) {
+	formats := []csvFormat{
+		{Name: "ANZ", Account: "ANZ", ImportPrefix: "anz"},
+		{Name: "Visa", Account: "Visa", ImportPrefix: "visa", OFXAccountID: "4321"},
+	}
+	got := formatForOFXAccount(formats, "XXXXXXXXXXXX4321", "download.qfx")
+	if got == nil || got.Name != "Visa" {
+		t.Fatalf("format = %+v, want Visa", got)
+	}
+	got = formatForOFXAccount(formats, "999", "anz-feb.ofx")
+	if got == nil || got.Name != "ANZ" {
+		t.Fatalf("fallback format = %+v, want ANZ", got)
+	}
+}
+
+func TestScanDupesCmdOFXDedupesOnFITID(t *testing.T) {
+	db, cleanup := testDB(t)
+	defer cleanup()
+
+	if _, err := insertAccount(db, "Everyday", "debit", true); err != nil {
+		t.Fatalf("insertAccount: %v", err)
+	}
+	formats := []csvFormat{{Name: "Everyday", Account: "Everyday", ImportPrefix: "everyday", OFXAccountID: "123456789", DateFormat: "2006-01-02"}}
+
+	dir := t.TempDir()
+	file := "statement.ofx"
+	if err := os.WriteFile(filepath.Join(dir, file), []byte(testOFXSGML), 0o644); err != nil {
+		t.Fatalf("write ofx: %v", err)
+	}
+
+	msg := scanDupesCmd(db, file, dir, formats, nil, defaultSettings().dupeMatch())()
+	done, ok := msg.(importPreviewMsg)
+	if !ok || done.err != nil || done.snapshot == nil {
+		t.Fatalf("scan = %T %+v", msg, msg)
+	}
+	snap := done.snapshot
+	if snap.newCount != 2 || snap.dupeCount != 0 || snap.errorCount != 0 {
+		t.Fatalf("counts new=%d dupes=%d errors=%d", snap.newCount, snap.dupeCount, snap.errorCount)
+	}
+	if snap.statementBalance == nil || *snap.statementBalance != 150025 || snap.statementBalanceDate != "2026-02-28" {
+		t.Fatalf("statement balance = %v @ %q", snap.statementBalance, snap.statementBalanceDate)
+	}
+	if _, _, _, _, _, _, err := importSnapshotRows(db, snap, true); err != nil {
+		t.Fatalf("importSnapshotRows: %v", err)
+	}
+
+	// Same FITIDs with a changed description must still be duplicates.
+	edited := []byte(strings.Replace(testOFXSGML, "<NAME>DAN MURPHYS", "<NAME>DAN MURPHYS 0412", 1))
+	if err := os.WriteFile(filepath.Join(dir, file), edited, 0o644); err != nil {
+		t.Fatalf("rewrite ofx: %v", err)
+	}
+	msg = scanDupesCmd(db, file, dir, formats, nil, defaultSettings().dupeMatch())()
+	done = msg.(importPreviewMsg)
+	if done.err != nil {
+		t.Fatalf("rescan: %v", done.err)
+	}
+	if done.snapshot.dupeCount != 2 || done.snapshot.newCount != 0 {
+		t.Fatalf("rescan new=%d dupes=%d, want 0/2", done.snapshot.newCount, done.snapshot.dupeCount)
+	}
+}
+
+func TestOFXPreviewRowsFlagsRepeatedFITID(t *testing.T) {
+	stmts, err := parseOFX([]byte(strings.Replace(testOFXSGML, "FIT-002", "FIT-001", 1)))
+	if err != nil {
+		t.Fatalf("parseOFX: %v", err)
+	}
+	rows, _ := ofxPreviewRows(stmts[0], 1, map[string]bool{})
+	if len(rows) != 2 || rows[0].isDupe || !rows[1].isDupe {
+		t.Fatalf("rows = %+v, want the repeated FITID flagged", rows)
+	}
+}
+
+func TestLoadFilesCmdListsOFXAndQFX(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.csv", "b.OFX", "c.qfx", "notes.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("x"), 0o644); err != nil {
+			t.Fatalf("write %s: %v", name, err)
+		}
+	}
+	msg := loadFilesCmd(dir, importInboxSettings{}, nil)().(filesLoadedMsg)
+	if msg.err != nil {
+		t.Fatalf("loadFilesCmd: %v", msg.err)
+	}
+	if len(msg.files) != 3 {
+		t.Fatalf("files=%v want 3 importable files", msg.files)
+	}
+}
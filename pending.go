@@ -0,0 +1,239 @@
+package main
+
+import (
+	"database/sql"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Transaction states. Pending authorisations are replaced by their posted
+// transaction when a later import brings it in.
+const (
+	txnStatusPosted  = "posted"
+	txnStatusPending = "pending"
+)
+
+// Pending-to-posted matching: a posted row settles a pending transaction in
+// the same account dated up to pendingMatchDays earlier or later, described
+// at least pendingMatchSimilarity alike, with an amount of the same sign
+// within pendingAmountSlack of the pending amount (tips, FX and hotel holds
+// change between authorisation and posting).
+const (
+	pendingMatchDays       = 10
+	pendingMatchSimilarity = 0.5
+	pendingAmountSlack     = 0.25
+)
+
+// txnStatusFromText maps a bank's status cell onto a transaction status.
+// Anything not recognisably pending counts as posted.
+func txnStatusFromText(raw string) string {
+	switch strings.ToLower(strings.TrimSpace(raw)) {
+	case "pending", "p", "authorised", "authorized", "authorisation", "authorization",
+		"uncleared", "unposted", "hold", "on hold", "processing":
+		return txnStatusPending
+	}
+	return txnStatusPosted
+}
+
+// txnStatusOrPosted defaults an unset status (OFX, QIF, formats without a
+// status_col) to posted.
+func txnStatusOrPosted(status string) string {
+	if status == txnStatusPending {
+		return txnStatusPending
+	}
+	return txnStatusPosted
+}
+
+// pendingAmountMatches reports whether a posted amount is plausibly the
+// settled form of a pending one.
+func pendingAmountMatches(pending, posted money) bool {
+	if (pending < 0) != (posted < 0) {
+		return false
+	}
+	slack := max(moneyFromFloat(pending.abs().float()*pendingAmountSlack), 100)
+	return (posted - pending).abs() <= slack
+}
+
+// matchPendingTransactions links posted preview rows to the pending
+// transactions they settle. Each pending transaction matches at most one
+// row, preferring the most similar description, then the closest amount,
+// then the closest date. A row that was only an exact duplicate of a pending
+// transaction is no longer treated as a duplicate.
+func matchPendingTransactions(db *sql.DB, rows []importPreviewRow, accountID int) error {
+	minDate, maxDate := "", ""
+	for _, row := range rows {
+		if row.status == txnStatusPending {
+			continue
+		}
+		if minDate == "" || row.dateISO < minDate {
+			minDate = row.dateISO
+		}
+		if row.dateISO > maxDate {
+			maxDate = row.dateISO
+		}
+	}
+	if minDate == "" {
+		return nil
+	}
+	lo, errLo := time.Parse("2006-01-02", minDate)
+	hi, errHi := time.Parse("2006-01-02", maxDate)
+	if errLo != nil || errHi != nil {
+		return nil
+	}
+	window := time.Duration(pendingMatchDays) * 24 * time.Hour
+
+	dbRows, err := db.Query(`
+		SELECT id, date_iso, amount, description
+		FROM transactions
+		WHERE account_id = ? AND status = ? AND date_iso >= ? AND date_iso <= ?
+		ORDER BY date_iso, id
+	`, accountID, txnStatusPending, lo.Add(-window).Format("2006-01-02"), hi.Add(window).Format("2006-01-02"))
+	if err != nil {
+		return fmt.Errorf("load pending transactions: %w", err)
+	}
+	defer dbRows.Close()
+	var candidates []importDupeMatch
+	for dbRows.Next() {
+		var c importDupeMatch
+		if err := dbRows.Scan(&c.txnID, &c.dateISO, &c.amount, &c.description); err != nil {
+			return fmt.Errorf("scan pending transaction: %w", err)
+		}
+		candidates = append(candidates, c)
+	}
+	if err := dbRows.Err(); err != nil {
+		return err
+	}
+	if len(candidates) == 0 {
+		return nil
+	}
+
+	used := make(map[int]bool)
+	for i := range rows {
+		row := &rows[i]
+		if row.status == txnStatusPending {
+			continue
+		}
+		var best *importDupeMatch
+		var bestAmt money
+		bestDays := 0
+		for _, c := range candidates {
+			if used[c.txnID] || !pendingAmountMatches(c.amount, row.amount) {
+				continue
+			}
+			exact := c.dateISO == row.dateISO && c.amount == row.amount && c.description == row.description
+			if row.isDupe && !exact {
+				// The duplicate is some other transaction, not this pending one.
+				continue
+			}
+			days, ok := daysBetweenISO(row.dateISO, c.dateISO)
+			if !ok || days > pendingMatchDays {
+				continue
+			}
+			c.similarity = descriptionSimilarity(row.description, c.description)
+			if c.similarity < pendingMatchSimilarity {
+				continue
+			}
+			amt := (row.amount - c.amount).abs()
+			better := best == nil || c.similarity > best.similarity ||
+				(c.similarity == best.similarity && (amt < bestAmt || (amt == bestAmt && days < bestDays)))
+			if better {
+				match := c
+				best, bestAmt, bestDays = &match, amt, days
+			}
+		}
+		if best != nil {
+			used[best.txnID] = true
+			row.replacesPending = best
+			row.isDupe = false
+		}
+	}
+	return nil
+}
+
+// settlePendingTx moves a pending transaction's category, notes, tags,
+// allocations and history onto the posted transaction that replaces it,
+// then deletes the pending row and counts it against the posted row's
+// import so that import can't be undone.
+func settlePendingTx(tx *sql.Tx, pendingID, postedID int) error {
+	stmts := []struct {
+		query string
+		args  []any
+	}{
+		{`UPDATE transactions
+		  SET category_id = COALESCE((SELECT category_id FROM transactions WHERE id = ?), category_id),
+		      notes = CASE WHEN (SELECT notes FROM transactions WHERE id = ?) != ''
+		                   THEN (SELECT notes FROM transactions WHERE id = ?) ELSE notes END
+		  WHERE id = ?`, []any{pendingID, pendingID, pendingID, postedID}},
+		{`INSERT OR IGNORE INTO transaction_tags (transaction_id, tag_id)
+		  SELECT ?, tag_id FROM transaction_tags WHERE transaction_id = ?`, []any{postedID, pendingID}},
+		{`UPDATE transaction_allocations SET parent_txn_id = ? WHERE parent_txn_id = ?`, []any{postedID, pendingID}},
+		{`UPDATE transaction_history SET txn_id = ? WHERE txn_id = ?`, []any{postedID, pendingID}},
+		{`DELETE FROM transaction_tags WHERE transaction_id = ?`, []any{pendingID}},
+		{`DELETE FROM transactions WHERE id = ? AND status = ?`, []any{pendingID, txnStatusPending}},
+		{`UPDATE imports SET settled_pending = settled_pending + 1
+		  WHERE id = (SELECT import_id FROM transactions WHERE id = ?)`, []any{postedID}},
+	}
+	for _, stmt := range stmts {
+		if _, err := tx.Exec(stmt.query, stmt.args...); err != nil {
+			return fmt.Errorf("settle pending txn %d: %w", pendingID, err)
+		}
+	}
+	return fitAllocationsToAmountTx(tx, postedID)
+}
+
+// fitAllocationsToAmountTx scales a transaction's allocations down
+// proportionally when together they exceed its amount, as they can after
+// settling moves them onto a smaller posted amount. Allocations that round
+// to nothing are removed.
+func fitAllocationsToAmountTx(tx *sql.Tx, txnID int) error {
+	amount, err := loadTransactionAmountTx(tx, txnID)
+	if err != nil {
+		return err
+	}
+	rows, err := tx.Query(`SELECT id, amount FROM transaction_allocations WHERE parent_txn_id = ? ORDER BY id`, txnID)
+	if err != nil {
+		return fmt.Errorf("load allocations for txn %d: %w", txnID, err)
+	}
+	type alloc struct {
+		id     int
+		amount money
+	}
+	var allocs []alloc
+	var total money
+	for rows.Next() {
+		var a alloc
+		if err := rows.Scan(&a.id, &a.amount); err != nil {
+			rows.Close()
+			return fmt.Errorf("scan allocation: %w", err)
+		}
+		allocs = append(allocs, a)
+		total += a.amount.abs()
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("iterate allocations: %w", err)
+	}
+	if total <= amount.abs() {
+		return nil
+	}
+	for _, a := range allocs {
+		scaled := money(int64(a.amount.abs()) * int64(amount.abs()) / int64(total))
+		if scaled == 0 {
+			if _, err := tx.Exec(`DELETE FROM transaction_allocation_tags WHERE allocation_id = ?`, a.id); err != nil {
+				return fmt.Errorf("delete allocation tags: %w", err)
+			}
+			if _, err := tx.Exec(`DELETE FROM transaction_allocations WHERE id = ?`, a.id); err != nil {
+				return fmt.Errorf("delete allocation: %w", err)
+			}
+			continue
+		}
+		if amount < 0 {
+			scaled = -scaled
+		}
+		if _, err := tx.Exec(`UPDATE transaction_allocations SET amount = ? WHERE id = ?`, scaled, a.id); err != nil {
+			return fmt.Errorf("scale allocation %d: %w", a.id, err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,214 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestTxnStatusFromText(t *testing.T) {
+	cases := map[string]string{
+		"Pending":     txnStatusPending,
+		" AUTHORISED": txnStatusPending,
+		"uncleared":   txnStatusPending,
+		"Posted":      txnStatusPosted,
+		"cleared":     txnStatusPosted,
+		"":            txnStatusPosted,
+	}
+	for in, want := range cases {
+		if got := txnStatusFromText(in); got != want {
+			t.Fatalf("txnStatusFromText(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
+
+func TestPendingAmountMatches(t *testing.T) {
+	cases := []struct {
+		pending, posted money
+		want            bool
+	}{
+		{-4000, -4000, true},
+		{-4000, -4600, true},  // tip within 25%
+		{-4000, -6000, false}, // too far off
+		{-50, -140, true},     // small amounts get a $1 floor
+		{-4000, 4000, false},  // refund, not a settlement
+	}
+	for _, tc := range cases {
+		if got := pendingAmountMatches(tc.pending, tc.posted); got != tc.want {
+			t.Fatalf("pendingAmountMatches(%s, %s) = %v, want %v", tc.pending, tc.posted, got, tc.want)
+		}
+	}
+}
+
+func TestPostedImportReplacesPendingAndCarriesEdits(t *testing.T) {
+	db, cleanup := testDB(t)
+	defer cleanup()
+
+	if _, err := insertAccount(db, "ANZ", "debit", true); err != nil {
+		t.Fatalf("insertAccount: %v", err)
+	}
+	statusCol := 3
+	format := testANZFormat()
+	format.Account = "ANZ"
+	format.ImportPrefix = "anz"
+	format.DescJoin = false
+	format.StatusCol = &statusCol
+	dir := t.TempDir()
+	importFile := func(name, content string) ingestDoneMsg {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatalf("write csv: %v", err)
+		}
+		preview, ok := scanDupesCmd(db, name, dir, []csvFormat{format}, nil, defaultSettings().dupeMatch())().(importPreviewMsg)
+		if !ok || preview.err != nil || preview.snapshot == nil {
+			t.Fatalf("scanDupesCmd(%s): ok=%v err=%v", name, ok, preview.err)
+		}
+		done, ok := ingestSnapshotCmd(db, preview.snapshot, true, true)().(ingestDoneMsg)
+		if !ok || done.err != nil {
+			t.Fatalf("ingestSnapshotCmd(%s): ok=%v err=%v", name, ok, done.err)
+		}
+		return done
+	}
+
+	importFile("ANZ-1.csv", "3/02/2026,-40.00,CAFE SYDNEY,Pending\n3/02/2026,-12.00,BAKERY,Posted\n")
+	rows, err := loadRows(db)
+	if err != nil {
+		t.Fatalf("loadRows: %v", err)
+	}
+	var pending transaction
+	for _, r := range rows {
+		if r.description == "CAFE SYDNEY" {
+			pending = r
+		}
+	}
+	if pending.status != txnStatusPending {
+		t.Fatalf("CAFE SYDNEY status = %q, want pending", pending.status)
+	}
+
+	// Hand edits on the pending row.
+	var dining int
+	if err := db.QueryRow(`SELECT id FROM categories WHERE name = 'Dining & Drinks'`).Scan(&dining); err != nil {
+		t.Fatalf("lookup category: %v", err)
+	}
+	if err := updateTransactionDetail(db, pending.id, &dining, "team lunch"); err != nil {
+		t.Fatalf("updateTransactionDetail: %v", err)
+	}
+	tagID, err := insertTag(db, "work", "#94e2d5", nil)
+	if err != nil {
+		t.Fatalf("insertTag: %v", err)
+	}
+	if err := setTransactionTags(db, pending.id, []int{tagID}); err != nil {
+		t.Fatalf("setTransactionTags: %v", err)
+	}
+	if _, err := insertTransactionAllocation(db, pending.id, 1000, nil, "my share", nil); err != nil {
+		t.Fatalf("insertTransactionAllocation: %v", err)
+	}
+
+	// Posted two days later with a tip added; the bakery row is a plain dupe.
+	done := importFile("ANZ-2.csv", "5/02/2026,-46.00,CAFE SYDNEY AU,Posted\n3/02/2026,-12.00,BAKERY,Posted\n")
+	if done.count != 1 || done.dupes != 1 {
+		t.Fatalf("count=%d dupes=%d, want 1 and 1", done.count, done.dupes)
+	}
+
+	rows, err = loadRows(db)
+	if err != nil {
+		t.Fatalf("loadRows: %v", err)
+	}
+	if len(rows) != 2 {
+		t.Fatalf("got %d transactions, want 2 (pending replaced)", len(rows))
+	}
+	var posted transaction
+	for _, r := range rows {
+		if r.id == pending.id {
+			t.Fatal("pending transaction was not deleted")
+		}
+		if r.description == "CAFE SYDNEY AU" {
+			posted = r
+		}
+	}
+	if posted.status != txnStatusPosted || posted.amount != -4600 {
+		t.Fatalf("posted = %+v, want posted -46", posted)
+	}
+	if posted.categoryID == nil || *posted.categoryID != dining || posted.notes != "team lunch" {
+		t.Fatalf("posted category/notes = %v/%q, want Dining & Drinks/team lunch", posted.categoryID, posted.notes)
+	}
+	tags, err := loadTransactionTags(db)
+	if err != nil {
+		t.Fatalf("loadTransactionTags: %v", err)
+	}
+	if len(tags[posted.id]) != 1 || tags[posted.id][0].id != tagID {
+		t.Fatalf("posted tags = %+v, want [work]", tags[posted.id])
+	}
+	allocs, err := loadTransactionAllocationsForParents(db, []int{posted.id})
+	if err != nil {
+		t.Fatalf("loadTransactionAllocationsForParents: %v", err)
+	}
+	if len(allocs) != 1 || allocs[0].note != "my share" {
+		t.Fatalf("posted allocations = %+v, want the carried-over split", allocs)
+	}
+	imports, err := loadImports(db)
+	if err != nil {
+		t.Fatalf("loadImports: %v", err)
+	}
+	for _, imp := range imports {
+		if imp.filename == "ANZ-2.csv" {
+			if _, err := loadImportUndoSummary(db, imp.id); err == nil {
+				t.Fatal("undo allowed for an import that settled a pending transaction")
+			}
+		}
+	}
+
+	node, err := parseFilterStrict("status:pending")
+	if err != nil {
+		t.Fatalf("parse status filter: %v", err)
+	}
+	if evalFilter(node, posted, nil) || !evalFilter(node, pending, nil) {
+		t.Fatal("status:pending should match only the pending row")
+	}
+	if _, err := parseFilterStrict("status:cleared"); err == nil {
+		t.Fatal("expected error for unknown status value")
+	}
+}
+
+func TestSettlePendingScalesOverAllocation(t *testing.T) {
+	db, cleanup := testDB(t)
+	defer cleanup()
+
+	insert := func(amount money, status string) int {
+		t.Helper()
+		res, err := db.Exec(`INSERT INTO transactions (date_raw, date_iso, amount, description, notes, status)
+			VALUES ('03/02/2026', '2026-02-03', ?, 'CAFE', '', ?)`, amount, status)
+		if err != nil {
+			t.Fatalf("insert txn: %v", err)
+		}
+		id, _ := res.LastInsertId()
+		return int(id)
+	}
+	pendingID := insert(-4000, txnStatusPending)
+	postedID := insert(-3200, txnStatusPosted)
+	for _, amt := range []money{3000, 1000} {
+		if _, err := insertTransactionAllocation(db, pendingID, amt, nil, "", nil); err != nil {
+			t.Fatalf("insertTransactionAllocation: %v", err)
+		}
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		t.Fatalf("begin: %v", err)
+	}
+	if err := settlePendingTx(tx, pendingID, postedID); err != nil {
+		tx.Rollback() //nolint:errcheck
+		t.Fatalf("settlePendingTx: %v", err)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatalf("commit: %v", err)
+	}
+
+	allocs, err := loadTransactionAllocationsForParents(db, []int{postedID})
+	if err != nil {
+		t.Fatalf("loadTransactionAllocationsForParents: %v", err)
+	}
+	if len(allocs) != 2 || allocs[0].amount != -2400 || allocs[1].amount != -800 {
+		t.Fatalf("allocations = %+v, want -24.00 and -8.00", allocs)
+	}
+}
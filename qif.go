@@ -0,0 +1,379 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"database/sql"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// qifSplit is one S/E/$ split line of a QIF transaction.
+type qifSplit struct {
+	category string
+	memo     string
+	amount   money
+}
+
+// qifRecord is a parsed QIF transaction: the same date/amount/description
+// triple a CSV row yields, plus the QIF-only category, memo and splits.
+type qifRecord struct {
+	parsedCSVRow
+	line     int
+	category string
+	memo     string
+	splits   []qifSplit
+}
+
+func isQIFFileName(name string) bool {
+	return strings.EqualFold(filepath.Ext(name), ".qif")
+}
+
+// qifTransactionTypes are the !Type headers whose records are ledger
+// transactions. Investment, category and memorized lists are skipped.
+var qifTransactionTypes = map[string]bool{
+	"bank":  true,
+	"cash":  true,
+	"ccard": true,
+	"oth a": true,
+	"oth l": true,
+}
+
+// parseQIF reads a QIF export. dateFormat is the mapped account's Go layout;
+// QIF's apostrophe year separator ("2/3'26") is normalised before parsing.
+// Only one account's transactions may appear per file.
+func parseQIF(data []byte, dateFormat string) (accountName string, records []qifRecord, parseErrors []importPreviewParseError, totalRows int, err error) {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	var (
+		section     string // lower-case !Type value, "account" or ""
+		current     map[byte][]string
+		splits      []qifSplit
+		startLine   int
+		rawLines    []string // the current record's lines, kept for rejects
+		lineNo      int
+		pendingAcct string
+		seenAccts   = make(map[string]bool)
+	)
+	reset := func() {
+		current = make(map[byte][]string)
+		splits = nil
+		startLine = 0
+		rawLines = nil
+	}
+	reset()
+
+	flush := func() {
+		defer reset()
+		if section == "account" {
+			if names := current['N']; len(names) > 0 {
+				pendingAcct = strings.TrimSpace(names[0])
+			}
+			return
+		}
+		if !qifTransactionTypes[section] || startLine == 0 {
+			return
+		}
+		totalRows++
+		rec, issue := qifRecordFromFields(current, splits, dateFormat)
+		if issue != nil {
+			first := func(codes string) string {
+				for i := 0; i < len(codes); i++ {
+					if vals := current[codes[i]]; len(vals) > 0 {
+						return vals[0]
+					}
+				}
+				return ""
+			}
+			parseErrors = append(parseErrors, importPreviewParseError{
+				rowIndex:    totalRows,
+				sourceLine:  startLine,
+				field:       issue.field,
+				message:     issue.message,
+				raw:         strings.Join(rawLines, "\n"),
+				dateRaw:     first("D"),
+				amountRaw:   first("TU"),
+				description: first("P"),
+			})
+			return
+		}
+		rec.line = startLine
+		records = append(records, rec)
+		if pendingAcct != "" {
+			seenAccts[strings.ToLower(pendingAcct)] = true
+			accountName = pendingAcct
+		}
+	}
+
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimRight(scanner.Text(), "\r")
+		if strings.TrimSpace(line) == "" {
+			continue
+		}
+		if line[0] == '!' {
+			header := strings.ToLower(strings.TrimSpace(line[1:]))
+			switch {
+			case header == "account":
+				section = "account"
+			case strings.HasPrefix(header, "type:"):
+				section = strings.TrimSpace(strings.TrimPrefix(header, "type:"))
+			case strings.HasPrefix(header, "option:"), strings.HasPrefix(header, "clear:"):
+				// AutoSwitch markers only bracket the account list.
+			default:
+				section = header
+			}
+			reset()
+			continue
+		}
+		if line[0] == '^' {
+			flush()
+			continue
+		}
+		if startLine == 0 {
+			startLine = lineNo
+		}
+		rawLines = append(rawLines, line)
+		code, value := line[0], strings.TrimSpace(line[1:])
+		switch code {
+		case 'S':
+			splits = append(splits, qifSplit{category: value})
+		case 'E', '$':
+			if len(splits) == 0 {
+				splits = append(splits, qifSplit{})
+			}
+			last := &splits[len(splits)-1]
+			if code == 'E' {
+				last.memo = value
+				continue
+			}
+			amount, amtErr := parseMoney(value)
+			if amtErr != nil {
+				current['$'] = append(current['$'], value)
+				continue
+			}
+			last.amount = amount
+		default:
+			current[code] = append(current[code], value)
+		}
+	}
+	if scanErr := scanner.Err(); scanErr != nil {
+		return "", nil, nil, totalRows, fmt.Errorf("read qif: %w", scanErr)
+	}
+	// Tolerate a missing trailing "^".
+	flush()
+
+	if len(seenAccts) > 1 {
+		return "", nil, nil, totalRows, fmt.Errorf("file contains transactions for %d accounts; export one account per file", len(seenAccts))
+	}
+	return accountName, records, parseErrors, totalRows, nil
+}
+
+func qifRecordFromFields(fields map[byte][]string, splits []qifSplit, dateFormat string) (qifRecord, *previewParseIssue) {
+	first := func(code byte) string {
+		if vals := fields[code]; len(vals) > 0 {
+			return strings.TrimSpace(vals[0])
+		}
+		return ""
+	}
+	if bad := first('$'); bad != "" {
+		return qifRecord{}, &previewParseIssue{field: "split", message: fmt.Sprintf("invalid split amount %q", bad)}
+	}
+
+	dateRaw := first('D')
+	if dateRaw == "" {
+		return qifRecord{}, &previewParseIssue{field: "date", message: "date is required"}
+	}
+	dateISO, err := parseQIFDate(dateRaw, dateFormat)
+	if err != nil {
+		return qifRecord{}, &previewParseIssue{field: "date", message: err.Error()}
+	}
+
+	amountRaw := first('T')
+	if amountRaw == "" {
+		amountRaw = first('U')
+	}
+	if amountRaw == "" {
+		return qifRecord{}, &previewParseIssue{field: "amount", message: "amount is required"}
+	}
+	amount, err := parseMoney(amountRaw)
+	if err != nil {
+		return qifRecord{}, &previewParseIssue{field: "amount", message: err.Error()}
+	}
+
+	payee := first('P')
+	memo := first('M')
+	description := payee
+	if description == "" {
+		description = memo
+		memo = ""
+	}
+
+	var splitAbs money
+	for _, sp := range splits {
+		if sp.amount == 0 {
+			continue
+		}
+		if (sp.amount > 0) != (amount > 0) {
+			return qifRecord{}, &previewParseIssue{field: "split", message: "split sign differs from transaction total"}
+		}
+		splitAbs += sp.amount.abs()
+	}
+	if splitAbs > amount.abs() {
+		return qifRecord{}, &previewParseIssue{field: "split", message: "splits exceed transaction total"}
+	}
+
+	return qifRecord{
+		parsedCSVRow: parsedCSVRow{
+			dateRaw:     dateRaw,
+			dateISO:     dateISO,
+			amount:      amount,
+			description: description,
+		},
+		category: qifCategoryName(first('L')),
+		memo:     memo,
+		splits:   splits,
+	}, nil
+}
+
+// parseQIFDate parses a QIF D field using the account's date layout. QIF
+// writers use an apostrophe before two-digit years from 2000 on and may pad
+// single digits with spaces, so both are normalised first.
+func parseQIFDate(raw, dateFormat string) (string, error) {
+	normalized := strings.ReplaceAll(strings.ReplaceAll(raw, "'", "/"), " ", "")
+	layout := strings.TrimSpace(dateFormat)
+	if layout == "" {
+		layout = "1/2/2006"
+	}
+	layouts := []string{layout, strings.Replace(layout, "2006", "06", 1), "2006-01-02"}
+	for _, l := range layouts {
+		if parsed, err := time.Parse(l, normalized); err == nil {
+			return parsed.Format("2006-01-02"), nil
+		}
+	}
+	return "", fmt.Errorf("parse date %q with layout %q", raw, layout)
+}
+
+// qifCategoryName maps a QIF L/S value onto a jaskmoney category name:
+// the "/Class" suffix is dropped, "[Account]" transfers become Transfers and
+// Quicken's "--Split--" placeholder means no parent category.
+func qifCategoryName(raw string) string {
+	name := strings.TrimSpace(raw)
+	if strings.EqualFold(name, "--Split--") {
+		return ""
+	}
+	if idx := strings.Index(name, "/"); idx >= 0 {
+		name = strings.TrimSpace(name[:idx])
+	}
+	if strings.HasPrefix(name, "[") && strings.HasSuffix(name, "]") {
+		return "Transfers"
+	}
+	return name
+}
+
+// formatForAccountName returns the first format bound to the named account.
+func formatForAccountName(formats []csvFormat, name string) *csvFormat {
+	for i := range formats {
+		if strings.EqualFold(strings.TrimSpace(formats[i].Account), strings.TrimSpace(name)) {
+			return &formats[i]
+		}
+	}
+	return nil
+}
+
+// buildQIFImportPreviewSnapshot parses a QIF file into an import preview
+// snapshot. The account comes from the file's !Account block when it names
+// a known account, otherwise from the filename prefix like CSV imports.
+func buildQIFImportPreviewSnapshot(db *sql.DB, path, fileName string, formats []csvFormat, savedFilters []savedFilter, match dupeMatchSettings) (*importPreviewSnapshot, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("open qif: %w", err)
+	}
+
+	var acct *account
+	format := detectFormat(formats, fileName)
+	dateFormat := ""
+	if format != nil {
+		dateFormat = format.DateFormat
+	}
+	acctName, records, parseErrors, totalRows, err := parseQIF(data, dateFormat)
+	if err != nil {
+		return nil, fmt.Errorf("parse qif: %w", err)
+	}
+	if acctName != "" {
+		if named := formatForAccountName(formats, acctName); named != nil && named != format {
+			// Re-parse with the named account's date layout.
+			format = named
+			dateFormat = format.DateFormat
+			if _, records, parseErrors, totalRows, err = parseQIF(data, dateFormat); err != nil {
+				return nil, fmt.Errorf("parse qif: %w", err)
+			}
+		}
+		if acct, err = loadAccountByNameCI(db, acctName); err != nil {
+			return nil, fmt.Errorf("resolve account %q: %w", acctName, err)
+		}
+	}
+	if acct == nil {
+		if format == nil {
+			return nil, fmt.Errorf("no matching format for %q", fileName)
+		}
+		if acct, err = loadAccountByNameCI(db, format.Account); err != nil {
+			return nil, fmt.Errorf("resolve account %q: %w", format.Account, err)
+		}
+		if acct == nil {
+			return nil, fmt.Errorf("account %q not found; create or sync it in Manager", format.Account)
+		}
+	}
+
+	existingSet, err := loadDuplicateSet(db)
+	if err != nil {
+		return nil, fmt.Errorf("load duplicates: %w", err)
+	}
+	rows := make([]importPreviewRow, 0, len(records))
+	for i, rec := range records {
+		allocations := make([]importPreviewAllocation, 0, len(rec.splits))
+		for _, sp := range rec.splits {
+			if sp.amount == 0 {
+				continue
+			}
+			allocations = append(allocations, importPreviewAllocation{
+				amount:       sp.amount,
+				categoryName: qifCategoryName(sp.category),
+				note:         sp.memo,
+			})
+		}
+		key := duplicateKeyForAccount(rec.dateISO, rec.amount, rec.description, &acct.id)
+		rows = append(rows, importPreviewRow{
+			index:        i + 1,
+			sourceLine:   rec.line,
+			dateRaw:      rec.dateRaw,
+			dateISO:      rec.dateISO,
+			amount:       rec.amount,
+			description:  rec.description,
+			notes:        rec.memo,
+			categoryName: rec.category,
+			allocations:  allocations,
+			isDupe:       existingSet[key],
+		})
+	}
+
+	snapshot := &importPreviewSnapshot{
+		fileName:    fileName,
+		createdAt:   time.Now(),
+		totalRows:   totalRows,
+		rows:        rows,
+		parseErrors: parseErrors,
+		errorCount:  len(parseErrors),
+		accountID:   acct.id,
+		formatName:  "QIF",
+		dateFormat:  dateFormat,
+	}
+	if err := finalizeImportPreviewSnapshot(db, snapshot, *acct, savedFilters, match); err != nil {
+		return nil, err
+	}
+	return snapshot, nil
+}
@@ -0,0 +1,151 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const testQIF = `!Account
+NEveryday
+TBank
+^
+!Type:Bank
+D3/02'26
+T-45.50
+PWOOLWORTHS 1234
+MWeekly shop
+LGroceries
+^
+D3/04'26
+T-120.00
+PKMART
+L--Split--
+SHousehold
+EPlates
+$-80.00
+SClothing/Kids
+$-40.00
+^
+D3/05'26
+T1,500.00
+PSALARY
+L[Savings]
+^
+D3/06'26
+T-10.00
+PBAD SPLIT
+SFees
+$5.00
+^
+`
+
+func TestParseQIFRecordsAndSplits(t *testing.T) {
+	acct, records, parseErrors, total, err := parseQIF([]byte(testQIF), "2/01/2006")
+	if err != nil {
+		t.Fatalf("parseQIF: %v", err)
+	}
+	if acct != "Everyday" {
+		t.Fatalf("account = %q, want Everyday", acct)
+	}
+	if total != 4 || len(records) != 3 || len(parseErrors) != 1 {
+		t.Fatalf("total=%d records=%d errors=%d", total, len(records), len(parseErrors))
+	}
+	if parseErrors[0].field != "split" || parseErrors[0].rowIndex != 4 {
+		t.Fatalf("parse error = %+v", parseErrors[0])
+	}
+	first := records[0]
+	if first.dateISO != "2026-02-03" || first.amount != -4550 || first.description != "WOOLWORTHS 1234" {
+		t.Fatalf("first = %+v", first)
+	}
+	if first.category != "Groceries" || first.memo != "Weekly shop" {
+		t.Fatalf("first category/memo = %q/%q", first.category, first.memo)
+	}
+	split := records[1]
+	if split.category != "" || len(split.splits) != 2 {
+		t.Fatalf("split record = %+v", split)
+	}
+	if split.splits[1].amount != -4000 || qifCategoryName(split.splits[1].category) != "Clothing" {
+		t.Fatalf("second split = %+v", split.splits[1])
+	}
+	if records[2].category != "Transfers" || records[2].amount != 150000 {
+		t.Fatalf("transfer record = %+v", records[2])
+	}
+}
+
+func TestParseQIFRejectsMultipleAccounts(t *testing.T) {
+	data := "!Account\nNOne\n^\n!Type:Bank\nD1/2/2026\nT-1\nPA\n^\n!Account\nNTwo\n^\n!Type:Bank\nD1/3/2026\nT-2\nPB\n^\n"
+	if _, _, _, _, err := parseQIF([]byte(data), "1/2/2006"); err == nil {
+		t.Fatal("expected error for multi-account QIF")
+	}
+}
+
+func TestQIFImportCreatesCategoriesAndAllocations(t *testing.T) {
+	db, cleanup := testDB(t)
+	defer cleanup()
+
+	if _, err := insertAccount(db, "Everyday", "debit", true); err != nil {
+		t.Fatalf("insertAccount: %v", err)
+	}
+	formats := []csvFormat{{Name: "Everyday", Account: "Everyday", ImportPrefix: "everyday", DateFormat: "2/01/2006"}}
+
+	dir := t.TempDir()
+	file := "download.qif"
+	if err := os.WriteFile(filepath.Join(dir, file), []byte(testQIF), 0o644); err != nil {
+		t.Fatalf("write qif: %v", err)
+	}
+	msg := scanDupesCmd(db, file, dir, formats, nil, defaultSettings().dupeMatch())()
+	done, ok := msg.(importPreviewMsg)
+	if !ok || done.err != nil || done.snapshot == nil {
+		t.Fatalf("scan = %T %+v", msg, msg)
+	}
+	snap := done.snapshot
+	if snap.newCount != 3 || snap.errorCount != 1 {
+		t.Fatalf("counts new=%d errors=%d", snap.newCount, snap.errorCount)
+	}
+	if snap.rows[0].previewCat != "Groceries" {
+		t.Fatalf("preview category = %q, want Groceries", snap.rows[0].previewCat)
+	}
+
+	_, inserted, _, _, txnIDs, _, err := importSnapshotRows(db, snap, true)
+	if err != nil {
+		t.Fatalf("importSnapshotRows: %v", err)
+	}
+	if inserted != 3 {
+		t.Fatalf("inserted=%d want 3", inserted)
+	}
+
+	categories, err := loadCategories(db)
+	if err != nil {
+		t.Fatalf("loadCategories: %v", err)
+	}
+	byName := make(map[string]category)
+	for _, c := range categories {
+		byName[c.name] = c
+	}
+	for _, want := range []string{"Household", "Clothing", "Transfers"} {
+		if _, ok := byName[want]; !ok {
+			t.Fatalf("category %q not created; have %v", want, categories)
+		}
+	}
+
+	var notes string
+	var catID int
+	if err := db.QueryRow(`SELECT notes, category_id FROM transactions WHERE id = ?`, txnIDs[0]).Scan(&notes, &catID); err != nil {
+		t.Fatalf("query first txn: %v", err)
+	}
+	if notes != "Weekly shop" || catID != byName["Groceries"].id {
+		t.Fatalf("first txn notes=%q category=%d", notes, catID)
+	}
+
+	allocs, err := loadTransactionAllocationsForParents(db, []int{txnIDs[1]})
+	if err != nil {
+		t.Fatalf("load allocations: %v", err)
+	}
+	if len(allocs) != 2 {
+		t.Fatalf("allocations=%d want 2", len(allocs))
+	}
+	if allocs[0].amount != -8000 || allocs[0].note != "Plates" || allocs[0].categoryID == nil || *allocs[0].categoryID != byName["Household"].id {
+		t.Fatalf("first allocation = %+v", allocs[0])
+	}
+}
@@ -13,9 +13,9 @@ func testPhase5Model(t *testing.T) (model, func()) {
 	_, err := db.Exec(`
 		INSERT INTO transactions (date_raw, date_iso, amount, description, notes)
 		VALUES
-			('03/02/2026', '2026-02-03', -10.00, 'PHASE5 A', ''),
-			('03/02/2026', '2026-02-03', -20.00, 'PHASE5 B', ''),
-			('03/02/2026', '2026-02-03', -30.00, 'PHASE5 C', '')
+			('03/02/2026', '2026-02-03', -1000, 'PHASE5 A', ''),
+			('03/02/2026', '2026-02-03', -2000, 'PHASE5 B', ''),
+			('03/02/2026', '2026-02-03', -3000, 'PHASE5 C', '')
 	`)
 	if err != nil {
 		cleanup()
@@ -43,7 +43,7 @@ func testPhase5Model(t *testing.T) (model, func()) {
 		t.Fatalf("loadTransactionTags: %v", err)
 	}
 
-	m := newModel()
+	m := newModel("")
 	m.db = db
 	m.ready = true
 	m.activeTab = tabManager
@@ -253,7 +253,7 @@ func TestPhase5AddAllocationSingleRow(t *testing.T) {
 	got3 := runCmdUpdate(t, got2, cmd)
 
 	var count int
-	var amount float64
+	var amount money
 	var note string
 	err := got3.db.QueryRow(`SELECT COUNT(*), COALESCE(SUM(amount), 0), COALESCE(MAX(note), '') FROM transaction_allocations WHERE parent_txn_id = ?`, targetID).Scan(&count, &amount, &note)
 	if err != nil {
@@ -262,8 +262,8 @@ func TestPhase5AddAllocationSingleRow(t *testing.T) {
 	if count != 1 {
 		t.Fatalf("allocation rows = %d, want 1", count)
 	}
-	if amount != -12.50 {
-		t.Fatalf("allocation sum = %.2f, want -12.50", amount)
+	if amount != -1250 {
+		t.Fatalf("allocation sum = %s, want -12.50", amount)
 	}
 	if note != "Refund" {
 		t.Fatalf("allocation note = %q, want %q", note, "Refund")
@@ -275,7 +275,7 @@ func TestPhase5DeleteAllocationFromChildRow(t *testing.T) {
 	defer cleanup()
 
 	parentID := m.getFilteredRows()[m.cursor].id
-	if _, err := insertTransactionAllocation(m.db, parentID, 5, nil, "", nil); err != nil {
+	if _, err := insertTransactionAllocation(m.db, parentID, 500, nil, "", nil); err != nil {
 		t.Fatalf("insertTransactionAllocation: %v", err)
 	}
 	m2, _ := m.Update(refreshCmd(m.db)())
@@ -300,7 +300,7 @@ func TestPhase5DeleteAllocationFromChildRow(t *testing.T) {
 }
 
 func TestPhase5FooterBindingsUseCategoryPickerScope(t *testing.T) {
-	m := newModel()
+	m := newModel("")
 	m.catPicker = newPicker("Quick Categorize", nil, false, "")
 
 	bindings := m.footerBindings()
@@ -320,8 +320,8 @@ func TestUpdateTransactionsCategoryBulk(t *testing.T) {
 
 	res, err := db.Exec(`
 		INSERT INTO transactions (date_raw, date_iso, amount, description, notes)
-		VALUES ('03/02/2026', '2026-02-03', -10.00, 'BULK A', ''),
-		       ('03/02/2026', '2026-02-03', -20.00, 'BULK B', '')
+		VALUES ('03/02/2026', '2026-02-03', -1000, 'BULK A', ''),
+		       ('03/02/2026', '2026-02-03', -2000, 'BULK B', '')
 	`)
 	if err != nil {
 		t.Fatalf("insert txns: %v", err)
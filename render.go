@@ -166,9 +166,12 @@ var tabNames = []string{"Dashboard", "Budget", "Manager", "Settings"}
 // Section & chrome rendering
 // ---------------------------------------------------------------------------
 
-func renderHeader(appName string, activeTab, width int, accountLabel string) string {
-	// Line 1: App name + tab bar
+func renderHeader(appName, ledgerLabel string, activeTab, width int, accountLabel string) string {
+	// Line 1: App name + open ledger + tab bar
 	name := headerAppStyle.Render(appName)
+	if strings.TrimSpace(ledgerLabel) != "" {
+		name += " " + lipgloss.NewStyle().Foreground(colorSubtext0).Render("["+ledgerLabel+"]")
+	}
 
 	// Build tab bar
 	var tabs []string
@@ -850,7 +853,7 @@ func renderAllocationAmountModal(m model) string {
 }
 
 // renderFilePicker renders a simple list of CSV files with a cursor.
-func renderFilePicker(files []string, cursor int, keys *KeyRegistry) string {
+func renderFilePicker(files []string, matches map[string][]formatMatch, cursor, formatChoice int, keys *KeyRegistry) string {
 	if len(files) == 0 {
 		return renderModalContent("Import CSV", []string{
 			lipgloss.NewStyle().Foreground(colorOverlay1).Render("Loading CSV files..."),
@@ -860,21 +863,184 @@ func renderFilePicker(files []string, cursor int, keys *KeyRegistry) string {
 			actionKeyLabel(keys, scopeFilePicker, actionClose, "esc"),
 		))
 	}
-	lines := make([]string, 0, len(files))
+	nameWidth := 0
+	for _, f := range files {
+		nameWidth = max(nameWidth, len(f))
+	}
+	lines := make([]string, 0, len(files)+1)
 	for i, f := range files {
 		prefix := "  "
 		if i == cursor {
 			prefix = cursorStyle.Render("> ")
 		}
-		lines = append(lines, prefix+lipgloss.NewStyle().Foreground(colorText).Render(f))
+		line := prefix + lipgloss.NewStyle().Foreground(colorText).Render(padRight(f, nameWidth))
+		if candidates := matches[f]; len(candidates) > 0 {
+			choice := 0
+			if i == cursor && formatChoice < len(candidates) {
+				choice = formatChoice
+			}
+			c := candidates[choice]
+			label := fmt.Sprintf("  %s %d%%", c.name, int(c.score*100+0.5))
+			if len(candidates) > 1 {
+				label += fmt.Sprintf(" (%d/%d)", choice+1, len(candidates))
+			}
+			line += lipgloss.NewStyle().Foreground(colorSubtext0).Render(label)
+		}
+		lines = append(lines, line)
 	}
 	return renderModalContent("Import CSV", lines, fmt.Sprintf(
-		"%s select  %s cancel",
+		"%s select  %s format  %s import all  %s new format  %s cancel",
 		actionKeyLabel(keys, scopeFilePicker, actionSelect, "enter"),
+		actionKeyLabel(keys, scopeFilePicker, actionRight, "l"),
+		actionKeyLabel(keys, scopeFilePicker, actionImportPending, "a"),
+		actionKeyLabel(keys, scopeFilePicker, actionFormatWizard, "w"),
 		actionKeyLabel(keys, scopeFilePicker, actionClose, "esc"),
 	))
 }
 
+// renderFormatWizard draws the sample grid with marked columns and the
+// detected delimiter, header and date layout.
+func renderFormatWizard(w *formatWizardState, keys *KeyRegistry) string {
+	const cellWidth = 14
+	roleLabels := map[int]string{wizardRoleDate: "DATE", wizardRoleAmount: "AMOUNT", wizardRoleDesc: "DESC"}
+	roleStyle := lipgloss.NewStyle().Foreground(colorAccent).Bold(true)
+	cellStyle := lipgloss.NewStyle().Foreground(colorText)
+	headerStyle := lipgloss.NewStyle().Foreground(colorOverlay1)
+
+	var body []string
+	accountVal := w.account.Value
+	if w.focus == 1 {
+		accountVal = w.account.render()
+	}
+	body = append(body, modalCursor(w.focus == 1)+detailLabelStyle.Render("Account:   ")+detailValueStyle.Render(accountVal))
+
+	delimName := map[string]string{",": "comma", ";": "semicolon", "\t": "tab", "|": "pipe"}[w.delimiter]
+	header := "no"
+	if w.hasHeader {
+		header = "yes"
+	}
+	layout := w.dateLayout
+	if layout == "" {
+		layout = lipgloss.NewStyle().Foreground(colorError).Render("not detected")
+	}
+	body = append(body,
+		"  "+detailLabelStyle.Render("Delimiter: ")+detailValueStyle.Render(delimName)+
+			detailLabelStyle.Render("  Header: ")+detailValueStyle.Render(header)+
+			detailLabelStyle.Render("  Date: ")+detailValueStyle.Render(layout),
+		"",
+	)
+
+	marks := make([]string, 0, w.colCount)
+	for col := 0; col < w.colCount; col++ {
+		label := roleLabels[w.roleOf(col)]
+		if label == "" {
+			label = fmt.Sprintf("col %d", col)
+		}
+		cell := padRight(truncate(label, cellWidth-1), cellWidth)
+		if w.focus == 0 && col == w.cursorCol {
+			cell = cursorStyle.Render(cell)
+		} else if w.roleOf(col) != wizardRoleNone {
+			cell = roleStyle.Render(cell)
+		} else {
+			cell = headerStyle.Render(cell)
+		}
+		marks = append(marks, cell)
+	}
+	body = append(body, modalCursor(w.focus == 0)+strings.Join(marks, ""))
+	for i, rec := range w.records {
+		cells := make([]string, 0, w.colCount)
+		for col := 0; col < w.colCount; col++ {
+			val := ""
+			if col < len(rec) {
+				val = strings.TrimSpace(rec[col])
+			}
+			style := cellStyle
+			if i == 0 && w.hasHeader {
+				style = headerStyle
+			}
+			cells = append(cells, style.Render(padRight(truncate(val, cellWidth-1), cellWidth)))
+		}
+		body = append(body, "  "+strings.Join(cells, ""))
+	}
+	if w.err != "" {
+		body = append(body, "", lipgloss.NewStyle().Foreground(colorError).Render("Error: "+w.err))
+	}
+
+	footer := scrollStyle.Render(fmt.Sprintf(
+		"tab field  %s mark  %s header  %s delimiter  %s save  %s cancel",
+		actionKeyLabel(keys, scopeFormatWizard, actionToggleSelect, "space"),
+		actionKeyLabel(keys, scopeFormatWizard, actionWizardToggleHeader, "ctrl+t"),
+		actionKeyLabel(keys, scopeFormatWizard, actionWizardCycleDelimiter, "ctrl+d"),
+		actionKeyLabel(keys, scopeFormatWizard, actionSave, "enter"),
+		actionKeyLabel(keys, scopeFormatWizard, actionClose, "esc"),
+	))
+	width := min(2+cellWidth*max(1, w.colCount), 110)
+	return renderModalContentWithWidth("Format Wizard: "+w.fileName, body, footer, max(width, 56))
+}
+
+// renderImportRejects draws the quarantine list with the selected reject's
+// raw record and editable date, amount and description.
+func renderImportRejects(rejects []importReject, v *importRejectsReview, keys *KeyRegistry) string {
+	const listRows = 8
+	var body []string
+	if len(rejects) == 0 {
+		body = append(body, lipgloss.NewStyle().Foreground(colorOverlay1).Render("No rejected rows."))
+	}
+	top := max(0, min(v.cursor-listRows/2, len(rejects)-listRows))
+	for i := top; i < min(len(rejects), top+listRows); i++ {
+		r := rejects[i]
+		prefix := "  "
+		if i == v.cursor {
+			prefix = cursorStyle.Render("> ")
+		}
+		body = append(body, prefix+detailValueStyle.Render(fmt.Sprintf("%s:%d", r.filename, r.sourceLine))+
+			"  "+lipgloss.NewStyle().Foreground(colorError).Render(truncate(r.field+": "+r.message, 48)))
+	}
+	if len(rejects) > listRows {
+		body = append(body, scrollStyle.Render(fmt.Sprintf("  %d of %d", v.cursor+1, len(rejects))))
+	}
+
+	if v.cursor >= 0 && v.cursor < len(rejects) {
+		r := rejects[v.cursor]
+		account := r.accountName
+		if account == "" {
+			account = "none"
+		}
+		body = append(body, "",
+			detailLabelStyle.Render("  Account:     ")+detailValueStyle.Render(account),
+			detailLabelStyle.Render("  Raw:"))
+		for _, line := range splitLines(r.raw) {
+			body = append(body, "    "+lipgloss.NewStyle().Foreground(colorOverlay1).Render(truncate(line, 70)))
+		}
+		fields := []struct {
+			label string
+			field *textField
+		}{{"Date:        ", &v.date}, {"Amount:      ", &v.amount}, {"Description: ", &v.description}}
+		body = append(body, "")
+		for i, f := range fields {
+			val := f.field.Value
+			if i == v.focus {
+				val = f.field.render()
+			}
+			body = append(body, modalCursor(i == v.focus)+detailLabelStyle.Render(f.label)+detailValueStyle.Render(val))
+		}
+		if r.dateFormat != "" {
+			body = append(body, scrollStyle.Render("  date layout "+r.dateFormat+" or YYYY-MM-DD"))
+		}
+	}
+	if v.err != "" {
+		body = append(body, "", lipgloss.NewStyle().Foreground(colorError).Render("Error: "+v.err))
+	}
+
+	footer := scrollStyle.Render(fmt.Sprintf(
+		"tab field  %s promote  %s discard  %s close",
+		actionKeyLabel(keys, scopeImportRejects, actionSave, "enter"),
+		actionKeyLabel(keys, scopeImportRejects, actionDelete, "del"),
+		actionKeyLabel(keys, scopeImportRejects, actionClose, "esc"),
+	))
+	return renderModalContentWithWidth("Rejected Rows", body, footer, 76)
+}
+
 func renderImportPreview(
 	snapshot *importPreviewSnapshot,
 	postRules,
@@ -897,18 +1063,80 @@ func renderImportPreviewCompact(snapshot *importPreviewSnapshot, postRules, show
 	body := []string{
 		detailLabelStyle.Render("Summary"),
 		detailLabelStyle.Render("  File:    ") + detailValueStyle.Render(snapshot.fileName),
+		detailLabelStyle.Render("  Format:  ") + detailValueStyle.Render(importFormatLabel(snapshot.formatName, snapshot.fileSize)),
+		detailLabelStyle.Render("  Range:   ") + detailValueStyle.Render(importDateRangeLabel(snapshot.dateFrom, snapshot.dateTo)),
 		detailLabelStyle.Render("  Rows:    ") + detailValueStyle.Render(fmt.Sprintf("%d total", snapshot.totalRows)),
 		detailLabelStyle.Render("  New:     ") + detailValueStyle.Render(fmt.Sprintf("%d", snapshot.newCount)),
 		detailLabelStyle.Render("  Dupes:   ") + detailValueStyle.Render(fmt.Sprintf("%d", snapshot.dupeCount)),
+		detailLabelStyle.Render("  Likely:  ") + detailValueStyle.Render(fmt.Sprintf("%d (%d skipped)", snapshot.likelyDupeCount, likelyDupeSkipCount(snapshot))),
 		detailLabelStyle.Render("  Errors:  ") + detailValueStyle.Render(fmt.Sprintf("%d", snapshot.errorCount)),
 		detailLabelStyle.Render("  Rules:   ") + detailValueStyle.Render(map[bool]string{true: "ON", false: "OFF"}[postRules]),
-		"",
+	}
+	if snapshot.statementBalance != nil {
+		balance := formatMoney(*snapshot.statementBalance)
+		if snapshot.statementBalanceDate != "" {
+			balance += " as of " + snapshot.statementBalanceDate
+		}
+		body = append(body, detailLabelStyle.Render("  Balance: ")+detailValueStyle.Render(balance))
+	}
+	if n := editedImportRowCount(snapshot); n > 0 {
+		body = append(body, detailLabelStyle.Render("  Edited:  ")+detailValueStyle.Render(fmt.Sprintf("%d (kept over rules)", n)))
+	}
+	if snapshot.pendingSettleCount > 0 {
+		body = append(body, detailLabelStyle.Render("  Settles: ")+detailValueStyle.Render(fmt.Sprintf("%d pending", snapshot.pendingSettleCount)))
+	}
+	body = append(body, "")
+
+	if len(snapshot.balanceGaps) > 0 {
+		warn := lipgloss.NewStyle().Foreground(colorWarning)
+		body = append(body, warn.Render(fmt.Sprintf("Balance chain broken in %d place(s):", len(snapshot.balanceGaps))))
+		for i := 0; i < min(3, len(snapshot.balanceGaps)); i++ {
+			body = append(body, "  "+snapshot.balanceGaps[i].String())
+		}
+		if len(snapshot.balanceGaps) > 3 {
+			body = append(body, fmt.Sprintf("  +%d more gaps not shown", len(snapshot.balanceGaps)-3))
+		}
+		body = append(body, "")
+	}
+
+	if len(snapshot.sameFileImports) > 0 || len(snapshot.coveringImports) > 0 {
+		warn := lipgloss.NewStyle().Foreground(colorWarning)
+		if len(snapshot.sameFileImports) > 0 {
+			body = append(body, warn.Render("Same file imported before:"))
+			for _, prev := range snapshot.sameFileImports[:min(3, len(snapshot.sameFileImports))] {
+				body = append(body, fmt.Sprintf("  %s on %s (%d rows)", prev.filename, prev.importedAt, prev.rowCount))
+			}
+		}
+		if len(snapshot.coveringImports) > 0 {
+			body = append(body, warn.Render("Date range already imported for this account:"))
+			for _, prev := range snapshot.coveringImports[:min(3, len(snapshot.coveringImports))] {
+				body = append(body, fmt.Sprintf("  %s covered %s on %s", prev.filename, importDateRangeLabel(prev.dateFrom, prev.dateTo), prev.importedAt))
+			}
+		}
+		if !snapshot.reimportConfirmed {
+			body = append(body, warn.Render("Importing will ask for confirmation."))
+		}
+		body = append(body, "")
 	}
 
 	if snapshot.errorCount > 0 {
-		body = append(body, lipgloss.NewStyle().Foreground(colorError).Render("Import blocked: fix parse/normalize errors before confirming."))
+		switch {
+		case snapshot.quarantine && snapshot.canQuarantine():
+			body = append(body, lipgloss.NewStyle().Foreground(colorWarning).Render(fmt.Sprintf("%d rows will be quarantined for review:", len(snapshot.parseErrors))))
+		case snapshot.canQuarantine():
+			body = append(body, lipgloss.NewStyle().Foreground(colorError).Render(fmt.Sprintf(
+				"Import blocked: fix parse/normalize errors, or press %s to quarantine them.",
+				actionKeyLabel(keys, scopeImportPreview, actionQuarantineRejects, "x"))))
+		default:
+			body = append(body, lipgloss.NewStyle().Foreground(colorError).Render("Import blocked: fix parse/normalize errors before confirming."))
+		}
 		for i := 0; i < min(5, len(snapshot.parseErrors)); i++ {
 			pe := snapshot.parseErrors[i]
+			if pe.rowIndex == 0 {
+				// File-level errors (e.g. a missing header) have no data row.
+				body = append(body, fmt.Sprintf("  line %d (%s): %s", pe.sourceLine, pe.field, pe.message))
+				continue
+			}
 			body = append(body, fmt.Sprintf("  line %d (row %d, %s): %s", pe.sourceLine, pe.rowIndex, pe.field, pe.message))
 		}
 		if len(snapshot.parseErrors) > 5 {
@@ -930,23 +1158,47 @@ func renderImportPreviewCompact(snapshot *importPreviewSnapshot, postRules, show
 		table := renderImportPreviewTable(rows, postRules, cursor, topIndex, compactRows, terminalWidth)
 		body = append(body, splitLines(table)...)
 		body = append(body, detailLabelStyle.Render(fmt.Sprintf("  showing %d rows/page", compactRows)))
+		if cursor >= 0 && cursor < len(rows) && rows[cursor].likelyDupe != nil {
+			body = append(body, renderLikelyDupeMatch(rows[cursor]))
+		}
+		if cursor >= 0 && cursor < len(rows) && rows[cursor].replacesPending != nil {
+			p := rows[cursor].replacesPending
+			body = append(body, detailLabelStyle.Render("  Settles pending: ")+
+				detailValueStyle.Render(fmt.Sprintf("%s  %s  %s", p.dateISO, formatMoney(p.amount), p.description)))
+		}
 	}
 
 	previewLabel := "preview"
 	if showAll {
 		previewLabel = "dupes"
 	}
-	footer := strings.Join([]string{
+	hints := []string{
 		renderActionHint(keys, scopeImportPreview, actionImportAll, "a", "import all"),
 		renderActionHint(keys, scopeImportPreview, actionSkipDupes, "s", "skip"),
+		renderActionHint(keys, scopeImportPreview, actionQuickCategory, "c", "cat"),
+		renderActionHint(keys, scopeImportPreview, actionQuickTag, "t", "tag"),
 		renderActionHint(keys, scopeImportPreview, actionImportPreviewToggle, "p", previewLabel),
 		renderActionHint(keys, scopeImportPreview, actionImportRawView, "r", "rules"),
-		renderActionHint(keys, scopeImportPreview, actionClose, "esc", "cancel"),
-	}, "  ")
+	}
+	if snapshot.canQuarantine() {
+		hints = append(hints, renderActionHint(keys, scopeImportPreview, actionQuarantineRejects, "x", "quarantine"))
+	}
+	hints = append(hints, renderActionHint(keys, scopeImportPreview, actionClose, "esc", "cancel"))
+	footer := strings.Join(hints, "  ")
 	width := max(96, min(136, terminalWidth-8))
 	return renderModalContentWithWidth("Import Preview", body, footer, width)
 }
 
+func editedImportRowCount(snapshot *importPreviewSnapshot) int {
+	n := 0
+	for _, row := range snapshot.rows {
+		if row.edited {
+			n++
+		}
+	}
+	return n
+}
+
 func compactImportRows(snapshot *importPreviewSnapshot, showAll bool) []importPreviewRow {
 	if snapshot == nil {
 		return nil
@@ -954,15 +1206,27 @@ func compactImportRows(snapshot *importPreviewSnapshot, showAll bool) []importPr
 	if showAll {
 		return snapshot.rows
 	}
-	out := make([]importPreviewRow, 0, snapshot.dupeCount)
-	for _, row := range snapshot.rows {
-		if row.isDupe {
-			out = append(out, row)
-		}
+	indices := importPreviewDisplayedIndices(snapshot, false)
+	out := make([]importPreviewRow, 0, len(indices))
+	for _, idx := range indices {
+		out = append(out, snapshot.rows[idx])
 	}
 	return out
 }
 
+// renderLikelyDupeMatch describes the existing transaction a flagged preview
+// row matched and what importing with duplicates skipped will do with it.
+func renderLikelyDupeMatch(row importPreviewRow) string {
+	match := row.likelyDupe
+	decision := lipgloss.NewStyle().Foreground(colorWarning).Render("skip")
+	if !row.skipLikelyDupe {
+		decision = lipgloss.NewStyle().Foreground(colorSuccess).Render("import")
+	}
+	return detailLabelStyle.Render("  Likely duplicate of: ") +
+		detailValueStyle.Render(fmt.Sprintf("%s  %s  %s (%.0f%% similar)", match.dateISO, formatMoney(match.amount), match.description, match.similarity*100)) +
+		detailLabelStyle.Render("  -> ") + decision
+}
+
 func renderImportPreviewTable(rows []importPreviewRow, postRules bool, cursor, topIndex, visibleRows, terminalWidth int) string {
 	if visibleRows <= 0 {
 		visibleRows = 10
@@ -971,7 +1235,11 @@ func renderImportPreviewTable(rows []importPreviewRow, postRules bool, cursor, t
 	txnTags := make(map[int][]tag)
 	categories := make([]category, 0)
 	catSeen := make(map[string]bool)
-	if postRules {
+	showCats := postRules
+	for _, row := range rows {
+		showCats = showCats || row.edited
+	}
+	if showCats {
 		categories = append(categories, category{id: 1, name: "Uncategorised"})
 		catSeen["Uncategorised"] = true
 	}
@@ -984,7 +1252,27 @@ func renderImportPreviewTable(rows []importPreviewRow, postRules bool, cursor, t
 			amount:      row.amount,
 			description: row.description,
 		}
-		if postRules {
+		switch {
+		case row.likelyDupe != nil:
+			txn.description = "~ " + row.description
+		case row.replacesPending != nil:
+			txn.description = "^ " + row.description
+		case row.status == txnStatusPending:
+			txn.description = "(pending) " + row.description
+		}
+		if row.edited {
+			cat := row.editCategory
+			if cat == "" {
+				cat = "Uncategorised"
+			}
+			txn.categoryName = cat
+			txn.categoryColor = row.editCatColor
+			if !catSeen[cat] {
+				categories = append(categories, category{name: cat, color: txn.categoryColor})
+				catSeen[cat] = true
+			}
+			txnTags[txnID] = append(txnTags[txnID], row.editTags...)
+		} else if postRules {
 			cat := strings.TrimSpace(row.previewCat)
 			if cat == "" {
 				cat = "Uncategorised"
@@ -1134,10 +1422,10 @@ func renderTransactionTable(
 		} else if row.amount < 0 {
 			amountStyle = amountStyle.Foreground(colorError)
 		}
-		amountText := fmt.Sprintf("%.2f", row.amount)
+		amountText := row.amount.String()
 		amountAnn := ""
-		if !row.isAllocation && row.fullAmount != 0 && math.Abs(row.fullAmount-row.amount) > 1e-9 {
-			amountAnn = fmt.Sprintf(" [%.2f]", row.fullAmount)
+		if !row.isAllocation && row.fullAmount != 0 && row.fullAmount != row.amount {
+			amountAnn = fmt.Sprintf(" [%s]", row.fullAmount)
 		}
 		showAnn := amountAnn != "" && ansi.StringWidth(amountText+amountAnn) <= amountW
 		annStyle := lipgloss.NewStyle().Foreground(colorOverlay1).Background(rowBg)
@@ -1495,11 +1783,11 @@ func renderDashboardCompositionMode(m model, mode widgetMode, rows []transaction
 func renderDashboardCompareBarsMode(m model, mode widgetMode, rows []transaction, width int) string {
 	switch mode.id {
 	case "budget_vs_actual":
-		budgetTotal := 0.0
+		var budgetTotal money
 		for _, line := range m.budgetLines {
 			budgetTotal += line.budgeted
 		}
-		actual := 0.0
+		var actual money
 		for _, row := range rows {
 			if row.amount < 0 {
 				actual += -row.amount
@@ -1510,8 +1798,7 @@ func renderDashboardCompareBarsMode(m model, mode widgetMode, rows []transaction
 		barActual := renderMiniMeter("Actual", actual, scale, max(1, width/2))
 		return barBudget + "\n" + barActual
 	case "income_vs_expense":
-		income := 0.0
-		expense := 0.0
+		var income, expense money
 		for _, row := range rows {
 			if row.amount > 0 {
 				income += row.amount
@@ -1524,8 +1811,7 @@ func renderDashboardCompareBarsMode(m model, mode widgetMode, rows []transaction
 	case "month_over_month":
 		curMonth := latestDebitMonthKey(rows)
 		prevMonth := previousMonthKey(curMonth)
-		curSpend := 0.0
-		prevSpend := 0.0
+		var curSpend, prevSpend money
 		for _, row := range rows {
 			if row.amount >= 0 {
 				continue
@@ -1586,15 +1872,15 @@ func previousMonthKey(monthKey string) string {
 	return t.AddDate(0, -1, 0).Format("2006-01")
 }
 
-func renderMiniMeter(label string, value float64, maxValue float64, barWidth int) string {
+func renderMiniMeter(label string, value money, maxValue money, barWidth int) string {
 	if barWidth < 1 {
 		barWidth = 1
 	}
-	amount := math.Abs(value)
-	scale := math.Abs(maxValue)
+	amount := value.abs()
+	scale := maxValue.abs()
 	filled := 0
 	if scale > 0 {
-		filled = int(math.Round((amount / scale) * float64(barWidth)))
+		filled = int(math.Round((amount.float() / scale.float()) * float64(barWidth)))
 	}
 	if filled < 0 {
 		filled = 0
@@ -1623,12 +1909,12 @@ func renderDashboardTopMerchants(rows []transaction, width int) string {
 	if len(rows) == 0 {
 		return "No merchant spend in scope."
 	}
-	spendByMerchant := make(map[string]float64)
+	spendByMerchant := make(map[string]money)
 	for _, row := range rows {
 		if row.amount >= 0 {
 			continue
 		}
-		name := strings.TrimSpace(row.description)
+		name := txnMerchant(row)
 		if name == "" {
 			name = "Unknown"
 		}
@@ -1636,10 +1922,10 @@ func renderDashboardTopMerchants(rows []transaction, width int) string {
 	}
 	type merchantSpend struct {
 		name  string
-		spend float64
+		spend money
 	}
 	sorted := make([]merchantSpend, 0, len(spendByMerchant))
-	maxSpend := 0.0
+	var maxSpend money
 	for name, spend := range spendByMerchant {
 		sorted = append(sorted, merchantSpend{name: name, spend: spend})
 		if spend > maxSpend {
@@ -1660,7 +1946,7 @@ func renderDashboardTopMerchants(rows []transaction, width int) string {
 	}
 	for i := 0; i < limit; i++ {
 		item := sorted[i]
-		ratio := item.spend / maxSpend
+		ratio := item.spend.float() / maxSpend.float()
 		fill := int(math.Round(ratio * float64(barWidth)))
 		if fill < 1 {
 			fill = 1
@@ -1686,9 +1972,9 @@ func hasRecurringTag(tags []tag) bool {
 // renderSummaryCards renders the summary cards: balance, income, expenses,
 // transaction count, uncategorised count/amount.
 func renderSummaryCards(rows []transaction, categories []category, width int) string {
-	var income, expenses float64
+	var income, expenses money
 	var uncatCount int
-	var uncatTotal float64
+	var uncatTotal money
 	minDate := ""
 	maxDate := ""
 	for _, r := range rows {
@@ -1699,7 +1985,7 @@ func renderSummaryCards(rows []transaction, categories []category, width int) st
 		}
 		if isUncategorised(r) {
 			uncatCount++
-			uncatTotal += math.Abs(r.amount)
+			uncatTotal += r.amount.abs()
 		}
 		if minDate == "" || r.dateISO < minDate {
 			minDate = r.dateISO
@@ -1722,7 +2008,7 @@ func renderSummaryCards(rows []transaction, categories []category, width int) st
 		col2W = 16
 	}
 
-	debits := math.Abs(expenses)
+	debits := expenses.abs()
 	credits := income
 	days := 1.0
 	if minDate != "" && maxDate != "" {
@@ -1735,17 +2021,17 @@ func renderSummaryCards(rows []transaction, categories []category, width int) st
 			}
 		}
 	}
-	dailyBurn := 0.0
+	var dailyBurn money
 	if debits > 0 {
-		dailyBurn = debits / days
+		dailyBurn = moneyFromFloat(debits.float() / days)
 	}
 	savingsRate := 0.0
 	if credits > 0 {
-		savingsRate = ((credits - debits) / credits) * 100
+		savingsRate = ((credits - debits).float() / credits.float()) * 100
 	}
 	runway := "∞"
 	if dailyBurn > 0 {
-		runway = fmt.Sprintf("%.1f days", balance/dailyBurn)
+		runway = fmt.Sprintf("%.1f days", balance.float()/dailyBurn.float())
 	}
 
 	row1 := padRight(infoLabelStyle.Render("Balance      ")+balanceStyle(balance, greenSty, redSty), col1W) +
@@ -1795,23 +2081,19 @@ func dashboardDateRangeFromBounds(rows []transaction, start, endExcl time.Time,
 	return formatMonth(minDate) + " – " + formatMonth(maxDate)
 }
 
-func balanceStyle(amount float64, green, red lipgloss.Style) string {
-	s := formatMoney(math.Abs(amount))
+func balanceStyle(amount money, green, red lipgloss.Style) string {
+	s := formatMoney(amount.abs())
 	if amount >= 0 {
 		return green.Render(s)
 	}
 	return red.Render("-" + s)
 }
 
-func formatMoney(v float64) string {
+// formatMoney renders cents as "$1,234.56", exactly.
+func formatMoney(v money) string {
 	neg := v < 0
-	if neg {
-		v = -v
-	}
-	// Format with commas
-	whole := int64(v)
-	frac := v - float64(whole)
-	s := fmt.Sprintf("%d", whole)
+	v = v.abs()
+	s := fmt.Sprintf("%d", int64(v/100))
 	// Insert commas
 	if len(s) > 3 {
 		var parts []string
@@ -1822,7 +2104,7 @@ func formatMoney(v float64) string {
 		parts = append([]string{s}, parts...)
 		s = strings.Join(parts, ",")
 	}
-	result := fmt.Sprintf("$%s.%02d", s, int(frac*100+0.5))
+	result := fmt.Sprintf("$%s.%02d", s, int64(v%100))
 	if neg {
 		return "-" + result
 	}
@@ -1881,7 +2163,7 @@ func countActiveCategories(rows []transaction) int {
 type categorySpend struct {
 	name   string
 	color  string
-	amount float64 // absolute value of expenses
+	amount money // absolute value of expenses
 }
 
 // renderCategoryBreakdown renders a horizontal bar chart of spending by category.
@@ -1889,12 +2171,12 @@ type categorySpend struct {
 func renderCategoryBreakdown(rows []transaction, categories []category, width int) string {
 	// Aggregate expenses by category
 	spendMap := make(map[string]*categorySpend)
-	var totalExpenses float64
+	var totalExpenses money
 	for _, r := range rows {
 		if r.amount >= 0 {
 			continue // skip income
 		}
-		abs := math.Abs(r.amount)
+		abs := r.amount.abs()
 		totalExpenses += abs
 		key := r.categoryName
 		if key == "" {
@@ -1938,7 +2220,7 @@ func renderCategoryBreakdown(rows []transaction, categories []category, width in
 	})
 
 	display := sorted
-	maxAmount := 0.0
+	var maxAmount money
 	for _, s := range display {
 		if s.amount > maxAmount {
 			maxAmount = s.amount
@@ -1955,7 +2237,7 @@ func renderCategoryBreakdown(rows []transaction, categories []category, width in
 	pctW := 4 // e.g. " 42%"
 	amtW := 0
 	for _, s := range display {
-		w := len("$" + formatWholeNumber(s.amount))
+		w := len("$" + formatWholeNumber(s.amount.float()))
 		if w > amtW {
 			amtW = w
 		}
@@ -2007,10 +2289,10 @@ func renderCategoryBreakdown(rows []transaction, categories []category, width in
 	for _, s := range display {
 		pct := 0.0
 		if totalExpenses > 0 {
-			pct = s.amount / totalExpenses * 100
+			pct = s.amount.float() / totalExpenses.float() * 100
 		}
 		pctText := fmt.Sprintf("%4.0f%%", pct)
-		amtText := fmt.Sprintf("%*s", amtW, "$"+formatWholeNumber(s.amount))
+		amtText := fmt.Sprintf("%*s", amtW, "$"+formatWholeNumber(s.amount.float()))
 		reservedRight := 1 + ansi.StringWidth(pctText) + 1 + ansi.StringWidth(amtText)
 		availableLeft := width - reservedRight
 		if availableLeft < 0 {
@@ -2029,7 +2311,7 @@ func renderCategoryBreakdown(rows []transaction, categories []category, width in
 			rowBarW = 0
 		}
 
-		ratio := s.amount / maxAmount
+		ratio := s.amount.float() / maxAmount.float()
 		filled := int(math.Round(float64(rowBarW) * ratio))
 		if ratio >= 0.999999 {
 			filled = rowBarW
@@ -2136,7 +2418,7 @@ func aggregateDailySpendForRange(rows []transaction, start, end time.Time) ([]fl
 	if days <= 0 {
 		return nil, nil
 	}
-	byDay := make(map[string]float64)
+	byDay := make(map[string]money)
 	for _, r := range rows {
 		if r.dateISO < startISO || r.dateISO > endISO {
 			continue
@@ -2151,7 +2433,7 @@ func aggregateDailySpendForRange(rows []transaction, start, end time.Time) ([]fl
 	dates := make([]time.Time, 0, days)
 	for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
 		key := d.Format("2006-01-02")
-		values = append(values, byDay[key])
+		values = append(values, byDay[key].float())
 		dates = append(dates, d)
 	}
 	return values, dates
@@ -2169,7 +2451,7 @@ func aggregateDailyNetForRange(rows []transaction, start, end time.Time) ([]floa
 	if days <= 0 {
 		return nil, nil
 	}
-	byDay := make(map[string]float64)
+	byDay := make(map[string]money)
 	for _, r := range rows {
 		if r.dateISO < startISO || r.dateISO > endISO {
 			continue
@@ -2181,7 +2463,7 @@ func aggregateDailyNetForRange(rows []transaction, start, end time.Time) ([]floa
 	dates := make([]time.Time, 0, days)
 	for d := start; !d.After(end); d = d.AddDate(0, 0, 1) {
 		key := d.Format("2006-01-02")
-		values = append(values, byDay[key])
+		values = append(values, byDay[key].float())
 		dates = append(dates, d)
 	}
 	return values, dates
@@ -2831,7 +3113,10 @@ func renderSettingsContent(m model) string {
 	importContent := renderSettingsImportHistory(m, rightWidth-4)
 	importBox := renderSettingsSectionBox("Import History", settSecImportHistory, m, rightWidth, importContent)
 
-	rightCol := chartBox + "\n" + dbBox + "\n" + importBox
+	backupsContent := renderSettingsBackups(m, rightWidth-4)
+	backupsBox := renderSettingsSectionBox("Backups", settSecBackups, m, rightWidth, backupsContent)
+
+	rightCol := chartBox + "\n" + dbBox + "\n" + importBox + "\n" + backupsBox
 
 	return lipgloss.JoinHorizontal(lipgloss.Top, leftCol, strings.Repeat(" ", gap), rightCol)
 }
@@ -3210,20 +3495,93 @@ func renderSettingsDBImport(m model, width int) string {
 
 func renderSettingsImportHistory(m model, width int) string {
 	var lines []string
+	if n := len(m.importRejects); n > 0 {
+		lines = append(lines, lipgloss.NewStyle().Foreground(colorWarning).Render(fmt.Sprintf(
+			"%d rejected rows in quarantine (%s to review)", n, actionKeyLabel(m.keys, scopeSettingsActiveImportHist, actionReviewRejects, "r"))), "")
+	}
 	if len(m.imports) == 0 {
 		lines = append(lines, lipgloss.NewStyle().Foreground(colorOverlay1).Render("No imports yet."))
 	} else {
-		for _, imp := range m.imports {
+		showCursor := m.settSection == settSecImportHistory && m.settActive
+		for i, imp := range m.imports {
+			prefix := "  "
+			if showCursor && i == m.settItemCursor {
+				prefix = cursorStyle.Render("> ")
+			}
 			fname := lipgloss.NewStyle().Foreground(colorText).Render(imp.filename)
 			count := infoValueStyle.Render(fmt.Sprintf("%d rows", imp.rowCount))
 			date := infoLabelStyle.Render(imp.importedAt)
-			lines = append(lines, fname+"  "+count+"  "+date)
+			lines = append(lines, prefix+fname+"  "+count+"  "+date)
+			if imp.contentHash == "" {
+				continue // recorded before provenance was tracked
+			}
+			details := []string{importFormatLabel(imp.format, imp.fileSize)}
+			if imp.accountName != "" {
+				details = append(details, imp.accountName)
+			}
+			details = append(details, importDateRangeLabel(imp.dateFrom, imp.dateTo), "sha256 "+imp.contentHash[:min(12, len(imp.contentHash))])
+			lines = append(lines, "    "+infoLabelStyle.Render(strings.Join(details, " · ")))
+		}
+	}
+	_ = width
+	return strings.Join(lines, "\n")
+}
+
+func renderSettingsBackups(m model, width int) string {
+	if len(m.backups) == 0 {
+		return lipgloss.NewStyle().Foreground(colorOverlay1).Render(fmt.Sprintf(
+			"No snapshots yet. One is taken before migrations and clears (newest %d kept).", backupRetention))
+	}
+	var lines []string
+	showCursor := m.settSection == settSecBackups && m.settActive
+	for i, b := range m.backups {
+		prefix := "  "
+		if showCursor && i == m.settItemCursor {
+			prefix = cursorStyle.Render("> ")
 		}
+		reason := lipgloss.NewStyle().Foreground(colorText).Render(b.reason)
+		size := infoValueStyle.Render(formatByteSize(b.size))
+		date := infoLabelStyle.Render(b.createdAt.Format("2006-01-02 15:04:05"))
+		lines = append(lines, prefix+date+"  "+reason+"  "+size)
 	}
 	_ = width
 	return strings.Join(lines, "\n")
 }
 
+// importFormatLabel renders an import's format and file size, e.g.
+// "ANZ · 2.4 KB".
+func importFormatLabel(format string, size int64) string {
+	if format == "" {
+		format = "unknown format"
+	}
+	if size <= 0 {
+		return format
+	}
+	return format + " · " + formatByteSize(size)
+}
+
+func importDateRangeLabel(from, to string) string {
+	switch {
+	case from == "":
+		return "no dated rows"
+	case from == to:
+		return from
+	default:
+		return from + " to " + to
+	}
+}
+
+func formatByteSize(n int64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", float64(n)/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", float64(n)/(1<<10))
+	default:
+		return fmt.Sprintf("%d B", n)
+	}
+}
+
 func renderManagerAccountModal(m model) string {
 	title := "Edit Account"
 	if m.managerModalIsNew {
@@ -3671,7 +4029,7 @@ func renderBudgetAnalyticsStrip(m model, width int) string {
 	momDelta := currentSpend - prevSpend
 	momPctText := "n/a"
 	if prevSpend > 0 {
-		momPctText = fmt.Sprintf("%+.1f%%", (momDelta/prevSpend)*100)
+		momPctText = fmt.Sprintf("%+.1f%%", (momDelta.float()/prevSpend.float())*100)
 	}
 	momColor := greenSty
 	if momDelta > 0 {
@@ -3691,9 +4049,9 @@ func renderBudgetAnalyticsStrip(m model, width int) string {
 	return row + "\n" + momLine + "\n" + sparkLabel + sparkline
 }
 
-func budgetMonthSpendForScope(m model, monthKey string) float64 {
+func budgetMonthSpendForScope(m model, monthKey string) money {
 	rows := rowsForBudgetMonthAndScope(m, monthKey)
-	total := 0.0
+	var total money
 	for _, row := range rows {
 		if row.amount >= 0 {
 			continue
@@ -3818,9 +4176,9 @@ func renderBudgetPlannerGrid(m model, width int) string {
 				amtStyle = amtStyle.Foreground(colorSubtext0)
 			}
 
-			amtText := fmt.Sprintf("%*.0f", monthW, amount)
+			amtText := fmt.Sprintf("%*.0f", monthW, amount.float())
 			if isOverride && !isCursorCell {
-				amtText = fmt.Sprintf("%*.0f", monthW-1, amount) + "*"
+				amtText = fmt.Sprintf("%*.0f", monthW-1, amount.float()) + "*"
 			}
 			row += cellStyle.Render(sep) + amtStyle.Render(amtText)
 		}
@@ -3872,7 +4230,7 @@ func budgetIDForCategory(budgets []categoryBudget, categoryID int) int {
 	return 0
 }
 
-func budgetOverrideAmount(overrides []budgetOverride, monthKey string) (float64, bool) {
+func budgetOverrideAmount(overrides []budgetOverride, monthKey string) (money, bool) {
 	for _, ov := range overrides {
 		if ov.monthKey == monthKey {
 			return ov.amount, true
@@ -3883,7 +4241,7 @@ func budgetOverrideAmount(overrides []budgetOverride, monthKey string) (float64,
 
 // renderDetail renders a standalone transaction detail modal (test helper surface).
 func renderDetail(txn transaction, tags []tag, notes string, notesCursor int, editing string, keys *KeyRegistry) string {
-	return renderDetailCore(txn, tags, notes, notesCursor, editing, nil, nil, keys)
+	return renderDetailCore(txn, tags, notes, notesCursor, editing, nil, nil, nil, keys)
 }
 
 func renderDetailWithAllocations(m model, keys *KeyRegistry) string {
@@ -3896,10 +4254,67 @@ func renderDetailWithAllocations(m model, keys *KeyRegistry) string {
 	if !row.isAllocation {
 		allocations = m.allocationsByParent[row.id]
 	}
-	return renderDetailCore(row, tags, m.detailNotes, m.detailNotesCursor, m.detailEditing, allocations, m.allocationTagsByID, keys)
+	history := historyLines(m.detailHistory, m.rules, m.imports)
+	return renderDetailCore(row, tags, m.detailNotes, m.detailNotesCursor, m.detailEditing, allocations, m.allocationTagsByID, history, keys)
 }
 
-func renderDetailCore(txn transaction, tags []tag, notes string, notesCursor int, editing string, allocations []transactionAllocation, allocationTags map[int][]tag, keys *KeyRegistry) string {
+// detailHistoryLimit caps the History section of the detail modal.
+const detailHistoryLimit = 8
+
+// historyLines formats the newest changes for the detail modal, one line
+// each: "2026-02-03 14:05 rule Supermarkets  category: → Groceries".
+func historyLines(changes []txnChange, rules []ruleV2, imports []importRecord) []string {
+	if len(changes) == 0 {
+		return nil
+	}
+	ruleNames := make(map[int]string, len(rules))
+	for _, r := range rules {
+		ruleNames[r.id] = r.name
+	}
+	importNames := make(map[int]string, len(imports))
+	for _, imp := range imports {
+		importNames[imp.id] = imp.filename
+	}
+	var out []string
+	for i, c := range changes {
+		if i == detailHistoryLimit {
+			out = append(out, fmt.Sprintf("… %d older", len(changes)-i))
+			break
+		}
+		when := c.changedAt
+		if len(when) > 16 {
+			when = when[:16]
+		}
+		source := c.source.kind
+		switch c.source.kind {
+		case changeRule:
+			if name := ruleNames[c.source.id]; name != "" {
+				source = "rule " + name
+			} else {
+				source = fmt.Sprintf("rule #%d", c.source.id)
+			}
+		case changeImport:
+			if name := importNames[c.source.id]; name != "" {
+				source = "import " + name
+			} else {
+				source = fmt.Sprintf("import #%d", c.source.id)
+			}
+		}
+		var change string
+		switch {
+		case c.oldValue == "":
+			change = fmt.Sprintf("%s: + %s", c.field, c.newValue)
+		case c.newValue == "":
+			change = fmt.Sprintf("%s: − %s", c.field, c.oldValue)
+		default:
+			change = fmt.Sprintf("%s: %s → %s", c.field, c.oldValue, c.newValue)
+		}
+		out = append(out, when+" "+source, "  "+change)
+	}
+	return out
+}
+
+func renderDetailCore(txn transaction, tags []tag, notes string, notesCursor int, editing string, allocations []transactionAllocation, allocationTags map[int][]tag, history []string, keys *KeyRegistry) string {
 	const detailModalWidth = 52
 	const detailTextWrap = 40
 	var body []string
@@ -3911,11 +4326,26 @@ func renderDetailCore(txn transaction, tags []tag, notes string, notesCursor int
 		amtStyle = debitStyle
 	}
 	dateAmountLine := detailLabelStyle.Render("Date: ") + detailValueStyle.Render(txn.dateISO) + "  " +
-		detailLabelStyle.Render("Amount: ") + amtStyle.Render(fmt.Sprintf("%.2f", txn.amount))
+		detailLabelStyle.Render("Amount: ") + amtStyle.Render(txn.amount.String())
+	if txn.accountCurrency != "" {
+		dateAmountLine += " " + detailLabelStyle.Render(txn.accountCurrency)
+	}
 	body = append(body, dateAmountLine)
-	if !txn.isAllocation && txn.fullAmount != 0 && math.Abs(txn.fullAmount-txn.amount) > 1e-9 {
+	if txn.origAmount != nil {
+		body = append(body, detailLabelStyle.Render("Foreign:     ")+detailValueStyle.Render(strings.TrimSpace(fmt.Sprintf("%s %s", *txn.origAmount, txn.origCurrency))))
+	}
+	if !txn.isAllocation && txn.fullAmount != 0 && txn.fullAmount != txn.amount {
 		body = append(body, detailLabelStyle.Render("Original:    ")+detailValueStyle.Render(formatMoney(txn.fullAmount)))
 	}
+	if txn.status == txnStatusPending {
+		body = append(body, detailLabelStyle.Render("Status:      ")+lipgloss.NewStyle().Foreground(colorWarning).Render("pending"))
+	}
+	if txn.merchant != "" && txn.merchant != txn.description {
+		body = append(body, detailLabelStyle.Render("Merchant:    ")+detailValueStyle.Render(truncate(txn.merchant, detailTextWrap)))
+	}
+	if txn.bankBalance != nil {
+		body = append(body, detailLabelStyle.Render("Bank bal:    ")+detailValueStyle.Render(formatMoney(*txn.bankBalance)))
+	}
 	if txn.isAllocation && txn.parentTxnID > 0 {
 		body = append(body, detailLabelStyle.Render("Parent txn:  ")+detailValueStyle.Render(fmt.Sprintf("#%d", txn.parentTxnID)))
 	}
@@ -3987,6 +4417,14 @@ func renderDetailCore(txn transaction, tags []tag, notes string, notesCursor int
 		body = append(body, "")
 	}
 
+	if len(history) > 0 {
+		body = append(body, detailLabelStyle.Render("History"))
+		for _, line := range history {
+			body = append(body, detailValueStyle.Render(truncate(line, detailTextWrap)))
+		}
+		body = append(body, "")
+	}
+
 	// Notes
 	notesLabel := detailLabelStyle.Render("Notes: ")
 	notePrefix := "Notes: "
@@ -6,7 +6,7 @@ import (
 )
 
 func TestRenderFilterEditorModalShowsScopedMatchCount(t *testing.T) {
-	m := newModel()
+	m := newModel("")
 	m.filterEditOpen = true
 	m.filterEditExpr = `desc:coffee`
 	m.rows = []transaction{
@@ -22,7 +22,7 @@ func TestRenderFilterEditorModalShowsScopedMatchCount(t *testing.T) {
 }
 
 func TestRenderRuleEditorModalShowsScopedMatchCount(t *testing.T) {
-	m := newModel()
+	m := newModel("")
 	m.ruleEditorOpen = true
 	m.ruleEditorFilterID = "coffee"
 	m.savedFilters = []savedFilter{
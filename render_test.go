@@ -19,11 +19,11 @@ import (
 
 func testDashboardRows() []transaction {
 	return []transaction{
-		{id: 1, dateISO: "2026-01-15", amount: -55.30, description: "WOOLWORTHS", categoryName: "Groceries", categoryColor: "#94e2d5"},
-		{id: 2, dateISO: "2026-01-20", amount: 500.00, description: "SALARY", categoryName: "Income", categoryColor: "#a6e3a1"},
-		{id: 3, dateISO: "2026-02-03", amount: -20.00, description: "DAN MURPHYS", categoryName: "Dining & Drinks", categoryColor: "#fab387"},
-		{id: 4, dateISO: "2026-02-04", amount: -12.50, description: "UBER", categoryName: "Transport", categoryColor: "#89b4fa"},
-		{id: 5, dateISO: "2026-02-05", amount: 203.92, description: "PAYMENT", categoryName: "Uncategorised", categoryColor: "#7f849c"},
+		{id: 1, dateISO: "2026-01-15", amount: -5530, description: "WOOLWORTHS", categoryName: "Groceries", categoryColor: "#94e2d5"},
+		{id: 2, dateISO: "2026-01-20", amount: 50000, description: "SALARY", categoryName: "Income", categoryColor: "#a6e3a1"},
+		{id: 3, dateISO: "2026-02-03", amount: -2000, description: "DAN MURPHYS", categoryName: "Dining & Drinks", categoryColor: "#fab387"},
+		{id: 4, dateISO: "2026-02-04", amount: -1250, description: "UBER", categoryName: "Transport", categoryColor: "#89b4fa"},
+		{id: 5, dateISO: "2026-02-05", amount: 20392, description: "PAYMENT", categoryName: "Uncategorised", categoryColor: "#7f849c"},
 	}
 }
 
@@ -73,7 +73,7 @@ func TestRenderCategoryBreakdownEmpty(t *testing.T) {
 
 func TestRenderCategoryBreakdownIncomeOnly(t *testing.T) {
 	rows := []transaction{
-		{amount: 500.00, categoryName: "Income"},
+		{amount: 50000, categoryName: "Income"},
 	}
 	output := renderCategoryBreakdown(rows, nil, 80)
 	if !strings.Contains(output, "Uncategorised") {
@@ -112,7 +112,7 @@ func TestRenderCategoryBreakdownShowsAllCategories(t *testing.T) {
 	cats := []string{"A", "B", "C", "D", "E", "F", "G", "H"}
 	for i, c := range cats {
 		rows = append(rows, transaction{
-			amount:        float64(-(i + 1) * 10),
+			amount:        money(-(i + 1) * 1000),
 			categoryName:  c,
 			categoryColor: "#94e2d5",
 		})
@@ -128,7 +128,7 @@ func TestRenderCategoryBreakdownShowsAllCategories(t *testing.T) {
 
 func TestRenderCategoryBreakdownIncludesKnownZeroSpendCategories(t *testing.T) {
 	rows := []transaction{
-		{amount: -25.0, categoryName: "Groceries", categoryColor: "#94e2d5"},
+		{amount: -2500, categoryName: "Groceries", categoryColor: "#94e2d5"},
 	}
 	allCats := []category{
 		{id: 1, name: "Groceries", color: "#94e2d5"},
@@ -149,8 +149,8 @@ func TestRenderCategoryBreakdownIncludesKnownZeroSpendCategories(t *testing.T) {
 
 func TestRenderCategoryBreakdownStableOrderOnTies(t *testing.T) {
 	rows := []transaction{
-		{amount: -10.0, categoryName: "B"},
-		{amount: -10.0, categoryName: "A"},
+		{amount: -1000, categoryName: "B"},
+		{amount: -1000, categoryName: "A"},
 	}
 
 	first := renderCategoryBreakdown(rows, nil, 80)
@@ -165,11 +165,11 @@ func TestRenderCategoryBreakdownStableOrderOnTies(t *testing.T) {
 func TestRenderDashboardCompareBarsBudgetVsActualScalesBars(t *testing.T) {
 	m := model{
 		budgetLines: []budgetLine{
-			{categoryName: "Total", budgeted: 1000},
+			{categoryName: "Total", budgeted: 100000},
 		},
 	}
 	rows := []transaction{
-		{dateISO: "2026-02-03", amount: -500},
+		{dateISO: "2026-02-03", amount: -50000},
 	}
 
 	out := renderDashboardCompareBarsMode(m, widgetMode{id: "budget_vs_actual"}, rows, 20)
@@ -190,11 +190,11 @@ func TestRenderBudgetAnalyticsStripIncludesMonthOverMonthSpend(t *testing.T) {
 	m := model{
 		budgetMonth: "2026-03",
 		rows: []transaction{
-			{dateISO: "2026-01-12", amount: -500, accountName: "ANZ"},
-			{dateISO: "2026-03-05", amount: -100, accountName: "ANZ"},
-			{dateISO: "2026-02-18", amount: -40, accountName: "ANZ"},
-			{dateISO: "2026-03-10", amount: -50, accountName: "ANZ"},
-			{dateISO: "2026-03-11", amount: 250, accountName: "ANZ"},
+			{dateISO: "2026-01-12", amount: -50000, accountName: "ANZ"},
+			{dateISO: "2026-03-05", amount: -10000, accountName: "ANZ"},
+			{dateISO: "2026-02-18", amount: -4000, accountName: "ANZ"},
+			{dateISO: "2026-03-10", amount: -5000, accountName: "ANZ"},
+			{dateISO: "2026-03-11", amount: 25000, accountName: "ANZ"},
 		},
 	}
 	out := renderBudgetAnalyticsStrip(m, 80)
@@ -212,11 +212,11 @@ func TestRenderBudgetAnalyticsStripIncludesMonthOverMonthSpend(t *testing.T) {
 func TestRenderBudgetTableIncludesCompareBarsSection(t *testing.T) {
 	m := model{
 		budgetMonth: "2026-03",
-		budgetLines: []budgetLine{{categoryName: "Groceries", budgeted: 500}},
+		budgetLines: []budgetLine{{categoryName: "Groceries", budgeted: 50000}},
 		rows: []transaction{
-			{dateISO: "2026-03-05", amount: -100, accountName: "ANZ"},
-			{dateISO: "2026-03-06", amount: 200, accountName: "ANZ"},
-			{dateISO: "2026-02-10", amount: -40, accountName: "ANZ"},
+			{dateISO: "2026-03-05", amount: -10000, accountName: "ANZ"},
+			{dateISO: "2026-03-06", amount: 20000, accountName: "ANZ"},
+			{dateISO: "2026-02-10", amount: -4000, accountName: "ANZ"},
 		},
 	}
 	out := renderBudgetTable(m)
@@ -253,10 +253,10 @@ func TestAggregateDailySpendBasic(t *testing.T) {
 	now := time.Now()
 	today := now.Format("2006-01-02")
 	rows := []transaction{
-		{dateISO: today, amount: -100.00, categoryName: "Groceries"},
-		{dateISO: today, amount: -50.00, categoryName: "Dining"},
-		{dateISO: today, amount: 500.00, categoryName: "Income"},
-		{dateISO: today, amount: -25.00, categoryName: "Uncategorised"},
+		{dateISO: today, amount: -10000, categoryName: "Groceries"},
+		{dateISO: today, amount: -5000, categoryName: "Dining"},
+		{dateISO: today, amount: 50000, categoryName: "Income"},
+		{dateISO: today, amount: -2500, categoryName: "Uncategorised"},
 	}
 	data, dates := aggregateDailySpend(rows, spendingTrackerDays)
 	if len(data) != spendingTrackerDays {
@@ -274,7 +274,7 @@ func TestAggregateDailySpendBasic(t *testing.T) {
 func TestAggregateDailySpendExcludesOldData(t *testing.T) {
 	old := time.Now().AddDate(0, 0, -(spendingTrackerDays + 5)).Format("2006-01-02")
 	rows := []transaction{
-		{dateISO: old, amount: -100.00},
+		{dateISO: old, amount: -10000},
 	}
 	data, _ := aggregateDailySpend(rows, spendingTrackerDays)
 	for _, v := range data {
@@ -301,7 +301,7 @@ func TestAggregateDailySpendEmpty(t *testing.T) {
 func TestAggregateDailySpendCreditOnly(t *testing.T) {
 	now := time.Now()
 	rows := []transaction{
-		{dateISO: now.Format("2006-01-02"), amount: 500.00},
+		{dateISO: now.Format("2006-01-02"), amount: 50000},
 	}
 	data, _ := aggregateDailySpend(rows, spendingTrackerDays)
 	last := data[len(data)-1]
@@ -313,7 +313,7 @@ func TestAggregateDailySpendCreditOnly(t *testing.T) {
 func TestRenderSpendingTrackerShowsYAxisLabels(t *testing.T) {
 	now := time.Now()
 	rows := []transaction{
-		{dateISO: now.Format("2006-01-02"), amount: -123.45},
+		{dateISO: now.Format("2006-01-02"), amount: -12345},
 	}
 	output := renderSpendingTracker(rows, 80)
 	if strings.Contains(output, "$") {
@@ -325,11 +325,11 @@ func TestNetCashflowModesKeepYAxisColumnAligned(t *testing.T) {
 	start := time.Date(2026, time.January, 1, 0, 0, 0, 0, time.Local)
 	end := start.AddDate(0, 0, 29)
 	rows := []transaction{
-		{dateISO: "2026-01-02", amount: -80},
-		{dateISO: "2026-01-05", amount: -20},
-		{dateISO: "2026-01-10", amount: 200},
-		{dateISO: "2026-01-14", amount: -40},
-		{dateISO: "2026-01-20", amount: 150},
+		{dateISO: "2026-01-02", amount: -8000},
+		{dateISO: "2026-01-05", amount: -2000},
+		{dateISO: "2026-01-10", amount: 20000},
+		{dateISO: "2026-01-14", amount: -4000},
+		{dateISO: "2026-01-20", amount: 15000},
 	}
 
 	spending := renderSpendingTrackerWithRangeSized(rows, 84, time.Monday, start, end, 16)
@@ -357,7 +357,7 @@ func TestNetCashflowModesKeepYAxisColumnAlignedWithEmptyRows(t *testing.T) {
 }
 
 func TestDashboardNetCashflowPaneModesKeepYAxisColumnAligned(t *testing.T) {
-	m := newModel()
+	m := newModel("")
 	rows := []transaction(nil)
 	modeSpending := widgetMode{id: "spending", label: "Spending", viewType: "line"}
 	modeNetWorth := widgetMode{id: "net_worth", label: "Net Worth", viewType: "line"}
@@ -372,7 +372,7 @@ func TestDashboardNetCashflowPaneModesKeepYAxisColumnAligned(t *testing.T) {
 }
 
 func TestDashboardNetCashflowModeTrimsTrailingBlankLine(t *testing.T) {
-	m := newModel()
+	m := newModel("")
 	mode := widgetMode{id: "net_worth", label: "Net Worth", viewType: "line"}
 	out := renderDashboardNetCashflowMode(m, mode, nil, 81, 16)
 	lines := splitLines(ansi.Strip(out))
@@ -385,13 +385,13 @@ func TestDashboardNetCashflowModeTrimsTrailingBlankLine(t *testing.T) {
 }
 
 func TestDashboardNetCashflowModeSpendingUsesRawTrackerOutput(t *testing.T) {
-	m := newModel()
+	m := newModel("")
 	mode := widgetMode{id: "spending", label: "Spending", viewType: "line"}
 	start, end := m.dashboardChartRange(time.Now())
 	rows := []transaction{
-		{dateISO: start.AddDate(0, 0, 1).Format("2006-01-02"), amount: -80},
-		{dateISO: start.AddDate(0, 0, 6).Format("2006-01-02"), amount: -20},
-		{dateISO: start.AddDate(0, 0, 10).Format("2006-01-02"), amount: -40},
+		{dateISO: start.AddDate(0, 0, 1).Format("2006-01-02"), amount: -8000},
+		{dateISO: start.AddDate(0, 0, 6).Format("2006-01-02"), amount: -2000},
+		{dateISO: start.AddDate(0, 0, 10).Format("2006-01-02"), amount: -4000},
 	}
 	width := 84
 	height := 16
@@ -425,7 +425,7 @@ func TestDashboardAnalyticsPaneWidthsHonorsMinimums(t *testing.T) {
 }
 
 func TestDashboardAnalyticsRegionUsesDedicated7030Split(t *testing.T) {
-	m := newModel()
+	m := newModel("")
 	m.ready = true
 	m.width = 140
 	m.height = 44
@@ -479,7 +479,7 @@ func firstChartVerticalColumn(chart string) int {
 func TestRenderSpendingTrackerShowsGridlines(t *testing.T) {
 	now := time.Now()
 	rows := []transaction{
-		{dateISO: now.Format("2006-01-02"), amount: -123.45, categoryName: "Groceries"},
+		{dateISO: now.Format("2006-01-02"), amount: -12345, categoryName: "Groceries"},
 	}
 	output := renderSpendingTrackerWithWeekAnchor(rows, 80, time.Sunday)
 	if !strings.Contains(output, "│") {
@@ -819,20 +819,21 @@ func TestBuildGridlineColumnsTodayOverridesMajor(t *testing.T) {
 
 func TestFormatMoney(t *testing.T) {
 	tests := []struct {
-		input float64
+		input money
 		want  string
 	}{
-		{0.0, "$0.00"},
-		{1234.56, "$1,234.56"},
-		{100.00, "$100.00"},
-		{999999.99, "$999,999.99"},
-		{0.50, "$0.50"},
-		{50.10, "$50.10"},
+		{0, "$0.00"},
+		{123456, "$1,234.56"},
+		{10000, "$100.00"},
+		{99999999, "$999,999.99"},
+		{50, "$0.50"},
+		{5010, "$50.10"},
+		{-99, "-$0.99"},
 	}
 	for _, tt := range tests {
 		got := formatMoney(tt.input)
 		if got != tt.want {
-			t.Errorf("formatMoney(%.2f) = %q, want %q", tt.input, got, tt.want)
+			t.Errorf("formatMoney(%d) = %q, want %q", tt.input, got, tt.want)
 		}
 	}
 }
@@ -924,7 +925,7 @@ func TestRenderSpendingTrackerEmpty(t *testing.T) {
 func TestRenderSpendingTrackerCreditOnly(t *testing.T) {
 	now := time.Now()
 	rows := []transaction{
-		{dateISO: now.Format("2006-01-02"), amount: 500.00},
+		{dateISO: now.Format("2006-01-02"), amount: 50000},
 	}
 	output := renderSpendingTracker(rows, 80)
 	if output == "" {
@@ -933,7 +934,7 @@ func TestRenderSpendingTrackerCreditOnly(t *testing.T) {
 }
 
 func TestDashboardViewSpendingTrackerTitle(t *testing.T) {
-	m := newModel()
+	m := newModel("")
 	output := m.dashboardView()
 	if !strings.Contains(output, "Spending Tracker") {
 		t.Error("missing Spending Tracker title in dashboard")
@@ -945,14 +946,14 @@ func TestDashboardViewSpendingTrackerTitle(t *testing.T) {
 // ---------------------------------------------------------------------------
 
 func TestRenderHeaderContainsAppName(t *testing.T) {
-	output := renderHeader("Jaskmoney", 0, 80, "")
+	output := renderHeader("Jaskmoney", "", 0, 80, "")
 	if !strings.Contains(output, "Jaskmoney") {
 		t.Error("missing app name in header")
 	}
 }
 
 func TestRenderHeaderContainsAllTabs(t *testing.T) {
-	output := renderHeader("Jaskmoney", 0, 80, "")
+	output := renderHeader("Jaskmoney", "", 0, 80, "")
 	for _, tab := range tabNames {
 		if !strings.Contains(output, tab) {
 			t.Errorf("missing tab %q in header", tab)
@@ -962,8 +963,8 @@ func TestRenderHeaderContainsAllTabs(t *testing.T) {
 
 func TestRenderHeaderActiveTabHighlight(t *testing.T) {
 	// Verify header still includes expected tab labels for current nav set.
-	h0 := renderHeader("App", 0, 80, "")
-	h1 := renderHeader("App", 1, 80, "")
+	h0 := renderHeader("App", "", 0, 80, "")
+	h1 := renderHeader("App", "", 1, 80, "")
 	if !strings.Contains(h0, "Dashboard") {
 		t.Error("tab 0 header missing Dashboard")
 	}
@@ -973,7 +974,7 @@ func TestRenderHeaderActiveTabHighlight(t *testing.T) {
 }
 
 func TestRenderHeaderZeroWidth(t *testing.T) {
-	output := renderHeader("Jaskmoney", 0, 0, "")
+	output := renderHeader("Jaskmoney", "", 0, 0, "")
 	if output == "" {
 		t.Error("header should render even with zero width")
 	}
@@ -1044,7 +1045,7 @@ func TestRenderTransactionTableDescriptionDisplayLimit40(t *testing.T) {
 		{
 			id:          1,
 			dateISO:     "2026-02-10",
-			amount:      -12.34,
+			amount:      -1234,
 			description: "123456789012345678901234567890123456789012345678901234567890",
 		},
 	}
@@ -1064,7 +1065,7 @@ func TestRenderTransactionTableAccountColumnAfterDescription(t *testing.T) {
 		{
 			id:            1,
 			dateISO:       "2026-02-10",
-			amount:        -12.34,
+			amount:        -1234,
 			description:   "DESC_TOKEN",
 			categoryID:    &catID,
 			categoryName:  "Groceries",
@@ -1074,7 +1075,7 @@ func TestRenderTransactionTableAccountColumnAfterDescription(t *testing.T) {
 		{
 			id:            2,
 			dateISO:       "2026-02-11",
-			amount:        -56.78,
+			amount:        -5678,
 			description:   "Other row",
 			categoryID:    &catID,
 			categoryName:  "Groceries",
@@ -1105,8 +1106,8 @@ func TestRenderTransactionTableAllocationRowUsesRowPrefix(t *testing.T) {
 		{
 			id:            1,
 			dateISO:       "2026-02-10",
-			amount:        -100.00,
-			fullAmount:    -100.00,
+			amount:        -10000,
+			fullAmount:    -10000,
 			description:   "Parent",
 			categoryID:    &catID,
 			categoryName:  "Groceries",
@@ -1119,7 +1120,7 @@ func TestRenderTransactionTableAllocationRowUsesRowPrefix(t *testing.T) {
 			parentTxnID:   1,
 			allocationID:  42,
 			dateISO:       "2026-02-10",
-			amount:        -25.00,
+			amount:        -2500,
 			description:   "Child split",
 			categoryID:    &catID,
 			categoryName:  "Groceries",
@@ -1163,7 +1164,7 @@ func TestRenderSpendingTrackerAlwaysRenders(t *testing.T) {
 
 func TestRenderFilePicker(t *testing.T) {
 	files := []string{"ANZ.csv", "CBA.csv", "test.csv"}
-	output := renderFilePicker(files, 1, NewKeyRegistry())
+	output := renderFilePicker(files, nil, 1, 0, NewKeyRegistry())
 	if !strings.Contains(output, "Import CSV") {
 		t.Error("missing title")
 	}
@@ -1176,7 +1177,7 @@ func TestRenderFilePicker(t *testing.T) {
 }
 
 func TestRenderFilePickerEmpty(t *testing.T) {
-	output := renderFilePicker(nil, 0, NewKeyRegistry())
+	output := renderFilePicker(nil, nil, 0, 0, NewKeyRegistry())
 	if !strings.Contains(output, "Loading") {
 		t.Error("empty file list should show loading message")
 	}
@@ -1190,9 +1191,9 @@ func TestRenderImportPreviewCompact(t *testing.T) {
 		dupeCount:  2,
 		errorCount: 0,
 		rows: []importPreviewRow{
-			{index: 1, sourceLine: 1, dateISO: "2026-02-03", amount: -10, description: "FIRST", isDupe: true},
-			{index: 2, sourceLine: 2, dateISO: "2026-02-04", amount: -11, description: "SECOND", isDupe: true},
-			{index: 3, sourceLine: 3, dateISO: "2026-02-05", amount: -12, description: "THIRD", isDupe: false},
+			{index: 1, sourceLine: 1, dateISO: "2026-02-03", amount: -1000, description: "FIRST", isDupe: true},
+			{index: 2, sourceLine: 2, dateISO: "2026-02-04", amount: -1100, description: "SECOND", isDupe: true},
+			{index: 3, sourceLine: 3, dateISO: "2026-02-05", amount: -1200, description: "THIRD", isDupe: false},
 		},
 	}
 	output := renderImportPreview(snapshot, true, false, 0, 0, 20, 140, NewKeyRegistry())
@@ -1218,8 +1219,8 @@ func TestRenderImportPreviewPostRulesShowsCategoryAndTags(t *testing.T) {
 		dupeCount:  1,
 		errorCount: 0,
 		rows: []importPreviewRow{
-			{index: 1, sourceLine: 1, dateISO: "2026-02-03", amount: -10, description: "GROCERY", isDupe: false, previewCat: "Groceries", previewTags: []string{"essentials", "weekly"}},
-			{index: 2, sourceLine: 2, dateISO: "2026-02-04", amount: -11, description: "DUPLICATE", isDupe: true, previewCat: "Dining", previewTags: []string{"takeaway"}},
+			{index: 1, sourceLine: 1, dateISO: "2026-02-03", amount: -1000, description: "GROCERY", isDupe: false, previewCat: "Groceries", previewTags: []string{"essentials", "weekly"}},
+			{index: 2, sourceLine: 2, dateISO: "2026-02-04", amount: -1100, description: "DUPLICATE", isDupe: true, previewCat: "Dining", previewTags: []string{"takeaway"}},
 		},
 	}
 	output := renderImportPreview(snapshot, true, true, 0, 0, 20, 140, NewKeyRegistry())
@@ -1238,7 +1239,7 @@ func TestRenderImportPreviewCompactUsesConfiguredPageSize(t *testing.T) {
 			index:       i + 1,
 			sourceLine:  i + 1,
 			dateISO:     "2026-02-03",
-			amount:      -1,
+			amount:      -100,
 			description: "ROW",
 			isDupe:      true,
 		})
@@ -1266,7 +1267,7 @@ func TestRenderImportPreviewShowsParseErrorBannerAndBlockedHint(t *testing.T) {
 			{rowIndex: 2, sourceLine: 2, field: "date", message: "invalid date"},
 		},
 		rows: []importPreviewRow{
-			{index: 1, sourceLine: 1, dateISO: "2026-02-03", amount: -10, description: "VALID"},
+			{index: 1, sourceLine: 1, dateISO: "2026-02-03", amount: -1000, description: "VALID"},
 		},
 	}
 	output := renderImportPreview(snapshot, true, false, 0, 0, 20, 140, NewKeyRegistry())
@@ -1282,7 +1283,7 @@ func TestRenderImportPreviewShowsParseErrorBannerAndBlockedHint(t *testing.T) {
 }
 
 func TestRenderManagerAccountModalShowsFooter(t *testing.T) {
-	m := newModel()
+	m := newModel("")
 	m.keys = NewKeyRegistry()
 	if err := m.keys.ApplyKeybindingConfig([]keybindingConfig{
 		{Scope: scopeManagerModal, Action: string(actionConfirm), Keys: []string{"ctrl+s"}},
@@ -1330,7 +1331,7 @@ func TestRenderDetailUsesConfiguredEditKey(t *testing.T) {
 	}
 	txn := transaction{
 		dateISO:      "2026-02-10",
-		amount:       -12.34,
+		amount:       -1234,
 		description:  "Coffee",
 		categoryName: "Dining",
 	}
@@ -1347,7 +1348,7 @@ func TestRenderDetailUsesFixedWidthAndWrapsLongNotes(t *testing.T) {
 	keys := NewKeyRegistry()
 	txn := transaction{
 		dateISO:      "2026-02-10",
-		amount:       -12.34,
+		amount:       -1234,
 		description:  "Coffee and breakfast",
 		categoryName: "Dining",
 	}
@@ -1379,7 +1380,7 @@ func TestRenderDetailMetadataOrderAndSingleLineDateAmount(t *testing.T) {
 	keys := NewKeyRegistry()
 	txn := transaction{
 		dateISO:      "2026-02-10",
-		amount:       -12.34,
+		amount:       -1234,
 		description:  "PAYMENT THANKYOU 551646",
 		categoryName: "Transport",
 	}
@@ -1427,7 +1428,7 @@ func TestRenderManagerSectionBoxTitleInTopBorder(t *testing.T) {
 }
 
 func TestViewKeepsHeaderVisibleWhenManagerBodyOverflows(t *testing.T) {
-	m := newModel()
+	m := newModel("")
 	m.ready = true
 	m.width = 220
 	m.height = 12
@@ -1439,7 +1440,7 @@ func TestViewKeepsHeaderVisibleWhenManagerBodyOverflows(t *testing.T) {
 		rows = append(rows, transaction{
 			id:          i + 1,
 			dateISO:     "2026-02-10",
-			amount:      -10.0 - float64(i),
+			amount:      money(-1000 - 100*i),
 			description: "Txn",
 		})
 	}
@@ -1456,7 +1457,7 @@ func TestViewKeepsHeaderVisibleWhenManagerBodyOverflows(t *testing.T) {
 }
 
 func TestViewKeepsHeaderVisibleWhenManagerModalOpen(t *testing.T) {
-	m := newModel()
+	m := newModel("")
 	m.ready = true
 	m.width = 120
 	m.height = 16
@@ -1478,13 +1479,13 @@ func TestViewKeepsHeaderVisibleWhenManagerModalOpen(t *testing.T) {
 }
 
 func TestViewManagerBodyUsesFullViewportWidth(t *testing.T) {
-	m := newModel()
+	m := newModel("")
 	m.ready = true
 	m.width = 90
 	m.height = 16
 	m.activeTab = tabManager
 	m.accounts = []account{{id: 1, name: "Main", acctType: "transaction", isActive: true}}
-	m.rows = []transaction{{id: 1, dateISO: "2026-02-10", amount: -10, description: "Txn"}}
+	m.rows = []transaction{{id: 1, dateISO: "2026-02-10", amount: -1000, description: "Txn"}}
 
 	out := m.View()
 	lines := splitLines(out)
@@ -1501,7 +1502,7 @@ func TestViewManagerBodyUsesFullViewportWidth(t *testing.T) {
 }
 
 func TestViewAllLinesMatchViewportWidth(t *testing.T) {
-	m := newModel()
+	m := newModel("")
 	m.ready = true
 	m.width = 100
 	m.height = 14
@@ -1534,7 +1535,7 @@ func TestDashboardChartWidthsUsesExactAvailableWidth(t *testing.T) {
 }
 
 func TestDashboardOverviewBoxUsesFullViewportWidth(t *testing.T) {
-	m := newModel()
+	m := newModel("")
 	m.ready = true
 	m.width = 120
 	m.height = 20
@@ -1564,7 +1565,7 @@ func TestDashboardOverviewBoxUsesFullViewportWidth(t *testing.T) {
 }
 
 func TestDashboardOverviewRowsDoNotClipAtRightEdge(t *testing.T) {
-	m := newModel()
+	m := newModel("")
 	m.ready = true
 	m.width = 140
 	m.height = 20
@@ -1585,7 +1586,7 @@ func TestDashboardOverviewRowsDoNotClipAtRightEdge(t *testing.T) {
 }
 
 func TestDashboardChartRowsDoNotClipAtRightEdge(t *testing.T) {
-	m := newModel()
+	m := newModel("")
 	m.ready = true
 	m.width = 160
 	m.height = 24
@@ -1607,7 +1608,7 @@ func TestDashboardChartRowsDoNotClipAtRightEdge(t *testing.T) {
 
 func TestViewManagerTransactionsNeverExceedsViewportWidthWithLongContent(t *testing.T) {
 	catID := 1
-	m := newModel()
+	m := newModel("")
 	m.ready = true
 	m.width = 120
 	m.height = 20
@@ -1619,7 +1620,7 @@ func TestViewManagerTransactionsNeverExceedsViewportWidthWithLongContent(t *test
 		{
 			id:           1,
 			dateISO:      "2026-02-03",
-			amount:       -20.00,
+			amount:       -2000,
 			description:  "DAN MURPHY'S / 580 MELBOURN SPOTSWOOD EXTREMELY LONG DESCRIPTION",
 			categoryID:   &catID,
 			categoryName: "Dining & Drinks",
@@ -1647,7 +1648,7 @@ func TestViewManagerTransactionsNeverExceedsViewportWidthWithLongContent(t *test
 }
 
 func TestRenderSettingsDBAndImportHistoryCards(t *testing.T) {
-	m := newModel()
+	m := newModel("")
 	m.dbInfo = dbInfo{
 		schemaVersion:    2,
 		transactionCount: 10,
@@ -1678,7 +1679,7 @@ func TestRenderSettingsDBAndImportHistoryCards(t *testing.T) {
 }
 
 func TestRenderSettingsSectionBoxUsesBorderTitleStyle(t *testing.T) {
-	m := newModel()
+	m := newModel("")
 	m.settSection = settSecCategories
 	out := renderSettingsSectionBox("Categories", settSecCategories, m, 32, "content")
 	if !strings.Contains(out, "╭") || !strings.Contains(out, "╯") {
@@ -1694,7 +1695,7 @@ func TestRenderSettingsSectionBoxUsesBorderTitleStyle(t *testing.T) {
 // ---------------------------------------------------------------------------
 
 func TestRenderStatusError(t *testing.T) {
-	m := newModel()
+	m := newModel("")
 	m.width = 80
 	normal := m.renderStatus("ok", false)
 	errOut := m.renderStatus("fail", true)
@@ -11,7 +11,7 @@ import (
 func TestUpdateDispatcherEscClosesOverlaysInPriorityOrder(t *testing.T) {
 	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
 
-	m := newModel()
+	m := newModel("")
 	m.ready = true
 	m.commandOpen = true
 	m.commandUIKind = commandUIKindPalette
@@ -122,7 +122,7 @@ func TestUpdateDispatcherEscClosesOverlaysInPriorityOrder(t *testing.T) {
 func TestUpdateSettingsTextInputShieldsPrintableShortcutKeys(t *testing.T) {
 	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
 
-	m := newModel()
+	m := newModel("")
 	m.activeTab = tabSettings
 	m.ready = true
 	m.settMode = settModeAddTag
@@ -170,7 +170,7 @@ func TestCommandUIOpenBlockedByBusyStatesViaTopLevelUpdate(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			m := newModel()
+			m := newModel("")
 			m.ready = true
 			tt.mut(&m)
 
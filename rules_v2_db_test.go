@@ -15,8 +15,8 @@ func TestOpenDBCreatesV7SchemaRulesV2(t *testing.T) {
 	if err := db.QueryRow("SELECT version FROM schema_meta LIMIT 1").Scan(&ver); err != nil {
 		t.Fatalf("query schema version: %v", err)
 	}
-	if ver != 7 {
-		t.Fatalf("schema version = %d, want 7", ver)
+	if ver != schemaVersion {
+		t.Fatalf("schema version = %d, want %d", ver, schemaVersion)
 	}
 
 	tables := []string{
@@ -65,6 +65,7 @@ func TestMigrateFromV4ToV7PreservesDataAndDropsLegacyRules(t *testing.T) {
 	path := f.Name()
 	_ = f.Close()
 	defer os.Remove(path)
+	defer os.RemoveAll(backupDir(path))
 
 	db, err := sql.Open("sqlite", path)
 	if err != nil {
@@ -149,7 +150,7 @@ func TestMigrateFromV4ToV7PreservesDataAndDropsLegacyRules(t *testing.T) {
 		INSERT INTO tags (name, color, sort_order) VALUES ('WEEKLY', '#89b4fa', 1);
 		INSERT INTO accounts (name, type, sort_order, is_active) VALUES ('A1', 'debit', 1, 1);
 		INSERT INTO transactions (date_raw, date_iso, amount, description, category_id, notes, account_id)
-			VALUES ('1/01/2026', '2026-01-01', -12.30, 'LEGACY TXN', NULL, '', 1);
+			VALUES ('1/01/2026', '2026-01-01', -1230, 'LEGACY TXN', NULL, '', 1);
 		INSERT INTO imports (filename, row_count) VALUES ('legacy.csv', 1);
 		INSERT INTO category_rules (pattern, category_id, priority) VALUES ('legacy', 1, 0);
 		INSERT INTO tag_rules (pattern, tag_id, priority) VALUES ('legacy', 1, 0);
@@ -170,8 +171,8 @@ func TestMigrateFromV4ToV7PreservesDataAndDropsLegacyRules(t *testing.T) {
 	if err := upgraded.QueryRow("SELECT version FROM schema_meta LIMIT 1").Scan(&ver); err != nil {
 		t.Fatalf("query version after migrate: %v", err)
 	}
-	if ver != 7 {
-		t.Fatalf("schema version after migrate = %d, want 7", ver)
+	if ver != schemaVersion {
+		t.Fatalf("schema version after migrate = %d, want %d", ver, schemaVersion)
 	}
 
 	var txnCount, tagCount, accountCount, importCount int
@@ -316,7 +317,7 @@ func TestApplyRulesV2ToScope_OrderCategoryAndTagSemantics(t *testing.T) {
 		t.Helper()
 		res, execErr := db.Exec(`
 			INSERT INTO transactions (date_raw, date_iso, amount, description, notes, account_id)
-			VALUES ('1/01/2026', '2026-01-01', -10.00, ?, '', ?)
+			VALUES ('1/01/2026', '2026-01-01', -1000, ?, '', ?)
 		`, desc, accountID)
 		if execErr != nil {
 			t.Fatalf("insert txn %q: %v", desc, execErr)
@@ -413,7 +414,7 @@ func TestDryRunRulesV2MatchesApplyAndDoesNotWrite(t *testing.T) {
 		desc := fmt.Sprintf("GROCERY %d", i)
 		if _, err := db.Exec(`
 			INSERT INTO transactions (date_raw, date_iso, amount, description, notes, account_id)
-			VALUES ('1/01/2026', '2026-01-01', -20.00, ?, '', ?)
+			VALUES ('1/01/2026', '2026-01-01', -2000, ?, '', ?)
 		`, desc, acctID); err != nil {
 			t.Fatalf("insert txn %d: %v", i, err)
 		}
@@ -492,7 +493,7 @@ func TestApplyRulesV2ToScopeSkipsMissingSavedFilters(t *testing.T) {
 	groceries := cats[1].id
 	if _, err := db.Exec(`
 		INSERT INTO transactions (date_raw, date_iso, amount, description, notes, account_id)
-		VALUES ('1/01/2026', '2026-01-01', -9.99, 'WOOLWORTHS', '', ?)
+		VALUES ('1/01/2026', '2026-01-01', -999, 'WOOLWORTHS', '', ?)
 	`, acctID); err != nil {
 		t.Fatalf("insert txn: %v", err)
 	}
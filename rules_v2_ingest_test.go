@@ -26,7 +26,7 @@ func TestIngestCmdRulesV2TargetsImportedRowsOnly(t *testing.T) {
 
 	if _, err := db.Exec(`
 		INSERT INTO transactions (date_raw, date_iso, amount, description, notes, account_id)
-		VALUES ('1/01/2026', '2026-01-01', -12.50, 'WOOLWORTHS EXISTING', '', ?)
+		VALUES ('1/01/2026', '2026-01-01', -1250, 'WOOLWORTHS EXISTING', '', ?)
 	`, accountID); err != nil {
 		t.Fatalf("insert existing txn: %v", err)
 	}
@@ -6,7 +6,7 @@ import (
 )
 
 func TestSettingsRulesEnterOpensRuleEditor(t *testing.T) {
-	m := newModel()
+	m := newModel("")
 	m.ready = true
 	m.activeTab = tabSettings
 	m.settSection = settSecRules
@@ -28,7 +28,7 @@ func TestSettingsRulesEnterOpensRuleEditor(t *testing.T) {
 }
 
 func TestRulesDryRunMsgOpensModal(t *testing.T) {
-	m := newModel()
+	m := newModel("")
 	m.ready = true
 
 	next, _ := m.Update(rulesDryRunMsg{
@@ -62,7 +62,7 @@ func TestUpdateSettingsRulesToggleEnableUsesSpace(t *testing.T) {
 		t.Fatalf("load rules: %v", err)
 	}
 
-	m := newModel()
+	m := newModel("")
 	m.db = db
 	m.ready = true
 	m.activeTab = tabSettings
@@ -96,7 +96,7 @@ func TestUpdateSettingsRulesToggleEnableUsesSpace(t *testing.T) {
 }
 
 func TestRulesApplyStatusIncludesScopeLabel(t *testing.T) {
-	m := newModel()
+	m := newModel("")
 	m.filterAccounts = map[int]bool{1: true, 2: true}
 
 	next, _ := m.Update(rulesAppliedMsg{updatedTxns: 4, catChanges: 3, tagChanges: 5, failedRules: 1, scope: "2 selected accounts"})
@@ -116,7 +116,7 @@ func TestRulesApplyStatusIncludesScopeLabel(t *testing.T) {
 }
 
 func TestRuleEditorEnterOnFilterStepOpensPickerAndSelectsID(t *testing.T) {
-	m := newModel()
+	m := newModel("")
 	m.ruleEditorOpen = true
 	m.ruleEditorStep = 1
 	m.savedFilters = []savedFilter{{ID: "groceries", Name: "Groceries", Expr: "cat:Groceries"}}
@@ -144,7 +144,7 @@ func TestRuleEditorEnterOnFilterStepOpensPickerAndSelectsID(t *testing.T) {
 }
 
 func TestRuleEditorCategoryAndTagPickersRoundTrip(t *testing.T) {
-	m := newModel()
+	m := newModel("")
 	m.ruleEditorOpen = true
 	m.ruleEditorStep = 2
 	m.categories = []category{{id: 1, name: "Groceries"}}
@@ -184,7 +184,7 @@ func TestRuleEditorCategoryAndTagPickersRoundTrip(t *testing.T) {
 }
 
 func TestRuleEditorVimNavOverrideStillTypesLiteral(t *testing.T) {
-	m := newModel()
+	m := newModel("")
 	m.ruleEditorOpen = true
 	m.ruleEditorStep = 0
 	m.ruleEditorName = ""
@@ -225,7 +225,7 @@ func TestRuleReorderCursorFollowsMovedRule(t *testing.T) {
 		t.Fatalf("load rules: %v", err)
 	}
 
-	m := newModel()
+	m := newModel("")
 	m.db = db
 	m.ready = true
 	m.activeTab = tabSettings
@@ -258,7 +258,7 @@ func TestRuleReorderCursorFollowsMovedRule(t *testing.T) {
 }
 
 func TestRuleTagPickerEnterNoPendingTogglesAndCloses(t *testing.T) {
-	m := newModel()
+	m := newModel("")
 	m.ruleEditorOpen = true
 	m.ruleEditorStep = 3
 	m.tags = []tag{{id: 7, name: "WEEKLY"}}
@@ -279,7 +279,7 @@ func TestRuleTagPickerEnterNoPendingTogglesAndCloses(t *testing.T) {
 }
 
 func TestRuleTagPickerEnterWithPendingChangesSubmitsWithoutExtraToggle(t *testing.T) {
-	m := newModel()
+	m := newModel("")
 	m.ruleEditorOpen = true
 	m.ruleEditorStep = 3
 	m.tags = []tag{
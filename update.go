@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"path/filepath"
 	"strings"
 	"time"
 
@@ -17,10 +18,39 @@ func (m model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m.handleRefreshDone(msg)
 	case filesLoadedMsg:
 		return m.handleFilesLoaded(msg)
+	case formatWizardSavedMsg:
+		return m.handleFormatWizardSaved(msg)
 	case importPreviewMsg:
 		return m.handleImportPreview(msg)
+	case importArchivedMsg:
+		return m.handleImportArchived(msg)
+	case fxRatesLoadedMsg:
+		return m.handleFXRatesLoaded(msg)
+	case merchantsRerunMsg:
+		return m.handleMerchantsRerun(msg)
+	case ledgerStepMsg:
+		return m.handleLedgerStep(msg)
+	case backupRestoredMsg:
+		return m.handleBackupRestored(msg)
+	case ledgerSwitchedMsg:
+		return m.handleLedgerSwitched(msg)
+	case txnHistoryMsg:
+		if msg.err != nil {
+			m.setError(fmt.Sprintf("Load history failed: %v", msg.err))
+			return m, nil
+		}
+		if m.showDetail && m.detailIdx == msg.txnID {
+			m.detailHistory = msg.changes
+		}
+		return m, nil
+	case importRejectResolvedMsg:
+		return m.handleImportRejectResolved(msg)
 	case clearDoneMsg:
 		return m.handleClearDone(msg)
+	case importUndoSummaryMsg:
+		return m.handleImportUndoSummary(msg)
+	case importUndoneMsg:
+		return m.handleImportUndone(msg)
 	case ingestDoneMsg:
 		return m.handleIngestDone(msg)
 	case tea.WindowSizeMsg:
@@ -239,7 +269,13 @@ func (m model) handleRefreshDone(msg refreshDoneMsg) (tea.Model, tea.Cmd) {
 		m.txnTags = make(map[int][]tag)
 	}
 	m.imports = msg.imports
+	m.importRejects = msg.importRejects
+	m.backups = msg.backups
+	if m.rejectsReview != nil {
+		m.clampRejectsReview()
+	}
 	m.accounts = msg.accounts
+	m.fx = newFXConverter(m.reportingCurrency(), m.accounts, msg.fxRates)
 	m.dbInfo = msg.info
 	m.filterUsage = msg.filterUsage
 	if m.filterUsage == nil {
@@ -277,7 +313,7 @@ func (m model) handleRefreshDone(msg refreshDoneMsg) (tea.Model, tea.Cmd) {
 		} else {
 			m.allocationTagsByID = make(map[int][]tag)
 		}
-		if lines, err := computeBudgetLines(m.db, m.categoryBudgets, m.budgetOverrides, m.budgetMonth, m.filterAccounts); err == nil {
+		if lines, err := computeBudgetLines(m.db, m.categoryBudgets, m.budgetOverrides, m.budgetMonth, m.filterAccounts, m.fx); err == nil {
 			m.budgetLines = lines
 			m.budgetOverCount = 0
 			if len(lines) > 0 {
@@ -293,7 +329,7 @@ func (m model) handleRefreshDone(msg refreshDoneMsg) (tea.Model, tea.Cmd) {
 			}
 			m.budgetVarSparkline = m.computeBudgetVarianceSeries(6)
 		}
-		if targetLines, err := computeTargetLines(m.db, m.spendingTargets, m.targetOverrides, m.txnTags, m.savedFilters, m.filterAccounts); err == nil {
+		if targetLines, err := computeTargetLines(m.db, m.spendingTargets, m.targetOverrides, m.txnTags, m.savedFilters, m.filterAccounts, m.fx); err == nil {
 			m.targetLines = targetLines
 		}
 	}
@@ -340,23 +376,94 @@ func (m model) handleRefreshDone(msg refreshDoneMsg) (tea.Model, tea.Cmd) {
 }
 
 func (m model) handleFilesLoaded(msg filesLoadedMsg) (tea.Model, tea.Cmd) {
+	importAll := m.importAllOnLoad
+	m.importAllOnLoad = false
 	if msg.err != nil {
 		m.setError(fmt.Sprintf("File scan error: %v", msg.err))
 		m.importPicking = false
 		return m, nil
 	}
 	m.importFiles = msg.files
+	m.importFileMatches = msg.matches
 	m.importCursor = 0
+	m.importFormatChoice = 0
 	if len(msg.files) == 0 {
-		m.setStatus("No CSV files found in current directory.")
+		where := "current directory"
+		if m.importInbox.dir != "" {
+			where = "import inbox " + m.basePath
+		}
+		m.setStatusf("No CSV, OFX, QFX or QIF files found in %s.", where)
 		m.importPicking = false
+		return m, nil
+	}
+	if importAll {
+		return m, m.startImportQueue(msg.files)
 	}
 	return m, nil
 }
 
+// startImportQueue begins an "import all pending" run over files, opening a
+// preview for the first one.
+func (m *model) startImportQueue(files []string) tea.Cmd {
+	if m.db == nil {
+		m.setError("Database not ready.")
+		return nil
+	}
+	if len(files) == 0 {
+		m.setStatus("No pending files to import.")
+		return nil
+	}
+	m.importPicking = false
+	m.importQueue = &importQueueState{pending: append([]string(nil), files...), total: len(files)}
+	return m.nextQueuedImport()
+}
+
+// nextQueuedImport scans the next pending file of an import-all run, or
+// reports the run's totals once every file has been handled.
+func (m *model) nextQueuedImport() tea.Cmd {
+	q := m.importQueue
+	if q == nil {
+		return nil
+	}
+	if len(q.pending) == 0 {
+		m.importQueue = nil
+		if q.failed > 0 {
+			m.setError(q.summary() + " Last failure: " + q.lastErr)
+		} else {
+			m.setStatus(q.summary())
+		}
+		return nil
+	}
+	file := q.pending[0]
+	q.pending = q.pending[1:]
+	m.setStatusf("Import all pending (%d/%d): scanning %s...", q.position(), q.total, file)
+	return scanDupesCmd(m.db, file, m.basePath, m.formats, m.savedFilters, m.dupeMatch)
+}
+
+func (m model) handleFormatWizardSaved(msg formatWizardSavedMsg) (tea.Model, tea.Cmd) {
+	if msg.err != nil {
+		if m.formatWizard != nil {
+			m.formatWizard.err = msg.err.Error()
+		}
+		m.setError(fmt.Sprintf("Format save failed: %v", msg.err))
+		return m, nil
+	}
+	m.formatWizard = nil
+	m.formats = msg.formats
+	m.setStatusf("Saved format for %s. Scanning %s...", msg.format.Account, msg.fileName)
+	return m, tea.Batch(
+		refreshCmd(m.db),
+		scanDupesForFormatCmd(m.db, msg.path, m.basePath, msg.format, m.savedFilters, m.dupeMatch),
+	)
+}
+
 func (m model) handleImportPreview(msg importPreviewMsg) (tea.Model, tea.Cmd) {
 	if msg.err != nil {
 		m.setError(fmt.Sprintf("Scan failed: %v", msg.err))
+		if m.importQueue != nil {
+			m.importQueue.fail(msg.err)
+			return m, m.nextQueuedImport()
+		}
 		return m, nil
 	}
 	if msg.snapshot == nil {
@@ -368,6 +475,7 @@ func (m model) handleImportPreview(msg importPreviewMsg) (tea.Model, tea.Cmd) {
 	m.importPreviewShowAll = false
 	m.importPreviewCursor = 0
 	m.importPreviewScroll = 0
+	msg.snapshot.setMerchants(m.merchants)
 	m.importPreviewSnapshot = msg.snapshot
 	return m, nil
 }
@@ -384,9 +492,67 @@ func (m model) handleClearDone(msg clearDoneMsg) (tea.Model, tea.Cmd) {
 	return m, refreshCmd(m.db)
 }
 
+// handleImportUndoSummary arms the undo-import confirm once the counts of
+// what would be removed are known.
+func (m model) handleImportUndoSummary(msg importUndoSummaryMsg) (tea.Model, tea.Cmd) {
+	if msg.err != nil {
+		m.setError(fmt.Sprintf("Undo import failed: %v", msg.err))
+		return m, nil
+	}
+	s := msg.summary
+	prompt := fmt.Sprintf("Undo import %q: remove %d rows, %d allocations, %d tags", s.filename, s.rows, s.allocations, s.tags)
+	if s.edited > 0 {
+		prompt += fmt.Sprintf(" (%d edited since import)", s.edited)
+	} else {
+		prompt += " (no manual edits)"
+	}
+	keyLabel := m.primaryActionKey(scopeSettingsActiveImportHist, actionDelete, "del")
+	prompt += fmt.Sprintf(". Press %s again to confirm", keyLabel)
+	return m, m.armSettingsConfirm(confirmActionUndoImport, s.importID, prompt)
+}
+
+func (m model) handleImportUndone(msg importUndoneMsg) (tea.Model, tea.Cmd) {
+	if msg.err != nil {
+		m.setError(fmt.Sprintf("Undo import failed: %v", msg.err))
+		return m, nil
+	}
+	m.setStatusf("Undid import %s: removed %d transactions.", msg.filename, msg.removed)
+	if m.db == nil {
+		return m, nil
+	}
+	return m, refreshCmd(m.db)
+}
+
+// handleBackupRestored swaps in the reopened database after a restore and
+// only then closes the old handle, so commands started before the swap
+// never find it closed under them mid-restore. A failed restore leaves the
+// old database in place.
+func (m model) handleBackupRestored(msg backupRestoredMsg) (tea.Model, tea.Cmd) {
+	if msg.err != nil && msg.db == nil {
+		m.setError(fmt.Sprintf("Restore failed: %v", msg.err))
+		return m, nil
+	}
+	// A failed restore may still hand back the reverted database.
+	old := m.db
+	m.db = msg.db
+	if old != nil && old != msg.db {
+		_ = old.Close()
+	}
+	if msg.err != nil {
+		m.setError(fmt.Sprintf("Restore failed: %v", msg.err))
+	} else {
+		m.setStatusf("Restored %s. The previous database was snapshotted first.", msg.backup.name)
+	}
+	return m, refreshCmd(m.db)
+}
+
 func (m model) handleIngestDone(msg ingestDoneMsg) (tea.Model, tea.Cmd) {
 	if msg.err != nil {
 		m.setError(fmt.Sprintf("Import failed: %v", msg.err))
+		if m.importQueue != nil {
+			m.importQueue.fail(msg.err)
+			return m, m.nextQueuedImport()
+		}
 		return m, nil
 	}
 	base := ""
@@ -398,10 +564,88 @@ func (m model) handleIngestDone(msg ingestDoneMsg) (tea.Model, tea.Cmd) {
 	if msg.rulesApplied {
 		base += " | " + formatRulesSummary("Import scope", msg.rulesTxnUpdated, msg.rulesCatChanges, msg.rulesTagChanges, msg.rulesFailed)
 	}
+	if msg.quarantined > 0 {
+		base += fmt.Sprintf(" | %d rows quarantined for review", msg.quarantined)
+	}
 	m.setStatus(base)
-	if m.db == nil {
+	var cmds []tea.Cmd
+	if m.db != nil {
+		cmds = append(cmds, refreshCmd(m.db))
+	}
+	if m.importInbox.archives() && msg.sourcePath != "" {
+		cmds = append(cmds, archiveImportedFileCmd(m.basePath, msg.sourcePath))
+	}
+	if m.importQueue != nil {
+		m.importQueue.imported++
+		cmds = append(cmds, m.nextQueuedImport())
+	}
+	return m, tea.Batch(cmds...)
+}
+
+func (m model) handleImportRejectResolved(msg importRejectResolvedMsg) (tea.Model, tea.Cmd) {
+	if msg.err != nil {
+		if m.rejectsReview != nil {
+			m.rejectsReview.err = msg.err.Error()
+		}
+		m.setError(fmt.Sprintf("Rejected row failed: %v", msg.err))
 		return m, nil
 	}
+	if msg.promoted {
+		m.setStatusf("Promoted %s line %d to a transaction.", msg.file, msg.line)
+	} else {
+		m.setStatusf("Discarded %s line %d.", msg.file, msg.line)
+	}
+	return m, refreshCmd(m.db)
+}
+
+func (m model) handleImportArchived(msg importArchivedMsg) (tea.Model, tea.Cmd) {
+	if msg.err != nil {
+		m.setError(fmt.Sprintf("Imported %s but could not archive it: %v", msg.file, msg.err))
+	}
+	return m, nil
+}
+
+func (m model) handleFXRatesLoaded(msg fxRatesLoadedMsg) (tea.Model, tea.Cmd) {
+	if msg.err != nil {
+		m.setError(fmt.Sprintf("Load FX rates failed: %v", msg.err))
+		return m, nil
+	}
+	status := fmt.Sprintf("Loaded %d FX rates from %s.", msg.count, filepath.Base(msg.path))
+	if len(msg.missing) > 0 {
+		status += fmt.Sprintf(" No %s rate for %s; those amounts are left out of dashboards and budgets.", m.reportingCurrency(), strings.Join(msg.missing, ", "))
+	}
+	m.setStatus(status)
+	return m, refreshCmd(m.db)
+}
+
+func (m model) handleMerchantsRerun(msg merchantsRerunMsg) (tea.Model, tea.Cmd) {
+	if msg.err != nil {
+		m.setError(fmt.Sprintf("Re-run merchant rules failed: %v", msg.err))
+		return m, nil
+	}
+	m.merchantRules = msg.rules
+	m.merchants = msg.merchants
+	m.setStatusf("Merchant rules re-run: %d transactions updated.", msg.updated)
+	if msg.updated == 0 {
+		return m, nil
+	}
+	return m, refreshCmd(m.db)
+}
+
+func (m model) handleLedgerStep(msg ledgerStepMsg) (tea.Model, tea.Cmd) {
+	verb := "Redo"
+	if msg.undo {
+		verb = "Undo"
+	}
+	if msg.err != nil {
+		m.setError(fmt.Sprintf("%s failed: %v", verb, msg.err))
+		return m, nil
+	}
+	if msg.entry == nil {
+		m.setStatusf("Nothing to %s.", strings.ToLower(verb))
+		return m, nil
+	}
+	m.setStatusf("%s: %s (%d transactions).", verb, msg.entry.label, msg.entry.rowCount)
 	return m, refreshCmd(m.db)
 }
 
@@ -476,12 +720,20 @@ func (m model) currentAppSettings() appSettings {
 	out.DashCustomStart = m.dashCustomStart
 	out.DashCustomEnd = m.dashCustomEnd
 	out.CommandDefaultInterface = m.commandDefault
+	out.DupeMatchDays = m.dupeMatch.windowDays
+	out.DupeMatchSimilarity = m.dupeMatch.similarity
+	out.ImportInbox = m.importInbox.dir
+	out.ImportInboxRecursive = m.importInbox.recursive
+	out.ImportInboxGlobs = m.importInbox.globs
+	out.BaseCurrency = m.baseCurrency
+	out.FXRatesFile = m.fxRatesFile
+	out.MerchantRules = m.merchantRules
 	return normalizeSettings(out)
 }
 
-func saveSettingsCmd(s appSettings) tea.Cmd {
+func saveSettingsCmd(profile string, s appSettings) tea.Cmd {
 	return func() tea.Msg {
-		return settingsSavedMsg{err: saveAppSettings(s)}
+		return settingsSavedMsg{err: saveAppSettings(profile, s)}
 	}
 }
 
@@ -650,6 +902,10 @@ func settingsConfirmSpecFor(action settingsConfirmAction) (settingsConfirmSpec,
 		return settingsConfirmSpec{scope: scopeSettingsActiveFilters, action: actionDelete, fallback: "del"}, true
 	case confirmActionClearDB:
 		return settingsConfirmSpec{scope: scopeSettingsActiveDBImport, action: actionClearDB, fallback: "c"}, true
+	case confirmActionUndoImport:
+		return settingsConfirmSpec{scope: scopeSettingsActiveImportHist, action: actionDelete, fallback: "del"}, true
+	case confirmActionRestoreBackup:
+		return settingsConfirmSpec{scope: scopeSettingsActiveBackups, action: actionRestoreBackup, fallback: "r"}, true
 	default:
 		return settingsConfirmSpec{}, false
 	}
@@ -688,14 +944,20 @@ func (m *model) clearSettingsConfirm() {
 func (m *model) beginImportFlow() tea.Cmd {
 	m.importPicking = true
 	m.importFiles = nil
+	m.importFileMatches = nil
 	m.importCursor = 0
+	m.importFormatChoice = 0
+	m.importForWizard = false
+	m.importWizardAcct = ""
+	m.importQueue = nil
+	m.importAllOnLoad = false
 	m.importPreviewOpen = false
 	m.importPreviewSnapshot = nil
 	m.importPreviewPostRules = true
 	m.importPreviewShowAll = false
 	m.importPreviewCursor = 0
 	m.importPreviewScroll = 0
-	return loadFilesCmd(m.basePath)
+	return loadFilesCmd(m.basePath, m.importInbox, m.formats)
 }
 
 type jumpTarget struct {
@@ -823,6 +1085,9 @@ func (m *model) applyFocusedSection(activate bool) {
 		case sectionSettingsImportHistory:
 			m.settColumn = settColRight
 			m.settSection = settSecImportHistory
+		case sectionSettingsBackups:
+			m.settColumn = settColRight
+			m.settSection = settSecBackups
 		case sectionSettingsDatabase:
 			m.settColumn = settColRight
 			m.settSection = settSecDBImport
@@ -887,10 +1152,7 @@ func (m *model) applyTabDefaultsOnSwitch() {
 
 func moveSettingsSection(section, delta int) int {
 	col, row := settColumnRow(section)
-	rowCount := 3
-	if col == settColLeft {
-		rowCount = 4
-	}
+	rowCount := 4
 	if rowCount <= 0 {
 		return section
 	}
@@ -927,15 +1189,15 @@ func (m model) computeBudgetVarianceSeries(points int) []float64 {
 	series := make([]float64, 0, points)
 	for i := points - 1; i >= 0; i-- {
 		month := start.AddDate(0, -i, 0).Format("2006-01")
-		lines, err := computeBudgetLines(m.db, m.categoryBudgets, m.budgetOverrides, month, m.filterAccounts)
+		lines, err := computeBudgetLines(m.db, m.categoryBudgets, m.budgetOverrides, month, m.filterAccounts, m.fx)
 		if err != nil {
 			continue
 		}
-		total := 0.0
+		var total money
 		for _, line := range lines {
 			total += line.remaining
 		}
-		series = append(series, total)
+		series = append(series, total.float())
 	}
 	return series
 }
@@ -26,7 +26,7 @@ func (m model) updateBudget(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.dashAnchorMonth = now.Format("2006-01")
 		m.budgetMonth = now.Format("2006-01")
 		m.budgetYear = now.Year()
-		saveCmd := saveSettingsCmd(m.currentAppSettings())
+		saveCmd := saveSettingsCmd(m.profile, m.currentAppSettings())
 		if m.db != nil {
 			return m, tea.Batch(saveCmd, refreshCmd(m.db))
 		}
@@ -98,12 +98,12 @@ func (m model) updateBudgetEdit(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 				m.setError("Budget row not found.")
 				return m, nil
 			}
-			if err := upsertBudgetOverride(m.db, budgetID, month, value); err != nil {
+			if err := upsertBudgetOverride(m.db, budgetID, month, moneyFromFloat(value)); err != nil {
 				m.setError(fmt.Sprintf("Save override failed: %v", err))
 				return m, nil
 			}
 		} else {
-			if err := upsertCategoryBudget(m.db, line.categoryID, value); err != nil {
+			if err := upsertCategoryBudget(m.db, line.categoryID, moneyFromFloat(value)); err != nil {
 				m.setError(fmt.Sprintf("Save budget failed: %v", err))
 				return m, nil
 			}
@@ -65,7 +65,7 @@ func (m model) updateDashboard(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.dashTimeframeCursor = dashTimeframeThisMonth
 		m.dashAnchorMonth = now.Format("2006-01")
 		budgetChanged := m.syncBudgetMonthFromDashboard()
-		saveCmd := saveSettingsCmd(m.currentAppSettings())
+		saveCmd := saveSettingsCmd(m.profile, m.currentAppSettings())
 		if budgetChanged && m.db != nil {
 			return m, tea.Batch(saveCmd, refreshCmd(m.db))
 		}
@@ -94,7 +94,7 @@ func (m model) updateDashboard(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.dashTimeframe = m.dashTimeframeCursor
 		budgetChanged := m.syncBudgetMonthFromDashboard()
 		m.setStatusf("Dashboard timeframe: %s", dashTimeframeLabel(m.dashTimeframe))
-		saveCmd := saveSettingsCmd(m.currentAppSettings())
+		saveCmd := saveSettingsCmd(m.profile, m.currentAppSettings())
 		if budgetChanged && m.db != nil {
 			return m, tea.Batch(saveCmd, refreshCmd(m.db))
 		}
@@ -146,7 +146,7 @@ func (m model) updateDashboardCustomInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.dashCustomEditing = false
 		budgetChanged := m.syncBudgetMonthFromDashboard()
 		m.setStatusf("Dashboard timeframe: %s to %s", m.dashCustomStart, m.dashCustomEnd)
-		saveCmd := saveSettingsCmd(m.currentAppSettings())
+		saveCmd := saveSettingsCmd(m.profile, m.currentAppSettings())
 		if budgetChanged && m.db != nil {
 			return m, tea.Batch(saveCmd, refreshCmd(m.db))
 		}
@@ -374,9 +374,9 @@ func (m model) dashboardApplyCustomModeFromSavedFilterID(id string) (model, erro
 	return m, nil
 }
 
-func saveCustomPaneModesCmd(modes []customPaneMode) tea.Cmd {
+func saveCustomPaneModesCmd(profile string, modes []customPaneMode) tea.Cmd {
 	out := append([]customPaneMode(nil), modes...)
 	return func() tea.Msg {
-		return settingsSavedMsg{err: saveCustomPaneModes(out)}
+		return settingsSavedMsg{err: saveCustomPaneModes(profile, out)}
 	}
 }
@@ -7,7 +7,7 @@ import (
 )
 
 func TestDashboardJumpTargetsIncludeDateAndAnalyticsPanes(t *testing.T) {
-	m := newModel()
+	m := newModel("")
 	m.keys = NewKeyRegistry()
 	m.commands = NewCommandRegistry(m.keys, m.savedFilters)
 	m.activeTab = tabDashboard
@@ -32,7 +32,7 @@ func TestDashboardJumpTargetsIncludeDateAndAnalyticsPanes(t *testing.T) {
 }
 
 func TestDashboardFocusedPaneCyclesModesAndEscUnfocuses(t *testing.T) {
-	m := newModel()
+	m := newModel("")
 	m.keys = NewKeyRegistry()
 	m.commands = NewCommandRegistry(m.keys, m.savedFilters)
 	m.ready = true
@@ -99,7 +99,7 @@ func TestDashboardFocusedPaneCyclesModesAndEscUnfocuses(t *testing.T) {
 }
 
 func TestDashboardFocusedPaneBracketsDoNotCycleModes(t *testing.T) {
-	m := newModel()
+	m := newModel("")
 	m.keys = NewKeyRegistry()
 	m.commands = NewCommandRegistry(m.keys, m.savedFilters)
 	m.ready = true
@@ -120,7 +120,7 @@ func TestDashboardFocusedPaneBracketsDoNotCycleModes(t *testing.T) {
 }
 
 func TestDashboardMonthStepWorksAtTopLevelWithoutFocusBootstrap(t *testing.T) {
-	m := newModel()
+	m := newModel("")
 	m.keys = NewKeyRegistry()
 	m.commands = NewCommandRegistry(m.keys, m.savedFilters)
 	m.ready = true
@@ -148,7 +148,7 @@ func TestDashboardMonthStepWorksAtTopLevelWithoutFocusBootstrap(t *testing.T) {
 }
 
 func TestDashboardResetThisMonthWorksAtTopLevelWithoutFocusBootstrap(t *testing.T) {
-	m := newModel()
+	m := newModel("")
 	m.keys = NewKeyRegistry()
 	m.commands = NewCommandRegistry(m.keys, m.savedFilters)
 	m.ready = true
@@ -179,7 +179,7 @@ func TestDashboardResetThisMonthWorksAtTopLevelWithoutFocusBootstrap(t *testing.
 }
 
 func TestDashboardDrillDownSetsManagerFilterAndDrillReturn(t *testing.T) {
-	m := newModel()
+	m := newModel("")
 	m.keys = NewKeyRegistry()
 	m.commands = NewCommandRegistry(m.keys, m.savedFilters)
 	m.ready = true
@@ -190,7 +190,7 @@ func TestDashboardDrillDownSetsManagerFilterAndDrillReturn(t *testing.T) {
 	m.dashWidgets = newDashboardWidgets(nil)
 	m.filterInput = "cat:Legacy"
 	m.reparseFilterInput()
-	m.rows = []transaction{{id: 1, dateISO: "2026-01-10", amount: -10, categoryName: "Groceries", description: "A"}}
+	m.rows = []transaction{{id: 1, dateISO: "2026-01-10", amount: -1000, categoryName: "Groceries", description: "A"}}
 	m.dashWidgets[0].activeMode = findWidgetModeIndexByID(m.dashWidgets[0], "spending")
 
 	next, _ := m.Update(keyMsg("enter"))
@@ -273,7 +273,7 @@ func TestManagerJumpActivateClearsDrillReturnContext(t *testing.T) {
 func TestDashboardCustomModeEditUsesSavedFilterPicker(t *testing.T) {
 	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
 
-	m := newModel()
+	m := newModel("")
 	m.keys = NewKeyRegistry()
 	m.commands = NewCommandRegistry(m.keys, m.savedFilters)
 	m.ready = true
@@ -312,7 +312,7 @@ func TestDashboardCustomModeEditUsesSavedFilterPicker(t *testing.T) {
 
 func drilledDashboardModel(t *testing.T) model {
 	t.Helper()
-	m := newModel()
+	m := newModel("")
 	m.keys = NewKeyRegistry()
 	m.commands = NewCommandRegistry(m.keys, m.savedFilters)
 	m.ready = true
@@ -323,7 +323,7 @@ func drilledDashboardModel(t *testing.T) model {
 	m.dashWidgets = newDashboardWidgets(nil)
 	m.filterInput = "cat:Legacy"
 	m.reparseFilterInput()
-	m.rows = []transaction{{id: 1, dateISO: "2026-01-10", amount: -10, categoryName: "Groceries", description: "A"}}
+	m.rows = []transaction{{id: 1, dateISO: "2026-01-10", amount: -1000, categoryName: "Groceries", description: "A"}}
 	m.dashWidgets[0].activeMode = findWidgetModeIndexByID(m.dashWidgets[0], "spending")
 
 	next, _ := m.Update(keyMsg("enter"))
@@ -15,6 +15,7 @@ func (m *model) closeDetail() {
 	m.detailEditing = ""
 	m.detailNotes = ""
 	m.detailNotesCursor = 0
+	m.detailHistory = nil
 }
 
 func (m *model) openDetail(txn transaction) {
@@ -29,6 +30,7 @@ func (m *model) openDetail(txn transaction) {
 	m.detailRow = txn
 	m.detailRowValid = true
 	m.detailNotes = txn.notes
+	m.detailHistory = nil
 	m.detailEditing = ""
 	m.detailCatCursor = 0
 	// Position category cursor at current category
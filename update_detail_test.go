@@ -13,8 +13,8 @@ func TestDetailIgnoresAllocationShortcut(t *testing.T) {
 	if _, err := db.Exec(`
 		INSERT INTO transactions (date_raw, date_iso, amount, description, notes, account_id)
 		VALUES
-			('10/01/2026','2026-01-10',30.00,'CREDIT','',?),
-			('12/01/2026','2026-01-12',-20.00,'DEBIT','',?)
+			('10/01/2026','2026-01-10',3000,'CREDIT','',?),
+			('12/01/2026','2026-01-12',-2000,'DEBIT','',?)
 	`, accountID, accountID); err != nil {
 		t.Fatalf("insert transactions: %v", err)
 	}
@@ -33,7 +33,7 @@ func TestDetailIgnoresAllocationShortcut(t *testing.T) {
 		t.Fatal("credit row not found")
 	}
 
-	m := newModel()
+	m := newModel("")
 	m.db = db
 	m.ready = true
 	m.rows = rows
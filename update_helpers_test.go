@@ -49,6 +49,26 @@ func TestSettingsConfirmSpecFor(t *testing.T) {
 				fallback: "c",
 			},
 		},
+		{
+			name:   "undo import",
+			action: confirmActionUndoImport,
+			wantOK: true,
+			wantSpec: settingsConfirmSpec{
+				scope:    scopeSettingsActiveImportHist,
+				action:   actionDelete,
+				fallback: "del",
+			},
+		},
+		{
+			name:   "restore backup",
+			action: confirmActionRestoreBackup,
+			wantOK: true,
+			wantSpec: settingsConfirmSpec{
+				scope:    scopeSettingsActiveBackups,
+				action:   actionRestoreBackup,
+				fallback: "r",
+			},
+		},
 		{name: "none", action: confirmActionNone, wantOK: false},
 	}
 
@@ -79,7 +99,8 @@ func TestMoveSettingsSectionByColumn(t *testing.T) {
 		{name: "left forward to filters", start: settSecRules, delta: 1, wantSec: settSecFilters},
 		{name: "left wrap backward", start: settSecCategories, delta: -1, wantSec: settSecFilters},
 		{name: "right forward", start: settSecChart, delta: 1, wantSec: settSecDBImport},
-		{name: "right wrap backward", start: settSecChart, delta: -1, wantSec: settSecImportHistory},
+		{name: "right forward to backups", start: settSecImportHistory, delta: 1, wantSec: settSecBackups},
+		{name: "right wrap backward", start: settSecChart, delta: -1, wantSec: settSecBackups},
 	}
 
 	for _, tt := range tests {
@@ -122,7 +143,7 @@ func TestASCIITextHelpers(t *testing.T) {
 }
 
 func TestSettingsEditModeHelpers(t *testing.T) {
-	m := newModel()
+	m := newModel("")
 	m.categories = []category{
 		{id: 1, name: "Income"},
 		{id: 2, name: "Groceries"},
@@ -167,7 +188,7 @@ func TestSettingsEditModeHelpers(t *testing.T) {
 }
 
 func TestBeginSettingsRuleMode(t *testing.T) {
-	m := newModel()
+	m := newModel("")
 	m.categories = []category{
 		{id: 1, name: "Income"},
 		{id: 2, name: "Groceries"},
@@ -237,7 +258,7 @@ func TestRowsPerPageDeltaFromKeyName(t *testing.T) {
 }
 
 func TestBeginImportFlowResetsState(t *testing.T) {
-	m := newModel()
+	m := newModel("")
 	m.basePath = "."
 	m.importPicking = false
 	m.importFiles = []string{"old.csv"}
@@ -2,6 +2,7 @@ package main
 
 import (
 	"fmt"
+	"path/filepath"
 	"sort"
 	"strings"
 
@@ -278,6 +279,18 @@ func (m model) updateManagerModal(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.managerEditActive = !m.managerEditActive
 		}
 		return m, nil
+	case m.isAction(scopeManagerModal, actionFormatWizard, msg):
+		name := strings.TrimSpace(m.managerEditName)
+		if name == "" {
+			m.setError("Account name cannot be empty.")
+			return m, nil
+		}
+		m.closeManagerAccountModal()
+		cmd := m.beginImportFlow()
+		m.importForWizard = true
+		m.importWizardAcct = name
+		m.setStatus("Pick a sample CSV for " + name + ".")
+		return m, cmd
 	case isBackspaceKey(msg):
 		if m.managerEditFocus == 0 {
 			deleteASCIIByteBeforeCursor(&m.managerEditName, &m.managerEditNameCur)
@@ -301,6 +314,7 @@ func (m model) updateManagerModal(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			return m, nil
 		}
 		db := m.db
+		profile := m.profile
 		isNew := m.managerModalIsNew
 		id := m.managerEditID
 		sourceName := strings.TrimSpace(m.managerEditSource)
@@ -319,15 +333,15 @@ func (m model) updateManagerModal(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 					return refreshDoneMsg{err: err}
 				}
 				if sourceName != "" && !strings.EqualFold(sourceName, name) {
-					if err := removeFormatForAccount(sourceName); err != nil {
+					if err := removeFormatForAccount(profile, sourceName); err != nil {
 						return refreshDoneMsg{err: err}
 					}
 				}
 			}
-			if err := upsertFormatForAccount(name, acctType); err != nil {
+			if err := upsertFormatForAccount(profile, name, acctType); err != nil {
 				return refreshDoneMsg{err: err}
 			}
-			formats, _, err := loadAppConfig()
+			formats, _, err := loadAppConfig(profile)
 			if err != nil {
 				return refreshDoneMsg{err: err}
 			}
@@ -336,7 +350,7 @@ func (m model) updateManagerModal(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 					formats[i].ImportPrefix = prefix
 				}
 			}
-			if err := saveFormats(formats); err != nil {
+			if err := saveFormats(profile, formats); err != nil {
 				return refreshDoneMsg{err: err}
 			}
 			return refreshCmd(db)()
@@ -373,6 +387,7 @@ func (m model) updateManagerActionPicker(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		accountID := m.managerActionAcctID
 		accountName := m.managerActionName
 		db := m.db
+		profile := m.profile
 		m.closeManagerActionPicker()
 		switch res.ItemID {
 		case managerAccountActionClear:
@@ -384,7 +399,7 @@ func (m model) updateManagerActionPicker(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			return m, func() tea.Msg {
 				n, err := nukeAccountWithTransactions(db, accountID)
 				if err == nil {
-					err = removeFormatForAccount(accountName)
+					err = removeFormatForAccount(profile, accountName)
 				}
 				return accountNukedMsg{accountName: accountName, deletedTxns: n, err: err}
 			}
@@ -406,8 +421,33 @@ func (m model) updateFilePicker(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		return m, tea.Quit
 	case m.verticalDelta(scopeFilePicker, msg) != 0:
 		m.importCursor = moveBoundedCursor(m.importCursor, len(m.importFiles), m.verticalDelta(scopeFilePicker, msg))
+		m.importFormatChoice = 0
+		return m, nil
+	case m.isAction(scopeFilePicker, actionLeft, msg) || m.isAction(scopeFilePicker, actionRight, msg):
+		if m.importCursor >= len(m.importFiles) {
+			return m, nil
+		}
+		candidates := m.importFileMatches[m.importFiles[m.importCursor]]
+		if len(candidates) < 2 {
+			return m, nil
+		}
+		delta := 1
+		if m.isAction(scopeFilePicker, actionLeft, msg) {
+			delta = -1
+		}
+		m.importFormatChoice = (m.importFormatChoice + delta + len(candidates)) % len(candidates)
 		return m, nil
+	case m.isAction(scopeFilePicker, actionFormatWizard, msg):
+		return m.openFormatWizardForCursor()
+	case m.isAction(scopeFilePicker, actionImportPending, msg):
+		if m.importForWizard {
+			return m, nil
+		}
+		return m, m.startImportQueue(m.importFiles)
 	case m.isAction(scopeFilePicker, actionSelect, msg):
+		if m.importForWizard {
+			return m.openFormatWizardForCursor()
+		}
 		if len(m.importFiles) == 0 || m.importCursor >= len(m.importFiles) {
 			m.setStatus("No file selected.")
 			return m, nil
@@ -419,7 +459,89 @@ func (m model) updateFilePicker(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		file := m.importFiles[m.importCursor]
 		m.importPicking = false
 		m.setStatus("Scanning for duplicates...")
-		return m, scanDupesCmd(m.db, file, m.basePath, m.formats, m.savedFilters)
+		if candidates := m.importFileMatches[file]; m.importFormatChoice < len(candidates) {
+			idx := candidates[m.importFormatChoice].formatIndex
+			if idx >= 0 && idx < len(m.formats) {
+				return m, scanDupesForFormatCmd(m.db, file, m.basePath, m.formats[idx], m.savedFilters, m.dupeMatch)
+			}
+		}
+		return m, scanDupesCmd(m.db, file, m.basePath, m.formats, m.savedFilters, m.dupeMatch)
+	}
+	return m, nil
+}
+
+// openFormatWizardForCursor opens the CSV format wizard on the file under
+// the picker cursor.
+func (m model) openFormatWizardForCursor() (tea.Model, tea.Cmd) {
+	if len(m.importFiles) == 0 || m.importCursor >= len(m.importFiles) {
+		m.setStatus("No file selected.")
+		return m, nil
+	}
+	file := m.importFiles[m.importCursor]
+	if !strings.EqualFold(filepath.Ext(file), ".csv") {
+		m.setError("The format wizard only supports CSV files.")
+		return m, nil
+	}
+	wizard, err := newFormatWizard(filepath.Join(m.basePath, file), m.importWizardAcct)
+	if err != nil {
+		m.setError(fmt.Sprintf("Format wizard: %v", err))
+		return m, nil
+	}
+	m.importPicking = false
+	m.importForWizard = false
+	m.importWizardAcct = ""
+	m.formatWizard = wizard
+	return m, nil
+}
+
+// updateFormatWizard handles keys in the CSV format wizard modal.
+func (m model) updateFormatWizard(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	w := m.formatWizard
+	keyName := normalizeKeyName(msg.String())
+	if w.focus == 1 {
+		switch keyName {
+		case "esc", "enter", "tab", "shift+tab":
+			// handled below
+		default:
+			if w.account.handleKey(keyName, msg.String()) {
+				return m, nil
+			}
+		}
+	}
+
+	switch {
+	case m.isAction(scopeFormatWizard, actionClose, msg):
+		m.formatWizard = nil
+		return m, nil
+	case keyName == "tab" || keyName == "shift+tab":
+		w.focus = 1 - w.focus
+		return m, nil
+	case m.isAction(scopeFormatWizard, actionLeft, msg):
+		w.cursorCol = max(0, w.cursorCol-1)
+		return m, nil
+	case m.isAction(scopeFormatWizard, actionRight, msg):
+		w.cursorCol = min(max(0, w.colCount-1), w.cursorCol+1)
+		return m, nil
+	case m.isAction(scopeFormatWizard, actionToggleSelect, msg):
+		if w.colCount > 0 {
+			w.cycleRole()
+		}
+		return m, nil
+	case m.isAction(scopeFormatWizard, actionWizardToggleHeader, msg):
+		w.toggleHeader()
+		return m, nil
+	case m.isAction(scopeFormatWizard, actionWizardCycleDelimiter, msg):
+		w.cycleDelimiter()
+		return m, nil
+	case m.isAction(scopeFormatWizard, actionSave, msg):
+		format, err := w.buildFormat()
+		if err != nil {
+			w.err = err.Error()
+			return m, nil
+		}
+		w.err = ""
+		m.setStatus("Saving format...")
+		return m, saveFormatWizardCmd(m.db, m.profile, w.account.Value, w.path, format)
 	}
 	return m, nil
 }
@@ -450,9 +572,37 @@ func (m model) updateImportPreview(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.importPreviewScroll = previewTopIndexForCursor(m.importPreviewCursor, total, visible)
 		}
 		return m, nil
+	case m.isAction(scopeImportPreview, actionToggleSelect, msg):
+		indices := importPreviewDisplayedIndices(snapshot, m.importPreviewShowAll)
+		if m.importPreviewCursor < 0 || m.importPreviewCursor >= len(indices) {
+			return m, nil
+		}
+		row := &snapshot.rows[indices[m.importPreviewCursor]]
+		if row.likelyDupe == nil {
+			m.setStatus("Only likely duplicates can be toggled.")
+			return m, nil
+		}
+		row.skipLikelyDupe = !row.skipLikelyDupe
+		if row.skipLikelyDupe {
+			m.setStatusf("Row %d marked as duplicate; skipped on import.", row.index)
+		} else {
+			m.setStatusf("Row %d marked as new; imported.", row.index)
+		}
+		return m, nil
+	case m.isAction(scopeImportPreview, actionQuarantineRejects, msg):
+		return m.toggleImportQuarantine()
+	case m.isAction(scopeImportPreview, actionQuickCategory, msg):
+		return m.openImportPreviewCategoryPicker()
+	case m.isAction(scopeImportPreview, actionQuickTag, msg):
+		return m.openImportPreviewTagPicker()
 	case m.isAction(scopeImportPreview, actionImportAll, msg):
-		if snapshot.errorCount > 0 {
-			m.setError("Import blocked: preview has parse/normalize errors.")
+		if snapshot.importBlocked() {
+			m.setError(importBlockedMessage(snapshot, m.primaryActionKey(scopeImportPreview, actionQuarantineRejects, "x")))
+			return m, nil
+		}
+		if snapshot.needsReimportConfirm() {
+			snapshot.reimportConfirmed = true
+			m.setError(reimportWarning(snapshot) + ". Press " + m.primaryActionKey(scopeImportPreview, actionImportAll, "a") + " again to import anyway.")
 			return m, nil
 		}
 		applyRules := m.importPreviewPostRules
@@ -464,8 +614,13 @@ func (m model) updateImportPreview(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.setStatus("Importing all snapshot rows...")
 		return m, ingestSnapshotCmd(m.db, snapshot, false, applyRules)
 	case m.isAction(scopeImportPreview, actionSkipDupes, msg):
-		if snapshot.errorCount > 0 {
-			m.setError("Import blocked: preview has parse/normalize errors.")
+		if snapshot.importBlocked() {
+			m.setError(importBlockedMessage(snapshot, m.primaryActionKey(scopeImportPreview, actionQuarantineRejects, "x")))
+			return m, nil
+		}
+		if snapshot.needsReimportConfirm() {
+			snapshot.reimportConfirmed = true
+			m.setError(reimportWarning(snapshot) + ". Press " + m.primaryActionKey(scopeImportPreview, actionSkipDupes, "s") + " again to import anyway.")
 			return m, nil
 		}
 		applyRules := m.importPreviewPostRules
@@ -484,6 +639,10 @@ func (m model) updateImportPreview(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.importPreviewScroll = 0
 		m.importPreviewSnapshot = nil
 		m.setStatus("Import cancelled.")
+		if m.importQueue != nil {
+			m.importQueue.skipped++
+			return m, m.nextQueuedImport()
+		}
 		return m, nil
 	case m.isAction(scopeGlobal, actionQuit, msg):
 		return m, tea.Quit
@@ -491,20 +650,308 @@ func (m model) updateImportPreview(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	return m, nil
 }
 
-func importPreviewDisplayedCount(snapshot *importPreviewSnapshot, showAll bool) int {
+// importPreviewPicking reports whether a category or tag picker is open on
+// a preview row; the picker then takes keys ahead of the preview.
+func (m model) importPreviewPicking() bool {
+	return m.importPreviewPickingCategory || m.importPreviewPickingTags
+}
+
+// importPreviewCursorRow returns the snapshot.rows index under the preview
+// cursor, or -1.
+func (m model) importPreviewCursorRow() int {
+	indices := importPreviewDisplayedIndices(m.importPreviewSnapshot, m.importPreviewShowAll)
+	if m.importPreviewCursor < 0 || m.importPreviewCursor >= len(indices) {
+		return -1
+	}
+	return indices[m.importPreviewCursor]
+}
+
+func (m model) openImportPreviewCategoryPicker() (tea.Model, tea.Cmd) {
+	idx := m.importPreviewCursorRow()
+	if idx < 0 {
+		m.setStatus("No preview row selected.")
+		return m, nil
+	}
+	if len(m.categories) == 0 {
+		m.setStatus("No categories available.")
+		return m, nil
+	}
+	current := m.importRowClassification(m.importPreviewSnapshot.rows[idx]).category
+	items := make([]pickerItem, 0, len(m.categories))
+	for _, c := range m.categories {
+		items = append(items, pickerItem{ID: c.id, Label: c.name, Color: c.color})
+	}
+	p := newPicker("Row Category", items, false, "")
+	p.cursorOnly = true
+	for i, item := range p.filtered {
+		if strings.EqualFold(item.Label, current) {
+			p.cursor = i
+			break
+		}
+	}
+	m.catPicker = p
+	m.importPreviewPickingCategory = true
+	m.importPreviewPickRow = idx
+	return m, nil
+}
+
+func (m model) openImportPreviewTagPicker() (tea.Model, tea.Cmd) {
+	idx := m.importPreviewCursorRow()
+	if idx < 0 {
+		m.setStatus("No preview row selected.")
+		return m, nil
+	}
+	if len(m.tags) == 0 {
+		m.setStatus("No tags available.")
+		return m, nil
+	}
+	current := m.importRowClassification(m.importPreviewSnapshot.rows[idx])
+	var catID *int
+	for _, c := range m.categories {
+		if strings.EqualFold(c.name, current.category) {
+			id := c.id
+			catID = &id
+			break
+		}
+	}
+	items := make([]pickerItem, 0, len(m.tags))
+	for _, tg := range m.tags {
+		section := "Global"
+		if tg.categoryID != nil {
+			if catID != nil && *tg.categoryID == *catID {
+				section = "Scoped"
+			} else {
+				section = "Unscoped"
+			}
+		}
+		items = append(items, pickerItem{ID: tg.id, Label: tg.name, Color: tg.color, Section: section})
+	}
+	p := newPicker("Row Tags", items, true, "")
+	p.cursorOnly = true
+	selected := make([]int, 0, len(current.tags))
+	for _, tg := range current.tags {
+		selected = append(selected, tg.id)
+	}
+	p.SetSelectedIDs(selected)
+	m.tagPicker = p
+	m.importPreviewPickingTags = true
+	m.importPreviewPickRow = idx
+	return m, nil
+}
+
+// importRowClass is what a preview row will import as.
+type importRowClass struct {
+	category string // "" for none
+	color    string
+	tags     []tag
+}
+
+// openRejectsReview opens the rejected-rows modal on the oldest reject.
+func (m *model) openRejectsReview() {
+	if len(m.importRejects) == 0 {
+		m.setStatus("No rejected rows to review.")
+		return
+	}
+	m.rejectsReview = &importRejectsReview{}
+	m.rejectsReview.load(m.importRejects[0])
+}
+
+// clampRejectsReview keeps the review cursor on a reject after a reload,
+// closing the modal once quarantine is empty. Edits in progress survive as
+// long as the same reject stays selected.
+func (m *model) clampRejectsReview() {
+	v := m.rejectsReview
+	if len(m.importRejects) == 0 {
+		m.rejectsReview = nil
+		return
+	}
+	v.cursor = min(max(v.cursor, 0), len(m.importRejects)-1)
+	if m.importRejects[v.cursor].id != v.rejectID {
+		v.load(m.importRejects[v.cursor])
+	}
+}
+
+// updateImportRejects handles keys in the rejected-rows modal. Typing edits
+// the focused field of the selected reject; up/down pick another reject.
+func (m model) updateImportRejects(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	v := m.rejectsReview
+	keyName := normalizeKeyName(msg.String())
+	if v.focusedField().handleKey(keyName, msg.String()) {
+		return m, nil
+	}
+	switch {
+	case m.isAction(scopeImportRejects, actionClose, msg):
+		m.rejectsReview = nil
+		return m, nil
+	case keyName == "tab":
+		v.focus = (v.focus + 1) % importRejectFieldCount
+		return m, nil
+	case keyName == "shift+tab":
+		v.focus = (v.focus - 1 + importRejectFieldCount) % importRejectFieldCount
+		return m, nil
+	case m.verticalDelta(scopeImportRejects, msg) != 0:
+		next := moveBoundedCursor(v.cursor, len(m.importRejects), m.verticalDelta(scopeImportRejects, msg))
+		if next != v.cursor {
+			v.cursor = next
+			v.load(m.importRejects[next])
+		}
+		return m, nil
+	}
+
+	if m.db == nil || v.cursor < 0 || v.cursor >= len(m.importRejects) {
+		return m, nil
+	}
+	r := m.importRejects[v.cursor]
+	switch {
+	case m.isAction(scopeImportRejects, actionSave, msg):
+		fix, err := parseRejectFix(r, v.date.Value, v.amount.Value, v.description.Value)
+		if err != nil {
+			v.err = err.Error()
+			return m, nil
+		}
+		v.err = ""
+		m.setStatus("Promoting rejected row...")
+		return m, promoteImportRejectCmd(m.db, r, fix, m.merchants.normalize(fix.description))
+	case m.isAction(scopeImportRejects, actionDelete, msg):
+		m.setStatus("Discarding rejected row...")
+		return m, discardImportRejectCmd(m.db, r)
+	}
+	return m, nil
+}
+
+// toggleImportQuarantine switches between blocking on parse errors and
+// importing the good rows while quarantining the bad ones.
+func (m model) toggleImportQuarantine() (model, tea.Cmd) {
+	snapshot := m.importPreviewSnapshot
 	if snapshot == nil {
-		return 0
+		return m, nil
+	}
+	if !snapshot.canQuarantine() {
+		m.setError("Nothing to quarantine: only rows that fail to parse can be set aside.")
+		return m, nil
 	}
-	if showAll {
-		return len(snapshot.rows)
+	snapshot.quarantine = !snapshot.quarantine
+	if snapshot.quarantine {
+		m.setStatusf("%d bad rows will be quarantined for review; the rest import as usual.", len(snapshot.parseErrors))
+	} else {
+		m.setStatus("Quarantine off: parse errors block the import.")
 	}
-	dupeCount := 0
-	for _, row := range snapshot.rows {
-		if row.isDupe {
-			dupeCount++
+	return m, nil
+}
+
+func importBlockedMessage(snapshot *importPreviewSnapshot, quarantineKey string) string {
+	if snapshot.canQuarantine() {
+		return "Import blocked: preview has parse/normalize errors. Press " + quarantineKey + " to quarantine them and import the rest."
+	}
+	return "Import blocked: preview has parse/normalize errors."
+}
+
+// reimportWarning summarises why a snapshot looks like a repeat import.
+func reimportWarning(snapshot *importPreviewSnapshot) string {
+	if len(snapshot.sameFileImports) > 0 {
+		prev := snapshot.sameFileImports[0]
+		return fmt.Sprintf("This file was already imported as %s on %s", prev.filename, prev.importedAt)
+	}
+	prev := snapshot.coveringImports[0]
+	return fmt.Sprintf("%s already covered %s to %s for this account", prev.filename, prev.dateFrom, prev.dateTo)
+}
+
+// importRowClassification is the category and tags a preview row imports
+// with as things stand: the hand edits if any, else the rules projection
+// (rules on) or the source category (rules off).
+func (m model) importRowClassification(row importPreviewRow) importRowClass {
+	if row.edited {
+		return importRowClass{category: row.editCategory, color: row.editCatColor, tags: row.editTags}
+	}
+	if !m.importPreviewPostRules {
+		return importRowClass{category: strings.TrimSpace(row.categoryName)}
+	}
+	out := importRowClass{category: strings.TrimSpace(row.previewCat), color: row.previewCatColor}
+	if strings.EqualFold(out.category, "Uncategorised") {
+		out.category, out.color = "", ""
+	}
+	out.tags = append([]tag(nil), row.previewTagObjs...)
+	return out
+}
+
+// markImportRowEdited pins a preview row's current classification so a
+// later pick of only its category (or only its tags) keeps the other half.
+func (m model) markImportRowEdited(row *importPreviewRow) {
+	if row.edited {
+		return
+	}
+	class := m.importRowClassification(*row)
+	row.edited = true
+	row.editCategory = class.category
+	row.editCatColor = class.color
+	row.editTags = class.tags
+}
+
+func (m model) updateImportPreviewCategoryPick(res pickerResult) (tea.Model, tea.Cmd) {
+	switch res.Action {
+	case pickerActionCancelled:
+		m.catPicker = nil
+		m.importPreviewPickingCategory = false
+		return m, nil
+	case pickerActionSelected:
+		m.catPicker = nil
+		m.importPreviewPickingCategory = false
+		snapshot := m.importPreviewSnapshot
+		if snapshot == nil || m.importPreviewPickRow < 0 || m.importPreviewPickRow >= len(snapshot.rows) {
+			return m, nil
+		}
+		row := &snapshot.rows[m.importPreviewPickRow]
+		m.markImportRowEdited(row)
+		row.editCategory = res.ItemLabel
+		row.editCatColor = ""
+		for _, c := range m.categories {
+			if c.id == res.ItemID {
+				row.editCatColor = c.color
+				break
+			}
+		}
+		m.setStatusf("Row %d will import as %s.", row.index, res.ItemLabel)
+		return m, nil
+	}
+	return m, nil
+}
+
+func (m model) updateImportPreviewTagPick(res pickerResult) (tea.Model, tea.Cmd) {
+	switch res.Action {
+	case pickerActionCancelled:
+		m.tagPicker = nil
+		m.importPreviewPickingTags = false
+		return m, nil
+	case pickerActionSubmitted:
+		m.tagPicker = nil
+		m.importPreviewPickingTags = false
+		snapshot := m.importPreviewSnapshot
+		if snapshot == nil || m.importPreviewPickRow < 0 || m.importPreviewPickRow >= len(snapshot.rows) {
+			return m, nil
 		}
+		row := &snapshot.rows[m.importPreviewPickRow]
+		m.markImportRowEdited(row)
+		selected := make(map[int]bool, len(res.SelectedIDs))
+		for _, id := range res.SelectedIDs {
+			selected[id] = true
+		}
+		row.editTags = row.editTags[:0:0]
+		for _, tg := range m.tags {
+			if selected[tg.id] {
+				row.editTags = append(row.editTags, tg)
+			}
+		}
+		m.setStatusf("Row %d will import with %d tags.", row.index, len(row.editTags))
+		return m, nil
+	}
+	return m, nil
+}
+
+func importPreviewDisplayedCount(snapshot *importPreviewSnapshot, showAll bool) int {
+	if snapshot == nil {
+		return 0
 	}
-	return dupeCount
+	return len(importPreviewDisplayedIndices(snapshot, showAll))
 }
 
 func previewTopIndexForCursor(cursor, total, visible int) int {
@@ -1,6 +1,8 @@
 package main
 
 import (
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -18,7 +20,7 @@ func typeDashboardInput(t *testing.T, m model, s string) model {
 func TestDashboardSelectPresetFlow(t *testing.T) {
 	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
 
-	m := newModel()
+	m := newModel("")
 	m.activeTab = tabDashboard
 	m.ready = true
 	m.dashTimeframe = dashTimeframeThisMonth
@@ -72,7 +74,7 @@ func TestDashboardSelectPresetFlow(t *testing.T) {
 func TestDashboardCustomTimeframeFlow(t *testing.T) {
 	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
 
-	m := newModel()
+	m := newModel("")
 	m.activeTab = tabDashboard
 	m.ready = true
 	m.dashTimeframeFocus = true
@@ -115,7 +117,7 @@ func TestDashboardCustomTimeframeFlow(t *testing.T) {
 }
 
 func TestDashboardCustomTimeframeRejectsInvalidRange(t *testing.T) {
-	m := newModel()
+	m := newModel("")
 	m.activeTab = tabDashboard
 	m.ready = true
 	m.dashCustomEditing = true
@@ -137,7 +139,7 @@ func TestDashboardCustomTimeframeRejectsInvalidRange(t *testing.T) {
 }
 
 func TestDashboardMonthJumpSnapsAndEnablesMonthMode(t *testing.T) {
-	m := newModel()
+	m := newModel("")
 	m.activeTab = tabDashboard
 	m.ready = true
 	m.dashTimeframeFocus = true
@@ -162,7 +164,7 @@ func TestDashboardMonthJumpSnapsAndEnablesMonthMode(t *testing.T) {
 }
 
 func TestDashboardDatePaneResetToThisMonth(t *testing.T) {
-	m := newModel()
+	m := newModel("")
 	m.activeTab = tabDashboard
 	m.ready = true
 	m.dashTimeframeFocus = true
@@ -188,7 +190,7 @@ func TestDashboardDatePaneResetToThisMonth(t *testing.T) {
 func TestDetailAndSearchFlows(t *testing.T) {
 	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
 
-	m := newModel()
+	m := newModel("")
 	m.ready = true
 	catID := 1
 	txn := transaction{id: 42, categoryID: &catID, notes: "seed"}
@@ -252,7 +254,7 @@ func TestDetailAndSearchFlows(t *testing.T) {
 }
 
 func TestManagerAndImportPreviewActionFlows(t *testing.T) {
-	m := newModel()
+	m := newModel("")
 	m.ready = true
 	m.activeTab = tabManager
 	m.managerMode = managerModeAccounts
@@ -271,7 +273,7 @@ func TestManagerAndImportPreviewActionFlows(t *testing.T) {
 		totalRows: 1,
 		newCount:  1,
 		rows: []importPreviewRow{
-			{index: 1, sourceLine: 1, dateRaw: "3/02/2026", dateISO: "2026-02-03", amount: -12.34, description: "sample"},
+			{index: 1, sourceLine: 1, dateRaw: "3/02/2026", dateISO: "2026-02-03", amount: -1234, description: "sample"},
 		},
 	}
 	next, cmd := got.updateImportPreview(keyMsg("a"))
@@ -301,7 +303,7 @@ func TestManagerAndImportPreviewActionFlows(t *testing.T) {
 }
 
 func TestImportPreviewMsgOpensForZeroDupes(t *testing.T) {
-	m := newModel()
+	m := newModel("")
 	m.ready = true
 
 	next, _ := m.Update(importPreviewMsg{
@@ -311,7 +313,7 @@ func TestImportPreviewMsgOpensForZeroDupes(t *testing.T) {
 			newCount:  1,
 			dupeCount: 0,
 			rows: []importPreviewRow{
-				{index: 1, sourceLine: 1, dateRaw: "3/02/2026", dateISO: "2026-02-03", amount: -20, description: "ZERO"},
+				{index: 1, sourceLine: 1, dateRaw: "3/02/2026", dateISO: "2026-02-03", amount: -2000, description: "ZERO"},
 			},
 		},
 	})
@@ -328,14 +330,14 @@ func TestImportPreviewMsgOpensForZeroDupes(t *testing.T) {
 }
 
 func TestImportPreviewEscCancelsFromCompact(t *testing.T) {
-	m := newModel()
+	m := newModel("")
 	m.ready = true
 	m.importPreviewOpen = true
 	m.importPreviewSnapshot = &importPreviewSnapshot{
 		fileName:  "ANZ-esc.csv",
 		totalRows: 1,
 		rows: []importPreviewRow{
-			{index: 1, sourceLine: 1, dateRaw: "3/02/2026", dateISO: "2026-02-03", amount: -20, description: "ESC"},
+			{index: 1, sourceLine: 1, dateRaw: "3/02/2026", dateISO: "2026-02-03", amount: -2000, description: "ESC"},
 		},
 	}
 
@@ -350,7 +352,7 @@ func TestImportPreviewEscCancelsFromCompact(t *testing.T) {
 }
 
 func TestImportPreviewBlocksDecisionsWhenParseErrorsExist(t *testing.T) {
-	m := newModel()
+	m := newModel("")
 	m.ready = true
 	m.importPreviewOpen = true
 	m.importPreviewSnapshot = &importPreviewSnapshot{
@@ -359,7 +361,7 @@ func TestImportPreviewBlocksDecisionsWhenParseErrorsExist(t *testing.T) {
 		errorCount:  1,
 		parseErrors: []importPreviewParseError{{rowIndex: 2, sourceLine: 2, field: "date", message: "invalid date"}},
 		rows: []importPreviewRow{
-			{index: 1, sourceLine: 1, dateRaw: "3/02/2026", dateISO: "2026-02-03", amount: -20, description: "VALID"},
+			{index: 1, sourceLine: 1, dateRaw: "3/02/2026", dateISO: "2026-02-03", amount: -2000, description: "VALID"},
 		},
 	}
 
@@ -377,7 +379,7 @@ func TestImportPreviewBlocksDecisionsWhenParseErrorsExist(t *testing.T) {
 }
 
 func TestJumpModeToggleKeyClosesOverlay(t *testing.T) {
-	m := newModel()
+	m := newModel("")
 	m.ready = true
 	m.activeTab = tabManager
 	m.focusedSection = sectionManagerTransactions
@@ -414,7 +416,7 @@ func TestFilterReservedJumpTargetKeysExcludesV(t *testing.T) {
 }
 
 func TestSettingsJumpTargetsIncludeCategoriesAndTags(t *testing.T) {
-	m := newModel()
+	m := newModel("")
 	m.ready = true
 	m.activeTab = tabSettings
 
@@ -464,7 +466,7 @@ func TestSettingsJumpTargetsIncludeCategoriesAndTags(t *testing.T) {
 }
 
 func TestSettingsJumpKeyFocusesCategories(t *testing.T) {
-	m := newModel()
+	m := newModel("")
 	m.ready = true
 	m.activeTab = tabSettings
 	m.jumpModeActive = true
@@ -493,7 +495,7 @@ func TestSettingsJumpKeyFocusesCategories(t *testing.T) {
 }
 
 func TestManagerJumpTargetsActivateSections(t *testing.T) {
-	m := newModel()
+	m := newModel("")
 	m.ready = true
 	m.activeTab = tabManager
 
@@ -509,7 +511,7 @@ func TestManagerJumpTargetsActivateSections(t *testing.T) {
 }
 
 func TestSlashFromManagerAccountsSwitchesToTransactionsAndOpensFilter(t *testing.T) {
-	m := newModel()
+	m := newModel("")
 	m.ready = true
 	m.activeTab = tabManager
 	m.managerMode = managerModeAccounts
@@ -530,7 +532,7 @@ func TestSlashFromManagerAccountsSwitchesToTransactionsAndOpensFilter(t *testing
 }
 
 func TestManagerAccountsUseHorizontalNavigationWithoutWrap(t *testing.T) {
-	m := newModel()
+	m := newModel("")
 	m.activeTab = tabManager
 	m.managerMode = managerModeAccounts
 	m.accounts = []account{
@@ -559,7 +561,7 @@ func TestManagerAccountsUseHorizontalNavigationWithoutWrap(t *testing.T) {
 }
 
 func TestManagerModalTextInputShieldsShortcutKeys(t *testing.T) {
-	m := newModel()
+	m := newModel("")
 	m.openManagerAccountModal(true, nil)
 	m.managerEditFocus = 0 // name field
 
@@ -595,7 +597,7 @@ func TestManagerModalTextInputShieldsShortcutKeys(t *testing.T) {
 }
 
 func TestFilterInputCursorUsesArrowKeysAndTreatsHLAsText(t *testing.T) {
-	m := newModel()
+	m := newModel("")
 	m.activeTab = tabManager
 	m.filterInputMode = true
 	m.filterInput = "abc"
@@ -625,7 +627,7 @@ func TestFilterInputCursorUsesArrowKeysAndTreatsHLAsText(t *testing.T) {
 }
 
 func TestManagerAccountStripScopeLabelSimplified(t *testing.T) {
-	m := newModel()
+	m := newModel("")
 	m.accounts = []account{
 		{id: 1, name: "ANZ", acctType: "credit", txnCount: 3},
 		{id: 2, name: "CBA", acctType: "debit", txnCount: 0},
@@ -645,7 +647,7 @@ func TestManagerAccountStripScopeLabelSimplified(t *testing.T) {
 func TestFilterSaveRequiresAppliedExpression(t *testing.T) {
 	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
 
-	m := newModel()
+	m := newModel("")
 	m.filterInputMode = true
 	m.filterInput = "cat:Groceries"
 	m.filterInputCursor = len(m.filterInput)
@@ -693,7 +695,7 @@ func TestFilterSaveRequiresAppliedExpression(t *testing.T) {
 }
 
 func TestFilterEnterReturnsToTransactionsNavigation(t *testing.T) {
-	m := newModel()
+	m := newModel("")
 	m.activeTab = tabManager
 	m.managerMode = managerModeTransactions
 	m.rows = testTransactions()
@@ -717,7 +719,7 @@ func TestFilterEnterReturnsToTransactionsNavigation(t *testing.T) {
 }
 
 func TestFilterInputAllowsColonAndPreventsGlobalShortcutLeak(t *testing.T) {
-	m := newModel()
+	m := newModel("")
 	m.filterInputMode = true
 	m.activeTab = tabManager
 
@@ -759,7 +761,7 @@ func TestEscInTransactionsDoesNotClearAccountScope(t *testing.T) {
 		t.Fatalf("insert account B: %v", err)
 	}
 
-	m := newModel()
+	m := newModel("")
 	m.db = db
 	m.ready = true
 	m.activeTab = tabManager
@@ -796,7 +798,7 @@ func TestEscInTransactionsDoesNotClearAccountScope(t *testing.T) {
 }
 
 func TestDeleteInAccountsOpensActionModal(t *testing.T) {
-	m := newModel()
+	m := newModel("")
 	m.activeTab = tabManager
 	m.managerMode = managerModeAccounts
 	m.accounts = []account{{id: 1, name: "ANZ", acctType: "debit", txnCount: 2}}
@@ -807,3 +809,91 @@ func TestDeleteInAccountsOpensActionModal(t *testing.T) {
 		t.Fatal("expected manager action picker to open")
 	}
 }
+
+func TestImportPreviewRowEditsImportAsPicked(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	db, cleanup := testDB(t)
+	defer cleanup()
+
+	if _, err := insertAccount(db, "ANZ", "debit", true); err != nil {
+		t.Fatalf("insertAccount: %v", err)
+	}
+	format := testANZFormat()
+	format.Account = "ANZ"
+	format.ImportPrefix = "anz"
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "anz-feb.csv"), []byte("3/02/2026,-20.00,DAN MURPHYS\n4/02/2026,-8.00,BAKERY\n"), 0o644); err != nil {
+		t.Fatalf("write csv: %v", err)
+	}
+	preview, ok := scanDupesCmd(db, "anz-feb.csv", dir, []csvFormat{format}, nil, defaultSettings().dupeMatch())().(importPreviewMsg)
+	if !ok || preview.err != nil {
+		t.Fatalf("scanDupesCmd: ok=%v err=%v", ok, preview.err)
+	}
+	tagID, err := insertTag(db, "party", "#94e2d5", nil)
+	if err != nil {
+		t.Fatalf("insertTag: %v", err)
+	}
+
+	m := newModel("")
+	m.ready = true
+	m.db = db
+	if m.categories, err = loadCategories(db); err != nil {
+		t.Fatalf("loadCategories: %v", err)
+	}
+	if m.tags, err = loadTags(db); err != nil {
+		t.Fatalf("loadTags: %v", err)
+	}
+	next, _ := m.handleImportPreview(preview)
+	m = next.(model)
+	m.importPreviewShowAll = true
+	press := func(keys ...string) {
+		t.Helper()
+		for _, k := range keys {
+			next, _ := m.Update(keyMsg(k))
+			m = next.(model)
+		}
+	}
+
+	press("c")
+	if m.catPicker == nil || !m.importPreviewPickingCategory {
+		t.Fatal("expected the category picker over the preview")
+	}
+	press("G", "r", "o", "c", "enter")
+	row := m.importPreviewSnapshot.rows[0]
+	if !row.edited || row.editCategory != "Groceries" || m.catPicker != nil || !m.importPreviewOpen {
+		t.Fatalf("row after category pick = %+v (picker open=%v)", row, m.catPicker != nil)
+	}
+	press("t", "p", "a", "r", "t", "y", "space", "enter")
+	row = m.importPreviewSnapshot.rows[0]
+	if len(row.editTags) != 1 || row.editTags[0].id != tagID || row.editCategory != "Groceries" {
+		t.Fatalf("row after tag pick = %+v", row)
+	}
+	if !strings.Contains(m.View(), "Edited:") {
+		t.Fatal("preview summary should count edited rows")
+	}
+
+	done, ok := ingestSnapshotCmd(db, m.importPreviewSnapshot, true, true)().(ingestDoneMsg)
+	if !ok || done.err != nil || done.count != 2 {
+		t.Fatalf("ingest: ok=%v done=%+v", ok, done)
+	}
+	rows, err := loadRows(db)
+	if err != nil {
+		t.Fatalf("loadRows: %v", err)
+	}
+	txnTags, err := loadTransactionTags(db)
+	if err != nil {
+		t.Fatalf("loadTransactionTags: %v", err)
+	}
+	for _, r := range rows {
+		switch r.description {
+		case "DAN MURPHYS":
+			if r.categoryName != "Groceries" || len(txnTags[r.id]) != 1 || txnTags[r.id][0].id != tagID {
+				t.Fatalf("edited row imported as %q with tags %+v", r.categoryName, txnTags[r.id])
+			}
+		case "BAKERY":
+			if r.categoryID != nil || len(txnTags[r.id]) != 0 {
+				t.Fatalf("untouched row imported as %q with tags %+v", r.categoryName, txnTags[r.id])
+			}
+		}
+	}
+}
@@ -17,7 +17,10 @@ func settSectionForColumn(col, row int) int {
 		if row == 1 {
 			return settSecDBImport
 		}
-		return settSecImportHistory
+		if row == 2 {
+			return settSecImportHistory
+		}
+		return settSecBackups
 	}
 	// Left column: row 0 = Categories, row 1 = Tags, row 2 = Rules, row 3 = Filters.
 	if row <= 0 {
@@ -49,6 +52,8 @@ func settColumnRow(sec int) (int, int) {
 		return settColRight, 1
 	case settSecImportHistory:
 		return settColRight, 2
+	case settSecBackups:
+		return settColRight, 3
 	}
 	return settColLeft, 0
 }
@@ -69,6 +74,8 @@ func settingsActiveScope(section int) string {
 		return scopeSettingsActiveDBImport
 	case settSecImportHistory:
 		return scopeSettingsActiveImportHist
+	case settSecBackups:
+		return scopeSettingsActiveBackups
 	default:
 		return scopeSettingsActiveCategories
 	}
@@ -84,6 +91,8 @@ func settingsFocusSectionForSettSection(section int) int {
 		return sectionSettingsRules
 	case settSecImportHistory:
 		return sectionSettingsImportHistory
+	case settSecBackups:
+		return sectionSettingsBackups
 	case settSecFilters:
 		return sectionSettingsFilters
 	case settSecDBImport:
@@ -177,6 +186,48 @@ func (m model) updateSettingsActive(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	case settSecDBImport:
 		return m.updateSettingsDBImport(msg)
 	case settSecImportHistory:
+		return m.updateSettingsImportHistory(msg)
+	case settSecBackups:
+		return m.updateSettingsBackups(msg)
+	}
+	return m, nil
+}
+
+func (m model) updateSettingsBackups(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case m.verticalDelta(scopeSettingsActiveBackups, msg) != 0:
+		m.settItemCursor = moveBoundedCursor(m.settItemCursor, len(m.backups), m.verticalDelta(scopeSettingsActiveBackups, msg))
+		return m, nil
+	case m.isAction(scopeSettingsActiveBackups, actionRestoreBackup, msg):
+		if m.db == nil || m.settItemCursor < 0 || m.settItemCursor >= len(m.backups) {
+			return m, nil
+		}
+		b := m.backups[m.settItemCursor]
+		keyLabel := m.primaryActionKey(scopeSettingsActiveBackups, actionRestoreBackup, "r")
+		prompt := fmt.Sprintf("Restore %s snapshot from %s? Current data is snapshotted first. Press %s again to confirm",
+			b.reason, b.createdAt.Format("2006-01-02 15:04"), keyLabel)
+		return m, m.armSettingsConfirm(confirmActionRestoreBackup, m.settItemCursor, prompt)
+	}
+	return m, nil
+}
+
+func (m model) updateSettingsImportHistory(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch {
+	case m.verticalDelta(scopeSettingsActiveImportHist, msg) != 0:
+		m.settItemCursor = moveBoundedCursor(m.settItemCursor, len(m.imports), m.verticalDelta(scopeSettingsActiveImportHist, msg))
+		return m, nil
+	case m.isAction(scopeSettingsActiveImportHist, actionDelete, msg):
+		if m.db == nil || m.settItemCursor < 0 || m.settItemCursor >= len(m.imports) {
+			return m, nil
+		}
+		db := m.db
+		importID := m.imports[m.settItemCursor].id
+		return m, func() tea.Msg {
+			summary, err := loadImportUndoSummary(db, importID)
+			return importUndoSummaryMsg{summary: summary, err: err}
+		}
+	case m.isAction(scopeSettingsActiveImportHist, actionReviewRejects, msg):
+		m.openRejectsReview()
 		return m, nil
 	}
 	return m, nil
@@ -326,12 +377,12 @@ func (m model) updateSettingsDBImport(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		if delta > 0 && m.maxVisibleRows < 50 {
 			m.maxVisibleRows++
 			m.setStatusf("Rows per page: %d", m.maxVisibleRows)
-			return m, saveSettingsCmd(m.currentAppSettings())
+			return m, saveSettingsCmd(m.profile, m.currentAppSettings())
 		}
 		if delta < 0 && m.maxVisibleRows > 5 {
 			m.maxVisibleRows--
 			m.setStatusf("Rows per page: %d", m.maxVisibleRows)
-			return m, saveSettingsCmd(m.currentAppSettings())
+			return m, saveSettingsCmd(m.profile, m.currentAppSettings())
 		}
 		return m, nil
 	case m.isAction(scopeSettingsActiveDBImport, actionCommandDefault, msg):
@@ -341,7 +392,7 @@ func (m model) updateSettingsDBImport(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.commandDefault = commandUIKindColon
 		}
 		m.setStatusf("Command default: %s", commandDefaultLabel(m.commandDefault))
-		return m, saveSettingsCmd(m.currentAppSettings())
+		return m, saveSettingsCmd(m.profile, m.currentAppSettings())
 	}
 	return m, nil
 }
@@ -356,7 +407,7 @@ func (m model) updateSettingsChart(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.spendingWeekAnchor = time.Monday
 		}
 		m.setStatusf("Spending tracker week boundary: %s", spendingWeekAnchorLabel(m.spendingWeekAnchor))
-		return m, saveSettingsCmd(m.currentAppSettings())
+		return m, saveSettingsCmd(m.profile, m.currentAppSettings())
 	}
 	return m, nil
 }
@@ -400,6 +451,25 @@ func (m model) updateSettingsConfirm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		next.setStatusf("Deleted filter %q.", filterID)
 		return next, nil
+	case confirmActionUndoImport:
+		filename := ""
+		for _, imp := range m.imports {
+			if imp.id == id {
+				filename = imp.filename
+			}
+		}
+		m.setStatus("Undoing import...")
+		return m, func() tea.Msg {
+			removed, err := deleteImport(db, id)
+			return importUndoneMsg{filename: filename, removed: removed, err: err}
+		}
+	case confirmActionRestoreBackup:
+		if id < 0 || id >= len(m.backups) {
+			return m, nil
+		}
+		b := m.backups[id]
+		m.setStatusf("Restoring %s...", b.name)
+		return m, restoreBackupCmd(db, b)
 	case confirmActionClearDB:
 		m.setStatus("Clearing database...")
 		return m, func() tea.Msg {
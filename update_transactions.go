@@ -3,7 +3,6 @@ package main
 import (
 	"database/sql"
 	"fmt"
-	"math"
 	"sort"
 	"strconv"
 	"strings"
@@ -189,7 +188,7 @@ func (m model) openAllocationAmountModal(filtered []transaction) (tea.Model, tea
 	row := filtered[m.cursor]
 	parentID := row.id
 	editID := 0
-	defaultAmount := math.Abs(row.amount)
+	defaultAmount := row.amount.abs()
 	if row.isAllocation {
 		parentID = row.parentTxnID
 		editID = row.allocationID
@@ -208,7 +207,7 @@ func (m model) openAllocationAmountModal(filtered []transaction) (tea.Model, tea
 	m.allocationEditID = editID
 	m.allocationModalFocus = 0
 	if defaultAmount > 0 {
-		m.allocationAmount = fmt.Sprintf("%.2f", defaultAmount)
+		m.allocationAmount = defaultAmount.String()
 		m.allocationAmountCur = len(m.allocationAmount)
 	} else {
 		m.allocationAmount = ""
@@ -253,6 +252,9 @@ func (m model) updateCatPicker(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	res := m.catPicker.HandleMsg(msg, func(action Action, in tea.KeyMsg) bool {
 		return m.isAction(scopeCategoryPicker, action, in)
 	})
+	if m.importPreviewPickingCategory {
+		return m.updateImportPreviewCategoryPick(res)
+	}
 	if m.ruleEditorPickingCategory {
 		switch res.Action {
 		case pickerActionCancelled:
@@ -304,6 +306,12 @@ func (m model) updateTagPicker(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	if m.tagPicker == nil {
 		return m, nil
 	}
+	if m.importPreviewPickingTags {
+		res := m.tagPicker.HandleMsg(msg, func(action Action, in tea.KeyMsg) bool {
+			return m.isAction(scopeTagPicker, action, in)
+		})
+		return m.updateImportPreviewTagPick(res)
+	}
 	if m.ruleEditorPickingTags {
 		if m.isAction(scopeTagPicker, actionSelect, msg) {
 			row := m.tagPicker.currentRow()
@@ -485,61 +493,78 @@ func splitRowTargets(rowIDs []int) (txnIDs []int, allocationIDs []int) {
 	return txnIDs, allocationIDs
 }
 
+// applyCategoryToRowTargets sets the category on a mixed selection of
+// transactions and allocations as one journaled edit, so a single undo
+// reverses the whole selection.
 func applyCategoryToRowTargets(db *sql.DB, rowIDs []int, categoryID *int) (int, error) {
 	txnIDs, allocationIDs := splitRowTargets(rowIDs)
 	affected := 0
-	if len(txnIDs) > 0 {
-		n, err := updateTransactionsCategory(db, txnIDs, categoryID)
-		if err != nil {
-			return 0, err
+	err := editLedger(db, "set category", ledgerScope{txnIDs: txnIDs, allocationIDs: allocationIDs}, func(tx *sql.Tx) error {
+		if len(txnIDs) > 0 {
+			n, err := updateTransactionsCategoryTx(tx, txnIDs, categoryID)
+			if err != nil {
+				return err
+			}
+			affected += n
 		}
-		affected += n
-	}
-	for _, allocationID := range allocationIDs {
-		if err := updateTransactionAllocationCategory(db, allocationID, categoryID); err != nil {
-			return 0, err
+		for _, allocationID := range allocationIDs {
+			if err := updateTransactionAllocationCategoryTx(tx, allocationID, categoryID); err != nil {
+				return err
+			}
+			affected++
 		}
-		affected++
+		return nil
+	})
+	if err != nil {
+		return 0, err
 	}
 	return affected, nil
 }
 
 func addTagsToRowTargets(db *sql.DB, rowIDs, tagIDs []int) (int, error) {
 	txnIDs, allocationIDs := splitRowTargets(rowIDs)
+	if len(tagIDs) == 0 || len(txnIDs)+len(allocationIDs) == 0 {
+		return 0, nil
+	}
 	affected := 0
-	if len(txnIDs) > 0 {
-		n, err := addTagsToTransactions(db, txnIDs, tagIDs)
+	err := editLedger(db, "add tags", ledgerScope{txnIDs: txnIDs, allocationIDs: allocationIDs}, func(tx *sql.Tx) error {
+		n, err := addTagsToTransactionsTx(tx, txnIDs, tagIDs)
 		if err != nil {
-			return 0, err
+			return err
 		}
-		affected += n
-	}
-	if len(allocationIDs) > 0 {
-		n, err := addTagsToAllocations(db, allocationIDs, tagIDs)
+		m, err := addTagsToAllocationsTx(tx, allocationIDs, tagIDs)
 		if err != nil {
-			return 0, err
+			return err
 		}
-		affected += n
+		affected = n + m
+		return nil
+	})
+	if err != nil {
+		return 0, err
 	}
 	return affected, nil
 }
 
 func removeTagFromRowTargets(db *sql.DB, rowIDs []int, tagID int) (int, error) {
 	txnIDs, allocationIDs := splitRowTargets(rowIDs)
+	if tagID <= 0 || len(txnIDs)+len(allocationIDs) == 0 {
+		return 0, nil
+	}
 	affected := 0
-	if len(txnIDs) > 0 {
-		n, err := removeTagFromTransactions(db, txnIDs, tagID)
+	err := editLedger(db, "remove tag", ledgerScope{txnIDs: txnIDs, allocationIDs: allocationIDs}, func(tx *sql.Tx) error {
+		n, err := removeTagFromTransactionsTx(tx, txnIDs, tagID)
 		if err != nil {
-			return 0, err
+			return err
 		}
-		affected += n
-	}
-	if len(allocationIDs) > 0 {
-		n, err := removeTagFromAllocations(db, allocationIDs, tagID)
+		m, err := removeTagFromAllocationsTx(tx, allocationIDs, tagID)
 		if err != nil {
-			return 0, err
+			return err
 		}
-		affected += n
+		affected = n + m
+		return nil
+	})
+	if err != nil {
+		return 0, err
 	}
 	return affected, nil
 }
@@ -600,7 +625,8 @@ func (m model) updateAllocationAmountModal(msg tea.KeyMsg) (tea.Model, tea.Cmd)
 			m.setError("Database not ready.")
 			return m, nil
 		}
-		amount, err := strconv.ParseFloat(strings.TrimSpace(m.allocationAmount), 64)
+		value, err := strconv.ParseFloat(strings.TrimSpace(m.allocationAmount), 64)
+		amount := moneyFromFloat(value)
 		if err != nil || amount <= 0 {
 			m.setError("Invalid allocation amount.")
 			return m, nil
@@ -7,11 +7,14 @@ import (
 	"path/filepath"
 )
 
-func runStartupHarness(out io.Writer) error {
-	m := newModel()
+// runStartupHarness builds the startup model, with dbFlag as --db, and
+// reports its status.
+func runStartupHarness(profile, dbFlag string, out io.Writer) error {
+	m := newModel(profile).withDBOverride(dbFlag)
 	if out != nil {
 		fmt.Fprintf(out, "startup_status_err=%t\n", m.statusErr)
 		fmt.Fprintf(out, "startup_status=%s\n", m.status)
+		fmt.Fprintf(out, "startup_db=%s\n", m.dbPath)
 	}
 	if m.statusErr {
 		if m.status == "" {
@@ -23,13 +26,20 @@ func runStartupHarness(out io.Writer) error {
 }
 
 // runValidation executes a non-TUI validation path using a temporary DB and CSV.
-func runValidation() error {
+// With dbFlag (--db) it also checks that database opens and migrates.
+func runValidation(profile, dbFlag string) error {
 	dir, err := os.MkdirTemp("", "jaskmoney-validate-")
 	if err != nil {
 		return fmt.Errorf("create temp dir: %w", err)
 	}
 	defer os.RemoveAll(dir)
 
+	if dbFlag != "" {
+		if err := validateDatabaseCopy(expandHomePath(dbFlag), dir); err != nil {
+			return err
+		}
+	}
+
 	dbPath := filepath.Join(dir, "validate.db")
 	db, err := openDB(dbPath)
 	if err != nil {
@@ -37,7 +47,7 @@ func runValidation() error {
 	}
 	defer db.Close()
 
-	formats, err := loadFormats()
+	formats, err := loadFormats(profile)
 	if err != nil {
 		return fmt.Errorf("load formats: %w", err)
 	}
@@ -91,3 +101,28 @@ func runValidation() error {
 	}
 	return nil
 }
+
+// validateDatabaseCopy opens a copy of the database at path in dir, so the
+// migration and integrity check never write to the ledger itself.
+func validateDatabaseCopy(path, dir string) error {
+	if !fileExists(path) {
+		return fmt.Errorf("database %s does not exist", path)
+	}
+	copyPath := filepath.Join(dir, "ledger-copy.db")
+	if err := copyFile(path, copyPath); err != nil {
+		return fmt.Errorf("copy database: %w", err)
+	}
+	db, err := openDB(copyPath)
+	if err != nil {
+		return fmt.Errorf("open %s: %w", path, err)
+	}
+	defer db.Close()
+	var check string
+	if err := db.QueryRow(`PRAGMA quick_check`).Scan(&check); err != nil {
+		return fmt.Errorf("check %s: %w", path, err)
+	}
+	if check != "ok" {
+		return fmt.Errorf("database %s is corrupt: %s", path, check)
+	}
+	return nil
+}
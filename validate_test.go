@@ -13,7 +13,7 @@ func TestRunStartupHarnessOK(t *testing.T) {
 	t.Setenv("XDG_CONFIG_HOME", xdg)
 
 	var out bytes.Buffer
-	err := runStartupHarness(&out)
+	err := runStartupHarness("", "", &out)
 	if err != nil {
 		t.Fatalf("runStartupHarness: %v", err)
 	}
@@ -41,7 +41,7 @@ confirm_repeatz = ["ctrl+r"]
 	}
 
 	var out bytes.Buffer
-	err := runStartupHarness(&out)
+	err := runStartupHarness("", "", &out)
 	if err != nil {
 		t.Fatalf("expected startup harness to recover by resetting defaults, got: %v\noutput:\n%s", err, out.String())
 	}
@@ -70,7 +70,7 @@ cancel_any = ["ctrl+x"]
 	}
 
 	var out bytes.Buffer
-	err := runStartupHarness(&out)
+	err := runStartupHarness("", "", &out)
 	if err != nil {
 		t.Fatalf("expected startup to reset invalid legacy aliases, got: %v\noutput:\n%s", err, out.String())
 	}
@@ -82,7 +82,33 @@ cancel_any = ["ctrl+x"]
 
 func TestRunValidationOK(t *testing.T) {
 	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
-	if err := runValidation(); err != nil {
+	if err := runValidation("", ""); err != nil {
 		t.Fatalf("runValidation: %v", err)
 	}
 }
+
+func TestRunValidationAndStartupHarnessHonourDBFlag(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	path := filepath.Join(t.TempDir(), "ledger.db")
+	db, err := openDB(path)
+	if err != nil {
+		t.Fatalf("openDB: %v", err)
+	}
+	db.Close()
+
+	if err := runValidation("", path); err != nil {
+		t.Fatalf("runValidation with --db: %v", err)
+	}
+	missing := filepath.Join(t.TempDir(), "missing.db")
+	if err := runValidation("", missing); err == nil || !strings.Contains(err.Error(), "does not exist") {
+		t.Fatalf("runValidation with a missing --db = %v, want does not exist", err)
+	}
+
+	var out bytes.Buffer
+	if err := runStartupHarness("", missing, &out); err != nil {
+		t.Fatalf("runStartupHarness: %v", err)
+	}
+	if !strings.Contains(out.String(), "startup_db="+missing) {
+		t.Fatalf("startup harness ignored --db:\n%s", out.String())
+	}
+}